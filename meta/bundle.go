@@ -114,6 +114,21 @@ func (b *Bundle) OperationsMetas() []xdr.OperationMeta {
 	}
 }
 
+// TransactionChanges returns every change produced by the application of the
+// transaction, excluding the changes recorded in the fee meta, in the order
+// they were applied.
+func (b *Bundle) TransactionChanges() (ret []xdr.LedgerEntryChange) {
+	if b.TransactionMeta.V > 0 {
+		ret = append(ret, b.TransactionMeta.V1.TxChanges...)
+	}
+
+	for _, op := range b.OperationsMetas() {
+		ret = append(ret, op.Changes...)
+	}
+
+	return
+}
+
 //filterChanges takes a LedgerEntryChange slice and filters out changes that don't match the given target ledger key
 func filterChanges(changes []xdr.LedgerEntryChange, target xdr.LedgerKey) (filteredChanges []xdr.LedgerEntryChange) {
 	for _, change := range changes {
@@ -76,6 +76,17 @@ var _ = Describe("meta.Bundle", func() {
 		})
 	})
 
+	Describe("TransactionChanges", func() {
+		It("excludes the fee meta", func() {
+			changes := createAccount.TransactionChanges()
+			Expect(changes).To(HaveLen(2))
+			Expect(changes[0].Type).To(Equal(xdr.LedgerEntryChangeTypeLedgerEntryCreated))
+
+			key := changes[0].LedgerKey()
+			Expect(key.Equals(newAccount.LedgerKey())).To(BeTrue())
+		})
+	})
+
 	Describe("StateBefore", func() {
 		Context("Accounts", func() {
 			It("return nil when the account was created in the operation", func() {
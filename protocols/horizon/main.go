@@ -88,6 +88,20 @@ func (this *Account) GetData(key string) ([]byte, error) {
 	return base64.StdEncoding.DecodeString(this.Data[key])
 }
 
+// AccountBatchEntry represents the result of looking up a single account of a
+// batch of accounts.  `Account` is only populated if the account was found.
+type AccountBatchEntry struct {
+	ID      string   `json:"id"`
+	PT      string   `json:"paging_token"`
+	Found   bool     `json:"found"`
+	Account *Account `json:"account,omitempty"`
+}
+
+// PagingToken implementation for hal.Pageable
+func (res AccountBatchEntry) PagingToken() string {
+	return res.PT
+}
+
 // AccountFlags represents the state of an account's flags
 type AccountFlags struct {
 	AuthRequired  bool `json:"auth_required"`
@@ -106,6 +120,25 @@ type AccountThresholds struct {
 // Asset represents a single asset
 type Asset base.Asset
 
+// AssetDailyStat represents the statistics of a single Asset on a given day
+type AssetDailyStat struct {
+	PT                       string `json:"paging_token"`
+	Day                      string `json:"day"`
+	Amount                   string `json:"amount"`
+	NumAccounts              int32  `json:"num_accounts"`
+	NumUnauthorizedAccounts  int32  `json:"num_unauthorized_accounts"`
+	UnauthorizedAmount       string `json:"unauthorized_amount"`
+	AmountInOffers           string `json:"amount_in_offers"`
+	NumHoldersAboveThreshold int32  `json:"num_holders_above_threshold"`
+	PaymentCount             int32  `json:"payment_count"`
+	PaymentVolume            string `json:"payment_volume"`
+}
+
+// PagingToken implementation for hal.Pageable
+func (res AssetDailyStat) PagingToken() string {
+	return res.PT
+}
+
 // AssetStat represents the statistics for a single Asset
 type AssetStat struct {
 	Links struct {
@@ -113,10 +146,16 @@ type AssetStat struct {
 	} `json:"_links"`
 
 	base.Asset
-	PT          string       `json:"paging_token"`
-	Amount      string       `json:"amount"`
-	NumAccounts int32        `json:"num_accounts"`
-	Flags       AccountFlags `json:"flags"`
+	PT                       string       `json:"paging_token"`
+	Amount                   string       `json:"amount"`
+	NumAccounts              int32        `json:"num_accounts"`
+	NumUnauthorizedAccounts  int32        `json:"num_unauthorized_accounts"`
+	UnauthorizedAmount       string       `json:"unauthorized_amount"`
+	AmountInOffers           string       `json:"amount_in_offers"`
+	NumHoldersAboveThreshold int32        `json:"num_holders_above_threshold"`
+	PaymentCount24h          int32        `json:"payment_count_24h"`
+	PaymentVolume24h         string       `json:"payment_volume_24h"`
+	Flags                    AccountFlags `json:"flags"`
 }
 
 // PagingToken implementation for hal.Pageable
@@ -133,6 +172,28 @@ type Balance struct {
 	base.Asset
 }
 
+// Health represents the result of the checks horizon performs to determine
+// whether it is able to serve requests, as reported by the `/health` and
+// `/ready` endpoints.
+type Health struct {
+	Links struct {
+		Self hal.Link `json:"self"`
+	} `json:"_links"`
+
+	Status            string                 `json:"status"`
+	HistoryLatest     int32                  `json:"history_latest_ledger"`
+	CoreLatest        int32                  `json:"core_latest_ledger"`
+	LedgersBehindCore int32                  `json:"ledgers_behind_core"`
+	CoreState         string                 `json:"core_state,omitempty"`
+	Checks            map[string]HealthCheck `json:"checks"`
+}
+
+// HealthCheck represents the result of a single check of a Health resource.
+type HealthCheck struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
 // HistoryAccount is a simple resource, used for the account collection actions.
 // It provides only the "TotalOrderID" of the account and its account id.
 type HistoryAccount struct {
@@ -165,12 +226,40 @@ type Ledger struct {
 	MaxTxSetSize     int32     `json:"max_tx_set_size"`
 	ProtocolVersion  int32     `json:"protocol_version"`
 	HeaderXDR        string    `json:"header_xdr"`
+	HistoryFiltered  bool      `json:"history_filtered,omitempty"`
 }
 
 func (this Ledger) PagingToken() string {
 	return this.PT
 }
 
+// Market represents the trading activity of an asset pair over the last 24
+// hours, along with the best prices of its order book.  Prices are in units of
+// the counter asset per unit of the base asset.
+type Market struct {
+	PT                 string `json:"paging_token"`
+	BaseAssetType      string `json:"base_asset_type"`
+	BaseAssetCode      string `json:"base_asset_code,omitempty"`
+	BaseAssetIssuer    string `json:"base_asset_issuer,omitempty"`
+	CounterAssetType   string `json:"counter_asset_type"`
+	CounterAssetCode   string `json:"counter_asset_code,omitempty"`
+	CounterAssetIssuer string `json:"counter_asset_issuer,omitempty"`
+	TradeCount         int64  `json:"trade_count"`
+	BaseVolume         string `json:"base_volume"`
+	CounterVolume      string `json:"counter_volume"`
+	Open               string `json:"open"`
+	High               string `json:"high"`
+	Low                string `json:"low"`
+	Close              string `json:"close"`
+	BestBid            string `json:"best_bid,omitempty"`
+	BestAsk            string `json:"best_ask,omitempty"`
+}
+
+// PagingToken implementation for hal.Pageable
+func (res Market) PagingToken() string {
+	return res.PT
+}
+
 // Offer is the display form of an offer to trade currency.
 type Offer struct {
 	Links struct {
@@ -188,18 +277,73 @@ type Offer struct {
 	Price              string     `json:"price"`
 	LastModifiedLedger int32      `json:"last_modified_ledger"`
 	LastModifiedTime   *time.Time `json:"last_modified_time"`
+	State              string     `json:"state,omitempty"`
 }
 
 func (this Offer) PagingToken() string {
 	return this.PT
 }
 
+// OfferEvent is the display form of a single change made to an offer.
+type OfferEvent struct {
+	Links struct {
+		Offer     hal.Link `json:"offer"`
+		Operation hal.Link `json:"operation"`
+	} `json:"_links"`
+
+	ID         string     `json:"id"`
+	PT         string     `json:"paging_token"`
+	OfferID    int64      `json:"offer_id"`
+	Type       string     `json:"type"`
+	Seller     string     `json:"seller"`
+	Selling    Asset      `json:"selling"`
+	Buying     Asset      `json:"buying"`
+	Amount     string     `json:"amount"`
+	PriceR     Price      `json:"price_r"`
+	Price      string     `json:"price"`
+	Ledger     int32      `json:"ledger"`
+	LedgerTime *time.Time `json:"ledger_time"`
+}
+
+func (this OfferEvent) PagingToken() string {
+	return this.PT
+}
+
 // OrderBookSummary represents a snapshot summary of a given order book
 type OrderBookSummary struct {
-	Bids    []PriceLevel `json:"bids"`
-	Asks    []PriceLevel `json:"asks"`
-	Selling Asset        `json:"base"`
-	Buying  Asset        `json:"counter"`
+	Bids    []PriceLevel   `json:"bids"`
+	Asks    []PriceLevel   `json:"asks"`
+	Selling Asset          `json:"base"`
+	Buying  Asset          `json:"counter"`
+	Stats   OrderBookStats `json:"stats"`
+}
+
+// OrderBookStats represents the depth and spread statistics of an order book.
+// Bid amounts are denominated in the counter asset, ask amounts in the base
+// asset.
+type OrderBookStats struct {
+	BestBid        string `json:"best_bid,omitempty"`
+	BestAsk        string `json:"best_ask,omitempty"`
+	MidPrice       string `json:"mid_price,omitempty"`
+	Spread         string `json:"spread,omitempty"`
+	BidDepth       string `json:"bid_depth"`
+	AskDepth       string `json:"ask_depth"`
+	DepthPercent   string `json:"depth_percent,omitempty"`
+	BidDepthWithin string `json:"bid_depth_within,omitempty"`
+	AskDepthWithin string `json:"ask_depth_within,omitempty"`
+}
+
+// OrderBookDiff represents the changes made to an order book since the last
+// event of an order book stream.  A price level whose amount is zero was
+// removed.  When a side has more price levels than the limit of the stream,
+// its TruncatedAt field is the price of the last level sent: the levels beyond
+// it are not reported.
+type OrderBookDiff struct {
+	Bids            []PriceLevel   `json:"bids"`
+	Asks            []PriceLevel   `json:"asks"`
+	BidsTruncatedAt string         `json:"bids_truncated_at,omitempty"`
+	AsksTruncatedAt string         `json:"asks_truncated_at,omitempty"`
+	Stats           OrderBookStats `json:"stats"`
 }
 
 // Path represents a single payment path.
@@ -225,9 +369,10 @@ type Price base.Price
 
 // PriceLevel represents an aggregation of offers that share a given price
 type PriceLevel struct {
-	PriceR Price  `json:"price_r"`
-	Price  string `json:"price"`
-	Amount string `json:"amount"`
+	PriceR           Price  `json:"price_r"`
+	Price            string `json:"price"`
+	Amount           string `json:"amount"`
+	CumulativeAmount string `json:"cumulative_amount,omitempty"`
 }
 
 // Root is the initial map of links into the api.
@@ -251,6 +396,7 @@ type Root struct {
 	CoreSequence         int32  `json:"core_latest_ledger"`
 	NetworkPassphrase    string `json:"network_passphrase"`
 	ProtocolVersion      int32  `json:"protocol_version"`
+	HistoryFiltered      bool   `json:"history_filtered"`
 }
 
 // Signer represents one of an account's signers.
@@ -327,9 +473,12 @@ type TradeEffect struct {
 type TradeAggregation struct {
 	Timestamp     int64     `json:"timestamp"`
 	TradeCount    int64     `json:"trade_count"`
+	BuyCount      int64     `json:"buy_count"`
+	SellCount     int64     `json:"sell_count"`
 	BaseVolume    string    `json:"base_volume"`
 	CounterVolume string    `json:"counter_volume"`
 	Average       string    `json:"avg"`
+	VWAP          string    `json:"vwap"`
 	High          string    `json:"high"`
 	HighR         xdr.Price `json:"high_r"`
 	Low           string    `json:"low"`
@@ -418,6 +567,63 @@ type TransactionSuccess struct {
 	Meta   string `json:"result_meta_xdr"`
 }
 
+// Webhook represents a webhook registered to be notified of the payments and
+// effects of a set of accounts.  Secret, which signs the deliveries, is only
+// returned when the webhook is registered.
+type Webhook struct {
+	Links struct {
+		Self        hal.Link `json:"self"`
+		DeadLetters hal.Link `json:"dead_letters"`
+	} `json:"_links"`
+
+	ID         string      `json:"id"`
+	PT         string      `json:"paging_token"`
+	URL        string      `json:"url"`
+	Secret     string      `json:"secret,omitempty"`
+	Accounts   []string    `json:"accounts"`
+	EventTypes []string    `json:"event_types"`
+	Asset      *base.Asset `json:"asset,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// PagingToken implementation for hal.Pageable
+func (res Webhook) PagingToken() string {
+	return res.PT
+}
+
+// WebhookDeadLetter represents an event that could not be delivered to a
+// webhook.
+type WebhookDeadLetter struct {
+	ID        string          `json:"id"`
+	PT        string          `json:"paging_token"`
+	WebhookID string          `json:"webhook_id"`
+	Event     json.RawMessage `json:"event"`
+	Attempts  int32           `json:"attempts"`
+	LastError string          `json:"last_error"`
+	FailedAt  time.Time       `json:"failed_at"`
+}
+
+// PagingToken implementation for hal.Pageable
+func (res WebhookDeadLetter) PagingToken() string {
+	return res.PT
+}
+
+// WebhookEvent is the body of the requests delivering events to webhooks.
+// Record is the payment or effect resource, as served by the `/payments` and
+// `/effects` endpoints.
+type WebhookEvent struct {
+	ID        string          `json:"id"`
+	WebhookID string          `json:"webhook_id"`
+	Type      string          `json:"type"`
+	Record    json.RawMessage `json:"record"`
+}
+
+// WebhookReplay represents the result of replaying the dead letters of a
+// webhook.
+type WebhookReplay struct {
+	Replayed int64 `json:"replayed"`
+}
+
 // KeyTypeFromAddress converts the version byte of the provided strkey encoded
 // value (for example an account id or a signer key) and returns the appropriate
 // horizon-specific type name.
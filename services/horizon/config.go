@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	stdLog "log"
+	"net/url"
+	"os"
+	"os/signal"
+	"regexp"
+	"sort"
+	"strings"
+	"syscall"
+
+	sconfig "github.com/lomocoin/stellar-go/support/config"
+	"github.com/lomocoin/stellar-go/support/errors"
+	"github.com/lomocoin/stellar-go/support/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// configFile is the TOML config file set by --config-file.  Its keys are the
+// names of the command line flags, which take precedence over it along with
+// the environment variables.  It is only decoded to be validated: viper reads
+// the values.  Every key is optional in the file, since the flags and the
+// environment may set it instead: newConfig checks that the required settings,
+// such as db-url, stellar-core-db-url, stellar-core-url and network-passphrase,
+// are set once the three sources are merged.
+type configFile struct {
+	Port                      int    `toml:"port" valid:"optional"`
+	DatabaseURL               string `toml:"db-url" valid:"optional"`
+	StellarCoreDatabaseURL    string `toml:"stellar-core-db-url" valid:"optional"`
+	DatabaseReplicaURLs       string `toml:"db-replica-urls" valid:"optional"`
+	StellarCoreReplicaURLs    string `toml:"stellar-core-db-replica-urls" valid:"optional"`
+	StellarCoreURL            string `toml:"stellar-core-url" valid:"optional"`
+	PerHourRateLimit          int    `toml:"per-hour-rate-limit" valid:"optional"`
+	MaxDBConnections          int    `toml:"max-db-connections" valid:"optional"`
+	SSEUpdateFrequency        int    `toml:"sse-update-frequency" valid:"optional"`
+	ConnectionTimeout         int    `toml:"connection-timeout" valid:"optional"`
+	ShutdownTimeout           int    `toml:"shutdown-timeout" valid:"optional"`
+	RateLimitRedisKey         string `toml:"rate-limit-redis-key" valid:"optional"`
+	RedisURL                  string `toml:"redis-url" valid:"optional"`
+	APIKeysFile               string `toml:"api-keys-file" valid:"optional"`
+	ResponseCacheSize         int    `toml:"response-cache-size" valid:"optional"`
+	ResponseCacheRedis        bool   `toml:"response-cache-redis" valid:"optional"`
+	ResponseCacheMaxAge       int    `toml:"response-cache-max-age" valid:"optional"`
+	FriendbotURL              string `toml:"friendbot-url" valid:"optional"`
+	LogLevel                  string `toml:"log-level" valid:"optional"`
+	LogFile                   string `toml:"log-file" valid:"optional"`
+	SentryDSN                 string `toml:"sentry-dsn" valid:"optional"`
+	LogglyToken               string `toml:"loggly-token" valid:"optional"`
+	LogglyTag                 string `toml:"loggly-tag" valid:"optional"`
+	TLSCert                   string `toml:"tls-cert" valid:"optional"`
+	TLSKey                    string `toml:"tls-key" valid:"optional"`
+	Ingest                    bool   `toml:"ingest" valid:"optional"`
+	NetworkPassphrase         string `toml:"network-passphrase" valid:"optional"`
+	HistoryRetentionCount     uint   `toml:"history-retention-count" valid:"optional"`
+	ReaperArchiveURL          string `toml:"reaper-archive-url" valid:"optional"`
+	HistoryStaleThreshold     uint   `toml:"history-stale-threshold" valid:"optional"`
+	SkipCursorUpdate          bool   `toml:"skip-cursor-update" valid:"optional"`
+	EnableAssetStats          bool   `toml:"enable-asset-stats" valid:"optional"`
+	AssetStatsHolderThreshold string `toml:"asset-stats-holder-threshold" valid:"optional"`
+	EnableWebhooks            bool   `toml:"enable-webhooks" valid:"optional"`
+	WebhookMaxAttempts        int    `toml:"webhook-max-attempts" valid:"optional"`
+	GraphQLMaxCost            int    `toml:"graphql-max-cost" valid:"optional"`
+	IngestAccountFilter       string `toml:"ingest-account-filter" valid:"optional"`
+	IngestAssetFilter         string `toml:"ingest-asset-filter" valid:"optional"`
+	MaxPathLength             uint   `toml:"max-path-length" valid:"optional"`
+	PathFinder                string `toml:"path-finder" valid:"optional"`
+	PathFinderTimeout         int    `toml:"path-finder-timeout" valid:"optional"`
+	PathFinderMaxResults      uint   `toml:"path-finder-max-results" valid:"optional"`
+}
+
+// redacted replaces the secrets printed by `horizon config check`.
+const redacted = "REDACTED"
+
+// secretParam matches the password parameter of connection urls and the
+// signature and credential parameters of presigned urls, such as those of S3.
+var secretParam = regexp.MustCompile(
+	`((?:^|[?&\s])(?i:password|x-amz-signature|x-amz-credential|x-amz-security-token|signature|awsaccesskeyid)=)[^&\s]*`,
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config [command]",
+	Short: "commands to inspect horizon's configuration",
+}
+
+var configCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "validates the configuration and prints it, secrets redacted",
+	Long:  "check validates the configuration set by the command line flags, the environment variables and the config file, then prints the resulting settings as a config file with the passwords of the database and redis urls, the sentry dsn and the loggly token redacted",
+	Run: func(cmd *cobra.Command, args []string) {
+		_, err := newConfig()
+		if err != nil {
+			stdLog.Fatal(err)
+		}
+
+		printConfig(os.Stdout)
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configCheckCmd)
+}
+
+// readConfigFile validates the config file, if any, and loads it into viper.
+func readConfigFile() error {
+	path := viper.GetString("config-file")
+	if path == "" {
+		return nil
+	}
+
+	var file configFile
+	err := sconfig.Read(path, &file)
+	if err != nil {
+		switch cause := errors.Cause(err).(type) {
+		case *sconfig.InvalidConfigError:
+			fields := make([]string, 0, len(cause.InvalidFields))
+			for field, message := range cause.InvalidFields {
+				fields = append(fields, field+": "+message)
+			}
+			sort.Strings(fields)
+			return errors.Errorf("Invalid config file %s: %s", path, strings.Join(fields, ", "))
+		default:
+			return errors.Wrapf(err, "Invalid config file %s", path)
+		}
+	}
+
+	viper.SetConfigFile(path)
+	viper.SetConfigType("toml")
+	return errors.Wrapf(viper.ReadInConfig(), "Invalid config file %s", path)
+}
+
+// printConfig prints the value of every flag, as set by the command line, the
+// environment or the config file, in the format of the config file.
+func printConfig(w io.Writer) {
+	fmt.Fprintln(w, "# effective horizon config, secrets redacted")
+	rootCmd.PersistentFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Name == "config-file" {
+			return
+		}
+
+		switch f.Value.Type() {
+		case "string":
+			fmt.Fprintf(w, "%s = %q\n", f.Name, redact(f.Name, viper.GetString(f.Name)))
+		case "bool":
+			fmt.Fprintf(w, "%s = %t\n", f.Name, viper.GetBool(f.Name))
+		default:
+			fmt.Fprintf(w, "%s = %d\n", f.Name, viper.GetInt(f.Name))
+		}
+	})
+}
+
+// redact hides the secrets held by the value of the `name` setting.
+func redact(name, value string) string {
+	if value == "" {
+		return value
+	}
+
+	switch name {
+	case "sentry-dsn", "loggly-token":
+		return redacted
+	case "db-url", "stellar-core-db-url", "redis-url",
+		"stellar-core-url", "friendbot-url", "reaper-archive-url":
+		return redactURL(value)
+	case "db-replica-urls", "stellar-core-db-replica-urls":
+		urls := splitList(value)
+		for i := range urls {
+			urls[i] = redactURL(urls[i])
+		}
+		return strings.Join(urls, ",")
+	}
+
+	return value
+}
+
+// redactURL hides the password of a url, set either in its user info or as a
+// `password` parameter, and its signature and credential parameters.
+func redactURL(value string) string {
+	value = secretParam.ReplaceAllString(value, "${1}"+redacted)
+
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return value
+	}
+
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redacted)
+	}
+	return u.String()
+}
+
+// reloadOnSIGHUP reloads the config of the app, re-reading the config file,
+// whenever horizon receives a SIGHUP.  Only the settings applied by
+// App.Reload change: the others require a restart.
+func reloadOnSIGHUP() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	for range signals {
+		log.Info("received SIGHUP, reloading config")
+
+		c, err := newConfig()
+		if err != nil {
+			log.WithField("err", err).Error("invalid config, keeping the current one")
+			continue
+		}
+
+		app.Reload(c)
+	}
+}
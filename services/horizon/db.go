@@ -6,10 +6,13 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/apikey"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/schema"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/ingest"
 	"github.com/lomocoin/stellar-go/support/db"
@@ -69,8 +72,9 @@ var dbInitAssetStatsCmd = &cobra.Command{
 		}
 
 		assetStats := ingest.AssetStats{
-			CoreSession:    cdb,
-			HistorySession: hdb,
+			CoreSession:     cdb,
+			HistorySession:  hdb,
+			HolderThreshold: config.AssetHolderThreshold,
 		}
 
 		log.Println("Getting assets from core DB...")
@@ -107,6 +111,52 @@ var dbClearCmd = &cobra.Command{
 	},
 }
 
+var dbCreateAPIKeyCmd = &cobra.Command{
+	Use:   "create-api-key [NAME] [PER_HOUR_RATE_LIMIT] [BURST] [ROUTES]",
+	Short: "creates an api key",
+	Long:  "create-api-key stores a new random api key named NAME, rate limited to PER_HOUR_RATE_LIMIT requests per hour (0, the default, lifts the limit) with a burst of BURST requests and restricted to the comma separated ROUTES, if any, in horizon's db.  The key is printed once: only its hash is stored.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 || len(args) > 4 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+
+		row := history.APIKey{Name: args[0]}
+		limits := []*int{&row.PerHourRateLimit, &row.Burst}
+		for i, arg := range args[1:] {
+			if i == len(limits) {
+				row.Routes = strings.Join(apikey.SplitRoutes(arg), ",")
+				break
+			}
+
+			limit, err := strconv.Atoi(arg)
+			if err != nil || limit < 0 {
+				log.Fatalf("invalid limit %q", arg)
+			}
+			*limits[i] = limit
+		}
+
+		token, err := apikey.NewToken()
+		if err != nil {
+			log.Fatal(err)
+		}
+		row.KeyHash = apikey.Hash(token)
+
+		hdb, err := db.Open("postgres", viper.GetString("db-url"))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		q := &history.Q{Session: hdb}
+		err = q.InsertAPIKey(row)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println(token)
+	},
+}
+
 var dbInitCmd = &cobra.Command{
 	Use:   "init",
 	Short: "install schema",
@@ -179,6 +229,42 @@ var dbReapCmd = &cobra.Command{
 	},
 }
 
+var dbRestoreRangeCmd = &cobra.Command{
+	Use:   "restore-range [START_LEDGER] [END_LEDGER]",
+	Short: "restores reaped history for a range of ledgers from the reaper archive",
+	Long:  "restore-range loads every archived segment overlapping the ledgers START_LEDGER through END_LEDGER (inclusive) from the archive configured with --reaper-archive-url back into horizon's db",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+
+		start, err := strconv.ParseInt(args[0], 10, 32)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		end, err := strconv.ParseInt(args[1], 10, 32)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if start > end {
+			log.Fatal("START_LEDGER must not be greater than END_LEDGER")
+		}
+
+		initApp(cmd, args)
+		hlog.DefaultLogger.Logger.Level = config.LogLevel
+
+		count, err := app.RestoreHistory(int32(start), int32(end))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Println(fmt.Sprintf("Restored %d segments", count))
+	},
+}
+
 var dbRebaseCmd = &cobra.Command{
 	Use:   "rebase",
 	Short: "rebases clears the horizon db and ingests the latest ledger segment from stellar-core",
@@ -247,6 +333,7 @@ var dbReingestCmd = &cobra.Command{
 }
 
 func init() {
+	dbCmd.AddCommand(dbCreateAPIKeyCmd)
 	dbCmd.AddCommand(dbInitCmd)
 	dbCmd.AddCommand(dbInitAssetStatsCmd)
 	dbCmd.AddCommand(dbBackfillCmd)
@@ -255,6 +342,7 @@ func init() {
 	dbCmd.AddCommand(dbReapCmd)
 	dbCmd.AddCommand(dbReingestCmd)
 	dbCmd.AddCommand(dbRebaseCmd)
+	dbCmd.AddCommand(dbRestoreRangeCmd)
 }
 
 func ingestSystem(ingestConfig ingest.Config) *ingest.System {
@@ -268,6 +356,10 @@ func ingestSystem(ingestConfig ingest.Config) *ingest.System {
 		log.Fatal(err)
 	}
 
+	// history is always ingested using the configured filter, such that
+	// reingesting or backfilling ledgers records the same history
+	ingestConfig.Filter = config.IngestFilter
+
 	passphrase := viper.GetString("network-passphrase")
 	if passphrase == "" {
 		log.Fatal("network-passphrase is blank: reingestion requires manually setting passphrase")
@@ -1,19 +1,25 @@
 package horizon
 
 import (
+	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/lomocoin/stellar-go/services/horizon/internal/actions"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/apikey"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/core"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/httpx"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/ledger"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/render/export"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/render/problem"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/toid"
 	"github.com/lomocoin/stellar-go/support/errors"
 	"github.com/lomocoin/stellar-go/support/log"
+	"github.com/lomocoin/stellar-go/support/render/hal"
 )
 
 // Action is the "base type" for all actions in horizon.  It provides
@@ -32,29 +38,45 @@ type Action struct {
 }
 
 // CoreQ provides access to queries that access the stellar core database.
+// Read-only requests query a read replica, when one is fresh enough.
 func (action *Action) CoreQ() *core.Q {
 	if action.cq == nil {
-		action.cq = &core.Q{Session: action.App.CoreSession(action.R.Context())}
+		if action.isReadOnly() {
+			action.cq = &core.Q{Session: action.App.CoreReadSession(action.R.Context())}
+		} else {
+			action.cq = &core.Q{Session: action.App.CoreSession(action.R.Context())}
+		}
 	}
 
 	return action.cq
 }
 
 // HistoryQ provides access to queries that access the history portion of
-// horizon's database.
+// horizon's database.  Read-only requests query a read replica, when one is
+// fresh enough.
 func (action *Action) HistoryQ() *history.Q {
 	if action.hq == nil {
-		action.hq = &history.Q{Session: action.App.HorizonSession(action.R.Context())}
+		if action.isReadOnly() {
+			action.hq = &history.Q{Session: action.App.HorizonReadSession(action.R.Context())}
+		} else {
+			action.hq = &history.Q{Session: action.App.HorizonSession(action.R.Context())}
+		}
 	}
 
 	return action.hq
 }
 
+// isReadOnly returns true if the request cannot modify any state, and can
+// therefore be served from read replicas.
+func (action *Action) isReadOnly() bool {
+	return action.R.Method == http.MethodGet || action.R.Method == http.MethodHead
+}
+
 // Prepare sets the action's App field based upon the context
 func (action *Action) Prepare(w http.ResponseWriter, r *http.Request) {
 	base := &action.Base
 	action.App = AppFromContext(r.Context())
-	base.Prepare(w, r, action.App.ctx, action.App.config.SSEUpdateFrequency)
+	base.Prepare(w, r, action.App.draining, action.App.SSEUpdateFrequency())
 	if action.R.Context() != nil {
 		action.Log = log.Ctx(action.R.Context())
 	} else {
@@ -122,6 +144,86 @@ func (action *Action) ValidateCursorWithinHistory() {
 	}
 }
 
+// SetImmutable marks the response as immutable, allowing both the response
+// cache and the HTTP caches in front of horizon to store it.
+func (action *Action) SetImmutable() {
+	if action.Err != nil {
+		return
+	}
+
+	maxAge := action.App.config.ResponseCacheMaxAge / time.Second
+	action.W.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", maxAge))
+}
+
+// SetImmutablePage marks a page of `count` records loaded using `pq` as
+// immutable when it can no longer change: a full ascending page, a descending
+// page starting within the settled ledgers or a page of the records of a
+// settled ledger `ledgerFilter` (0 if the page is not filtered by ledger).
+//
+// Settled ledgers are the ledgers found in the primary database and in every
+// read replica in use, i.e. ledgers no more than `StaleThreshold` ledgers
+// behind the latest ledger.
+func (action *Action) SetImmutablePage(pq db2.PageQuery, count int, ledgerFilter int32) {
+	if action.Err != nil {
+		return
+	}
+
+	settled := ledger.CurrentState().HistoryLatest - int32(action.App.config.StaleThreshold)
+
+	switch {
+	case ledgerFilter > 0:
+		if ledgerFilter <= settled {
+			action.SetImmutable()
+		}
+	case pq.Order == db2.OrderAscending:
+		if uint64(count) == pq.Limit {
+			action.SetImmutable()
+		}
+	case pq.Order == db2.OrderDescending && pq.Cursor != "":
+		cursor, _, err := pq.CursorInt64Pair(db2.DefaultPairSep)
+		if err == nil && toid.Parse(cursor).LedgerSequence <= settled {
+			action.SetImmutable()
+		}
+	}
+}
+
+// ExportPages sends the records of an export to `stream`, loading them with
+// `loadPage` in pages of at most db2.MaxPageSize records, each page starting
+// after the last record of the previous one, until `pq.Limit` records are
+// sent or a page comes back short.
+func (action *Action) ExportPages(
+	stream export.Stream,
+	pq db2.PageQuery,
+	loadPage func(db2.PageQuery) []hal.Pageable,
+) {
+	remaining := pq.Limit
+
+	for remaining > 0 && action.Err == nil {
+		page := pq
+		page.Limit = remaining
+		if page.Limit > db2.MaxPageSize {
+			page.Limit = db2.MaxPageSize
+		}
+
+		records := loadPage(page)
+		if action.Err != nil {
+			return
+		}
+
+		for _, record := range records {
+			stream.Send(record)
+		}
+		stream.Flush()
+
+		if uint64(len(records)) < page.Limit {
+			return
+		}
+
+		remaining -= page.Limit
+		pq.Cursor = records[len(records)-1].PagingToken()
+	}
+}
+
 // EnsureHistoryFreshness halts processing and raises
 func (action *Action) EnsureHistoryFreshness() {
 	if action.Err != nil {
@@ -139,11 +241,13 @@ func (action *Action) EnsureHistoryFreshness() {
 	}
 }
 
-// FullURL returns the full url for this request
+// FullURL returns the full url for this request, without the API key the
+// client may have sent in its query string so that it is not echoed in the
+// links of the response.
 func (action *Action) FullURL() *url.URL {
 	result := action.baseURL()
 	result.Path = action.R.URL.Path
-	result.RawQuery = action.R.URL.RawQuery
+	result.RawQuery = apikey.StripURL(action.R.URL).RawQuery
 	return result
 }
 
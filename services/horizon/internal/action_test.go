@@ -0,0 +1,51 @@
+package horizon
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/lomocoin/stellar-go/protocols/horizon"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/render"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/render/export"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/test"
+	"github.com/lomocoin/stellar-go/support/render/hal"
+)
+
+func TestActionExportPages(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+
+	// loads pages of records numbered from the cursor, up to 450 records
+	var pages []db2.PageQuery
+	loadPage := func(pq db2.PageQuery) []hal.Pageable {
+		pages = append(pages, pq)
+		start, _ := strconv.Atoi(pq.Cursor)
+		var records []hal.Pageable
+		for i := start + 1; i <= start+int(pq.Limit) && i <= 450; i++ {
+			records = append(records, horizon.Transaction{PT: strconv.Itoa(i)})
+		}
+		return records
+	}
+
+	w := httptest.NewRecorder()
+	stream := export.NewStream(tt.Ctx, w, render.MimeNDJSON)
+	action := &Action{}
+	action.ExportPages(stream, db2.PageQuery{Cursor: "0", Order: "asc", Limit: 1000}, loadPage)
+	tt.Require.NoError(action.Err)
+	tt.Assert.Equal(450, stream.SentCount())
+	if tt.Assert.Len(pages, 3) {
+		tt.Assert.Equal("200", pages[1].Cursor)
+		tt.Assert.Equal(uint64(200), pages[2].Limit)
+	}
+
+	// stops at the limit of the export
+	pages = nil
+	stream = export.NewStream(tt.Ctx, httptest.NewRecorder(), render.MimeNDJSON)
+	action.ExportPages(stream, db2.PageQuery{Cursor: "0", Order: "asc", Limit: 250}, loadPage)
+	tt.Assert.Equal(250, stream.SentCount())
+	if tt.Assert.Len(pages, 2) {
+		tt.Assert.Equal(uint64(50), pages[1].Limit)
+	}
+}
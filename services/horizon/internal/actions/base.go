@@ -9,6 +9,7 @@ import (
 	horizonContext "github.com/lomocoin/stellar-go/services/horizon/internal/context"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/ledger"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/render"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/render/export"
 	hProblem "github.com/lomocoin/stellar-go/services/horizon/internal/render/problem"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/render/sse"
 	"github.com/lomocoin/stellar-go/support/errors"
@@ -25,9 +26,11 @@ type Base struct {
 	R   *http.Request
 	Err error
 
+	// appCtx is done once horizon starts shutting down, ending the streams.
 	appCtx             context.Context
 	sseUpdateFrequency time.Duration
 	isSetup            bool
+	isExport           bool
 }
 
 // Prepare established the common attributes that get used in nearly every
@@ -67,7 +70,10 @@ func (base *Base) Execute(action interface{}) {
 			goto NotAcceptable
 		}
 
-		stream := sse.NewStream(ctx, base.W)
+		stream, ok := sse.StreamFromContext(ctx)
+		if !ok {
+			stream = sse.NewStream(ctx, base.W)
+		}
 
 		for {
 			lastLedgerState := ledger.CurrentState()
@@ -75,7 +81,7 @@ func (base *Base) Execute(action interface{}) {
 			// Rate limit the request if it's a call to stream since it queries the DB every second. See
 			// https://github.com/lomocoin/stellar-go/issues/715 for more details.
 			app := base.R.Context().Value(&horizonContext.AppContextKey)
-			rateLimiter := app.(RateLimiterProvider).GetRateLimiter()
+			rateLimiter := app.(RateLimiterProvider).GetRateLimiter(base.R)
 			if rateLimiter != nil {
 				limited, _, err := rateLimiter.RateLimiter.RateLimit(rateLimiter.VaryBy.Key(base.R), 1)
 				if err != nil {
@@ -140,6 +146,10 @@ func (base *Base) Execute(action interface{}) {
 				continue
 			case <-ctx.Done():
 			case <-base.appCtx.Done():
+				// horizon is shutting down: tell the client to reconnect
+				// elsewhere.
+				stream.Shutdown()
+				return
 			}
 
 			stream.Done()
@@ -158,6 +168,44 @@ func (base *Base) Execute(action interface{}) {
 			problem.Render(ctx, base.W, base.Err)
 			return
 		}
+	case render.MimeText:
+		action, ok := action.(Text)
+
+		if !ok {
+			goto NotAcceptable
+		}
+
+		action.Text()
+
+		if base.Err != nil {
+			problem.Render(ctx, base.W, base.Err)
+			return
+		}
+	case render.MimeCSV, render.MimeNDJSON:
+		action, ok := action.(Export)
+
+		if !ok {
+			goto NotAcceptable
+		}
+
+		base.isExport = true
+		stream := export.NewStream(ctx, base.W, contentType)
+		action.Export(stream)
+
+		if base.Err != nil {
+			// Like streams, exports render the error as a normal HTTP error as
+			// long as no record has been sent.
+			if stream.SentCount() == 0 {
+				problem.Render(ctx, base.W, base.Err)
+				return
+			}
+
+			log.Ctx(ctx).Error(base.Err)
+			stream.Err(errors.New("Unexpected export error"))
+			return
+		}
+
+		stream.Done()
 	default:
 		goto NotAcceptable
 	}
@@ -1,6 +1,7 @@
 package actions
 
 import (
+	"encoding/base64"
 	"fmt"
 	"mime"
 	"net/url"
@@ -192,7 +193,8 @@ func (base *Base) GetLimit(name string, def uint64, max uint64) uint64 {
 }
 
 // GetPageQuery is a helper that returns a new db.PageQuery struct initialized
-// using the results from a call to GetPagingParams()
+// using the results from a call to GetPagingParams().  Exports default to, and
+// allow limits of up to, db2.MaxExportSize records.
 func (base *Base) GetPageQuery(opts ...Opt) db2.PageQuery {
 	disableCursorValidation := false
 
@@ -209,13 +211,24 @@ func (base *Base) GetPageQuery(opts ...Opt) db2.PageQuery {
 
 	cursor := base.GetCursor(ParamCursor)
 	order := base.GetString(ParamOrder)
-	limit := base.GetLimit(ParamLimit, db2.DefaultPageSize, db2.MaxPageSize)
+	defLimit, maxLimit := uint64(db2.DefaultPageSize), uint64(db2.MaxPageSize)
+	if base.isExport {
+		defLimit, maxLimit = db2.MaxExportSize, db2.MaxExportSize
+	}
+	limit := base.GetLimit(ParamLimit, defLimit, maxLimit)
 
 	if base.Err != nil {
 		return db2.PageQuery{}
 	}
 
-	r, err := db2.NewPageQuery(cursor, !disableCursorValidation, order, limit)
+	// exports are loaded in pages of at most db2.MaxPageSize records, the
+	// limit of the page query being the total number of records exported.
+	pageLimit := limit
+	if pageLimit > db2.MaxPageSize {
+		pageLimit = db2.MaxPageSize
+	}
+
+	r, err := db2.NewPageQuery(cursor, !disableCursorValidation, order, pageLimit)
 
 	if err != nil {
 		if invalidFieldError, ok := err.(*db2.InvalidFieldError); ok {
@@ -223,8 +236,10 @@ func (base *Base) GetPageQuery(opts ...Opt) db2.PageQuery {
 		} else {
 			base.Err = problem.BadRequest
 		}
+		return r
 	}
 
+	r.Limit = limit
 	return r
 }
 
@@ -400,6 +415,46 @@ func (base *Base) MaybeGetAsset(prefix string) (xdr.Asset, bool) {
 	return base.GetAsset(prefix), true
 }
 
+// GetMemo retrieves a memo filter from the `memo` and `memo_type` parameters,
+// formatted as it is stored in the `memo` column of `history_transactions`.
+// `memo_type` is optional but requires `memo`.  Populates err if the memo is
+// not valid for its type.
+func (base *Base) GetMemo() (memoType string, memo string) {
+	if base.Err != nil {
+		return
+	}
+
+	memoType = base.GetString("memo_type")
+	memo = base.GetString("memo")
+
+	if memo == "" {
+		if memoType != "" {
+			base.SetInvalidField("memo", errors.New("required with memo_type"))
+		}
+		return
+	}
+
+	switch memoType {
+	case "", "text":
+		if len(memo) > 28 {
+			base.SetInvalidField("memo", errors.New("text memos are at most 28 bytes long"))
+		}
+	case "id":
+		if _, err := strconv.ParseUint(memo, 10, 64); err != nil {
+			base.SetInvalidField("memo", errors.New("invalid id memo"))
+		}
+	case "hash", "return":
+		raw, err := base64.StdEncoding.DecodeString(memo)
+		if err != nil || len(raw) != 32 {
+			base.SetInvalidField("memo", errors.New("hash memos must be 32 base64 encoded bytes"))
+		}
+	default:
+		base.SetInvalidField("memo_type", errors.New("unknown memo type"))
+	}
+
+	return
+}
+
 // GetTimeMillis retrieves a TimeMillis from the action parameter of the given name.
 // Populates err if the value is not a valid TimeMillis
 func (base *Base) GetTimeMillis(name string) (timeMillis time.Millis) {
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/go-chi/chi"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/ledger"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/test"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/toid"
@@ -271,6 +272,41 @@ func TestGetLimit(t *testing.T) {
 	tt.Assert.Error(action.Err)
 }
 
+func TestGetMemo(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+
+	cases := []struct {
+		Query    string
+		Type     string
+		Memo     string
+		HasError bool
+	}{
+		{"", "", "", false},
+		{"?memo=hello", "", "hello", false},
+		{"?memo=hello&memo_type=text", "text", "hello", false},
+		{"?memo=123&memo_type=id", "id", "123", false},
+		{"?memo=abc&memo_type=id", "", "", true},
+		{"?memo=AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA%3D&memo_type=hash", "hash", "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=", false},
+		{"?memo=AAAA&memo_type=return", "", "", true},
+		{"?memo_type=text", "", "", true},
+		{"?memo=hello&memo_type=bogus", "", "", true},
+	}
+
+	for _, kase := range cases {
+		action := makeAction("/"+kase.Query, nil)
+		memoType, memo := action.GetMemo()
+		if kase.HasError {
+			tt.Assert.Error(action.Err, kase.Query)
+			continue
+		}
+		if tt.Assert.NoError(action.Err, kase.Query) {
+			tt.Assert.Equal(kase.Type, memoType)
+			tt.Assert.Equal(kase.Memo, memo)
+		}
+	}
+}
+
 func TestGetPageQuery(t *testing.T) {
 	tt := test.Start(t)
 	defer tt.Finish()
@@ -295,6 +331,24 @@ func TestGetPageQuery(t *testing.T) {
 	makeAction("/?limit=0", nil)
 	_ = action.GetPageQuery()
 	tt.Assert.Error(action.Err)
+
+	// exports allow larger limits
+	action = makeAction("/?limit=5000", nil)
+	action.isExport = true
+	pq = action.GetPageQuery()
+	tt.Assert.NoError(action.Err)
+	tt.Assert.Equal(uint64(5000), pq.Limit)
+
+	action = makeAction("/", nil)
+	action.isExport = true
+	pq = action.GetPageQuery()
+	tt.Assert.NoError(action.Err)
+	tt.Assert.Equal(uint64(db2.MaxExportSize), pq.Limit)
+
+	action = makeAction("/?limit=10001", nil)
+	action.isExport = true
+	_ = action.GetPageQuery()
+	tt.Assert.Error(action.Err)
 }
 
 func TestGetString(t *testing.T) {
@@ -1,6 +1,9 @@
 package actions
 
-import "github.com/lomocoin/stellar-go/services/horizon/internal/render/sse"
+import (
+	"github.com/lomocoin/stellar-go/services/horizon/internal/render/export"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/render/sse"
+)
 
 // JSON implementors can respond to a request whose response type was negotiated
 // to be MimeHal or MimeJSON.
@@ -14,8 +17,20 @@ type Raw interface {
 	Raw()
 }
 
+// Text implementors can respond to a request whose response type was
+// negotiated to be MimeText.
+type Text interface {
+	Text()
+}
+
 // SSE implementors can respond to a request whose response type was negotiated
 // to be MimeEventStream.
 type SSE interface {
 	SSE(sse.Stream)
 }
+
+// Export implementors can respond to a request whose response type was
+// negotiated to be MimeCSV or MimeNDJSON.
+type Export interface {
+	Export(export.Stream)
+}
@@ -1,23 +1,320 @@
 package horizon
 
 import (
+	"database/sql"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/lomocoin/stellar-go/protocols/horizon"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/actions"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/core"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/ledger"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/render/problem"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/render/sse"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/resourceadapter"
+	"github.com/lomocoin/stellar-go/strkey"
+	"github.com/lomocoin/stellar-go/support/errors"
 	"github.com/lomocoin/stellar-go/support/render/hal"
+	"github.com/lomocoin/stellar-go/xdr"
 )
 
 // This file contains the actions:
 //
+// AccountIndexAction: pages of accounts looked up in bulk
 // AccountShowAction: details for single account (including stellar-core state)
 
-// AccountShowAction renders a account summary found by its address.
+// maxAccountBatchSize is the maximum number of accounts that can be looked up
+// by a single request to AccountIndexAction.
+const maxAccountBatchSize = 1000
+
+// AccountIndexAction renders a page of accounts, paged in the lexical order
+// of their addresses.  The accounts are selected by exactly one of the `id`
+// parameter, the comma separated addresses of the accounts provided in the
+// query string or a form encoded POST body, the `signer` parameter, a public
+// key whose accounts it can sign for are listed, or the `asset` parameter, a
+// `CODE:ISSUER` asset whose trusting accounts are listed.  When looking up
+// addresses, every page entry reports whether the account exists, so that a
+// missing account does not fail the whole request.
+type AccountIndexAction struct {
+	Action
+	Addresses         []string
+	SignerFilter      string
+	AssetCodeFilter   string
+	AssetIssuerFilter string
+	PagingParams      db2.PageQuery
+	Records           []horizon.AccountBatchEntry
+	Page              hal.Page
+}
+
+// postedAccountPage is the page rendered for a POST request.  Links carry the
+// parameters of a page in their query string, where the addresses of the body
+// would end up in logs and caches, so it only links to itself, without them:
+// the following pages are fetched by repeating the POST with a `cursor`.
+type postedAccountPage struct {
+	Links struct {
+		Self hal.Link `json:"self"`
+	} `json:"_links"`
+	hal.BasePage
+}
+
+// JSON is a method for actions.JSON
+func (action *AccountIndexAction) JSON() {
+	action.Do(
+		action.loadParams,
+		action.loadRecords,
+		action.loadPage,
+		func() {
+			if action.R.Method != http.MethodPost {
+				hal.Render(action.W, action.Page)
+				return
+			}
+
+			var page postedAccountPage
+			page.Links.Self = action.Page.Links.Self
+			page.BasePage = action.Page.BasePage
+			hal.Render(action.W, page)
+		},
+	)
+}
+
+func (action *AccountIndexAction) loadParams() {
+	ids := action.GetString("id")
+	action.SignerFilter = action.GetAddress("signer")
+	asset := action.GetString("asset")
+	action.PagingParams = action.GetPageQuery(actions.DisableCursorValidation)
+	if action.Err != nil {
+		return
+	}
+
+	filters := 0
+	for _, filter := range []string{ids, action.SignerFilter, asset} {
+		if filter != "" {
+			filters++
+		}
+	}
+	switch filters {
+	case 0:
+		action.SetInvalidField("id", errors.New("one of id, signer or asset is required"))
+		return
+	case 1:
+	default:
+		action.SetInvalidField("id", errors.New("only one of id, signer or asset can be provided"))
+		return
+	}
+
+	switch {
+	case ids != "":
+		action.loadAddresses(ids)
+	case asset != "":
+		action.loadAssetFilter(asset)
+	}
+}
+
+// loadAddresses parses the comma separated addresses of the `id` parameter.
+func (action *AccountIndexAction) loadAddresses(ids string) {
+	seen := map[string]bool{}
+	for _, id := range strings.Split(ids, ",") {
+		id = strings.TrimSpace(id)
+		if seen[id] {
+			continue
+		}
+
+		_, err := strkey.Decode(strkey.VersionByteAccountID, id)
+		if err != nil {
+			action.SetInvalidField("id", errors.Errorf("invalid address: %s", id))
+			return
+		}
+
+		seen[id] = true
+		action.Addresses = append(action.Addresses, id)
+	}
+
+	if len(action.Addresses) > maxAccountBatchSize {
+		action.SetInvalidField("id", errors.Errorf("at most %d addresses can be looked up at once", maxAccountBatchSize))
+		return
+	}
+
+	sort.Strings(action.Addresses)
+}
+
+// loadAssetFilter parses the `CODE:ISSUER` asset of the `asset` parameter.
+func (action *AccountIndexAction) loadAssetFilter(asset string) {
+	parts := strings.Split(asset, ":")
+	if len(parts) != 2 {
+		action.SetInvalidField("asset", errors.New("must be formatted as CODE:ISSUER"))
+		return
+	}
+
+	var (
+		issuer xdr.AccountId
+		a      xdr.Asset
+	)
+	err := issuer.SetAddress(parts[1])
+	if err != nil {
+		action.SetInvalidField("asset", errors.New("invalid issuer"))
+		return
+	}
+
+	err = a.SetCredit(parts[0], issuer)
+	if err != nil {
+		action.SetInvalidField("asset", err)
+		return
+	}
+
+	action.AssetCodeFilter, action.AssetIssuerFilter = parts[0], parts[1]
+}
+
+// loadRecords loads the accounts of the requested page with a single query
+// for each of the accounts, data entries, signers and trustlines tables.
+func (action *AccountIndexAction) loadRecords() {
+	var page []string
+	switch {
+	case action.SignerFilter != "":
+		action.Err = action.CoreQ().
+			AccountIDsForSigner(&page, action.SignerFilter, action.PagingParams)
+	case action.AssetCodeFilter != "":
+		action.Err = action.CoreQ().AccountIDsForAsset(
+			&page,
+			action.AssetCodeFilter,
+			action.AssetIssuerFilter,
+			action.PagingParams,
+		)
+	default:
+		page = action.pageAddresses()
+	}
+	if action.Err != nil {
+		return
+	}
+
+	if len(page) == 0 {
+		action.Records = []horizon.AccountBatchEntry{}
+		return
+	}
+
+	app := AppFromContext(action.R.Context())
+	protocolVersion := app.protocolVersion
+
+	var (
+		accounts   []core.Account
+		data       []core.AccountData
+		signers    []core.Signer
+		trustlines []core.Trustline
+	)
+
+	action.Err = action.CoreQ().AccountsByAddresses(&accounts, page, protocolVersion)
+	if action.Err != nil {
+		return
+	}
+
+	action.Err = action.CoreQ().AllDataByAddresses(&data, page)
+	if action.Err != nil {
+		return
+	}
+
+	action.Err = action.CoreQ().SignersByAddresses(&signers, page)
+	if action.Err != nil {
+		return
+	}
+
+	action.Err = action.CoreQ().TrustlinesByAddresses(&trustlines, page, protocolVersion)
+	if action.Err != nil {
+		return
+	}
+
+	byAddress := map[string]core.Account{}
+	for _, account := range accounts {
+		byAddress[account.Accountid] = account
+	}
+	dataByAddress := map[string][]core.AccountData{}
+	for _, d := range data {
+		dataByAddress[d.Accountid] = append(dataByAddress[d.Accountid], d)
+	}
+	signersByAddress := map[string][]core.Signer{}
+	for _, signer := range signers {
+		signersByAddress[signer.Accountid] = append(signersByAddress[signer.Accountid], signer)
+	}
+	trustlinesByAddress := map[string][]core.Trustline{}
+	for _, tl := range trustlines {
+		trustlinesByAddress[tl.Accountid] = append(trustlinesByAddress[tl.Accountid], tl)
+	}
+
+	action.Records = make([]horizon.AccountBatchEntry, len(page))
+	for i, address := range page {
+		entry := &action.Records[i]
+		entry.ID = address
+		entry.PT = address
+
+		account, found := byAddress[address]
+		if !found {
+			continue
+		}
+
+		entry.Found = true
+		entry.Account = &horizon.Account{}
+		action.Err = resourceadapter.PopulateAccount(
+			action.R.Context(),
+			entry.Account,
+			account,
+			dataByAddress[address],
+			signersByAddress[address],
+			trustlinesByAddress[address],
+			history.Account{},
+		)
+		if action.Err != nil {
+			return
+		}
+	}
+}
+
+// pageAddresses returns the requested addresses that belong to the page
+// specified by `PagingParams`.
+func (action *AccountIndexAction) pageAddresses() []string {
+	var page []string
+	cursor := action.PagingParams.Cursor
+
+	if action.PagingParams.Order == db2.OrderDescending {
+		for i := len(action.Addresses) - 1; i >= 0; i-- {
+			if cursor == "" || action.Addresses[i] < cursor {
+				page = append(page, action.Addresses[i])
+			}
+		}
+	} else {
+		for _, address := range action.Addresses {
+			if address > cursor {
+				page = append(page, address)
+			}
+		}
+	}
+
+	if uint64(len(page)) > action.PagingParams.Limit {
+		page = page[:action.PagingParams.Limit]
+	}
+	return page
+}
+
+func (action *AccountIndexAction) loadPage() {
+	for _, record := range action.Records {
+		action.Page.Add(record)
+	}
+
+	action.Page.FullURL = action.FullURL()
+	action.Page.Limit = action.PagingParams.Limit
+	action.Page.Cursor = action.PagingParams.Cursor
+	action.Page.Order = action.PagingParams.Order
+	action.Page.PopulateLinks()
+}
+
+// AccountShowAction renders a account summary found by its address.  When the
+// `at_ledger` or `at_time` parameter is provided, the state of the account as
+// of that point in history is rendered instead of its current state.
 type AccountShowAction struct {
 	Action
 	Address        string
+	AtLedger       int32
 	HistoryRecord  history.Account
 	CoreData       []core.AccountData
 	CoreRecord     core.Account
@@ -54,9 +351,44 @@ func (action *AccountShowAction) SSE(stream sse.Stream) {
 
 func (action *AccountShowAction) loadParams() {
 	action.Address = action.GetAddress("account_id", actions.RequiredParam)
+	action.AtLedger = action.GetInt32("at_ledger")
+	if action.Err != nil {
+		return
+	}
+
+	atTime := action.GetString("at_time")
+	if atTime == "" {
+		return
+	}
+
+	if action.AtLedger != 0 {
+		action.SetInvalidField("at_time", errors.New("cannot be combined with at_ledger"))
+		return
+	}
+
+	t, err := time.Parse(time.RFC3339, atTime)
+	if err != nil {
+		action.SetInvalidField("at_time", err)
+		return
+	}
+
+	action.Err = action.HistoryQ().LedgerSequenceAt(&action.AtLedger, t.UTC())
+	if action.Err != nil {
+		return
+	}
+
+	// no ledger in our history closed before the requested time
+	if action.AtLedger == 0 {
+		action.Err = &problem.BeforeHistory
+	}
 }
 
 func (action *AccountShowAction) loadRecord() {
+	if action.AtLedger != 0 {
+		action.loadHistoricalRecord()
+		return
+	}
+
 	app := AppFromContext(action.R.Context())
 	protocolVersion := app.protocolVersion
 
@@ -98,6 +430,161 @@ func (action *AccountShowAction) loadRecord() {
 	}
 }
 
+// loadHistoricalRecord loads the state of the account as of `AtLedger` from the
+// state history recorded during ingestion.  Accounts and trustlines that have
+// not changed since before the ingested history began have no recorded state,
+// in which case their current state is used if it predates `AtLedger`.
+func (action *AccountShowAction) loadHistoricalRecord() {
+	ls := ledger.CurrentState()
+	if action.AtLedger < ls.HistoryElder {
+		action.Err = &problem.BeforeHistory
+		return
+	}
+	if action.AtLedger > ls.HistoryLatest {
+		action.SetInvalidField("at_ledger", errors.New("ledger has not been ingested yet"))
+		return
+	}
+
+	app := AppFromContext(action.R.Context())
+	protocolVersion := app.protocolVersion
+
+	var (
+		state   history.AccountState
+		current core.Account
+		entry   xdr.LedgerEntry
+	)
+
+	action.Err = action.HistoryQ().
+		AccountStateAt(&state, action.Address, action.AtLedger)
+	switch {
+	case action.HistoryQ().NoRows(action.Err):
+		var found bool
+		found, action.Err = action.HistoryQ().AccountHasState(action.Address)
+		if action.Err != nil {
+			return
+		}
+
+		// the recorded history of the account begins after the requested ledger
+		if found {
+			action.Err = sql.ErrNoRows
+			return
+		}
+
+		action.Err = action.CoreQ().
+			AccountByAddress(&current, action.Address, protocolVersion)
+		if action.Err != nil {
+			return
+		}
+
+		if current.Lastmodified > action.AtLedger {
+			action.Err = sql.ErrNoRows
+			return
+		}
+
+		action.CoreRecord = current
+		action.Err = action.CoreQ().
+			SignersByAddress(&action.CoreSigners, action.Address)
+		if action.Err != nil {
+			return
+		}
+	case action.Err != nil:
+		return
+	case !state.Entry.Valid:
+		// the account did not exist at the requested ledger
+		action.Err = sql.ErrNoRows
+		return
+	default:
+		action.Err = xdr.SafeUnmarshalBase64(state.Entry.String, &entry)
+		if action.Err != nil {
+			return
+		}
+
+		action.CoreRecord = core.AccountFromEntry(entry)
+		action.CoreSigners = core.SignersFromEntry(entry)
+	}
+
+	action.loadHistoricalTrustlines(protocolVersion)
+	if action.Err != nil {
+		return
+	}
+
+	// data entries are not tracked by the state history
+	action.CoreData = []core.AccountData{}
+
+	action.Err = action.HistoryQ().
+		AccountByAddress(&action.HistoryRecord, action.Address)
+	if action.HistoryQ().NoRows(action.Err) {
+		action.Err = nil
+	}
+}
+
+// loadHistoricalTrustlines loads the trustlines of the account as of
+// `AtLedger`.
+func (action *AccountShowAction) loadHistoricalTrustlines(protocolVersion int32) {
+	var (
+		states  []history.TrustlineState
+		tracked []history.TrustlineState
+		current []core.Trustline
+	)
+
+	action.Err = action.HistoryQ().
+		TrustlineStatesAt(&states, action.Address, action.AtLedger)
+	if action.Err != nil {
+		return
+	}
+
+	action.CoreTrustlines = []core.Trustline{}
+	for _, state := range states {
+		if !state.Entry.Valid {
+			continue
+		}
+
+		var entry xdr.LedgerEntry
+		action.Err = xdr.SafeUnmarshalBase64(state.Entry.String, &entry)
+		if action.Err != nil {
+			return
+		}
+
+		var tl core.Trustline
+		tl, action.Err = core.TrustlineFromEntry(entry)
+		if action.Err != nil {
+			return
+		}
+		action.CoreTrustlines = append(action.CoreTrustlines, tl)
+	}
+
+	action.Err = action.HistoryQ().TrustlineStateAssets(&tracked, action.Address)
+	if action.Err != nil {
+		return
+	}
+
+	action.Err = action.CoreQ().
+		TrustlinesByAddress(&current, action.Address, protocolVersion)
+	if action.Err != nil {
+		return
+	}
+
+	// trustlines without any recorded state have not changed since before the
+	// ingested history began.
+	for _, tl := range current {
+		if tl.Lastmodified > action.AtLedger {
+			continue
+		}
+
+		untracked := true
+		for _, t := range tracked {
+			if t.AssetType == tl.Assettype && t.AssetCode == tl.Assetcode && t.AssetIssuer == tl.Issuer {
+				untracked = false
+				break
+			}
+		}
+
+		if untracked {
+			action.CoreTrustlines = append(action.CoreTrustlines, tl)
+		}
+	}
+}
+
 func (action *AccountShowAction) loadResource() {
 	action.Err = resourceadapter.PopulateAccount(
 		action.R.Context(),
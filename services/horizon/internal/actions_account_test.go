@@ -2,9 +2,13 @@ package horizon
 
 import (
 	"encoding/json"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/lomocoin/stellar-go/protocols/horizon"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/schema"
+	"github.com/lomocoin/stellar-go/xdr"
 )
 
 func TestAccountActions_Show(t *testing.T) {
@@ -57,6 +61,108 @@ func TestAccountActions_ShowRegressions(t *testing.T) {
 
 }
 
+func TestAccountActions_ShowAtLedger(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	// the state history tables are newer than the scenario
+	_, err := schema.Migrate(ht.HorizonDB.DB, schema.MigrateUp, 0)
+	ht.Require.NoError(err)
+
+	// the account was created with 100 lumens at ledger 2, and was paid 5
+	// lumens at ledger 3
+	address := "GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON"
+	recordAccountState(ht, address, 2, 1000000000)
+	recordAccountState(ht, address, 3, 1050000000)
+
+	balanceAt := func(query string) string {
+		w := ht.Get("/accounts/" + address + "?" + query)
+		if !ht.Assert.Equal(200, w.Code) {
+			return ""
+		}
+
+		var result horizon.Account
+		ht.Require.NoError(json.Unmarshal(w.Body.Bytes(), &result))
+		ht.Assert.Equal("8589934592", result.Sequence)
+		balance, err := result.GetNativeBalance()
+		ht.Require.NoError(err)
+		return balance
+	}
+
+	ht.Assert.Equal("100.0000000", balanceAt("at_ledger=2"))
+	ht.Assert.Equal("105.0000000", balanceAt("at_ledger=3"))
+
+	// ledger 2 closed at 22:16:57 and ledger 3 at 22:16:58
+	ht.Assert.Equal("100.0000000", balanceAt("at_time=2018-12-11T22:16:57Z"))
+	ht.Assert.Equal("100.0000000", balanceAt("at_time=2018-12-11T22:16:57.5Z"))
+	ht.Assert.Equal("105.0000000", balanceAt("at_time=2018-12-11T22:16:58Z"))
+
+	// the account did not exist yet at ledger 1
+	w := ht.Get("/accounts/" + address + "?at_ledger=1")
+	ht.Assert.Equal(404, w.Code)
+
+	// accounts without recorded state fall back to their current state
+	w = ht.Get("/accounts/GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H?at_ledger=2")
+	if ht.Assert.Equal(200, w.Code) {
+		var result horizon.Account
+		ht.Require.NoError(json.Unmarshal(w.Body.Bytes(), &result))
+		ht.Assert.Equal("3", result.Sequence)
+	}
+
+	// ledger that has not been ingested yet
+	w = ht.Get("/accounts/" + address + "?at_ledger=4")
+	if ht.Assert.Equal(400, w.Code) {
+		ht.Assert.ProblemType(w.Body, "bad_request")
+		ht.Assert.Contains(w.Body.String(), "at_ledger")
+		ht.Assert.Contains(w.Body.String(), "ledger has not been ingested yet")
+	}
+
+	// time before the first ledger
+	w = ht.Get("/accounts/" + address + "?at_time=1969-01-01T00:00:00Z")
+	if ht.Assert.Equal(410, w.Code) {
+		ht.Assert.ProblemType(w.Body, "before_history")
+	}
+
+	// invalid and conflicting parameters
+	w = ht.Get("/accounts/" + address + "?at_time=yesterday")
+	ht.Assert.Equal(400, w.Code)
+	w = ht.Get("/accounts/" + address + "?at_ledger=2&at_time=2018-12-11T22:16:57Z")
+	ht.Assert.Equal(400, w.Code)
+
+	// ledger that was reaped
+	ht.ReapHistory(2)
+
+	w = ht.Get("/accounts/" + address + "?at_ledger=1")
+	if ht.Assert.Equal(410, w.Code) {
+		ht.Assert.ProblemType(w.Body, "before_history")
+	}
+	ht.Assert.Equal("105.0000000", balanceAt("at_ledger=3"))
+}
+
+// recordAccountState records the state of the account at `address` as of
+// ledger `seq` in the state history, like ingestion does.
+func recordAccountState(ht *HTTPT, address string, seq int32, balance int64) {
+	var entry xdr.LedgerEntry
+	entry.LastModifiedLedgerSeq = xdr.Uint32(seq)
+	entry.Data.Type = xdr.LedgerEntryTypeAccount
+	entry.Data.Account = &xdr.AccountEntry{
+		Balance:    xdr.Int64(balance),
+		SeqNum:     xdr.SequenceNumber(8589934592),
+		Thresholds: xdr.Thresholds{1, 0, 0, 0},
+	}
+	ht.Require.NoError(entry.Data.Account.AccountId.SetAddress(address))
+
+	enc, err := xdr.MarshalBase64(entry)
+	ht.Require.NoError(err)
+
+	_, err = ht.App.HistoryQ().ExecRaw(`
+		INSERT INTO history_account_states (account_id, ledger_sequence, balance, entry)
+		VALUES (?, ?, ?, ?)`,
+		address, seq, balance, enc,
+	)
+	ht.Require.NoError(err)
+}
+
 func TestAccountActions_InvalidID(t *testing.T) {
 	ht := StartHTTPTest(t, "base")
 	defer ht.Finish()
@@ -67,3 +173,146 @@ func TestAccountActions_InvalidID(t *testing.T) {
 	)
 	ht.Assert.Equal(400, w.Code)
 }
+
+func TestAccountActions_Index(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	ids := []string{
+		"GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU",
+		"GDBAPLDCAEJV6LSEDFEAUDAVFYSNFRUYZ4X75YYJJMMX5KFVUOHX46SQ",
+		"GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2",
+	}
+
+	w := ht.Get("/accounts?limit=200&id=" + strings.Join(ids, ","))
+	if ht.Assert.Equal(200, w.Code) {
+		var records []horizon.AccountBatchEntry
+		ht.UnmarshalPage(w.Body, &records)
+		if ht.Assert.Len(records, 3) {
+			ht.Assert.Equal(ids[2], records[0].ID)
+			ht.Assert.True(records[0].Found)
+			ht.Assert.Equal(ids[2], records[0].Account.AccountID)
+
+			ht.Assert.Equal(ids[0], records[1].ID)
+			ht.Assert.True(records[1].Found)
+
+			// missing accounts are reported without failing the request
+			ht.Assert.Equal(ids[1], records[2].ID)
+			ht.Assert.False(records[2].Found)
+			ht.Assert.Nil(records[2].Account)
+		}
+	}
+
+	// paging
+	w = ht.Get("/accounts?limit=1&cursor=" + ids[2] + "&id=" + strings.Join(ids, ","))
+	if ht.Assert.Equal(200, w.Code) {
+		var records []horizon.AccountBatchEntry
+		ht.UnmarshalPage(w.Body, &records)
+		if ht.Assert.Len(records, 1) {
+			ht.Assert.Equal(ids[0], records[0].ID)
+		}
+	}
+
+	w = ht.Get("/accounts?order=desc&id=" + strings.Join(ids, ","))
+	if ht.Assert.Equal(200, w.Code) {
+		var records []horizon.AccountBatchEntry
+		ht.UnmarshalPage(w.Body, &records)
+		if ht.Assert.Len(records, 3) {
+			ht.Assert.Equal(ids[1], records[0].ID)
+		}
+	}
+
+	// form encoded POST
+	w = ht.Post("/accounts", url.Values{"id": []string{strings.Join(ids, ",")}, "limit": []string{"2"}})
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.NotContains(w.Body.String(), `"next"`)
+		ht.Assert.NotContains(w.Body.String(), `"prev"`)
+
+		// the links don't carry the addresses of the body
+		var records []horizon.AccountBatchEntry
+		links := ht.UnmarshalPage(w.Body, &records)
+		ht.Require.Len(records, 2)
+		self, err := url.Parse(links.Self.Href)
+		ht.Require.NoError(err)
+		ht.Assert.Equal("/accounts", self.Path)
+		ht.Assert.Empty(self.Query().Get("id"))
+
+		// the following page is fetched by repeating the POST with a cursor
+		w = ht.Post("/accounts", url.Values{
+			"id":     []string{strings.Join(ids, ",")},
+			"limit":  []string{"2"},
+			"cursor": []string{records[1].PT},
+		})
+		if ht.Assert.Equal(200, w.Code) {
+			ht.Assert.PageOf(1, w.Body)
+		}
+	}
+
+	// invalid requests
+	w = ht.Get("/accounts?id=GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU,bogus")
+	ht.Assert.Equal(400, w.Code)
+
+	w = ht.Get("/accounts")
+	ht.Assert.Equal(400, w.Code)
+}
+
+func TestAccountActions_IndexBySigner(t *testing.T) {
+	ht := StartHTTPTest(t, "kahuna")
+	defer ht.Finish()
+
+	// accounts listing the key as a signer
+	w := ht.Get("/accounts?signer=GD3E7HKMRNT6HGBGHBT6I6JE4N2S4W5KZ246TGJ4KQSXJ2P4BXCUPQMP")
+	if ht.Assert.Equal(200, w.Code) {
+		var records []horizon.AccountBatchEntry
+		ht.UnmarshalPage(w.Body, &records)
+		if ht.Assert.Len(records, 1) {
+			ht.Assert.Equal("GDXFAGJCSCI4CK2YHK6YRLA6TKEXFRX7BMGVMQOBMLIEUJRJ5YQNLMIB", records[0].ID)
+			ht.Assert.True(records[0].Found)
+		}
+	}
+
+	// the master key signs for its own account
+	w = ht.Get("/accounts?signer=GDXFAGJCSCI4CK2YHK6YRLA6TKEXFRX7BMGVMQOBMLIEUJRJ5YQNLMIB")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(1, w.Body)
+	}
+
+	w = ht.Get("/accounts?signer=bogus")
+	ht.Assert.Equal(400, w.Code)
+}
+
+func TestAccountActions_IndexByAsset(t *testing.T) {
+	ht := StartHTTPTest(t, "trades")
+	defer ht.Finish()
+
+	asset := "USD:GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4"
+
+	w := ht.Get("/accounts?asset=" + asset)
+	if ht.Assert.Equal(200, w.Code) {
+		var records []horizon.AccountBatchEntry
+		ht.UnmarshalPage(w.Body, &records)
+		if ht.Assert.Len(records, 2) {
+			ht.Assert.Equal("GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2", records[0].ID)
+			ht.Assert.Equal("GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU", records[1].ID)
+		}
+	}
+
+	w = ht.Get("/accounts?order=desc&limit=1&asset=" + asset)
+	if ht.Assert.Equal(200, w.Code) {
+		var records []horizon.AccountBatchEntry
+		ht.UnmarshalPage(w.Body, &records)
+		if ht.Assert.Len(records, 1) {
+			ht.Assert.Equal("GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU", records[0].ID)
+		}
+	}
+
+	// invalid requests
+	w = ht.Get("/accounts?asset=USD")
+	ht.Assert.Equal(400, w.Code)
+
+	w = ht.Get("/accounts?asset=USD:bogus")
+	ht.Assert.Equal(400, w.Code)
+
+	w = ht.Get("/accounts?asset=" + asset + "&signer=GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2")
+	ht.Assert.Equal(400, w.Code)
+}
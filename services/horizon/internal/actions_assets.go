@@ -9,11 +9,13 @@ import (
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/assets"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/resourceadapter"
 	"github.com/lomocoin/stellar-go/support/render/hal"
+	"github.com/lomocoin/stellar-go/xdr"
 )
 
 // This file contains the actions:
 //
 // AssetsAction: pages of assets
+// AssetDailyStatsAction: pages of the daily stats of an asset
 
 // AssetsAction renders a page of Assets
 type AssetsAction struct {
@@ -86,3 +88,65 @@ func (action *AssetsAction) loadPage() {
 	action.Page.Order = action.PagingParams.Order
 	action.Page.PopulateLinks()
 }
+
+// AssetDailyStatsAction renders a page of the daily stats of a single asset
+type AssetDailyStatsAction struct {
+	Action
+	Asset        xdr.Asset
+	PagingParams db2.PageQuery
+	Records      []assets.AssetDailyStatsR
+	Page         hal.Page
+}
+
+// JSON is a method for actions.JSON
+func (action *AssetDailyStatsAction) JSON() {
+	action.Do(
+		action.loadParams,
+		action.loadRecords,
+		action.loadPage,
+		func() {
+			hal.Render(action.W, action.Page)
+		},
+	)
+}
+
+func (action *AssetDailyStatsAction) loadParams() {
+	action.Asset = action.GetAsset("")
+	action.PagingParams = action.GetPageQuery(actions.DisableCursorValidation)
+}
+
+func (action *AssetDailyStatsAction) loadRecords() {
+	assetID, err := action.HistoryQ().GetAssetID(action.Asset)
+	if err != nil {
+		action.Err = err
+		return
+	}
+
+	sql, err := assets.AssetDailyStatsQ{
+		AssetID:   assetID,
+		PageQuery: action.PagingParams,
+	}.GetSQL()
+	if err != nil {
+		action.Err = err
+		return
+	}
+	action.Err = action.HistoryQ().Select(&action.Records, sql)
+}
+
+func (action *AssetDailyStatsAction) loadPage() {
+	for _, record := range action.Records {
+		var res horizon.AssetDailyStat
+		err := resourceadapter.PopulateAssetDailyStat(action.R.Context(), &res, record)
+		if err != nil {
+			action.Err = err
+			return
+		}
+		action.Page.Add(res)
+	}
+
+	action.Page.FullURL = action.FullURL()
+	action.Page.Limit = action.PagingParams.Limit
+	action.Page.Cursor = action.PagingParams.Cursor
+	action.Page.Order = action.PagingParams.Order
+	action.Page.PopulateLinks()
+}
@@ -2,9 +2,11 @@ package horizon
 
 import (
 	"testing"
+	"time"
 
 	"github.com/lomocoin/stellar-go/protocols/horizon"
 	"github.com/lomocoin/stellar-go/protocols/horizon/base"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/test"
 	"github.com/lomocoin/stellar-go/support/render/hal"
 )
@@ -28,9 +30,12 @@ func TestAssetsActions(t *testing.T) {
 			Code:   "BTC",
 			Issuer: "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4",
 		},
-		PT:          "BTC_GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4_credit_alphanum4",
-		Amount:      "100.9876000",
-		NumAccounts: 1,
+		PT:                 "BTC_GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4_credit_alphanum4",
+		Amount:             "100.9876000",
+		NumAccounts:        1,
+		UnauthorizedAmount: "0.0000000",
+		AmountInOffers:     "0.0000000",
+		PaymentVolume24h:   "0.0000000",
 		Flags: horizon.AccountFlags{
 			AuthRequired:  true,
 			AuthRevocable: false,
@@ -43,9 +48,12 @@ func TestAssetsActions(t *testing.T) {
 			Code:   "SCOT",
 			Issuer: "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU",
 		},
-		PT:          "SCOT_GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU_credit_alphanum4",
-		Amount:      "1000.0000000",
-		NumAccounts: 1,
+		PT:                 "SCOT_GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU_credit_alphanum4",
+		Amount:             "1000.0000000",
+		NumAccounts:        1,
+		UnauthorizedAmount: "0.0000000",
+		AmountInOffers:     "0.0000000",
+		PaymentVolume24h:   "0.0000000",
 		Flags: horizon.AccountFlags{
 			AuthRequired:  false,
 			AuthRevocable: true,
@@ -58,9 +66,12 @@ func TestAssetsActions(t *testing.T) {
 			Code:   "USD",
 			Issuer: "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4",
 		},
-		PT:          "USD_GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4_credit_alphanum4",
-		Amount:      "300001.0434000",
-		NumAccounts: 2,
+		PT:                 "USD_GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4_credit_alphanum4",
+		Amount:             "300001.0434000",
+		NumAccounts:        2,
+		UnauthorizedAmount: "0.0000000",
+		AmountInOffers:     "0.0000000",
+		PaymentVolume24h:   "0.0000000",
 		Flags: horizon.AccountFlags{
 			AuthRequired:  true,
 			AuthRevocable: false,
@@ -232,15 +243,6 @@ func TestAssetsActions(t *testing.T) {
 			ht := StartHTTPTest(t, "ingest_asset_stats")
 			defer ht.Finish()
 
-			// Ugly but saves us time needed to change each `StartHTTPTest` occurence.
-			appConfig := NewTestConfig()
-			appConfig.EnableAssetStats = true
-
-			var err error
-			ht.App, err = NewApp(appConfig)
-			ht.Assert.Nil(err)
-			ht.RH = test.NewRequestHelper(ht.App.web.router)
-
 			w := ht.Get(kase.path)
 			ht.Assert.Equal(200, w.Code)
 			ht.Assert.PageOf(len(kase.wantItems), w.Body)
@@ -264,15 +266,6 @@ func TestInvalidAssetCode(t *testing.T) {
 	ht := StartHTTPTest(t, "ingest_asset_stats")
 	defer ht.Finish()
 
-	// Ugly but saves us time needed to change each `StartHTTPTest` occurence.
-	appConfig := NewTestConfig()
-	appConfig.EnableAssetStats = true
-
-	var err error
-	ht.App, err = NewApp(appConfig)
-	ht.Assert.Nil(err)
-	ht.RH = test.NewRequestHelper(ht.App.web.router)
-
 	w := ht.Get("/assets?asset_code=ABCDEFGHIJKL")
 	ht.Assert.Equal(200, w.Code)
 
@@ -284,26 +277,88 @@ func TestInvalidAssetIssuer(t *testing.T) {
 	ht := StartHTTPTest(t, "ingest_asset_stats")
 	defer ht.Finish()
 
-	// Ugly but saves us time needed to change each `StartHTTPTest` occurence.
+	w := ht.Get("/assets?asset_issuer=GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4")
+	ht.Assert.Equal(200, w.Code)
+
+	w = ht.Get("/assets?asset_issuer=invalid")
+	ht.Assert.Equal(400, w.Code)
+}
+
+func TestAssetStatsEnabledByDefault(t *testing.T) {
+	ht := StartHTTPTest(t, "ingest_asset_stats")
+	defer ht.Finish()
+
+	w := ht.Get("/assets?asset_issuer=GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4")
+	ht.Assert.Equal(200, w.Code)
+	w = ht.Get("/assets/daily_stats?asset_code=USD&asset_issuer=GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4")
+	ht.Assert.NotEqual(404, w.Code)
+}
+
+func TestAssetStatsDisabled(t *testing.T) {
+	ht := StartHTTPTest(t, "ingest_asset_stats")
+	defer ht.Finish()
+
 	appConfig := NewTestConfig()
-	appConfig.EnableAssetStats = true
+	appConfig.DisableAssetStats = true
 
 	var err error
 	ht.App, err = NewApp(appConfig)
-	ht.Assert.Nil(err)
+	ht.Require.NoError(err)
 	ht.RH = test.NewRequestHelper(ht.App.web.router)
 
 	w := ht.Get("/assets?asset_issuer=GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4")
-	ht.Assert.Equal(200, w.Code)
-
-	w = ht.Get("/assets?asset_issuer=invalid")
-	ht.Assert.Equal(400, w.Code)
+	ht.Assert.Equal(404, w.Code)
 }
 
-func TestAssetStatsDisabledByDefault(t *testing.T) {
+func TestAssetDailyStatsActions(t *testing.T) {
 	ht := StartHTTPTest(t, "ingest_asset_stats")
 	defer ht.Finish()
 
-	w := ht.Get("/assets?asset_issuer=GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4")
+	var id int64
+	err = ht.HorizonDB.Get(&id, `
+		SELECT id FROM history_assets
+		WHERE asset_code = 'USD' AND asset_issuer = 'GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4'
+	`)
+	ht.Require.NoError(err)
+
+	q := &history.Q{Session: ht.HorizonSession()}
+
+	day := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	ht.Require.NoError(q.UpdateAssetDailyStats(history.AssetStat{
+		ID:                       id,
+		Amount:                   "1000000000",
+		NumAccounts:              2,
+		NumUnauthorizedAccounts:  1,
+		UnauthorizedAmount:       "50000000",
+		AmountInOffers:           "20000000",
+		NumHoldersAboveThreshold: 1,
+	}, day))
+	_, err = ht.HorizonDB.Exec(`
+		INSERT INTO history_asset_payments VALUES
+		(1, $1, 10000000, '2018-06-01 13:00:00'),
+		(2, $1, 30000000, '2018-06-03 10:00:00')
+	`, id)
+	ht.Require.NoError(err)
+
+	var records []horizon.AssetDailyStat
+	w := ht.Get("/assets/daily_stats?asset_type=credit_alphanum4&asset_code=USD&asset_issuer=GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4")
+	if ht.Assert.Equal(200, w.Code) && ht.Assert.PageOf(2, w.Body) {
+		ht.UnmarshalPage(w.Body, &records)
+		ht.Assert.Equal("2018-06-01", records[0].Day)
+		ht.Assert.Equal("100.0000000", records[0].Amount)
+		ht.Assert.Equal(int32(1), records[0].NumUnauthorizedAccounts)
+		ht.Assert.Equal("5.0000000", records[0].UnauthorizedAmount)
+		ht.Assert.Equal("2.0000000", records[0].AmountInOffers)
+		ht.Assert.Equal(int32(1), records[0].PaymentCount)
+		ht.Assert.Equal("1.0000000", records[0].PaymentVolume)
+
+		// days without updated stats carry over the latest earlier ones
+		ht.Assert.Equal("2018-06-03", records[1].Day)
+		ht.Assert.Equal("100.0000000", records[1].Amount)
+		ht.Assert.Equal(int32(1), records[1].PaymentCount)
+		ht.Assert.Equal("3.0000000", records[1].PaymentVolume)
+	}
+
+	w = ht.Get("/assets/daily_stats?asset_type=credit_alphanum4&asset_code=EUR&asset_issuer=GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4")
 	ht.Assert.Equal(404, w.Code)
 }
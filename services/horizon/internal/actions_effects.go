@@ -3,9 +3,11 @@ package horizon
 import (
 	"fmt"
 	"regexp"
+	gTime "time"
 
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/render/export"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/render/sse"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/resourceadapter"
 	"github.com/lomocoin/stellar-go/support/errors"
@@ -16,15 +18,29 @@ import (
 //
 // EffectIndexAction: pages of effects
 
+// effectExportColumns are the columns of CSV exports of effects: the fields
+// common to all effects and the fields of the effects moving funds.
+var effectExportColumns = []string{
+	"id", "paging_token", "account", "type", "created_at", "starting_balance",
+	"amount", "asset_type", "asset_code", "asset_issuer",
+	"seller", "offer_id",
+	"sold_amount", "sold_asset_type", "sold_asset_code", "sold_asset_issuer",
+	"bought_amount", "bought_asset_type", "bought_asset_code", "bought_asset_issuer",
+}
+
 // EffectIndexAction renders a page of effect resources, identified by
 // a normal page query and optionally filtered by an account, ledger,
-// transaction, or operation.
+// transaction, or operation, and searched by effect type and time range.
 type EffectIndexAction struct {
 	Action
 	AccountFilter     string
 	LedgerFilter      int32
 	TransactionFilter string
 	OperationFilter   int64
+	TypeFilter        history.EffectType
+	HasTypeFilter     bool
+	StartTimeFilter   gTime.Time
+	EndTimeFilter     gTime.Time
 
 	PagingParams db2.PageQuery
 	Records      []history.Effect
@@ -44,6 +60,7 @@ func (action *EffectIndexAction) JSON() {
 	)
 
 	action.Do(func() {
+		action.SetImmutablePage(action.PagingParams, len(action.Records), action.LedgerFilter)
 		hal.Render(action.W, action.Page)
 	})
 }
@@ -87,6 +104,29 @@ func (action *EffectIndexAction) SSE(stream sse.Stream) {
 	)
 }
 
+// Export is a method for actions.Export
+func (action *EffectIndexAction) Export(stream export.Stream) {
+	action.Do(
+		action.EnsureHistoryFreshness,
+		action.loadParams,
+		action.ValidateCursorWithinHistory,
+		func() {
+			stream.SetColumns(effectExportColumns...)
+			action.ExportPages(stream, action.PagingParams, action.loadExportPage)
+		},
+	)
+}
+
+// loadExportPage loads a page of the effects exported, starting after the
+// cursor of `pq`.
+func (action *EffectIndexAction) loadExportPage(pq db2.PageQuery) []hal.Pageable {
+	action.PagingParams = pq
+	action.Records = nil
+	action.Page = hal.Page{}
+	action.Do(action.loadRecords, action.loadLedgers, action.loadPage)
+	return action.Page.Embedded.Records
+}
+
 // loadLedgers populates the ledger cache for this action
 func (action *EffectIndexAction) loadLedgers() {
 	action.Ledgers = &history.LedgerCache{}
@@ -105,6 +145,20 @@ func (action *EffectIndexAction) loadParams() {
 	action.LedgerFilter = action.GetInt32("ledger_id")
 	action.TransactionFilter = action.GetString("tx_id")
 	action.OperationFilter = action.GetInt64("op_id")
+	action.StartTimeFilter = optionalTime(action.GetTimeMillis("start_time"))
+	action.EndTimeFilter = optionalTime(action.GetTimeMillis("end_time"))
+
+	if typ := action.GetString("type"); typ != "" {
+		for t, name := range resourceadapter.EffectTypeNames {
+			if name == typ {
+				action.TypeFilter, action.HasTypeFilter = t, true
+				break
+			}
+		}
+		if !action.HasTypeFilter {
+			action.SetInvalidField("type", errors.New("unknown effect type"))
+		}
+	}
 }
 
 // loadRecords populates action.Records
@@ -122,6 +176,13 @@ func (action *EffectIndexAction) loadRecords() {
 		effects.ForTransaction(action.TransactionFilter)
 	}
 
+	if action.HasTypeFilter {
+		effects.OfType(action.TypeFilter)
+	}
+	if !action.StartTimeFilter.IsZero() || !action.EndTimeFilter.IsZero() {
+		effects.ForTimeRange(action.StartTimeFilter, action.EndTimeFilter)
+	}
+
 	action.Err = effects.Page(action.PagingParams).Select(&action.Records)
 }
 
@@ -75,6 +75,36 @@ func TestEffectActions_Index(t *testing.T) {
 		ht.Logger.Error(w.Body.String())
 	})
 
+	t.Run("search filters", func(t *testing.T) {
+		ht := StartHTTPTest(t, "base")
+		defer ht.Finish()
+
+		// filtered by type
+		w := ht.Get("/effects?type=account_created")
+		if ht.Assert.Equal(200, w.Code) {
+			ht.Assert.PageOf(3, w.Body)
+		}
+
+		w = ht.Get("/accounts/GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU/effects?type=account_debited")
+		if ht.Assert.Equal(200, w.Code) {
+			ht.Assert.PageOf(1, w.Body)
+		}
+
+		w = ht.Get("/effects?type=bogus")
+		ht.Assert.Equal(400, w.Code)
+
+		// filtered by time range
+		w = ht.Get("/effects?limit=20&start_time=1")
+		if ht.Assert.Equal(200, w.Code) {
+			ht.Assert.PageOf(11, w.Body)
+		}
+
+		w = ht.Get("/effects?end_time=1000")
+		if ht.Assert.Equal(200, w.Code) {
+			ht.Assert.PageOf(0, w.Body)
+		}
+	})
+
 	t.Run("Effect resource props", func(t *testing.T) {
 		ht := StartHTTPTest(t, "base")
 		defer ht.Finish()
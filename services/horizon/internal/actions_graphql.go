@@ -0,0 +1,117 @@
+package horizon
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+
+	gographql "github.com/graph-gophers/graphql-go"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/core"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/graphql"
+	"github.com/lomocoin/stellar-go/support/errors"
+	"github.com/lomocoin/stellar-go/support/render/hal"
+)
+
+// This file contains the actions:
+//
+// GraphQLAction: resolves a GraphQL query
+
+// maxGraphQLBodySize is the maximum size of the body of a GraphQL request.
+const maxGraphQLBodySize = 64 * 1024
+
+// GraphQLAction resolves a GraphQL query against the history and core
+// databases.  Queries are read from the `query`, `operationName` and
+// `variables` parameters or, when POSTed, from a JSON body or an
+// `application/graphql` body.  The cost of the query is charged against the
+// rate limit of the request.
+type GraphQLAction struct {
+	Action
+	Request  graphql.Request
+	Response *gographql.Response
+}
+
+// JSON is a method for actions.JSON
+func (action *GraphQLAction) JSON() {
+	action.Do(
+		action.loadParams,
+		action.loadResponse,
+		func() {
+			hal.Render(action.W, action.Response)
+		},
+	)
+}
+
+func (action *GraphQLAction) loadParams() {
+	if action.R.Method == http.MethodPost {
+		mt, _, _ := mime.ParseMediaType(action.R.Header.Get("Content-Type"))
+		switch mt {
+		case "application/json":
+			err := json.NewDecoder(io.LimitReader(action.R.Body, maxGraphQLBodySize)).
+				Decode(&action.Request)
+			if err != nil {
+				action.SetInvalidField("body", errors.Wrap(err, "invalid JSON"))
+			}
+			action.validateQuery()
+			return
+		case "application/graphql":
+			body, err := ioutil.ReadAll(io.LimitReader(action.R.Body, maxGraphQLBodySize))
+			if err != nil {
+				action.Err = err
+				return
+			}
+			action.Request.Query = string(body)
+			action.validateQuery()
+			return
+		}
+
+		action.ValidateBodyType()
+	}
+
+	action.Request.Query = action.GetString("query")
+	action.Request.OperationName = action.GetString("operationName")
+	variables := action.GetString("variables")
+	if action.Err != nil {
+		return
+	}
+
+	if variables != "" {
+		err := json.Unmarshal([]byte(variables), &action.Request.Variables)
+		if err != nil {
+			action.SetInvalidField("variables", errors.New("must be a JSON object"))
+			return
+		}
+	}
+	action.validateQuery()
+}
+
+func (action *GraphQLAction) validateQuery() {
+	if action.Err == nil && action.Request.Query == "" {
+		action.SetInvalidField("query", errors.New("query is required"))
+	}
+}
+
+func (action *GraphQLAction) loadResponse() {
+	// queries are read-only, even when POSTed: they are served from the read
+	// replicas.
+	ctx := action.R.Context()
+	params := graphql.Params{
+		HistoryQ:        &history.Q{Session: action.App.HorizonReadSession(ctx)},
+		CoreQ:           &core.Q{Session: action.App.CoreReadSession(ctx)},
+		ProtocolVersion: action.App.protocolVersion,
+		MaxCost:         action.App.config.GraphQLMaxCost,
+	}
+	if params.MaxCost <= 0 {
+		params.MaxCost = graphql.DefaultMaxCost
+	}
+
+	rateLimiter := action.App.GetRateLimiter(action.R)
+	if rateLimiter != nil {
+		params.RateLimiter = rateLimiter.RateLimiter
+		params.RateLimitKey = rateLimiter.VaryBy.Key(action.R)
+	}
+
+	action.Response = graphql.Exec(ctx, params, action.Request)
+}
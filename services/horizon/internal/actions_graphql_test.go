@@ -0,0 +1,84 @@
+package horizon
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+	Extensions struct {
+		Cost struct {
+			Total int32 `json:"total"`
+			Max   int32 `json:"max"`
+		} `json:"cost"`
+	} `json:"extensions"`
+}
+
+func TestGraphQLAction(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	// a query is required
+	w := ht.Get("/graphql")
+	ht.Assert.Equal(400, w.Code)
+
+	query := `{ ledger(sequence: 1) { sequence transactions(first: 5) { edges { node { hash } } } } }`
+	w = ht.Get("/graphql?query=" + url.QueryEscape(query))
+	if ht.Assert.Equal(200, w.Code) {
+		var resp graphQLResponse
+		ht.Require.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+		ht.Assert.Empty(resp.Errors)
+		ht.Assert.Contains(string(resp.Data), `"sequence":1`)
+		ht.Assert.Equal(int32(6), resp.Extensions.Cost.Total)
+	}
+
+	// form encoded
+	w = ht.RH.Post("/graphql", url.Values{"query": {query}})
+	ht.Assert.Equal(200, w.Code)
+
+	// JSON body
+	body := `{"query":"query L($seq: Int!) { ledger(sequence: $seq) { sequence } }","variables":{"seq":1}}`
+	w = ht.RH.Post("/graphql", nil, func(r *http.Request) {
+		r.Header.Set("Content-Type", "application/json")
+		r.Body = ioutil.NopCloser(strings.NewReader(body))
+		r.ContentLength = int64(len(body))
+	})
+	if ht.Assert.Equal(200, w.Code) {
+		var resp graphQLResponse
+		ht.Require.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+		ht.Assert.Empty(resp.Errors)
+		ht.Assert.Contains(string(resp.Data), `"sequence":1`)
+	}
+}
+
+func TestGraphQLAction_MaxCost(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	c := NewTestConfig()
+	c.GraphQLMaxCost = 15
+	app, _ := NewApp(c)
+	defer app.Close()
+	rh := NewRequestHelper(app)
+
+	query := `{ transactions(first: 10) { edges { cursor } } effects(first: 10) { edges { cursor } } }`
+	w := rh.Get("/graphql?query=" + url.QueryEscape(query))
+	if assert.Equal(t, 200, w.Code) {
+		var resp graphQLResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		if assert.NotEmpty(t, resp.Errors) {
+			assert.Contains(t, resp.Errors[0].Message, "query cost exceeds the maximum of 15")
+		}
+		assert.Equal(t, int32(15), resp.Extensions.Cost.Max)
+	}
+}
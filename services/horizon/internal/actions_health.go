@@ -0,0 +1,110 @@
+package horizon
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lomocoin/stellar-go/protocols/horizon"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/httpx"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/ledger"
+	"github.com/lomocoin/stellar-go/support/render/hal"
+)
+
+// This file contains the actions:
+//
+// HealthAction: reports whether horizon is alive, that is able to reach its
+// database, along with the result of every other check
+// ReadyAction: reports whether horizon is ready to serve requests, that is
+// when every check passes
+
+// Health check names
+const (
+	HealthCheckHistoryDB   = "history_db"
+	HealthCheckCoreDB      = "core_db"
+	HealthCheckStellarCore = "stellar_core"
+	HealthCheckIngestion   = "ingestion"
+)
+
+// HealthAction renders the result of horizon's health checks, responding with
+// 503 Service Unavailable only when the history database cannot be reached.
+type HealthAction struct {
+	Action
+	Resource horizon.Health
+}
+
+// JSON is a method for actions.JSON
+func (action *HealthAction) JSON() {
+	action.Resource = action.App.Health(action.R.Context())
+	lb := hal.LinkBuilder{Base: httpx.BaseURL(action.R.Context())}
+	action.Resource.Links.Self = lb.Link("/health")
+
+	status := http.StatusOK
+	if !action.Resource.Checks[HealthCheckHistoryDB].OK {
+		status = http.StatusServiceUnavailable
+	}
+
+	renderHealth(action.W, status, action.Resource)
+}
+
+// ReadyAction renders the result of horizon's health checks, responding with
+// 503 Service Unavailable unless every check passes.  Load balancers should
+// use it to stop routing requests to nodes that are lagging behind the network.
+type ReadyAction struct {
+	Action
+	Resource horizon.Health
+}
+
+// JSON is a method for actions.JSON
+func (action *ReadyAction) JSON() {
+	action.Resource = action.App.Health(action.R.Context())
+	lb := hal.LinkBuilder{Base: httpx.BaseURL(action.R.Context())}
+	action.Resource.Links.Self = lb.Link("/ready")
+
+	status := http.StatusOK
+	if action.Resource.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+
+	renderHealth(action.W, status, action.Resource)
+}
+
+// renderHealth writes `resource` using `status` as the response's status code,
+// which hal.Render does not allow.
+func renderHealth(w http.ResponseWriter, status int, resource horizon.Health) {
+	js, err := hal.RenderToString(resource, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", "inline")
+	w.Header().Set("Content-Type", "application/hal+json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(status)
+	w.Write(js)
+}
+
+// pingCheck returns the result of checking the connectivity to `db`.
+func pingCheck(ctx context.Context, db *sqlx.DB) horizon.HealthCheck {
+	err := db.PingContext(ctx)
+	if err != nil {
+		return horizon.HealthCheck{Error: err.Error()}
+	}
+
+	return horizon.HealthCheck{OK: true}
+}
+
+// ingestionCheck returns the result of checking the ingestion lag described by
+// `ls` against `threshold`, a threshold of 0 disabling the check.
+func ingestionCheck(ls ledger.State, threshold uint) horizon.HealthCheck {
+	behind := ls.CoreLatest - ls.HistoryLatest
+	if threshold == 0 || behind <= int32(threshold) {
+		return horizon.HealthCheck{OK: true}
+	}
+
+	return horizon.HealthCheck{
+		Error: fmt.Sprintf("history is %d ledgers behind stellar-core, more than the threshold of %d", behind, threshold),
+	}
+}
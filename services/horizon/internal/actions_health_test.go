@@ -0,0 +1,93 @@
+package horizon
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/lomocoin/stellar-go/protocols/horizon"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/ledger"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/test"
+	"github.com/throttled/throttled"
+)
+
+func TestHealthActions(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	server := test.NewStaticMockServer(`{
+			"info": {
+				"build": "test-core",
+				"protocol_version": 4,
+				"state": "Catching up"
+			}
+		}`)
+	defer server.Close()
+
+	ht.App.config.StellarCoreURL = server.URL
+	ht.App.UpdateStellarCoreInfo()
+
+	w := ht.Get("/health")
+	if ht.Assert.Equal(200, w.Code) {
+		var actual horizon.Health
+		err := json.Unmarshal(w.Body.Bytes(), &actual)
+		ht.Require.NoError(err)
+		ht.Assert.Equal("unavailable", actual.Status)
+		ht.Assert.True(actual.Checks[HealthCheckHistoryDB].OK)
+		ht.Assert.True(actual.Checks[HealthCheckCoreDB].OK)
+		ht.Assert.False(actual.Checks[HealthCheckStellarCore].OK)
+		ht.Assert.Equal("Catching up", actual.CoreState)
+	}
+
+	w = ht.Get("/ready")
+	ht.Assert.Equal(503, w.Code)
+}
+
+// The health checks are neither rate limited nor subject to the API keys.
+func TestHealthActions_RateLimit(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	file := writeAPIKeysFile(t)
+	defer os.Remove(file)
+
+	c := NewTestConfig()
+	c.RateLimit = &throttled.RateQuota{
+		MaxRate:  throttled.PerHour(10),
+		MaxBurst: 0,
+	}
+	c.APIKeysFile = file
+	app, _ := NewApp(c)
+	defer app.Close()
+	rh := NewRequestHelper(app)
+
+	ht.Assert.Equal(200, rh.Get("/").Code)
+	ht.Assert.Equal(429, rh.Get("/").Code)
+	ht.Assert.Equal(401, rh.Get("/", requestHelperAPIKey("unknown")).Code)
+
+	for i := 0; i < 3; i++ {
+		w := rh.Get("/health")
+		ht.Assert.Equal(200, w.Code)
+		ht.Assert.Equal("", w.Header().Get("X-RateLimit-Limit"))
+		ht.Assert.NotEqual(401, rh.Get("/ready", requestHelperAPIKey("unknown")).Code)
+	}
+}
+
+func TestIngestionCheck(t *testing.T) {
+	ls := ledger.State{CoreLatest: 10, HistoryLatest: 5}
+
+	check := ingestionCheck(ls, 0)
+	if !check.OK {
+		t.Error("expected check to pass with threshold 0")
+	}
+
+	check = ingestionCheck(ls, 5)
+	if !check.OK {
+		t.Error("expected check to pass when lag equals threshold")
+	}
+
+	check = ingestionCheck(ls, 4)
+	if check.OK || check.Error == "" {
+		t.Error("expected check to fail when lag exceeds threshold")
+	}
+}
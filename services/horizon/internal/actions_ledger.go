@@ -33,6 +33,9 @@ func (action *LedgerIndexAction) JSON() {
 		action.ValidateCursorWithinHistory,
 		action.loadRecords,
 		action.loadPage,
+		func() {
+			action.SetImmutablePage(action.PagingParams, len(action.Records), 0)
+		},
 		func() { hal.Render(action.W, action.Page) },
 	)
 }
@@ -98,6 +101,7 @@ func (action *LedgerShowAction) JSON() {
 		action.loadParams,
 		action.verifyWithinHistory,
 		action.loadRecord,
+		action.SetImmutable,
 		func() {
 			var res horizon.Ledger
 			resourceadapter.PopulateLedger(action.R.Context(), &res, action.Record)
@@ -0,0 +1,140 @@
+package horizon
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lomocoin/stellar-go/protocols/horizon"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/actions"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/assets"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/core"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/markets"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/resourceadapter"
+	"github.com/lomocoin/stellar-go/support/errors"
+	"github.com/lomocoin/stellar-go/support/render/hal"
+	"github.com/lomocoin/stellar-go/xdr"
+)
+
+// This file contains the actions:
+//
+// MarketsAction: pages of markets
+
+// marketWindow is the period over which the trading activity of markets is
+// summarized.
+const marketWindow = 24 * time.Hour
+
+// MarketsAction renders a page of the asset pairs traded over the last 24
+// hours.
+type MarketsAction struct {
+	Action
+	Sort         string
+	PagingParams db2.PageQuery
+	Records      []markets.MarketR
+	Page         hal.Page
+}
+
+// JSON is a method for actions.JSON
+func (action *MarketsAction) JSON() {
+	action.Do(
+		action.EnsureHistoryFreshness,
+		action.loadParams,
+		action.loadRecords,
+		action.loadPage,
+		func() {
+			hal.Render(action.W, action.Page)
+		},
+	)
+}
+
+func (action *MarketsAction) loadParams() {
+	action.Sort = action.GetString("sort")
+	switch action.Sort {
+	case "":
+		action.Sort = markets.SortByPair
+	case markets.SortByPair, markets.SortByTradeCount, markets.SortByBaseVolume, markets.SortByCounterVolume:
+	default:
+		action.SetInvalidField("sort", fmt.Errorf(
+			"must be one of: %s, %s, %s, %s",
+			markets.SortByPair, markets.SortByTradeCount, markets.SortByBaseVolume, markets.SortByCounterVolume,
+		))
+		return
+	}
+
+	action.PagingParams = action.GetPageQuery(actions.DisableCursorValidation)
+}
+
+func (action *MarketsAction) loadRecords() {
+	sql, err := markets.MarketsQ{
+		Since:     time.Now().UTC().Add(-marketWindow),
+		Sort:      action.Sort,
+		PageQuery: action.PagingParams,
+	}.GetSQL()
+	if err != nil {
+		action.Err = err
+		return
+	}
+	action.Err = action.HistoryQ().Select(&action.Records, sql)
+}
+
+func (action *MarketsAction) loadPage() {
+	// the order books of every market of the page are loaded at once: the
+	// asks sell the base asset, the bids sell the counter asset.
+	pairs := make([][2]xdr.Asset, 0, 2*len(action.Records))
+	for _, record := range action.Records {
+		base, err := marketAsset(record.BaseAssetType, record.BaseAssetCode, record.BaseAssetIssuer)
+		if err != nil {
+			action.Err = err
+			return
+		}
+		counter, err := marketAsset(record.CounterAssetType, record.CounterAssetCode, record.CounterAssetIssuer)
+		if err != nil {
+			action.Err = err
+			return
+		}
+		pairs = append(pairs, [2]xdr.Asset{base, counter}, [2]xdr.Asset{counter, base})
+	}
+
+	var offers []core.Offer
+	err := action.CoreQ().BestOffers(&offers, pairs)
+	if err != nil {
+		action.Err = errors.Wrap(err, "failed to load order books")
+		return
+	}
+
+	best := map[string]*core.Offer{}
+	for i := range offers {
+		selling, buying, err := offers[i].Assets()
+		if err != nil {
+			action.Err = err
+			return
+		}
+		best[selling.String()+"/"+buying.String()] = &offers[i]
+	}
+
+	for i, record := range action.Records {
+		base, counter := pairs[2*i][0], pairs[2*i][1]
+		ask := best[base.String()+"/"+counter.String()]
+		bid := best[counter.String()+"/"+base.String()]
+
+		var res horizon.Market
+		resourceadapter.PopulateMarket(action.R.Context(), &res, record, ask, bid)
+		action.Page.Add(res)
+	}
+
+	action.Page.FullURL = action.FullURL()
+	action.Page.Limit = action.PagingParams.Limit
+	action.Page.Cursor = action.PagingParams.Cursor
+	action.Page.Order = action.PagingParams.Order
+	action.Page.PopulateLinks()
+}
+
+// marketAsset builds the xdr.Asset stored in `history_assets` as the given
+// type, code and issuer.
+func marketAsset(typ, code, issuer string) (xdr.Asset, error) {
+	t, err := assets.Parse(typ)
+	if err != nil {
+		return xdr.Asset{}, errors.Wrap(err, "invalid asset type")
+	}
+	return core.AssetFromDB(t, code, issuer)
+}
@@ -0,0 +1,68 @@
+package horizon
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/lomocoin/stellar-go/protocols/horizon"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
+	. "github.com/lomocoin/stellar-go/services/horizon/internal/test/trades"
+	stime "github.com/lomocoin/stellar-go/support/time"
+)
+
+func TestMarketsAction(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	seller := GetTestAccount()
+	buyer := GetTestAccount()
+	usd := GetTestAsset("usd")
+	eur := GetTestAsset("eur")
+	btc := GetTestAsset("btc")
+
+	recent := stime.MillisFromInt64(stime.Now().ToInt64() - hour)
+	old := stime.MillisFromInt64(stime.Now().ToInt64() - 2*day)
+
+	dbQ := &history.Q{Session: ht.HorizonSession()}
+	ht.Require.NoError(IngestTestTrade(dbQ, usd, eur, seller, buyer, 10, 20, old, 1))
+	ht.Require.NoError(IngestTestTrade(dbQ, usd, eur, seller, buyer, 10, 30, recent, 2))
+	ht.Require.NoError(IngestTestTrade(dbQ, usd, eur, seller, buyer, 10, 10, recent, 3))
+	ht.Require.NoError(IngestTestTrade(dbQ, usd, btc, seller, buyer, 100, 1, recent, 4))
+
+	var records []horizon.Market
+	w := ht.Get("/markets")
+	if ht.Assert.Equal(200, w.Code) && ht.Assert.PageOf(2, w.Body) {
+		ht.UnmarshalPage(w.Body, &records)
+		ht.Assert.Equal("usd", records[0].BaseAssetCode)
+		ht.Assert.Equal("eur", records[0].CounterAssetCode)
+		ht.Assert.Equal(int64(2), records[0].TradeCount)
+		ht.Assert.Equal("0.0000020", records[0].BaseVolume)
+		ht.Assert.Equal("0.0000040", records[0].CounterVolume)
+		ht.Assert.Equal("3.0000000", records[0].Open)
+		ht.Assert.Equal("3.0000000", records[0].High)
+		ht.Assert.Equal("1.0000000", records[0].Low)
+		ht.Assert.Equal("1.0000000", records[0].Close)
+		ht.Assert.Equal("", records[0].BestBid)
+		ht.Assert.Equal("", records[0].BestAsk)
+	}
+
+	q := make(url.Values)
+	q.Add("sort", "trade_count")
+	q.Add("order", "asc")
+	q.Add("limit", "1")
+	w = ht.GetWithParams("/markets", q)
+	if ht.Assert.Equal(200, w.Code) && ht.Assert.PageOf(1, w.Body) {
+		ht.UnmarshalPage(w.Body, &records)
+		ht.Assert.Equal("btc", records[0].CounterAssetCode)
+		ht.Assert.Equal(int64(1), records[0].TradeCount)
+	}
+
+	w = ht.Get(ht.UnmarshalNext(w.Body))
+	if ht.Assert.Equal(200, w.Code) && ht.Assert.PageOf(1, w.Body) {
+		ht.UnmarshalPage(w.Body, &records)
+		ht.Assert.Equal("eur", records[0].CounterAssetCode)
+	}
+
+	w = ht.Get("/markets?sort=price")
+	ht.Assert.Equal(400, w.Code)
+}
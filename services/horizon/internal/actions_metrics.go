@@ -1,12 +1,20 @@
 package horizon
 
 import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
 	"github.com/rcrowley/go-metrics"
 	"github.com/lomocoin/stellar-go/support/render/hal"
 )
 
 // MetricsAction collects and renders a snapshot from the metrics system that
-// will inlude any previously registered metrics.
+// will inlude any previously registered metrics.  The snapshot is rendered as
+// JSON or, when `text/plain` is requested, in the Prometheus text exposition
+// format.
 type MetricsAction struct {
 	Action
 	Snapshot map[string]interface{}
@@ -86,3 +94,140 @@ func (action *MetricsAction) LoadSnapshot() {
 	})
 
 }
+
+// Text renders the registered metrics in the Prometheus text exposition
+// format.  Metric names are prefixed with `horizon_` and the labels embedded
+// in a metric's registered name (e.g. `requests.duration{route="/ledgers"}`)
+// become Prometheus labels.  Counters and meters are exposed as counters,
+// gauges as gauges, request timers as histograms and the other timers and
+// histograms as summaries.
+func (action *MetricsAction) Text() {
+	families := map[string]*promFamily{}
+	family := func(name, typ string) *promFamily {
+		f, ok := families[name]
+		if !ok {
+			f = &promFamily{typ: typ}
+			families[name] = f
+		}
+		return f
+	}
+
+	action.App.metrics.Each(func(registered string, i interface{}) {
+		name, labels := promName(registered)
+
+		switch metric := i.(type) {
+		case metrics.Counter:
+			family(name+"_total", "counter").add(labels, name+"_total", labels, float64(metric.Count()))
+		case metrics.Meter:
+			family(name+"_total", "counter").add(labels, name+"_total", labels, float64(metric.Count()))
+		case metrics.Gauge:
+			family(name, "gauge").add(labels, name, labels, float64(metric.Value()))
+		case metrics.GaugeFloat64:
+			family(name, "gauge").add(labels, name, labels, metric.Value())
+		case *requestTimer:
+			cumulative, count, sum := metric.Histogram()
+			name += "_seconds"
+			f := family(name, "histogram")
+			for i, bound := range requestDurationBuckets {
+				f.add(labels, name+"_bucket", promLabel(labels, "le", fmt.Sprintf("%v", bound)), float64(cumulative[i]))
+			}
+			f.add(labels, name+"_bucket", promLabel(labels, "le", "+Inf"), float64(count))
+			f.add(labels, name+"_sum", labels, sum)
+			f.add(labels, name+"_count", labels, float64(count))
+		case metrics.Timer:
+			t := metric.Snapshot()
+			name += "_seconds"
+			f := family(name, "summary")
+			for i, p := range t.Percentiles(promQuantiles) {
+				f.add(labels, name, promQuantile(labels, promQuantiles[i]), p/1e9)
+			}
+			f.add(labels, name+"_sum", labels, float64(t.Sum())/1e9)
+			f.add(labels, name+"_count", labels, float64(t.Count()))
+		case metrics.Histogram:
+			h := metric.Snapshot()
+			f := family(name, "summary")
+			for i, p := range h.Percentiles(promQuantiles) {
+				f.add(labels, name, promQuantile(labels, promQuantiles[i]), p)
+			}
+			f.add(labels, name+"_sum", labels, float64(h.Sum()))
+			f.add(labels, name+"_count", labels, float64(h.Count()))
+		}
+	})
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out bytes.Buffer
+	for _, name := range names {
+		f := families[name]
+		sort.SliceStable(f.samples, func(i, j int) bool {
+			return f.samples[i].series < f.samples[j].series
+		})
+		fmt.Fprintf(&out, "# TYPE %s %s\n", name, f.typ)
+		for _, sample := range f.samples {
+			out.WriteString(sample.line)
+			out.WriteString("\n")
+		}
+	}
+
+	action.W.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	action.W.Write(out.Bytes())
+}
+
+// promQuantiles are the quantiles exposed for the Prometheus summaries.
+var promQuantiles = []float64{0.5, 0.75, 0.95, 0.99, 0.999}
+
+var promInvalidChars = regexp.MustCompile("[^a-zA-Z0-9_]")
+
+// promFamily is the set of samples exposed for a Prometheus metric family.
+type promFamily struct {
+	typ     string
+	samples []promSample
+}
+
+// promSample is a line of a Prometheus metric family.  Samples are sorted by
+// the labels of their series, keeping the order of the quantiles and buckets
+// of a series.
+type promSample struct {
+	series string
+	line   string
+}
+
+func (f *promFamily) add(series, name, labels string, value float64) {
+	if labels != "" {
+		name += "{" + labels + "}"
+	}
+	f.samples = append(f.samples, promSample{
+		series: series,
+		line:   fmt.Sprintf("%s %v", name, value),
+	})
+}
+
+// promName splits the name a metric was registered under into a Prometheus
+// metric name and the labels following it, if any.
+func promName(registered string) (name string, labels string) {
+	name = registered
+	if i := strings.Index(registered, "{"); i != -1 {
+		name = registered[:i]
+		labels = strings.TrimSuffix(registered[i+1:], "}")
+	}
+
+	return "horizon_" + promInvalidChars.ReplaceAllString(name, "_"), labels
+}
+
+// promQuantile adds the `quantile` label to `labels`.
+func promQuantile(labels string, q float64) string {
+	return promLabel(labels, "quantile", fmt.Sprintf("%v", q))
+}
+
+// promLabel adds the label `name` with `value` to `labels`.
+func promLabel(labels, name, value string) string {
+	label := fmt.Sprintf("%s=%q", name, value)
+	if labels == "" {
+		return label
+	}
+	return labels + "," + label
+}
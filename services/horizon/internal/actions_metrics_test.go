@@ -0,0 +1,65 @@
+package horizon
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMetricsAction(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	w := ht.Get("/metrics")
+	ht.Assert.Equal(200, w.Code)
+	ht.Assert.Contains(w.Body.String(), `"requests.total"`)
+
+	w = ht.Get("/metrics", func(r *http.Request) {
+		r.Header.Set("Accept", "text/plain")
+	})
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.Equal("text/plain; version=0.0.4", w.Header().Get("Content-Type"))
+		body := w.Body.String()
+		ht.Assert.Contains(body, "# TYPE horizon_requests_total_seconds histogram\n")
+		ht.Assert.Contains(body, "# TYPE horizon_requests_duration_seconds histogram\n")
+		ht.Assert.Contains(body, `horizon_requests_duration_seconds_bucket{route="/metrics",method="GET",le="10"} 1`)
+		ht.Assert.Contains(body, `horizon_requests_duration_seconds_bucket{route="/metrics",method="GET",le="+Inf"} 1`)
+		ht.Assert.Contains(body, `horizon_requests_duration_seconds_count{route="/metrics",method="GET"} 1`)
+		ht.Assert.Contains(body, "# TYPE horizon_requests_succeeded_total counter\n")
+		ht.Assert.Contains(body, `horizon_txsub_results_total{outcome="success"} 0`)
+		ht.Assert.Contains(body, "horizon_requests_open_streams 0\n")
+	}
+}
+
+func TestPromName(t *testing.T) {
+	name, labels := promName(`requests.duration{route="/ledgers",method="GET"}`)
+	if name != "horizon_requests_duration" || labels != `route="/ledgers",method="GET"` {
+		t.Errorf("unexpected name %q and labels %q", name, labels)
+	}
+
+	name, labels = promName("history.latest_ledger")
+	if name != "horizon_history_latest_ledger" || labels != "" {
+		t.Errorf("unexpected name %q and labels %q", name, labels)
+	}
+}
+
+func TestRequestTimer(t *testing.T) {
+	timer := newRequestTimer().(*requestTimer)
+	timer.Update(3 * time.Millisecond)
+	timer.Update(10 * time.Millisecond)
+	timer.Update(time.Second)
+	timer.Update(time.Minute)
+
+	cumulative, count, sum := timer.Histogram()
+	expected := []int64{1, 2, 2, 2, 2, 2, 2, 3, 3, 3, 3}
+	if !reflect.DeepEqual(cumulative, expected) {
+		t.Errorf("unexpected buckets %v", cumulative)
+	}
+	if count != 4 || timer.Count() != 4 {
+		t.Errorf("unexpected count %d", count)
+	}
+	if sum != 61.013 {
+		t.Errorf("unexpected sum %v", sum)
+	}
+}
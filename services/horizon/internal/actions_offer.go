@@ -7,10 +7,17 @@ import (
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/render/sse"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/resourceadapter"
+	"github.com/lomocoin/stellar-go/support/errors"
 	"github.com/lomocoin/stellar-go/support/render/hal"
+	"github.com/lomocoin/stellar-go/xdr"
 )
 
 // This file contains the actions:
+//
+// OffersByAccountAction: pages of offers for an account from stellar-core
+// OfferIndexAction: pages of offers, including removed ones, from history
+// OfferShowAction: latest state of a single offer from history
+// OfferEventIndexAction: pages of the changes made to a single offer
 
 // OffersByAccountAction renders a page of offer resources, for a given
 // account.  These offers are present in the ledger as of the latest validated
@@ -101,3 +108,264 @@ func (action *OffersByAccountAction) loadPage() {
 	action.Page.Order = action.PageQuery.Order
 	action.Page.PopulateLinks()
 }
+
+// OfferIndexAction renders a page of offer resources, including offers that
+// have since been filled or cancelled, as recorded by the ingestion system.
+type OfferIndexAction struct {
+	Action
+	SellerFilter          string
+	SellingAssetFilter    xdr.Asset
+	HasSellingAssetFilter bool
+	BuyingAssetFilter     xdr.Asset
+	HasBuyingAssetFilter  bool
+	StateFilter           string
+	PageQuery             db2.PageQuery
+	Records               []history.OfferEvent
+	Ledgers               *history.LedgerCache
+	Page                  hal.Page
+}
+
+// JSON is a method for actions.JSON
+func (action *OfferIndexAction) JSON() {
+	action.Do(
+		action.EnsureHistoryFreshness,
+		action.loadParams,
+		action.loadRecords,
+		action.loadLedgers,
+		action.loadPage,
+		func() {
+			hal.Render(action.W, action.Page)
+		},
+	)
+}
+
+// SSE is a method for actions.SSE
+func (action *OfferIndexAction) SSE(stream sse.Stream) {
+	action.Do(
+		action.EnsureHistoryFreshness,
+		action.loadParams,
+		action.loadRecords,
+		action.loadLedgers,
+		func() {
+			stream.SetLimit(int(action.PageQuery.Limit))
+			for _, record := range action.Records[stream.SentCount():] {
+				ledger, found := action.Ledgers.Records[record.LedgerSequence]
+				ledgerPtr := &ledger
+				if !found {
+					ledgerPtr = nil
+				}
+				var res horizon.Offer
+				resourceadapter.PopulateHistoryOffer(action.R.Context(), &res, record, ledgerPtr)
+				stream.Send(sse.Event{ID: res.PagingToken(), Data: res})
+			}
+		},
+	)
+}
+
+func (action *OfferIndexAction) loadParams() {
+	action.PageQuery = action.GetPageQuery()
+	action.SellerFilter = action.GetAddress("seller")
+	action.SellingAssetFilter, action.HasSellingAssetFilter = action.MaybeGetAsset("selling_")
+	action.BuyingAssetFilter, action.HasBuyingAssetFilter = action.MaybeGetAsset("buying_")
+	action.StateFilter = action.GetString("state")
+
+	switch action.StateFilter {
+	case "", history.OfferStateActive, history.OfferStateFilled, history.OfferStateCancelled:
+	default:
+		action.SetInvalidField("state", errors.New("must be one of: active, filled, cancelled"))
+	}
+}
+
+func (action *OfferIndexAction) loadRecords() {
+	offers := action.HistoryQ().Offers()
+
+	if action.SellerFilter != "" {
+		offers.ForSeller(action.SellerFilter)
+	}
+
+	if action.HasSellingAssetFilter {
+		offers.ForSellingAsset(action.SellingAssetFilter)
+	}
+
+	if action.HasBuyingAssetFilter {
+		offers.ForBuyingAsset(action.BuyingAssetFilter)
+	}
+
+	if action.StateFilter != "" {
+		offers.ForState(action.StateFilter)
+	}
+
+	action.Err = offers.Page(action.PageQuery).Select(&action.Records)
+}
+
+// loadLedgers populates the ledger cache for this action
+func (action *OfferIndexAction) loadLedgers() {
+	action.Ledgers = &history.LedgerCache{}
+
+	for _, offer := range action.Records {
+		action.Ledgers.Queue(offer.LedgerSequence)
+	}
+	action.Err = action.Ledgers.Load(action.HistoryQ())
+}
+
+func (action *OfferIndexAction) loadPage() {
+	for _, record := range action.Records {
+		ledger, found := action.Ledgers.Records[record.LedgerSequence]
+		ledgerPtr := &ledger
+		if !found {
+			ledgerPtr = nil
+		}
+
+		var res horizon.Offer
+		resourceadapter.PopulateHistoryOffer(action.R.Context(), &res, record, ledgerPtr)
+		action.Page.Add(res)
+	}
+
+	action.Page.FullURL = action.FullURL()
+	action.Page.Limit = action.PageQuery.Limit
+	action.Page.Cursor = action.PageQuery.Cursor
+	action.Page.Order = action.PageQuery.Order
+	action.Page.PopulateLinks()
+}
+
+// OfferShowAction renders the latest state of a single offer, as recorded by
+// the ingestion system.
+type OfferShowAction struct {
+	Action
+	OfferID  int64
+	Record   history.OfferEvent
+	Ledger   history.Ledger
+	Resource horizon.Offer
+}
+
+// JSON is a method for actions.JSON
+func (action *OfferShowAction) JSON() {
+	action.Do(
+		action.EnsureHistoryFreshness,
+		action.loadParams,
+		action.loadRecord,
+		action.loadResource,
+		func() {
+			hal.Render(action.W, action.Resource)
+		},
+	)
+}
+
+func (action *OfferShowAction) loadParams() {
+	action.OfferID = action.GetInt64("id")
+}
+
+func (action *OfferShowAction) loadRecord() {
+	action.Err = action.HistoryQ().OfferByID(&action.Record, action.OfferID)
+	if action.Err != nil {
+		return
+	}
+
+	action.Err = action.HistoryQ().
+		LedgerBySequence(&action.Ledger, action.Record.LedgerSequence)
+}
+
+func (action *OfferShowAction) loadResource() {
+	resourceadapter.PopulateHistoryOffer(
+		action.R.Context(),
+		&action.Resource,
+		action.Record,
+		&action.Ledger,
+	)
+}
+
+// OfferEventIndexAction renders a page of the changes made to a single offer.
+type OfferEventIndexAction struct {
+	Action
+	OfferID   int64
+	PageQuery db2.PageQuery
+	Records   []history.OfferEvent
+	Ledgers   *history.LedgerCache
+	Page      hal.Page
+}
+
+// JSON is a method for actions.JSON
+func (action *OfferEventIndexAction) JSON() {
+	action.Do(
+		action.EnsureHistoryFreshness,
+		action.loadParams,
+		action.loadRecords,
+		action.loadLedgers,
+		action.loadPage,
+		func() {
+			hal.Render(action.W, action.Page)
+		},
+	)
+}
+
+// SSE is a method for actions.SSE
+func (action *OfferEventIndexAction) SSE(stream sse.Stream) {
+	action.Do(
+		action.EnsureHistoryFreshness,
+		action.loadParams,
+		action.loadRecords,
+		action.loadLedgers,
+		func() {
+			stream.SetLimit(int(action.PageQuery.Limit))
+			for _, record := range action.Records[stream.SentCount():] {
+				ledger, found := action.Ledgers.Records[record.LedgerSequence]
+				ledgerPtr := &ledger
+				if !found {
+					ledgerPtr = nil
+				}
+				var res horizon.OfferEvent
+				resourceadapter.PopulateOfferEvent(action.R.Context(), &res, record, ledgerPtr)
+				stream.Send(sse.Event{ID: res.PagingToken(), Data: res})
+			}
+		},
+	)
+}
+
+func (action *OfferEventIndexAction) loadParams() {
+	action.PageQuery = action.GetPageQuery()
+	action.OfferID = action.GetInt64("offer_id")
+	if action.Err != nil {
+		return
+	}
+
+	if action.OfferID == 0 {
+		action.SetInvalidField("offer_id", errors.New("must be a positive integer"))
+	}
+}
+
+func (action *OfferEventIndexAction) loadRecords() {
+	action.Err = action.HistoryQ().OfferEvents().
+		ForOffer(action.OfferID).
+		Page(action.PageQuery).
+		Select(&action.Records)
+}
+
+// loadLedgers populates the ledger cache for this action
+func (action *OfferEventIndexAction) loadLedgers() {
+	action.Ledgers = &history.LedgerCache{}
+
+	for _, event := range action.Records {
+		action.Ledgers.Queue(event.LedgerSequence)
+	}
+	action.Err = action.Ledgers.Load(action.HistoryQ())
+}
+
+func (action *OfferEventIndexAction) loadPage() {
+	for _, record := range action.Records {
+		ledger, found := action.Ledgers.Records[record.LedgerSequence]
+		ledgerPtr := &ledger
+		if !found {
+			ledgerPtr = nil
+		}
+
+		var res horizon.OfferEvent
+		resourceadapter.PopulateOfferEvent(action.R.Context(), &res, record, ledgerPtr)
+		action.Page.Add(res)
+	}
+
+	action.Page.FullURL = action.FullURL()
+	action.Page.Limit = action.PageQuery.Limit
+	action.Page.Cursor = action.PageQuery.Cursor
+	action.Page.Order = action.PageQuery.Order
+	action.Page.PopulateLinks()
+}
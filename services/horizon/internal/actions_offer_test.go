@@ -1,8 +1,12 @@
 package horizon
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
+
+	"github.com/lomocoin/stellar-go/protocols/horizon"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
 )
 
 func TestOfferActions_Index(t *testing.T) {
@@ -50,3 +54,82 @@ func TestOfferActions_IndexNoLedgerData(t *testing.T) {
 		ht.Assert.Nil(records[2]["last_modified_time"])
 	}
 }
+
+func TestOfferActions_History(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	seller := "GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2"
+	events := []struct {
+		OfferID     int64
+		OperationID int64
+		Type        history.OfferEventType
+		Amount      int64
+	}{
+		{1, 100, history.OfferEventCreated, 1000000000},
+		{2, 150, history.OfferEventCreated, 500000000},
+		{1, 200, history.OfferEventPartiallyFilled, 400000000},
+		{2, 300, history.OfferEventCancelled, 0},
+	}
+	for _, e := range events {
+		_, err := ht.App.HistoryQ().ExecRaw(`
+			INSERT INTO history_offer_events (
+				offer_id, history_operation_id, ledger_sequence, type, seller_id,
+				selling_asset_type, buying_asset_type, buying_asset_code, buying_asset_issuer,
+				amount, pricen, priced, price, flags
+			) VALUES (?, ?, 3, ?, ?, 0, 1, 'USD', ?, ?, 1, 2, 0.5, 0)`,
+			e.OfferID, e.OperationID, e.Type, seller, seller, e.Amount,
+		)
+		ht.Require.NoError(err)
+	}
+
+	// the latest state of every offer
+	w := ht.Get("/offers")
+	if ht.Assert.Equal(200, w.Code) {
+		var records []horizon.Offer
+		ht.UnmarshalPage(w.Body, &records)
+		if ht.Assert.Len(records, 2) {
+			ht.Assert.Equal(int64(1), records[0].ID)
+			ht.Assert.Equal(history.OfferStateActive, records[0].State)
+			ht.Assert.Equal("40.0000000", records[0].Amount)
+			ht.Assert.Equal(int64(2), records[1].ID)
+			ht.Assert.Equal(history.OfferStateCancelled, records[1].State)
+		}
+	}
+
+	w = ht.Get("/offers?state=cancelled&seller=" + seller)
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(1, w.Body)
+	}
+
+	w = ht.Get("/offers?state=expired")
+	ht.Assert.Equal(400, w.Code)
+
+	w = ht.Get("/offers?selling_asset_type=native&buying_asset_type=credit_alphanum4&buying_asset_code=EUR&buying_asset_issuer=" + seller)
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(0, w.Body)
+	}
+
+	// a single offer
+	w = ht.Get("/offers/1")
+	if ht.Assert.Equal(200, w.Code) {
+		var offer horizon.Offer
+		ht.Require.NoError(json.Unmarshal(w.Body.Bytes(), &offer))
+		ht.Assert.Equal(history.OfferStateActive, offer.State)
+		ht.Assert.Equal(int32(3), offer.LastModifiedLedger)
+	}
+
+	w = ht.Get("/offers/3")
+	ht.Assert.Equal(404, w.Code)
+
+	// the events of an offer
+	w = ht.Get("/offers/1/events?order=desc")
+	if ht.Assert.Equal(200, w.Code) {
+		var records []horizon.OfferEvent
+		ht.UnmarshalPage(w.Body, &records)
+		if ht.Assert.Len(records, 2) {
+			ht.Assert.Equal("partially_filled", records[0].Type)
+			ht.Assert.Equal("created", records[1].Type)
+		}
+	}
+}
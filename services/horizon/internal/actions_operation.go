@@ -3,15 +3,20 @@ package horizon
 import (
 	"errors"
 	"fmt"
+	gTime "time"
 
+	"github.com/lomocoin/stellar-go/protocols/horizon/operations"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/ledger"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/render/export"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/render/problem"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/render/sse"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/resourceadapter"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/toid"
 	"github.com/lomocoin/stellar-go/support/render/hal"
+	"github.com/lomocoin/stellar-go/support/time"
+	"github.com/lomocoin/stellar-go/xdr"
 )
 
 // This file contains the actions:
@@ -19,14 +24,25 @@ import (
 // OperationIndexAction: pages of operations
 // OperationShowAction: single operation by id
 
+// operationExportColumns are the columns of CSV exports of operations and
+// payments: the fields common to all operations and the fields of the
+// operations moving funds.
+var operationExportColumns = []string{
+	"id", "paging_token", "transaction_hash", "created_at", "source_account", "type",
+	"from", "to", "funder", "account", "starting_balance",
+	"amount", "asset_type", "asset_code", "asset_issuer",
+	"source_amount", "source_asset_type", "source_asset_code", "source_asset_issuer",
+}
+
 // OperationIndexAction renders a page of operations resources, identified by
 // a normal page query and optionally filtered by an account, ledger, or
-// transaction.
+// transaction, and searched by the `OperationSearch` filters.
 type OperationIndexAction struct {
 	Action
 	LedgerFilter      int32
 	AccountFilter     string
 	TransactionFilter string
+	Search            OperationSearch
 	PagingParams      db2.PageQuery
 	Records           []history.Operation
 	Ledgers           *history.LedgerCache
@@ -43,6 +59,7 @@ func (action *OperationIndexAction) JSON() {
 		action.loadLedgers,
 		action.loadPage)
 	action.Do(func() {
+		action.SetImmutablePage(action.PagingParams, len(action.Records), action.LedgerFilter)
 		hal.Render(action.W, action.Page)
 	})
 }
@@ -85,11 +102,35 @@ func (action *OperationIndexAction) SSE(stream sse.Stream) {
 
 }
 
+// Export is a method for actions.Export
+func (action *OperationIndexAction) Export(stream export.Stream) {
+	action.Do(
+		action.EnsureHistoryFreshness,
+		action.loadParams,
+		action.ValidateCursorWithinHistory,
+		func() {
+			stream.SetColumns(operationExportColumns...)
+			action.ExportPages(stream, action.PagingParams, action.loadExportPage)
+		},
+	)
+}
+
+// loadExportPage loads a page of the operations exported, starting after the
+// cursor of `pq`.
+func (action *OperationIndexAction) loadExportPage(pq db2.PageQuery) []hal.Pageable {
+	action.PagingParams = pq
+	action.Records = nil
+	action.Page = hal.Page{}
+	action.Do(action.loadRecords, action.loadLedgers, action.loadPage)
+	return action.Page.Embedded.Records
+}
+
 func (action *OperationIndexAction) loadParams() {
 	action.ValidateCursorAsDefault()
 	action.AccountFilter = action.GetAddress("account_id")
 	action.LedgerFilter = action.GetInt32("ledger_id")
 	action.TransactionFilter = action.GetString("tx_id")
+	action.Search.Load(&action.Action)
 	action.PagingParams = action.GetPageQuery()
 }
 
@@ -106,6 +147,7 @@ func (action *OperationIndexAction) loadRecords() {
 		ops.ForTransaction(action.TransactionFilter)
 	}
 
+	action.Search.Apply(ops)
 	action.Err = ops.Page(action.PagingParams).Select(&action.Records)
 }
 
@@ -179,6 +221,7 @@ func (action *OperationShowAction) JSON() {
 		action.loadRecord,
 		action.loadLedger,
 		action.loadResource,
+		action.SetImmutable,
 	)
 	action.Do(func() {
 		hal.Render(action.W, action.Resource)
@@ -191,3 +234,77 @@ func (action *OperationShowAction) verifyWithinHistory() {
 		action.Err = &problem.BeforeHistory
 	}
 }
+
+// OperationSearch holds the search filters accepted by the operations and
+// payments endpoints on top of their account, ledger and transaction filters.
+type OperationSearch struct {
+	Type         xdr.OperationType
+	HasType      bool
+	Asset        xdr.Asset
+	HasAsset     bool
+	MinAmount    xdr.Int64
+	MaxAmount    xdr.Int64
+	Counterparty string
+	MemoType     string
+	Memo         string
+	StartTime    gTime.Time
+	EndTime      gTime.Time
+}
+
+// Load reads the search filters from the request of `action`.
+func (search *OperationSearch) Load(action *Action) {
+	if typ := action.GetString("type"); typ != "" {
+		for t, name := range operations.TypeNames {
+			if name == typ {
+				search.Type, search.HasType = t, true
+				break
+			}
+		}
+		if !search.HasType {
+			action.SetInvalidField("type", errors.New("unknown operation type"))
+			return
+		}
+	}
+
+	search.Asset, search.HasAsset = action.MaybeGetAsset("")
+	if action.GetString("min_amount") != "" {
+		search.MinAmount = action.GetPositiveAmount("min_amount")
+	}
+	if action.GetString("max_amount") != "" {
+		search.MaxAmount = action.GetPositiveAmount("max_amount")
+	}
+	search.Counterparty = action.GetAddress("counterparty")
+	search.MemoType, search.Memo = action.GetMemo()
+	search.StartTime = optionalTime(action.GetTimeMillis("start_time"))
+	search.EndTime = optionalTime(action.GetTimeMillis("end_time"))
+}
+
+// Apply adds the search filters to `ops`.
+func (search *OperationSearch) Apply(ops *history.OperationsQ) {
+	if search.HasType {
+		ops.OfType(search.Type)
+	}
+	if search.HasAsset {
+		ops.ForAsset(search.Asset)
+	}
+	if search.MinAmount != 0 || search.MaxAmount != 0 {
+		ops.ForAmountRange(search.MinAmount, search.MaxAmount)
+	}
+	if search.Counterparty != "" {
+		ops.ForCounterparty(search.Counterparty)
+	}
+	if search.Memo != "" {
+		ops.ForMemo(search.MemoType, search.Memo)
+	}
+	if !search.StartTime.IsZero() || !search.EndTime.IsZero() {
+		ops.ForTimeRange(search.StartTime, search.EndTime)
+	}
+}
+
+// optionalTime converts `t` to a time, the zero time if `t` is not set.
+func optionalTime(t time.Millis) gTime.Time {
+	if t.IsNil() {
+		return gTime.Time{}
+	}
+	return t.ToTime()
+}
@@ -74,6 +74,74 @@ func TestOperationActions_Index(t *testing.T) {
 	ht.Assert.Equal(404, w.Code)
 }
 
+func TestOperationActions_Search(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	// filtered by type
+	w := ht.Get("/operations?type=create_account")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(3, w.Body)
+	}
+
+	w = ht.Get("/operations?type=payment")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(1, w.Body)
+	}
+
+	w = ht.Get("/operations?type=bogus")
+	ht.Assert.Equal(400, w.Code)
+
+	// filtered by asset
+	w = ht.Get("/operations?asset_type=native")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(1, w.Body)
+	}
+
+	// filtered by amount
+	w = ht.Get("/operations?min_amount=50")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(3, w.Body)
+	}
+
+	w = ht.Get("/operations?max_amount=50")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(1, w.Body)
+	}
+
+	// filtered by counterparty
+	w = ht.Get("/operations?counterparty=GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(2, w.Body)
+	}
+
+	w = ht.Get("/operations?counterparty=bogus")
+	ht.Assert.Equal(400, w.Code)
+
+	// combined with an account filter
+	w = ht.Get("/accounts/GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU/operations?type=payment")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(1, w.Body)
+	}
+
+	// filtered by time range
+	w = ht.Get("/operations?start_time=1")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(4, w.Body)
+	}
+
+	w = ht.Get("/operations?end_time=1000")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(0, w.Body)
+	}
+
+	// payments
+	w = ht.Get("/payments?type=payment")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(1, w.Body)
+	}
+}
+
 func TestOperationActions_Show(t *testing.T) {
 	ht := StartHTTPTest(t, "base")
 	defer ht.Finish()
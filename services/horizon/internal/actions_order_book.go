@@ -1,10 +1,13 @@
 package horizon
 
 import (
+	"math/big"
 	"net/http"
 
+	"github.com/lomocoin/stellar-go/amount"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/core"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/resourceadapter"
+	"github.com/lomocoin/stellar-go/support/errors"
 	"github.com/lomocoin/stellar-go/support/render/problem"
 	"github.com/lomocoin/stellar-go/xdr"
 	"github.com/lomocoin/stellar-go/protocols/horizon"
@@ -12,14 +15,29 @@ import (
 	"github.com/lomocoin/stellar-go/support/render/hal"
 )
 
+// maxOrderBookLevels is the number of price levels of each side loaded to
+// aggregate an order book.
+const maxOrderBookLevels = 10000
+
 // OrderBookShowAction renders a account summary found by its address.
 type OrderBookShowAction struct {
 	Action
-	Selling  xdr.Asset
-	Buying   xdr.Asset
-	Record   core.OrderBookSummary
-	Resource horizon.OrderBookSummary
-	Limit    uint64
+	Selling      xdr.Asset
+	Buying       xdr.Asset
+	Record       core.OrderBookSummary
+	Depth        core.OrderBookDepth
+	Resource     horizon.OrderBookSummary
+	Limit        uint64
+	Tick         *big.Rat
+	DepthPercent *big.Rat
+	Diff         bool
+
+	// previous is the last resource sent by a stream in diff mode
+	previous *horizon.OrderBookSummary
+	// bidsTruncated and asksTruncated are true when a side of the order book
+	// has more price levels than the limit
+	bidsTruncated bool
+	asksTruncated bool
 }
 
 // LoadQuery sets action.Query from the request params
@@ -39,27 +57,93 @@ func (action *OrderBookShowAction) LoadQuery() {
 				"have specified selling_asset_code and selling_asset_issuer if selling_asset_type is not 'native', as well " +
 				"as buying_asset_code and buying_asset_issuer if buying_asset_type is not 'native'",
 		}
+		return
+	}
+
+	action.Tick = action.getPositiveRat("tick")
+	action.DepthPercent = action.getPositiveRat("depth_percent")
+	action.Diff = action.GetString("diff") == "true"
+}
+
+// getPositiveRat parses the positive decimal number of the parameter `name`,
+// returning nil if it is not provided.
+func (action *OrderBookShowAction) getPositiveRat(name string) *big.Rat {
+	value := action.GetString(name)
+	if action.Err != nil || value == "" {
+		return nil
+	}
+
+	result, ok := new(big.Rat).SetString(value)
+	if !ok || result.Sign() <= 0 {
+		action.SetInvalidField(name, errors.New("Value must be a positive number"))
+		return nil
 	}
+
+	return result
 }
 
-// LoadRecord populates action.Record
+// LoadRecord populates action.Record, aggregating its price levels when a tick
+// is provided, and action.Depth.  One more price level than the limit is
+// loaded to tell whether the sides of the order book are truncated.
 func (action *OrderBookShowAction) LoadRecord() {
+	limit := action.Limit + 1
+	if action.Tick != nil {
+		limit = maxOrderBookLevels
+	}
+
 	action.Err = action.CoreQ().GetOrderBookSummary(
 		&action.Record,
 		action.Selling,
 		action.Buying,
-		action.Limit,
+		limit,
+	)
+	if action.Err != nil {
+		return
+	}
+
+	if action.Tick != nil {
+		action.Record, action.Err = action.Record.Aggregate(action.Tick)
+		if action.Err != nil {
+			action.SetInvalidField("tick", action.Err)
+			return
+		}
+	}
+
+	var low, high *big.Rat
+	if mid := action.Record.MidPrice(); mid != nil && action.DepthPercent != nil {
+		low, high = core.DepthBounds(mid, action.DepthPercent)
+	}
+	action.Err = action.CoreQ().GetOrderBookDepth(
+		&action.Depth,
+		action.Selling,
+		action.Buying,
+		low,
+		high,
 	)
 }
 
 // LoadResource populates action.Record
 func (action *OrderBookShowAction) LoadResource() {
+	action.bidsTruncated = len(action.Record.Bids()) > int(action.Limit)
+	action.asksTruncated = len(action.Record.Asks()) > int(action.Limit)
+
 	action.Err = resourceadapter.PopulateOrderBookSummary(
 		action.R.Context(),
 		&action.Resource,
 		action.Selling,
 		action.Buying,
+		action.Record.Truncate(int(action.Limit)),
+	)
+	if action.Err != nil {
+		return
+	}
+
+	resourceadapter.PopulateOrderBookStats(
+		action.R.Context(),
+		&action.Resource.Stats,
 		action.Record,
+		action.Depth,
+		action.DepthPercent,
 	)
 }
 
@@ -77,10 +161,106 @@ func (action *OrderBookShowAction) SSE(stream sse.Stream) {
 	action.Do(action.LoadQuery, action.LoadRecord, action.LoadResource)
 
 	action.Do(func() {
-		stream.SetLimit(10)
-		stream.Send(sse.Event{
-			Data: action.Resource,
-		})
+		if !action.Diff {
+			stream.SetLimit(10)
+			stream.Send(sse.Event{
+				Data: action.Resource,
+			})
+			return
+		}
+
+		// In diff mode the first event is the full order book, the following
+		// ones only the price levels changed since the previous event.
+		if action.previous == nil {
+			stream.Send(sse.Event{
+				Event: "snapshot",
+				Data:  action.Resource,
+			})
+		} else if diff, changed := diffOrderBook(*action.previous, action.Resource, action.bidsTruncated, action.asksTruncated); changed {
+			stream.Send(sse.Event{
+				Event: "diff",
+				Data:  diff,
+			})
+		}
+
+		current := action.Resource
+		action.previous = &current
 	})
+}
+
+// diffOrderBook returns the price levels of `cur` that differ from `prev`, the
+// price levels of `prev` no longer in `cur` being included with a zero amount.
+// When a side of `cur` is truncated, the price levels of `prev` worse than its
+// last price level may only have fallen out of the limit: they are left out
+// and the diff carries the price of that last level instead.
+func diffOrderBook(prev, cur horizon.OrderBookSummary, bidsTruncated, asksTruncated bool) (horizon.OrderBookDiff, bool) {
+	diff := horizon.OrderBookDiff{
+		Bids: diffPriceLevels(prev.Bids, cur.Bids, bidsTruncated, func(price, last *big.Rat) bool {
+			return price.Cmp(last) < 0
+		}),
+		Asks: diffPriceLevels(prev.Asks, cur.Asks, asksTruncated, func(price, last *big.Rat) bool {
+			return price.Cmp(last) > 0
+		}),
+		Stats: cur.Stats,
+	}
+	if bidsTruncated && len(cur.Bids) > 0 {
+		diff.BidsTruncatedAt = cur.Bids[len(cur.Bids)-1].Price
+	}
+	if asksTruncated && len(cur.Asks) > 0 {
+		diff.AsksTruncatedAt = cur.Asks[len(cur.Asks)-1].Price
+	}
+
+	changed := len(diff.Bids) > 0 || len(diff.Asks) > 0 || prev.Stats != cur.Stats
+	return diff, changed
+}
+
+// diffPriceLevels returns the price levels of `cur` that differ from `prev`.
+// When `truncated`, the price levels of `prev` for which `worse` returns true
+// compared to the last price level of `cur` are not reported as removed.
+func diffPriceLevels(
+	prev, cur []horizon.PriceLevel,
+	truncated bool,
+	worse func(price, last *big.Rat) bool,
+) []horizon.PriceLevel {
+	result := []horizon.PriceLevel{}
+
+	amounts := map[string]string{}
+	for _, level := range prev {
+		amounts[level.Price] = level.Amount
+	}
+
+	for _, level := range cur {
+		previous, ok := amounts[level.Price]
+		delete(amounts, level.Price)
+		if ok && previous == level.Amount {
+			continue
+		}
+
+		level.CumulativeAmount = ""
+		result = append(result, level)
+	}
+
+	var last *big.Rat
+	if truncated && len(cur) > 0 {
+		last = priceLevelRat(cur[len(cur)-1])
+	}
+
+	for _, level := range prev {
+		if _, removed := amounts[level.Price]; !removed {
+			continue
+		}
+		if last != nil && worse(priceLevelRat(level), last) {
+			continue
+		}
+
+		level.Amount = amount.String(0)
+		level.CumulativeAmount = ""
+		result = append(result, level)
+	}
+
+	return result
+}
 
+func priceLevelRat(level horizon.PriceLevel) *big.Rat {
+	return big.NewRat(int64(level.PriceR.N), int64(level.PriceR.D))
 }
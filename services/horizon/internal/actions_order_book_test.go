@@ -2,9 +2,11 @@ package horizon
 
 import (
 	"encoding/json"
+	"math/big"
 	"testing"
 
 	"github.com/lomocoin/stellar-go/protocols/horizon"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestOrderBookActions_Show(t *testing.T) {
@@ -117,4 +119,94 @@ func TestOrderBookActions_Show(t *testing.T) {
 		ht.Assert.Equal("100.0000000", result.Asks[0].Amount)
 		ht.Assert.Equal("10.0000000", result.Bids[0].Amount)
 	}
+
+	// depth statistics
+	base := "/order_book?selling_asset_type=native&buying_asset_type=credit_alphanum4&buying_asset_code=USD&buying_asset_issuer=GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4"
+	w = ht.Get(base)
+	if ht.Assert.Equal(200, w.Code) {
+		err := json.Unmarshal(w.Body.Bytes(), &result)
+		ht.Require.NoError(err)
+
+		ht.Assert.Equal("6000.0000000", result.Stats.AskDepth)
+		ht.Assert.Equal("1110.0000000", result.Stats.BidDepth)
+		ht.Assert.Equal("6000.0000000", result.Asks[2].CumulativeAmount)
+		ht.Assert.Equal("1110.0000000", result.Bids[2].CumulativeAmount)
+		ht.Assert.NotEmpty(result.Stats.MidPrice)
+		ht.Assert.NotEmpty(result.Stats.Spread)
+	}
+
+	// the depth covers the whole order book rather than the levels within the
+	// limit
+	w = ht.Get(base + "&limit=1&depth_percent=25")
+	if ht.Assert.Equal(200, w.Code) {
+		err := json.Unmarshal(w.Body.Bytes(), &result)
+		ht.Require.NoError(err)
+
+		ht.Require.Len(result.Asks, 1)
+		ht.Require.Len(result.Bids, 1)
+		ht.Assert.Equal("6000.0000000", result.Stats.AskDepth)
+		ht.Assert.Equal("1110.0000000", result.Stats.BidDepth)
+		ht.Assert.Equal("25.00", result.Stats.DepthPercent)
+		ht.Assert.Equal("100.0000000", result.Stats.AskDepthWithin)
+		ht.Assert.Equal("10.0000000", result.Stats.BidDepthWithin)
+	}
+
+	// aggregated in a single bucket per side
+	w = ht.Get(base + "&tick=1000000&depth_percent=1")
+	if ht.Assert.Equal(200, w.Code) {
+		err := json.Unmarshal(w.Body.Bytes(), &result)
+		ht.Require.NoError(err)
+
+		ht.Require.Len(result.Asks, 1)
+		ht.Require.Len(result.Bids, 1)
+		ht.Assert.Equal("6000.0000000", result.Asks[0].Amount)
+		ht.Assert.Equal("1110.0000000", result.Bids[0].Amount)
+		ht.Assert.Equal("1.00", result.Stats.DepthPercent)
+	}
+
+	// invalid tick
+	w = ht.Get(base + "&tick=-1")
+	ht.Assert.Equal(400, w.Code)
+}
+
+func TestDiffOrderBook(t *testing.T) {
+	level := func(n, d int32, amount string) horizon.PriceLevel {
+		return horizon.PriceLevel{
+			PriceR: horizon.Price{N: n, D: d},
+			Price:  big.NewRat(int64(n), int64(d)).FloatString(7),
+			Amount: amount,
+		}
+	}
+
+	prev := horizon.OrderBookSummary{
+		Bids: []horizon.PriceLevel{level(3, 1, "1.0000000"), level(2, 1, "1.0000000")},
+		Asks: []horizon.PriceLevel{level(4, 1, "1.0000000"), level(5, 1, "1.0000000")},
+	}
+	// a better bid and ask pushed the worst levels out of a limit of 2
+	cur := horizon.OrderBookSummary{
+		Bids: []horizon.PriceLevel{level(7, 2, "1.0000000"), level(3, 1, "1.0000000")},
+		Asks: []horizon.PriceLevel{level(7, 2, "1.0000000"), level(4, 1, "1.0000000")},
+	}
+
+	// without truncation, the levels out of cur were removed
+	diff, changed := diffOrderBook(prev, cur, false, false)
+	assert.True(t, changed)
+	assert.Equal(t, []horizon.PriceLevel{level(7, 2, "1.0000000"), level(2, 1, "0.0000000")}, diff.Bids)
+	assert.Equal(t, []horizon.PriceLevel{level(7, 2, "1.0000000"), level(5, 1, "0.0000000")}, diff.Asks)
+	assert.Empty(t, diff.BidsTruncatedAt)
+	assert.Empty(t, diff.AsksTruncatedAt)
+
+	// with truncation, they merely fell out of the limit
+	diff, changed = diffOrderBook(prev, cur, true, true)
+	assert.True(t, changed)
+	assert.Equal(t, []horizon.PriceLevel{level(7, 2, "1.0000000")}, diff.Bids)
+	assert.Equal(t, []horizon.PriceLevel{level(7, 2, "1.0000000")}, diff.Asks)
+	assert.Equal(t, "3.0000000", diff.BidsTruncatedAt)
+	assert.Equal(t, "4.0000000", diff.AsksTruncatedAt)
+
+	// levels better than the last one are still reported as removed
+	cur.Bids = []horizon.PriceLevel{level(2, 1, "1.0000000")}
+	diff, _ = diffOrderBook(prev, cur, true, false)
+	assert.Equal(t, []horizon.PriceLevel{level(3, 1, "0.0000000")}, diff.Bids)
+	assert.Equal(t, "2.0000000", diff.BidsTruncatedAt)
 }
@@ -8,7 +8,10 @@ import (
 	"github.com/lomocoin/stellar-go/support/render/hal"
 )
 
-// PathIndexAction provides path finding
+// PathIndexAction provides path finding.  When the `source_amount` parameter is
+// provided it answers strict-send queries: the amount of the assets trusted by
+// the destination account received when sending `source_amount` of the source
+// asset.
 type PathIndexAction struct {
 	Action
 	Query   paths.Query
@@ -30,6 +33,14 @@ func (action *PathIndexAction) JSON() {
 }
 
 func (action *PathIndexAction) loadQuery() {
+	if action.GetString("source_amount") != "" {
+		action.Query.StrictSend = true
+		action.Query.SourceAmount = action.GetPositiveAmount("source_amount")
+		action.Query.SourceAsset = action.GetAsset("source_")
+		action.Query.DestinationAddress = action.GetAddress("destination_account", actions.RequiredParam)
+		return
+	}
+
 	action.Query.DestinationAmount = action.GetPositiveAmount("destination_amount")
 	action.Query.DestinationAddress = action.GetAddress("destination_account", actions.RequiredParam)
 	action.Query.DestinationAsset = action.GetAsset("destination_")
@@ -39,6 +50,15 @@ func (action *PathIndexAction) loadSourceAssets() {
 	app := AppFromContext(action.R.Context())
 	protocolVersion := app.protocolVersion
 
+	if action.Query.StrictSend {
+		action.Err = action.CoreQ().AssetsForAddress(
+			&action.Query.DestinationAssets,
+			action.Query.DestinationAddress,
+			protocolVersion,
+		)
+		return
+	}
+
 	action.Err = action.CoreQ().AssetsForAddress(
 		&action.Query.SourceAssets,
 		action.GetAddress("source_account"),
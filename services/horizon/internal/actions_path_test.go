@@ -2,7 +2,10 @@ package horizon
 
 import (
 	"net/url"
+	"strings"
 	"testing"
+
+	"github.com/lomocoin/stellar-go/protocols/horizon"
 )
 
 func TestPathActions_Index(t *testing.T) {
@@ -33,7 +36,77 @@ func TestPathActions_Index(t *testing.T) {
 	q.Add("destination_amount", "10")
 
 	w = ht.Get("/paths?" + q.Encode())
-	ht.Assert.Equal(200, w.Code)
-	ht.Assert.PageOf(3, w.Body)
+	if ht.Assert.Equal(200, w.Code) {
+		var records []horizon.Path
+		ht.UnmarshalPage(w.Body, &records)
+		ht.Assert.Len(records, 3)
+		ht.Assert.Equal(map[string]string{
+			"USD->EUR":         "5.0000000",
+			"USD->1->EUR":      "10.0000000",
+			"USD->21->22->EUR": "10.0000000",
+		}, pathAmounts(records, func(p horizon.Path) string {
+			ht.Assert.Equal("10.0000000", p.DestinationAmount)
+			return p.SourceAmount
+		}))
+	}
+
+	// strict-send
+	q = make(url.Values)
+	q.Add(
+		"destination_account",
+		"GAEDTJ4PPEFVW5XV2S7LUXBEHNQMX5Q2GM562RJGOQG7GVCE5H3HIB4V",
+	)
+	q.Add(
+		"source_asset_issuer",
+		"GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN",
+	)
+	q.Add("source_asset_type", "credit_alphanum4")
+	q.Add("source_asset_code", "USD")
+	q.Add("source_amount", "10")
+
+	w = ht.Get("/paths?" + q.Encode())
+	if ht.Assert.Equal(200, w.Code) {
+		// sending 10 USD to the EUR and native trusted by the destination
+		var records []horizon.Path
+		ht.UnmarshalPage(w.Body, &records)
+		ht.Assert.Len(records, 5)
+		ht.Assert.Equal(map[string]string{
+			"USD->EUR":             "20.0000000",
+			"USD->native":          "100.0000000",
+			"USD->1->EUR":          "10.0000000",
+			"USD->21->22->EUR":     "10.0000000",
+			"USD->31->32->33->EUR": "0.6250000",
+		}, pathAmounts(records, func(p horizon.Path) string {
+			ht.Assert.Equal("10.0000000", p.SourceAmount)
+			return p.DestinationAmount
+		}))
+	}
+
+	// strict-send with an invalid amount
+	q.Set("source_amount", "-10")
+	w = ht.Get("/paths?" + q.Encode())
+	ht.Assert.Equal(400, w.Code)
+}
+
+// pathAmounts returns the amounts returned by `amount` for each of the
+// `records`, by the codes of the assets of their path, e.g. `USD->1->EUR`.
+func pathAmounts(records []horizon.Path, amount func(horizon.Path) string) map[string]string {
+	code := func(typ, code string) string {
+		if typ == "native" {
+			return typ
+		}
+		return code
+	}
+
+	result := map[string]string{}
+	for _, p := range records {
+		assets := []string{code(p.SourceAssetType, p.SourceAssetCode)}
+		for _, a := range p.Path {
+			assets = append(assets, code(a.Type, a.Code))
+		}
+		assets = append(assets, code(p.DestinationAssetType, p.DestinationAssetCode))
 
+		result[strings.Join(assets, "->")] = amount(p)
+	}
+	return result
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/render/export"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/render/sse"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/resourceadapter"
 	"github.com/lomocoin/stellar-go/support/render/hal"
@@ -18,6 +19,7 @@ type PaymentsIndexAction struct {
 	LedgerFilter      int32
 	AccountFilter     string
 	TransactionFilter string
+	Search            OperationSearch
 	PagingParams      db2.PageQuery
 	Records           []history.Operation
 	Ledgers           *history.LedgerCache
@@ -35,6 +37,7 @@ func (action *PaymentsIndexAction) JSON() {
 		action.loadPage,
 	)
 	action.Do(func() {
+		action.SetImmutablePage(action.PagingParams, len(action.Records), action.LedgerFilter)
 		hal.Render(action.W, action.Page)
 	})
 }
@@ -76,11 +79,35 @@ func (action *PaymentsIndexAction) SSE(stream sse.Stream) {
 		})
 }
 
+// Export is a method for actions.Export
+func (action *PaymentsIndexAction) Export(stream export.Stream) {
+	action.Do(
+		action.EnsureHistoryFreshness,
+		action.loadParams,
+		action.ValidateCursorWithinHistory,
+		func() {
+			stream.SetColumns(operationExportColumns...)
+			action.ExportPages(stream, action.PagingParams, action.loadExportPage)
+		},
+	)
+}
+
+// loadExportPage loads a page of the payments exported, starting after the
+// cursor of `pq`.
+func (action *PaymentsIndexAction) loadExportPage(pq db2.PageQuery) []hal.Pageable {
+	action.PagingParams = pq
+	action.Records = nil
+	action.Page = hal.Page{}
+	action.Do(action.loadRecords, action.loadLedgers, action.loadPage)
+	return action.Page.Embedded.Records
+}
+
 func (action *PaymentsIndexAction) loadParams() {
 	action.ValidateCursorAsDefault()
 	action.AccountFilter = action.GetAddress("account_id")
 	action.LedgerFilter = action.GetInt32("ledger_id")
 	action.TransactionFilter = action.GetString("tx_id")
+	action.Search.Load(&action.Action)
 	action.PagingParams = action.GetPageQuery()
 }
 
@@ -97,6 +124,7 @@ func (action *PaymentsIndexAction) loadRecords() {
 		ops.ForTransaction(action.TransactionFilter)
 	}
 
+	action.Search.Apply(ops)
 	action.Err = ops.Page(action.PagingParams).Select(&action.Records)
 }
 
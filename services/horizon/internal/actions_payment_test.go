@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/test"
 	"github.com/lomocoin/stellar-go/protocols/horizon/operations"
 )
 
@@ -58,6 +59,38 @@ func TestPaymentActions(t *testing.T) {
 	ht.Assert.Equal(400, w.Code)
 }
 
+func TestPaymentActions_Memo(t *testing.T) {
+	ht := StartHTTPTest(t, "kahuna")
+	defer ht.Finish()
+
+	account := "/accounts/GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H/payments"
+
+	w := ht.Get(account + "?memo=hello")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(1, w.Body)
+	}
+
+	w = ht.Get(account + "?memo=123&memo_type=id")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(1, w.Body)
+	}
+
+	w = ht.Get(account + "?memo=123&memo_type=text")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(0, w.Body)
+	}
+
+	w = ht.Get(account + "?memo=hello&memo_type=id")
+	ht.Assert.Equal(400, w.Code)
+
+	// streams only emit the matching payments
+	w = ht.Get(account+"?memo=hello", test.RequestHelperStreaming)
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.Contains(w.Body.String(), "34359746561")
+		ht.Assert.NotContains(w.Body.String(), "34359742465")
+	}
+}
+
 func TestPayment_CreatedAt(t *testing.T) {
 	ht := StartHTTPTest(t, "base")
 	defer ht.Finish()
@@ -21,7 +21,7 @@ func (action *RootAction) JSON() {
 		&res,
 		ledger.CurrentState(),
 		action.App.horizonVersion,
-		action.App.coreVersion,
+		action.App.stellarCoreStatus().Version,
 		action.App.config.NetworkPassphrase,
 		action.App.protocolVersion,
 		action.App.config.FriendbotURL,
@@ -7,6 +7,7 @@ import (
 	"github.com/lomocoin/stellar-go/protocols/horizon"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/render/export"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/render/sse"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/resourceadapter"
 	"github.com/lomocoin/stellar-go/support/errors"
@@ -15,6 +16,14 @@ import (
 	"github.com/lomocoin/stellar-go/xdr"
 )
 
+// tradeExportColumns are the columns of CSV exports of trades.
+var tradeExportColumns = []string{
+	"id", "paging_token", "ledger_close_time", "offer_id", "base_is_seller",
+	"base_offer_id", "base_account", "base_amount", "base_asset_type", "base_asset_code", "base_asset_issuer",
+	"counter_offer_id", "counter_account", "counter_amount", "counter_asset_type", "counter_asset_code", "counter_asset_issuer",
+	"price.n", "price.d",
+}
+
 type TradeIndexAction struct {
 	Action
 	BaseAssetFilter       xdr.Asset
@@ -71,6 +80,28 @@ func (action *TradeIndexAction) SSE(stream sse.Stream) {
 	)
 }
 
+// Export is a method for actions.Export
+func (action *TradeIndexAction) Export(stream export.Stream) {
+	action.Do(
+		action.EnsureHistoryFreshness,
+		action.loadParams,
+		func() {
+			stream.SetColumns(tradeExportColumns...)
+			action.ExportPages(stream, action.PagingParams, action.loadExportPage)
+		},
+	)
+}
+
+// loadExportPage loads a page of the trades exported, starting after the
+// cursor of `pq`.
+func (action *TradeIndexAction) loadExportPage(pq db2.PageQuery) []hal.Pageable {
+	action.PagingParams = pq
+	action.Records = nil
+	action.Page = hal.Page{}
+	action.Do(action.loadRecords, action.loadPage)
+	return action.Page.Embedded.Records
+}
+
 // loadParams sets action.Query from the request params
 func (action *TradeIndexAction) loadParams() {
 	action.PagingParams = action.GetPageQuery()
@@ -184,7 +215,7 @@ func (action *TradeAggregateIndexAction) loadParams() {
 		if _, ok := history.AllowedResolutions[resolutionDuration]; !ok {
 			action.SetInvalidField("resolution", errors.New("illegal or missing resolution. "+
 				"allowed resolutions are: 1 minute (60000), 5 minutes (300000), 15 minutes (900000), 1 hour (3600000), "+
-				"1 day (86400000) and 1 week (604800000)"))
+				"4 hours (14400000), 1 day (86400000), 1 week (604800000) and 1 calendar month (2629746000)"))
 		}
 	}
 	// check if offset is legal
@@ -268,7 +299,7 @@ func (action *TradeAggregateIndexAction) loadPage() {
 		action.Page.Links.Next = action.Page.Links.Self
 	} else {
 		if action.PagingParams.Order == "asc" {
-			newStartTime := action.Records[len(action.Records)-1].Timestamp + action.ResolutionFilter
+			newStartTime := history.BucketEnd(action.Records[len(action.Records)-1].Timestamp, action.ResolutionFilter)
 			if newStartTime >= action.EndTimeFilter.ToInt64() {
 				newStartTime = action.EndTimeFilter.ToInt64()
 			}
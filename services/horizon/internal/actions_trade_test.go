@@ -13,6 +13,7 @@ import (
 	. "github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
 	. "github.com/lomocoin/stellar-go/services/horizon/internal/test/trades"
 	"github.com/lomocoin/stellar-go/support/render/hal"
+	stime "github.com/lomocoin/stellar-go/support/time"
 	"github.com/lomocoin/stellar-go/xdr"
 )
 
@@ -193,6 +194,7 @@ func TestTradeActions_Aggregation(t *testing.T) {
 	//add other trades as noise, to ensure asset filtering is working
 	_, _, err = PopulateTestTrades(dbQ, start, numOfTrades, minute, numOfTrades)
 	ht.Require.NoError(err)
+	ht.Require.NoError(RebuildTestTradeRollups(dbQ))
 
 	var records []horizon.TradeAggregation
 	var record horizon.TradeAggregation
@@ -465,6 +467,7 @@ func TestTradeActions_AggregationOffset(t *testing.T) {
 	// One trade every hour
 	ass1, ass2, err := PopulateTestTrades(dbQ, 0, 100, hour, 1)
 	ht.Require.NoError(err)
+	ht.Require.NoError(RebuildTestTradeRollups(dbQ))
 
 	q := make(url.Values)
 	setAssetQuery(&q, "base_", ass1)
@@ -512,3 +515,105 @@ func TestTradeActions_AggregationOffset(t *testing.T) {
 		})
 	}
 }
+
+// TestTradeActions_AggregationSides checks the side counts and vwap of trade
+// aggregations, both when aggregating trades and when aggregating rollups.
+func TestTradeActions_AggregationSides(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	seller := GetTestAccount()
+	buyer := GetTestAccount()
+	ass1 := GetTestAsset("usd")
+	ass2 := GetTestAsset("euro")
+
+	dbQ := &Q{ht.HorizonSession()}
+	ht.Require.NoError(IngestTestTrade(dbQ, ass1, ass2, seller, buyer, 10, 20, 0, 1))
+	ht.Require.NoError(IngestTestTrade(dbQ, ass1, ass2, seller, buyer, 10, 30, stime.MillisFromInt64(minute), 2))
+	ht.Require.NoError(IngestTestTrade(dbQ, ass2, ass1, seller, buyer, 40, 10, stime.MillisFromInt64(2*minute), 3))
+	ht.Require.NoError(RebuildTestTradeRollups(dbQ))
+
+	for _, resolution := range []int64{15 * minute, 4 * hour, day} {
+		q := make(url.Values)
+		q.Add("resolution", strconv.FormatInt(resolution, 10))
+
+		setAssetQuery(&q, "base_", ass1)
+		setAssetQuery(&q, "counter_", ass2)
+		var records []horizon.TradeAggregation
+		w := ht.GetWithParams(aggregationPath, q)
+		if ht.Assert.Equal(200, w.Code) && ht.Assert.PageOf(1, w.Body) {
+			ht.UnmarshalPage(w.Body, &records)
+			ht.Assert.Equal(int64(3), records[0].TradeCount)
+			ht.Assert.Equal(int64(2), records[0].BuyCount)
+			ht.Assert.Equal(int64(1), records[0].SellCount)
+			ht.Assert.Equal("0.0000030", records[0].BaseVolume)
+			ht.Assert.Equal("3.0000000", records[0].VWAP)
+			ht.Assert.Equal("2.0000000", records[0].Open)
+			ht.Assert.Equal("4.0000000", records[0].Close)
+		}
+
+		setAssetQuery(&q, "base_", ass2)
+		setAssetQuery(&q, "counter_", ass1)
+		w = ht.GetWithParams(aggregationPath, q)
+		if ht.Assert.Equal(200, w.Code) && ht.Assert.PageOf(1, w.Body) {
+			ht.UnmarshalPage(w.Body, &records)
+			ht.Assert.Equal(int64(1), records[0].BuyCount)
+			ht.Assert.Equal(int64(2), records[0].SellCount)
+			ht.Assert.Equal("0.0000090", records[0].BaseVolume)
+			ht.Assert.Equal("0.3333333", records[0].VWAP)
+			ht.Assert.Equal("0.5000000", records[0].Open)
+			ht.Assert.Equal("0.2500000", records[0].Close)
+		}
+	}
+}
+
+func TestTradeActions_AggregationMonth(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	const (
+		jan1  = int64(1514764800000) // 2018-01-01T00:00:00Z
+		jan31 = int64(1517356800000) // 2018-01-31T00:00:00Z
+		feb1  = int64(1517443200000) // 2018-02-01T00:00:00Z
+	)
+
+	seller := GetTestAccount()
+	buyer := GetTestAccount()
+	ass1 := GetTestAsset("usd")
+	ass2 := GetTestAsset("euro")
+
+	dbQ := &Q{ht.HorizonSession()}
+	ht.Require.NoError(IngestTestTrade(dbQ, ass1, ass2, seller, buyer, 10, 20, stime.MillisFromInt64(jan31), 1))
+	ht.Require.NoError(IngestTestTrade(dbQ, ass1, ass2, seller, buyer, 10, 30, stime.MillisFromInt64(feb1+hour), 2))
+	ht.Require.NoError(RebuildTestTradeRollups(dbQ))
+
+	q := make(url.Values)
+	setAssetQuery(&q, "base_", ass1)
+	setAssetQuery(&q, "counter_", ass2)
+	q.Add("resolution", strconv.FormatInt(int64(history.MonthResolution/time.Millisecond), 10))
+	q.Add("start_time", strconv.FormatInt(jan31-day, 10))
+	q.Add("end_time", strconv.FormatInt(feb1+28*day+hour, 10))
+	q.Add("limit", "1")
+
+	var records []horizon.TradeAggregation
+	w := ht.GetWithParams(aggregationPath, q)
+	if ht.Assert.Equal(200, w.Code) && ht.Assert.PageOf(1, w.Body) {
+		ht.UnmarshalPage(w.Body, &records)
+		ht.Assert.Equal(feb1, records[0].Timestamp)
+	}
+
+	q.Set("start_time", strconv.FormatInt(jan1, 10))
+	w = ht.GetWithParams(aggregationPath, q)
+	if ht.Assert.Equal(200, w.Code) && ht.Assert.PageOf(1, w.Body) {
+		ht.UnmarshalPage(w.Body, &records)
+		ht.Assert.Equal(jan1, records[0].Timestamp)
+		ht.Assert.Equal("2.0000000", records[0].Open)
+	}
+
+	w = ht.Get(ht.UnmarshalNext(w.Body))
+	if ht.Assert.Equal(200, w.Code) && ht.Assert.PageOf(1, w.Body) {
+		ht.UnmarshalPage(w.Body, &records)
+		ht.Assert.Equal(feb1, records[0].Timestamp)
+		ht.Assert.Equal("3.0000000", records[0].Open)
+	}
+}
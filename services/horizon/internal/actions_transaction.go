@@ -6,6 +6,7 @@ import (
 	"github.com/lomocoin/stellar-go/protocols/horizon"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/render/export"
 	hProblem "github.com/lomocoin/stellar-go/services/horizon/internal/render/problem"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/render/sse"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/resourceadapter"
@@ -19,15 +20,23 @@ import (
 // TransactionIndexAction: pages of transactions
 // TransactionShowAction: single transaction by sequence, by hash or id
 
+// transactionExportColumns are the columns of CSV exports of transactions.
+var transactionExportColumns = []string{
+	"id", "paging_token", "hash", "ledger", "created_at", "source_account",
+	"source_account_sequence", "fee_paid", "operation_count", "memo_type", "memo",
+}
+
 // TransactionIndexAction renders a page of ledger resources, identified by
-// a normal page query.
+// a normal page query and optionally filtered by an account, ledger or memo.
 type TransactionIndexAction struct {
 	Action
-	LedgerFilter  int32
-	AccountFilter string
-	PagingParams  db2.PageQuery
-	Records       []history.Transaction
-	Page          hal.Page
+	LedgerFilter   int32
+	AccountFilter  string
+	MemoTypeFilter string
+	MemoFilter     string
+	PagingParams   db2.PageQuery
+	Records        []history.Transaction
+	Page           hal.Page
 }
 
 // JSON is a method for actions.JSON
@@ -38,6 +47,9 @@ func (action *TransactionIndexAction) JSON() {
 		action.ValidateCursorWithinHistory,
 		action.loadRecords,
 		action.loadPage,
+		func() {
+			action.SetImmutablePage(action.PagingParams, len(action.Records), action.LedgerFilter)
+		},
 		func() {
 			hal.Render(action.W, action.Page)
 		},
@@ -66,10 +78,34 @@ func (action *TransactionIndexAction) SSE(stream sse.Stream) {
 	)
 }
 
+// Export is a method for actions.Export
+func (action *TransactionIndexAction) Export(stream export.Stream) {
+	action.Do(
+		action.EnsureHistoryFreshness,
+		action.loadParams,
+		action.ValidateCursorWithinHistory,
+		func() {
+			stream.SetColumns(transactionExportColumns...)
+			action.ExportPages(stream, action.PagingParams, action.loadExportPage)
+		},
+	)
+}
+
+// loadExportPage loads a page of the transactions exported, starting after the
+// cursor of `pq`.
+func (action *TransactionIndexAction) loadExportPage(pq db2.PageQuery) []hal.Pageable {
+	action.PagingParams = pq
+	action.Records = nil
+	action.Page = hal.Page{}
+	action.Do(action.loadRecords, action.loadPage)
+	return action.Page.Embedded.Records
+}
+
 func (action *TransactionIndexAction) loadParams() {
 	action.ValidateCursorAsDefault()
 	action.AccountFilter = action.GetAddress("account_id")
 	action.LedgerFilter = action.GetInt32("ledger_id")
+	action.MemoTypeFilter, action.MemoFilter = action.GetMemo()
 	action.PagingParams = action.GetPageQuery()
 }
 
@@ -84,6 +120,10 @@ func (action *TransactionIndexAction) loadRecords() {
 		txs.ForLedger(action.LedgerFilter)
 	}
 
+	if action.MemoFilter != "" {
+		txs.ForMemo(action.MemoTypeFilter, action.MemoFilter)
+	}
+
 	action.Err = txs.Page(action.PagingParams).Select(&action.Records)
 }
 
@@ -128,6 +168,7 @@ func (action *TransactionShowAction) JSON() {
 		action.loadParams,
 		action.loadRecord,
 		action.loadResource,
+		action.SetImmutable,
 		func() { hal.Render(action.W, action.Resource) },
 	)
 }
@@ -2,7 +2,9 @@ package horizon
 
 import (
 	"encoding/json"
+	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/lomocoin/stellar-go/services/horizon/internal/txsub"
@@ -10,6 +12,24 @@ import (
 	"github.com/lomocoin/stellar-go/protocols/horizon"
 )
 
+func TestTransactionActions_Memo(t *testing.T) {
+	ht := StartHTTPTest(t, "kahuna")
+	defer ht.Finish()
+
+	w := ht.Get("/transactions?memo=hello")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(1, w.Body)
+	}
+
+	w = ht.Get("/accounts/GA46VRKBCLI2X6DXLX7AIEVRFLH3UA7XBE3NGNP6O74HQ5LXHMGTV2JB/transactions?memo=123&memo_type=id")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(1, w.Body)
+	}
+
+	w = ht.Get("/transactions?memo_type=text")
+	ht.Assert.Equal(400, w.Code)
+}
+
 func TestTransactionActions_Show(t *testing.T) {
 	ht := StartHTTPTest(t, "base")
 	defer ht.Finish()
@@ -32,6 +52,41 @@ func TestTransactionActions_Show(t *testing.T) {
 	ht.Assert.Equal(404, w.Code)
 }
 
+func TestTransactionActions_Export(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	w := ht.Get("/transactions", func(r *http.Request) {
+		r.Header.Set("Accept", "text/csv")
+	})
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.Equal("text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		ht.Assert.Len(lines, 5)
+		ht.Assert.True(strings.HasPrefix(lines[0], "id,paging_token,hash,ledger,created_at"))
+	}
+
+	w = ht.Get("/transactions?format=ndjson&limit=3")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.Equal("application/x-ndjson; charset=utf-8", w.Header().Get("Content-Type"))
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		ht.Require.Len(lines, 3)
+		var actual horizon.Transaction
+		ht.Require.NoError(json.Unmarshal([]byte(lines[0]), &actual))
+		ht.Assert.NotEmpty(actual.Hash)
+	}
+
+	// exports allow limits above the page limit, up to their own
+	w = ht.Get("/transactions?format=csv&limit=5000")
+	ht.Assert.Equal(200, w.Code)
+	w = ht.Get("/transactions?format=csv&limit=10001")
+	ht.Assert.Equal(400, w.Code)
+
+	// single resources cannot be exported
+	w = ht.Get("/transactions/2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d?format=csv")
+	ht.Assert.Equal(406, w.Code)
+}
+
 func TestTransactionActions_Index(t *testing.T) {
 	ht := StartHTTPTest(t, "base")
 	defer ht.Finish()
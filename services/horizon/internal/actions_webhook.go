@@ -0,0 +1,335 @@
+package horizon
+
+import (
+	"database/sql"
+	"net/url"
+	"strings"
+
+	"github.com/guregu/null"
+	"github.com/lomocoin/stellar-go/protocols/horizon"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/apikey"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/ledger"
+	hProblem "github.com/lomocoin/stellar-go/services/horizon/internal/render/problem"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/resourceadapter"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/webhooks"
+	"github.com/lomocoin/stellar-go/strkey"
+	"github.com/lomocoin/stellar-go/support/errors"
+	"github.com/lomocoin/stellar-go/support/render/hal"
+)
+
+// This file contains the actions:
+//
+// WebhookCreateAction: registers a webhook
+// WebhookIndexAction: pages of the webhooks of an API key
+// WebhookShowAction: details for a webhook
+// WebhookDeleteAction: removes a webhook
+// WebhookDeadLetterIndexAction: pages of the dead letters of a webhook
+// WebhookReplayAction: replays the dead letters of a webhook
+
+// maxWebhookAccounts is the maximum number of accounts a webhook can be
+// notified of.
+const maxWebhookAccounts = 100
+
+// WebhookCreateAction registers a webhook for the API key of the request.
+type WebhookCreateAction struct {
+	Action
+	Record   history.Webhook
+	Resource horizon.Webhook
+}
+
+// JSON is a method for actions.JSON
+func (action *WebhookCreateAction) JSON() {
+	action.Do(
+		action.loadOwner,
+		action.loadParams,
+		action.loadRecord,
+		func() {
+			resourceadapter.PopulateWebhook(action.R.Context(), &action.Resource, action.Record)
+			action.Resource.Secret = action.Record.Secret
+			hal.Render(action.W, action.Resource)
+		},
+	)
+}
+
+func (action *WebhookCreateAction) loadOwner() {
+	action.Record.Owner = action.webhookOwner()
+}
+
+func (action *WebhookCreateAction) loadParams() {
+	action.ValidateBodyType()
+
+	rawURL := action.GetString("url")
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		action.SetInvalidField("url", errors.New("must be an absolute http or https url"))
+		return
+	}
+	action.Record.URL = rawURL
+
+	action.Record.Accounts = action.GetString("accounts")
+	accounts := action.Record.AccountList()
+	if len(accounts) == 0 || len(accounts) > maxWebhookAccounts {
+		action.SetInvalidField("accounts", errors.Errorf("must list between 1 and %d accounts", maxWebhookAccounts))
+		return
+	}
+	for _, account := range accounts {
+		if _, err := strkey.Decode(strkey.VersionByteAccountID, account); err != nil {
+			action.SetInvalidField("accounts", errors.Errorf("invalid address %s", account))
+			return
+		}
+	}
+	action.Record.Accounts = strings.Join(accounts, ",")
+
+	action.Record.EventTypes = action.GetString("event_types")
+	eventTypes := action.Record.EventTypeList()
+	for _, typ := range eventTypes {
+		if !webhooks.IsEventType(typ) {
+			action.SetInvalidField("event_types", errors.Errorf("unknown event type %s", typ))
+			return
+		}
+	}
+	action.Record.EventTypes = strings.Join(eventTypes, ",")
+
+	asset, ok := action.MaybeGetAsset("")
+	if action.Err != nil || !ok {
+		return
+	}
+
+	var typ, code, issuer string
+	action.Err = asset.Extract(&typ, &code, &issuer)
+	action.Record.AssetType = null.StringFrom(typ)
+	if typ != "native" {
+		action.Record.AssetCode = null.StringFrom(code)
+		action.Record.AssetIssuer = null.StringFrom(issuer)
+	}
+}
+
+func (action *WebhookCreateAction) loadRecord() {
+	action.Record.Secret, action.Err = webhooks.NewSecret()
+	if action.Err != nil {
+		return
+	}
+
+	// webhooks are notified of the events of the ledgers ingested from now on.
+	action.Record.LastLedger = ledger.CurrentState().HistoryLatest
+	action.Err = action.HistoryQ().InsertWebhook(&action.Record)
+}
+
+// WebhookIndexAction renders a page of the webhooks of the API key of the
+// request.
+type WebhookIndexAction struct {
+	Action
+	Owner        string
+	PagingParams db2.PageQuery
+	Records      []history.Webhook
+	Page         hal.Page
+}
+
+// JSON is a method for actions.JSON
+func (action *WebhookIndexAction) JSON() {
+	action.Do(
+		action.loadParams,
+		action.loadRecords,
+		action.loadPage,
+		func() {
+			hal.Render(action.W, action.Page)
+		},
+	)
+}
+
+func (action *WebhookIndexAction) loadParams() {
+	action.Owner = action.webhookOwner()
+	action.PagingParams = action.GetPageQuery()
+}
+
+func (action *WebhookIndexAction) loadRecords() {
+	action.Err = action.HistoryQ().WebhooksByOwner(&action.Records, action.Owner, action.PagingParams)
+}
+
+func (action *WebhookIndexAction) loadPage() {
+	for _, record := range action.Records {
+		var res horizon.Webhook
+		resourceadapter.PopulateWebhook(action.R.Context(), &res, record)
+		action.Page.Add(res)
+	}
+
+	action.Page.FullURL = action.FullURL()
+	action.Page.Limit = action.PagingParams.Limit
+	action.Page.Cursor = action.PagingParams.Cursor
+	action.Page.Order = action.PagingParams.Order
+	action.Page.PopulateLinks()
+}
+
+// WebhookShowAction renders a webhook of the API key of the request.
+type WebhookShowAction struct {
+	Action
+	Record   history.Webhook
+	Resource horizon.Webhook
+}
+
+// JSON is a method for actions.JSON
+func (action *WebhookShowAction) JSON() {
+	action.Do(
+		func() {
+			action.Record = action.loadWebhook()
+		},
+		func() {
+			resourceadapter.PopulateWebhook(action.R.Context(), &action.Resource, action.Record)
+			hal.Render(action.W, action.Resource)
+		},
+	)
+}
+
+// WebhookDeleteAction removes a webhook of the API key of the request, along
+// with its pending deliveries and dead letters, and renders it.
+type WebhookDeleteAction struct {
+	Action
+	Record   history.Webhook
+	Resource horizon.Webhook
+}
+
+// JSON is a method for actions.JSON
+func (action *WebhookDeleteAction) JSON() {
+	action.Do(
+		func() {
+			action.Record = action.loadWebhook()
+		},
+		action.deleteRecord,
+		func() {
+			resourceadapter.PopulateWebhook(action.R.Context(), &action.Resource, action.Record)
+			hal.Render(action.W, action.Resource)
+		},
+	)
+}
+
+func (action *WebhookDeleteAction) deleteRecord() {
+	deleted, err := action.HistoryQ().DeleteWebhook(action.Record.Owner, action.Record.ID)
+	if err != nil {
+		action.Err = err
+		return
+	}
+
+	if deleted == 0 {
+		action.Err = sql.ErrNoRows
+	}
+}
+
+// WebhookDeadLetterIndexAction renders a page of the events that could not be
+// delivered to a webhook of the API key of the request.
+type WebhookDeadLetterIndexAction struct {
+	Action
+	Webhook      history.Webhook
+	PagingParams db2.PageQuery
+	Records      []history.WebhookDeadLetter
+	Page         hal.Page
+}
+
+// JSON is a method for actions.JSON
+func (action *WebhookDeadLetterIndexAction) JSON() {
+	action.Do(
+		func() {
+			action.Webhook = action.loadWebhook()
+		},
+		action.loadParams,
+		action.loadRecords,
+		action.loadPage,
+		func() {
+			hal.Render(action.W, action.Page)
+		},
+	)
+}
+
+func (action *WebhookDeadLetterIndexAction) loadParams() {
+	action.PagingParams = action.GetPageQuery()
+}
+
+func (action *WebhookDeadLetterIndexAction) loadRecords() {
+	action.Err = action.HistoryQ().WebhookDeadLetters(&action.Records, action.Webhook.ID, action.PagingParams)
+}
+
+func (action *WebhookDeadLetterIndexAction) loadPage() {
+	for _, record := range action.Records {
+		var res horizon.WebhookDeadLetter
+		resourceadapter.PopulateWebhookDeadLetter(action.R.Context(), &res, record)
+		action.Page.Add(res)
+	}
+
+	action.Page.FullURL = action.FullURL()
+	action.Page.Limit = action.PagingParams.Limit
+	action.Page.Cursor = action.PagingParams.Cursor
+	action.Page.Order = action.PagingParams.Order
+	action.Page.PopulateLinks()
+}
+
+// WebhookReplayAction moves the dead letters of a webhook of the API key of the
+// request back to its deliveries: every dead letter, or only the one whose id
+// is the `dead_letter_id` parameter.
+type WebhookReplayAction struct {
+	Action
+	Webhook      history.Webhook
+	DeadLetterID int64
+	Resource     horizon.WebhookReplay
+}
+
+// JSON is a method for actions.JSON
+func (action *WebhookReplayAction) JSON() {
+	action.Do(
+		func() {
+			action.Webhook = action.loadWebhook()
+		},
+		func() {
+			action.ValidateBodyType()
+			action.DeadLetterID = action.GetInt64("dead_letter_id")
+		},
+		action.replay,
+		func() {
+			hal.Render(action.W, action.Resource)
+		},
+	)
+}
+
+func (action *WebhookReplayAction) replay() {
+	replayed, err := action.HistoryQ().ReplayWebhookDeadLetters(action.Webhook.ID, action.DeadLetterID)
+	if err != nil {
+		action.Err = err
+		return
+	}
+
+	if replayed == 0 && action.DeadLetterID != 0 {
+		action.Err = sql.ErrNoRows
+		return
+	}
+
+	action.Resource.Replayed = replayed
+}
+
+// webhookOwner returns the name of the API key of the request, which owns the
+// webhooks it registers.  Webhooks require an API key.
+func (action *Action) webhookOwner() string {
+	if action.Err != nil {
+		return ""
+	}
+
+	client, ok := action.App.web.apiKey(apikey.FromRequest(action.R))
+	if !ok {
+		action.Err = &hProblem.APIKeyRequired
+		return ""
+	}
+
+	return client.Name
+}
+
+// loadWebhook loads the webhook whose id is the `id` parameter of the request,
+// if it is owned by the API key of the request.
+func (action *Action) loadWebhook() (webhook history.Webhook) {
+	owner := action.webhookOwner()
+	id := action.GetInt64("id")
+	if action.Err != nil {
+		return
+	}
+
+	action.Err = action.HistoryQ().WebhookByID(&webhook, owner, id)
+	return
+}
@@ -0,0 +1,77 @@
+package horizon
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/lomocoin/stellar-go/support/render/problem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookActions_Disabled(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	w := ht.Get("/webhooks")
+	ht.Assert.Equal(404, w.Code)
+}
+
+func TestWebhookActions_Validation(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	file := writeAPIKeysFile(t)
+	defer os.Remove(file)
+
+	c := NewTestConfig()
+	c.APIKeysFile = file
+	c.EnableWebhooks = true
+	app, _ := NewApp(c)
+	defer app.Close()
+	rh := NewRequestHelper(app)
+
+	problemType := func(body []byte) string {
+		var p problem.P
+		json.Unmarshal(body, &p)
+		return p.Type
+	}
+
+	// webhooks require an API key
+	w := rh.Get("/webhooks")
+	if assert.Equal(t, 401, w.Code) {
+		assert.Equal(t, "api_key_required", problemType(w.Body.Bytes()))
+	}
+	w = rh.Post("/webhooks", url.Values{"url": {"https://example.com/hook"}})
+	assert.Equal(t, 401, w.Code)
+
+	valid := url.Values{
+		"url":         {"https://example.com/hook"},
+		"accounts":    {"GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2"},
+		"event_types": {"payment,account_credited"},
+		"asset_type":  {"native"},
+	}
+
+	invalid := map[string]func(url.Values){
+		"url":          func(v url.Values) { v.Set("url", "ftp://example.com") },
+		"accounts":     func(v url.Values) { v.Set("accounts", "GA5WBPYA,") },
+		"event_types":  func(v url.Values) { v.Set("event_types", "payment,bogus") },
+		"asset_issuer": func(v url.Values) { v.Set("asset_type", "credit_alphanum4") },
+	}
+
+	for field, invalidate := range invalid {
+		params := url.Values{}
+		for k, v := range valid {
+			params[k] = v
+		}
+		invalidate(params)
+
+		w = rh.Post("/webhooks", params, requestHelperAPIKey("partner-secret"))
+		if assert.Equal(t, 400, w.Code, field) {
+			var p problem.P
+			json.Unmarshal(w.Body.Bytes(), &p)
+			assert.Equal(t, field, p.Extras["invalid_field"], field)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package apikey
+
+import (
+	"strings"
+
+	"github.com/lomocoin/stellar-go/support/config"
+	"github.com/lomocoin/stellar-go/support/errors"
+	"github.com/throttled/throttled"
+)
+
+// ReadFile reads the API keys in the TOML file at `path`.
+func ReadFile(path string) ([]Key, error) {
+	var file File
+	err := config.Read(path, &file)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read api keys file")
+	}
+
+	return file.Keys, nil
+}
+
+// NewSet returns a set of `keys`, checking every key is valid and that keys
+// and names are unique.
+func NewSet(keys []Key) (Set, error) {
+	set := Set{}
+	names := map[string]bool{}
+
+	for _, key := range keys {
+		if key.Hash == "" && key.Key != "" {
+			key.Hash = Hash(key.Key)
+		}
+
+		switch {
+		case key.Hash == "":
+			return nil, errors.Errorf("api key %q has no key", key.Name)
+		case key.Name == "":
+			return nil, errors.New("api key has no name")
+		case key.PerHourRateLimit < 0:
+			return nil, errors.Errorf("api key %q has a negative per-hour-rate-limit", key.Name)
+		case key.Burst < 0:
+			return nil, errors.Errorf("api key %q has a negative burst", key.Name)
+		case names[key.Name]:
+			return nil, errors.Errorf("api key name %q is not unique", key.Name)
+		}
+
+		if _, ok := set[key.Hash]; ok {
+			return nil, errors.Errorf("api key %q is not unique", key.Name)
+		}
+
+		set[key.Hash] = key
+		names[key.Name] = true
+	}
+
+	return set, nil
+}
+
+// SplitRoutes splits a comma separated list of routes, as stored in the
+// database, ignoring empty elements.
+func SplitRoutes(routes string) []string {
+	var result []string
+	for _, route := range strings.Split(routes, ",") {
+		route = strings.TrimSpace(route)
+		if route != "" {
+			result = append(result, route)
+		}
+	}
+	return result
+}
+
+// Allows returns true if the key may request `path`.
+func (key Key) Allows(path string) bool {
+	if len(key.Routes) == 0 {
+		return true
+	}
+
+	for _, route := range key.Routes {
+		route = strings.TrimSuffix(route, "/")
+		if route == "" || path == route || strings.HasPrefix(path, route+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Quota returns the rate limit quota of the key, nil when the requests made
+// with the key are not rate limited.
+func (key Key) Quota() *throttled.RateQuota {
+	if key.PerHourRateLimit == 0 {
+		return nil
+	}
+
+	return &throttled.RateQuota{
+		MaxRate:  throttled.PerHour(key.PerHourRateLimit),
+		MaxBurst: key.Burst,
+	}
+}
@@ -0,0 +1,92 @@
+package apikey
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSet(t *testing.T) {
+	set, err := NewSet([]Key{
+		{Key: "a-secret", Name: "a"},
+		{Key: "b-secret", Name: "b", PerHourRateLimit: 100},
+		{Hash: Hash("c-secret"), Name: "c"},
+	})
+	if assert.NoError(t, err) {
+		assert.Len(t, set, 3)
+		assert.Equal(t, "b", set[Hash("b-secret")].Name)
+		assert.Equal(t, Hash("b-secret"), set[Hash("b-secret")].Hash)
+		assert.Equal(t, "c", set[Hash("c-secret")].Name)
+	}
+
+	invalid := [][]Key{
+		{{Name: "a"}},
+		{{Key: "a-secret"}},
+		{{Key: "a-secret", Name: "a", PerHourRateLimit: -1}},
+		{{Key: "a-secret", Name: "a", Burst: -1}},
+		{{Key: "a-secret", Name: "a"}, {Key: "b-secret", Name: "a"}},
+		{{Key: "a-secret", Name: "a"}, {Key: "a-secret", Name: "b"}},
+		{{Key: "a-secret", Name: "a"}, {Hash: Hash("a-secret"), Name: "b"}},
+	}
+	for _, keys := range invalid {
+		_, err := NewSet(keys)
+		assert.Error(t, err, "keys: %v", keys)
+	}
+}
+
+func TestKey_Allows(t *testing.T) {
+	key := Key{}
+	assert.True(t, key.Allows("/ledgers"))
+
+	key.Routes = []string{"/ledgers", "/accounts/"}
+	assert.True(t, key.Allows("/ledgers"))
+	assert.True(t, key.Allows("/ledgers/1/payments"))
+	assert.True(t, key.Allows("/accounts/GABC"))
+	assert.False(t, key.Allows("/ledgersx"))
+	assert.False(t, key.Allows("/transactions"))
+
+	key.Routes = []string{"/"}
+	assert.True(t, key.Allows("/transactions"))
+}
+
+func TestKey_Quota(t *testing.T) {
+	assert.Nil(t, Key{}.Quota())
+	assert.Equal(t, 5, Key{PerHourRateLimit: 10, Burst: 5}.Quota().MaxBurst)
+}
+
+func TestSplitRoutes(t *testing.T) {
+	assert.Nil(t, SplitRoutes(""))
+	assert.Equal(t, []string{"/ledgers", "/accounts"}, SplitRoutes(" /ledgers,,/accounts "))
+}
+
+func TestFromRequest(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/ledgers?api_key=query-secret", nil)
+	assert.Equal(t, "query-secret", FromRequest(r))
+
+	r.Header.Set(Header, "header-secret")
+	assert.Equal(t, "header-secret", FromRequest(r))
+
+	r, _ = http.NewRequest("GET", "/ledgers", nil)
+	assert.Equal(t, "", FromRequest(r))
+}
+
+func TestStripURL(t *testing.T) {
+	u, _ := url.Parse("https://horizon.example.com/ledgers?api_key=query-secret&limit=2")
+	assert.Equal(t, "https://horizon.example.com/ledgers?limit=2", StripURL(u).String())
+	assert.Equal(t, "query-secret", u.Query().Get(QueryParam))
+
+	u, _ = url.Parse("/ledgers?order=desc&limit=2")
+	assert.Equal(t, "/ledgers?order=desc&limit=2", StripURL(u).String())
+}
+
+func TestHash(t *testing.T) {
+	// echo -n partner-secret | sha256sum
+	assert.Equal(t, "25386993910f585ef9789d1de56b13c385f18751de51daf6050d20bd4fd65623", Hash("partner-secret"))
+
+	token, err := NewToken()
+	if assert.NoError(t, err) {
+		assert.Len(t, token, 64)
+	}
+}
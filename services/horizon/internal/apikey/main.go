@@ -0,0 +1,115 @@
+// Package apikey contains the API keys horizon identifies its clients with.
+// Every key comes with its own rate limit quota and may be restricted to a
+// list of routes.  Requests made without a key are anonymous and are rate
+// limited per IP address as usual.
+//
+// Keys are read from a TOML file, in which every key is a `[[keys]]` table,
+// and from the `api_keys` table of the horizon database, which only stores the
+// SHA-256 hash of every key.
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	// Header is the header clients send their API key in.
+	Header = "X-Api-Key"
+
+	// QueryParam is the query parameter clients can send their API key in,
+	// when they cannot set headers (e.g. EventSource in browsers).
+	QueryParam = "api_key"
+)
+
+// Key represents an API key along with the limits applying to the requests
+// made with it.
+type Key struct {
+	// Key is the secret value sent by the client.
+	Key string `toml:"key" valid:"required"`
+
+	// Hash is the hex-encoded SHA-256 hash of the secret value, the only part
+	// of the keys stored in the database.  It is computed from Key when empty.
+	Hash string `toml:"-"`
+
+	// Name identifies the key, in logs and metrics.
+	Name string `toml:"name" valid:"required"`
+
+	// PerHourRateLimit is the number of requests allowed per hour, 0 meaning
+	// the requests made with the key are not rate limited.
+	PerHourRateLimit int `toml:"per-hour-rate-limit"`
+
+	// Burst is the number of requests that can be made in a burst, on top of
+	// the first one, before being rate limited.
+	Burst int `toml:"burst"`
+
+	// Routes are the paths the key may request, including every path under
+	// them.  An empty list allows every path.
+	Routes []string `toml:"routes"`
+}
+
+// File represents the contents of an API keys file.
+type File struct {
+	Keys []Key `toml:"keys"`
+}
+
+// Set is a set of API keys, indexed by the hash of their secret value.
+type Set map[string]Key
+
+// RedisRateLimiter is a throttled.RateLimiter implementing the GCRA algorithm
+// with its state stored in redis, such that every horizon instance of a
+// cluster enforces the same quota.
+type RedisRateLimiter struct {
+	Pool    *redis.Pool
+	Prefix  string
+	PerHour int
+	Burst   int
+}
+
+// FromRequest returns the API key sent with `r`, or an empty string for
+// anonymous requests.
+func FromRequest(r *http.Request) string {
+	if key := r.Header.Get(Header); key != "" {
+		return key
+	}
+
+	return r.URL.Query().Get(QueryParam)
+}
+
+// StripURL returns a copy of `u` without the API key a client may have sent
+// in its query string, such that the key is not written to logs, caches or
+// links.
+func StripURL(u *url.URL) *url.URL {
+	result := *u
+
+	query := u.Query()
+	if _, ok := query[QueryParam]; ok {
+		query.Del(QueryParam)
+		result.RawQuery = query.Encode()
+	}
+
+	return &result
+}
+
+// Hash returns the hex-encoded SHA-256 hash of the API key `token`, which keys
+// are looked up by.
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewToken returns a new random API key.
+func NewToken() (string, error) {
+	raw := make([]byte, 32)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(raw), nil
+}
@@ -0,0 +1,72 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/lomocoin/stellar-go/support/errors"
+	"github.com/throttled/throttled"
+)
+
+// gcraScript updates the theoretical arrival time (TAT) of the next request
+// stored at KEYS[1] for a request made at ARGV[1], ARGV[2] being the emission
+// interval and ARGV[3] the delay variation tolerance, all in microseconds.  It
+// returns whether the request is limited, the time left until the TAT and
+// the time the request has to wait for when limited.
+var gcraScript = redis.NewScript(1, `
+local now = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local tolerance = tonumber(ARGV[3])
+
+local tat = tonumber(redis.call("GET", KEYS[1])) or now
+if tat < now then
+	tat = now
+end
+
+local newTat = tat + interval
+local diff = now - (newTat - tolerance)
+if diff < 0 then
+	return {1, tat - now, -diff}
+end
+
+redis.call("SET", KEYS[1], newTat, "PX", math.ceil((newTat - now) / 1000))
+return {0, newTat - now, 0}
+`)
+
+// RateLimit implements throttled.RateLimiter, limiting the requests made with
+// `key`.
+func (l *RedisRateLimiter) RateLimit(key string, quantity int) (bool, throttled.RateLimitResult, error) {
+	limit := l.Burst + 1
+	interval := time.Hour / time.Duration(l.PerHour)
+	tolerance := interval * time.Duration(limit)
+	result := throttled.RateLimitResult{Limit: limit, RetryAfter: -1}
+
+	conn := l.Pool.Get()
+	defer conn.Close()
+
+	values, err := redis.Int64s(gcraScript.Do(conn,
+		l.Prefix+key,
+		time.Now().UnixNano()/int64(time.Microsecond),
+		int64(interval*time.Duration(quantity)/time.Microsecond),
+		int64(tolerance/time.Microsecond),
+	))
+	if err != nil {
+		return false, result, errors.Wrap(err, "failed to run rate limit script")
+	}
+
+	limited := values[0] == 1
+	ttl := time.Duration(values[1]) * time.Microsecond
+	result.ResetAfter = ttl
+
+	if limited {
+		result.RetryAfter = time.Duration(values[2]) * time.Microsecond
+		return true, result, nil
+	}
+
+	next := tolerance - ttl
+	if next > -interval {
+		result.Remaining = int(next / interval)
+	}
+
+	return false, result, nil
+}
@@ -8,20 +8,25 @@ import (
 	"os"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gomodule/redigo/redis"
 	"github.com/rcrowley/go-metrics"
 	"github.com/lomocoin/stellar-go/clients/stellarcore"
+	protocol "github.com/lomocoin/stellar-go/protocols/horizon"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/apikey"
 	horizonContext "github.com/lomocoin/stellar-go/services/horizon/internal/context"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/core"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/ingest"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/ledger"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/operationfeestats"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/orderbook"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/paths"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/reap"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/txsub"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/webhooks"
 	"github.com/lomocoin/stellar-go/support/app"
 	"github.com/lomocoin/stellar-go/support/db"
 	"github.com/lomocoin/stellar-go/support/log"
@@ -36,24 +41,35 @@ type App struct {
 	web             *Web
 	historyQ        *history.Q
 	coreQ           *core.Q
+	historyReplicas *replicaSet
+	coreReplicas    *replicaSet
 	ctx             context.Context
 	cancel          func()
+	draining        context.Context
+	drain           func()
 	redis           *redis.Pool
-	coreVersion     string
+	coreStatus      atomic.Value
 	horizonVersion  string
 	protocolVersion int32
 	submitter       *txsub.System
 	paths           paths.Finder
+	orderBookGraph  *orderbook.Graph
 	ingester        *ingest.System
 	reaper          *reap.System
+	webhooks        *webhooks.System
 	ticks           *time.Ticker
 
+	// sseUpdateFrequency is the config's SSEUpdateFrequency, updated when the
+	// config is reloaded.
+	sseUpdateFrequency int64
+
 	// metrics
 	metrics                  metrics.Registry
 	historyLatestLedgerGauge metrics.Gauge
 	historyElderLedgerGauge  metrics.Gauge
 	horizonConnGauge         metrics.Gauge
 	coreLatestLedgerGauge    metrics.Gauge
+	ledgersBehindCoreGauge   metrics.Gauge
 	coreConnGauge            metrics.Gauge
 	goroutineGauge           metrics.Gauge
 }
@@ -63,6 +79,7 @@ func NewApp(config Config) (*App, error) {
 
 	result := &App{config: config}
 	result.horizonVersion = app.Version()
+	result.sseUpdateFrequency = int64(config.SSEUpdateFrequency)
 	result.ticks = time.NewTicker(1 * time.Second)
 	result.init()
 	return result, nil
@@ -76,8 +93,15 @@ func (a *App) Serve() {
 
 	addr := fmt.Sprintf(":%d", a.config.Port)
 
+	timeout := a.config.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
 	srv := &graceful.Server{
-		Timeout: 10 * time.Second,
+		// the transaction submissions timed out at the end of the shutdown
+		// timeout are given a grace period to send their response.
+		Timeout: timeout + shutdownGracePeriod,
 
 		Server: &http.Server{
 			Addr:              addr,
@@ -87,7 +111,7 @@ func (a *App) Serve() {
 
 		ShutdownInitiated: func() {
 			log.Info("received signal, gracefully stopping")
-			a.Close()
+			a.shutdown(timeout)
 		},
 	}
 
@@ -108,6 +132,8 @@ func (a *App) Serve() {
 		log.Panic(err)
 	}
 
+	a.waitForIngestion(timeout)
+	a.Close()
 	a.CloseDB()
 
 	log.Info("stopped")
@@ -125,6 +151,8 @@ func (a *App) Close() {
 func (a *App) CloseDB() {
 	a.historyQ.Session.DB.Close()
 	a.coreQ.Session.DB.Close()
+	a.historyReplicas.Close()
+	a.coreReplicas.Close()
 }
 
 // HistoryQ returns a helper object for performing sql queries against the
@@ -136,13 +164,54 @@ func (a *App) HistoryQ() *history.Q {
 // HorizonSession returns a new session that loads data from the horizon
 // database. The returned session is bound to `ctx`.
 func (a *App) HorizonSession(ctx context.Context) *db.Session {
-	return &db.Session{DB: a.historyQ.Session.DB, Ctx: ctx}
+	return &db.Session{
+		DB:           a.historyQ.Session.DB,
+		Ctx:          ctx,
+		ObserveQuery: a.historyQ.Session.ObserveQuery,
+	}
 }
 
 // CoreSession returns a new session that loads data from the stellar core
 // database. The returned session is bound to `ctx`.
 func (a *App) CoreSession(ctx context.Context) *db.Session {
-	return &db.Session{DB: a.coreQ.Session.DB, Ctx: ctx}
+	return &db.Session{
+		DB:           a.coreQ.Session.DB,
+		Ctx:          ctx,
+		ObserveQuery: a.coreQ.Session.ObserveQuery,
+	}
+}
+
+// HorizonReadSession returns a new session bound to `ctx` that loads data from
+// a read replica of the horizon database no more than `StaleThreshold` ledgers
+// behind the primary database, or from the primary database if there is none.
+func (a *App) HorizonReadSession(ctx context.Context) *db.Session {
+	replica := a.historyReplicas.Session(ledger.CurrentState().HistoryLatest, a.config.StaleThreshold)
+	if replica == nil {
+		return a.HorizonSession(ctx)
+	}
+
+	return &db.Session{
+		DB:           replica.DB,
+		Ctx:          ctx,
+		ObserveQuery: replica.ObserveQuery,
+	}
+}
+
+// CoreReadSession returns a new session bound to `ctx` that loads data from a
+// read replica of the stellar core database no more than `StaleThreshold`
+// ledgers behind the primary database, or from the primary database if there
+// is none.
+func (a *App) CoreReadSession(ctx context.Context) *db.Session {
+	replica := a.coreReplicas.Session(ledger.CurrentState().CoreLatest, a.config.StaleThreshold)
+	if replica == nil {
+		return a.CoreSession(ctx)
+	}
+
+	return &db.Session{
+		DB:           replica.DB,
+		Ctx:          ctx,
+		ObserveQuery: replica.ObserveQuery,
+	}
 }
 
 // CoreQ returns a helper object for performing sql queries aginst the
@@ -183,6 +252,11 @@ func (a *App) UpdateLedgerState() {
 		goto Failed
 	}
 
+	err = a.HistoryQ().HistoryFiltered(&next.HistoryFiltered)
+	if err != nil {
+		goto Failed
+	}
+
 	ledger.SetState(next)
 	return
 
@@ -193,6 +267,32 @@ Failed:
 
 }
 
+// UpdateReplicasState loads the latest ledger of every read replica of the
+// horizon and stellar core databases.
+func (a *App) UpdateReplicasState() {
+	update := func(r *replica, latestLedger func(dest interface{}) error) {
+		var latest int32
+		err := latestLedger(&latest)
+		if err != nil {
+			log.WithField("err", err.Error()).Error("failed to load replica ledger state")
+			latest = 0
+		}
+		r.SetLatest(latest)
+	}
+
+	if a.historyReplicas != nil {
+		for _, r := range a.historyReplicas.replicas {
+			update(r, (&history.Q{Session: r.session}).LatestLedger)
+		}
+	}
+
+	if a.coreReplicas != nil {
+		for _, r := range a.coreReplicas.replicas {
+			update(r, (&core.Q{Session: r.session}).LatestLedger)
+		}
+	}
+}
+
 // UpdateOperationFeeStatsState triggers a refresh of several operation fee metrics
 func (a *App) UpdateOperationFeeStatsState() {
 	var err error
@@ -246,7 +346,37 @@ Failed:
 
 }
 
-// UpdateStellarCoreInfo updates the value of coreVersion and networkPassphrase
+// UpdateOrderBookGraph reloads the in-memory order book graph used by the
+// "graph" path finder when stellar-core has closed a new ledger.
+func (a *App) UpdateOrderBookGraph() {
+	if a.orderBookGraph == nil {
+		return
+	}
+
+	err := a.orderBookGraph.Refresh(a.CoreQ(), ledger.CurrentState().CoreLatest)
+	if err != nil {
+		log.WithStack(err).
+			WithField("err", err.Error()).
+			Error("failed to refresh order book graph")
+	}
+}
+
+// coreStatus is the state of stellar-core, as last reported by its info
+// endpoint.
+type coreStatus struct {
+	Version string
+	State   string
+	Synced  bool
+}
+
+// stellarCoreStatus returns the state of stellar-core as last loaded by
+// UpdateStellarCoreInfo.  It is safe to call from any goroutine.
+func (a *App) stellarCoreStatus() coreStatus {
+	status, _ := a.coreStatus.Load().(coreStatus)
+	return status
+}
+
+// UpdateStellarCoreInfo updates the value of coreStatus and networkPassphrase
 // from the Stellar core API.
 func (a *App) UpdateStellarCoreInfo() {
 	if a.config.StellarCoreURL == "" {
@@ -255,6 +385,8 @@ func (a *App) UpdateStellarCoreInfo() {
 
 	fail := func(err error) {
 		log.Warnf("could not load stellar-core info: %s", err)
+		// the version is kept, as it is unlikely to have changed
+		a.coreStatus.Store(coreStatus{Version: a.stellarCoreStatus().Version})
 	}
 
 	core := &stellarcore.Client{
@@ -279,10 +411,51 @@ func (a *App) UpdateStellarCoreInfo() {
 		os.Exit(1)
 	}
 
-	a.coreVersion = resp.Info.Build
+	a.coreStatus.Store(coreStatus{
+		Version: resp.Info.Build,
+		State:   resp.Info.State,
+		Synced:  resp.IsSynced(),
+	})
 	a.protocolVersion = int32(resp.Info.ProtocolVersion)
 }
 
+// Health runs horizon's health checks: the connectivity to its databases,
+// whether stellar-core is synced with the network and whether ingestion is
+// lagging behind stellar-core by more than the configured `StaleThreshold`.
+func (a *App) Health(ctx context.Context) protocol.Health {
+	ls := ledger.CurrentState()
+	status := a.stellarCoreStatus()
+
+	result := protocol.Health{
+		Status:            "ok",
+		HistoryLatest:     ls.HistoryLatest,
+		CoreLatest:        ls.CoreLatest,
+		LedgersBehindCore: ls.CoreLatest - ls.HistoryLatest,
+		CoreState:         status.State,
+		Checks: map[string]protocol.HealthCheck{
+			HealthCheckHistoryDB: pingCheck(ctx, a.historyQ.Session.DB),
+			HealthCheckCoreDB:    pingCheck(ctx, a.coreQ.Session.DB),
+			HealthCheckIngestion: ingestionCheck(ls, a.config.StaleThreshold),
+		},
+	}
+
+	if a.config.StellarCoreURL != "" {
+		check := protocol.HealthCheck{OK: status.Synced}
+		if !check.OK {
+			check.Error = fmt.Sprintf("stellar-core is not synced, state: %q", status.State)
+		}
+		result.Checks[HealthCheckStellarCore] = check
+	}
+
+	for _, check := range result.Checks {
+		if !check.OK {
+			result.Status = "unavailable"
+		}
+	}
+
+	return result
+}
+
 // UpdateMetrics triggers a refresh of several metrics gauges, such as open
 // db connections and ledger state
 func (a *App) UpdateMetrics() {
@@ -291,9 +464,21 @@ func (a *App) UpdateMetrics() {
 	a.historyLatestLedgerGauge.Update(int64(ls.HistoryLatest))
 	a.historyElderLedgerGauge.Update(int64(ls.HistoryElder))
 	a.coreLatestLedgerGauge.Update(int64(ls.CoreLatest))
+	a.ledgersBehindCoreGauge.Update(int64(ls.CoreLatest - ls.HistoryLatest))
 
 	a.horizonConnGauge.Update(int64(a.historyQ.Session.DB.Stats().OpenConnections))
 	a.coreConnGauge.Update(int64(a.coreQ.Session.DB.Stats().OpenConnections))
+
+	if a.historyReplicas != nil {
+		for _, r := range a.historyReplicas.replicas {
+			r.lagGauge.Update(int64(ls.HistoryLatest - r.Latest()))
+		}
+	}
+	if a.coreReplicas != nil {
+		for _, r := range a.coreReplicas.replicas {
+			r.lagGauge.Update(int64(ls.CoreLatest - r.Latest()))
+		}
+	}
 }
 
 // DeleteUnretainedHistory forwards to the app's reaper.  See
@@ -302,14 +487,21 @@ func (a *App) DeleteUnretainedHistory() error {
 	return a.reaper.DeleteUnretainedHistory()
 }
 
+// RestoreHistory forwards to the app's reaper.  See `reap.Restore` for details
+func (a *App) RestoreHistory(start, end int32) (int, error) {
+	return a.reaper.Restore(start, end)
+}
+
 // Tick triggers horizon to update all of it's background processes such as
-// transaction submission, metrics, ingestion and reaping.
+// transaction submission, metrics, ingestion, reaping and webhook delivery.
 func (a *App) Tick() {
 	var wg sync.WaitGroup
 	log.Debug("ticking app")
-	// update ledger state, operation fee state, and stellar-core info in parallel
-	wg.Add(3)
+	// update ledger state, replicas state, operation fee state, and
+	// stellar-core info in parallel
+	wg.Add(4)
 	go func() { a.UpdateLedgerState(); wg.Done() }()
+	go func() { a.UpdateReplicasState(); wg.Done() }()
 	go func() { a.UpdateOperationFeeStatsState(); wg.Done() }()
 	go func() { a.UpdateStellarCoreInfo(); wg.Done() }()
 	wg.Wait()
@@ -318,9 +510,14 @@ func (a *App) Tick() {
 		go a.ingester.Tick()
 	}
 
-	wg.Add(2)
+	if a.webhooks != nil {
+		go a.webhooks.Tick()
+	}
+
+	wg.Add(3)
 	go func() { a.reaper.Tick(); wg.Done() }()
 	go func() { a.submitter.Tick(a.ctx); wg.Done() }()
+	go func() { a.UpdateOrderBookGraph(); wg.Done() }()
 	wg.Wait()
 
 	// finally, update metrics
@@ -353,8 +550,13 @@ func (a *App) Context(ctx context.Context) context.Context {
 	return context.WithValue(ctx, &horizonContext.AppContextKey, a)
 }
 
-// GetRateLimiter returns the HTTPRateLimiter of the App.
-func (a *App) GetRateLimiter() *throttled.HTTPRateLimiter {
+// GetRateLimiter returns the HTTPRateLimiter applying to `r`: the one of the
+// API key `r` was made with, if any, or the App's otherwise.
+func (a *App) GetRateLimiter(r *http.Request) *throttled.HTTPRateLimiter {
+	if client, ok := a.web.apiKey(apikey.FromRequest(r)); ok {
+		return client.rateLimiter
+	}
+
 	return a.web.rateLimiter
 }
 
@@ -4,6 +4,8 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/lomocoin/stellar-go/services/horizon/internal/ingest"
+	"github.com/lomocoin/stellar-go/xdr"
 	"github.com/sirupsen/logrus"
 	"github.com/throttled/throttled"
 )
@@ -28,8 +30,37 @@ type Config struct {
 	SentryDSN              string
 	LogglyTag              string
 	LogglyToken            string
+	// HistoryReplicaURLs and CoreReplicaURLs are the urls of read replicas of
+	// the horizon and stellar-core databases.  Read-only requests query the
+	// replicas that are no more than `StaleThreshold` ledgers behind the
+	// primary database; ingestion and transaction submission always use the
+	// primary database.
+	HistoryReplicaURLs []string
+	CoreReplicaURLs    []string
+	// ResponseCacheSize is the number of immutable responses kept in memory by
+	// the response cache, 0 disabling it.
+	ResponseCacheSize int
+	// ResponseCacheRedis makes the response cache store the immutable
+	// responses in redis, shared by every horizon of a cluster, instead.
+	ResponseCacheRedis bool
+	// ResponseCacheMaxAge is the max-age of the Cache-Control header of the
+	// immutable responses, and the time they are kept for in redis.
+	ResponseCacheMaxAge time.Duration
+	// APIKeysFile is the path to a TOML file listing API keys, read along
+	// with the keys stored in the `api_keys` table of the horizon database.
+	APIKeysFile string
 	// Maximum length of the path returned by `/paths` endpoint.
 	MaxPathLength uint
+	// PathFinder selects the implementation used by the `/paths` endpoint:
+	// "simple" searches stellar-core's database, "graph" searches an
+	// in-memory order book graph refreshed on every ledger.
+	PathFinder string
+	// PathFinderTimeout bounds the time spent searching for paths by the
+	// "graph" path finder.
+	PathFinderTimeout time.Duration
+	// PathFinderMaxResults is the maximum number of paths returned for each
+	// source asset by the "graph" path finder.
+	PathFinderMaxResults uint
 	// TLSCert is a path to a certificate file to use for horizon's TLS config
 	TLSCert string
 	// TLSKey is the path to a private key file to use for horizon's TLS config
@@ -42,6 +73,10 @@ type Config struct {
 	// determining a "retention duration", each ledger roughly corresponds to 10
 	// seconds of real time.
 	HistoryRetentionCount uint
+	// ReaperArchiveURL is the url of the archive, either `file://` or `s3://`,
+	// that history data is exported to before being removed by the reaper.
+	// Leave empty to delete unretained history without archiving it.
+	ReaperArchiveURL string
 	// StaleThreshold represents the number of ledgers a history database may be
 	// out-of-date by before horizon begins to respond with an error to history
 	// requests.
@@ -49,9 +84,28 @@ type Config struct {
 	// SkipCursorUpdate causes the ingestor to skip reporting the "last imported
 	// ledger" state to stellar-core.
 	SkipCursorUpdate bool
-	// EnableAssetStats is a feature flag that determines whether to calculate
-	// asset stats during the ingestion and expose `/assets` endpoint.
-	// Enabling it has a negative impact on CPU when ingesting ledgers full of
-	// many different assets related operations.
-	EnableAssetStats bool
+	// DisableAssetStats turns off the calculation of asset stats during the
+	// ingestion and the `/assets` endpoints, which are enabled by default.
+	// Disabling them saves CPU when ingesting ledgers full of many different
+	// assets related operations.
+	DisableAssetStats bool
+	// AssetHolderThreshold is the balance from which an account holding
+	// an asset counts towards its `num_holders_above_threshold` stat.
+	AssetHolderThreshold xdr.Int64
+	// IngestFilter restricts the history recorded by the ingestion system to
+	// the transactions involving an allowed account or asset.
+	IngestFilter ingest.Filter
+	// EnableWebhooks is a feature flag that determines whether to expose the
+	// `/webhooks` endpoints and to deliver events to the registered webhooks.
+	EnableWebhooks bool
+	// WebhookMaxAttempts is the number of attempts made to deliver an event
+	// to a webhook before moving it to the webhook's dead letters.
+	WebhookMaxAttempts int32
+	// ShutdownTimeout is the time given to the requests in flight, including
+	// the transaction submissions waiting for their result, to finish when
+	// horizon shuts down, and then to the current ingestion session to commit.
+	ShutdownTimeout time.Duration
+	// GraphQLMaxCost is the cost budget of a query to the `/graphql` endpoint:
+	// the number of records it can load.
+	GraphQLMaxCost int32
 }
@@ -0,0 +1,69 @@
+package assets
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
+)
+
+// AssetDailyStatsR is the result from the AssetDailyStatsQ query
+type AssetDailyStatsR struct {
+	Day                      string `db:"day"`
+	Amount                   string `db:"amount"`
+	NumAccounts              int32  `db:"num_accounts"`
+	NumUnauthorizedAccounts  int32  `db:"num_unauthorized_accounts"`
+	UnauthorizedAmount       string `db:"unauthorized_amount"`
+	AmountInOffers           string `db:"amount_in_offers"`
+	NumHoldersAboveThreshold int32  `db:"num_holders_above_threshold"`
+	PaymentCount             int32  `db:"payment_count"`
+	PaymentVolume            string `db:"payment_volume"`
+}
+
+// AssetDailyStatsQ is the query to fetch the daily history of the stats of a
+// single asset.  A day is included when the stats of the asset were updated
+// or the asset was paid on that day.  The stats of a day during which they
+// were not updated are those of the latest earlier day they were.
+type AssetDailyStatsQ struct {
+	AssetID   int64
+	PageQuery db2.PageQuery
+}
+
+// GetSQL allows this query to be executed by the caller
+func (q AssetDailyStatsQ) GetSQL() (sq.SelectBuilder, error) {
+	days := sq.Select("day").
+		From("asset_stats_daily").
+		Where(sq.Eq{"id": q.AssetID}).
+		Suffix("UNION SELECT date(ledger_closed_at) FROM history_asset_payments WHERE asset_id = ?", q.AssetID)
+
+	stats := sq.Select(
+		"to_char(days.day, 'YYYY-MM-DD') as day",
+		"COALESCE(stats.amount, '0') as amount",
+		"COALESCE(stats.num_accounts, 0) as num_accounts",
+		"COALESCE(stats.num_unauthorized_accounts, 0) as num_unauthorized_accounts",
+		"COALESCE(stats.unauthorized_amount, '0') as unauthorized_amount",
+		"COALESCE(stats.amount_in_offers, '0') as amount_in_offers",
+		"COALESCE(stats.num_holders_above_threshold, 0) as num_holders_above_threshold",
+		"COALESCE(pay.payment_count, 0) as payment_count",
+		"COALESCE(pay.payment_volume, 0) as payment_volume",
+	).
+		FromSelect(days, "days").
+		LeftJoin(
+			"LATERAL (SELECT * FROM asset_stats_daily"+
+				" WHERE id = ? AND day <= days.day"+
+				" ORDER BY day DESC LIMIT 1) stats ON true",
+			q.AssetID,
+		).
+		LeftJoin(
+			"(SELECT date(ledger_closed_at) as day, count(*) as payment_count, sum(amount) as payment_volume"+
+				" FROM history_asset_payments WHERE asset_id = ?"+
+				" GROUP BY 1) pay ON pay.day = days.day",
+			q.AssetID,
+		)
+
+	// days are formatted as YYYY-MM-DD, which sorts before "9"
+	cursor := q.PageQuery.Cursor
+	if q.PageQuery.Order == db2.OrderDescending && cursor == "" {
+		cursor = "9"
+	}
+
+	return q.PageQuery.ApplyToUsingCursor(sq.Select("*").FromSelect(stats, "daily_stats"), "day", cursor)
+}
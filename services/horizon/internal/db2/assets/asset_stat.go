@@ -1,20 +1,28 @@
 package assets
 
 import (
+	"time"
+
 	sq "github.com/Masterminds/squirrel"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
 )
 
 // AssetStatsR is the result from the AssetStatsQ query
 type AssetStatsR struct {
-	SortKey     string `db:"sort_key"`
-	Type        string `db:"asset_type"`
-	Code        string `db:"asset_code"`
-	Issuer      string `db:"asset_issuer"`
-	Amount      string `db:"amount"`
-	NumAccounts int32  `db:"num_accounts"`
-	Flags       int8   `db:"flags"`
-	Toml        string `db:"toml"`
+	SortKey                  string `db:"sort_key"`
+	Type                     string `db:"asset_type"`
+	Code                     string `db:"asset_code"`
+	Issuer                   string `db:"asset_issuer"`
+	Amount                   string `db:"amount"`
+	NumAccounts              int32  `db:"num_accounts"`
+	Flags                    int8   `db:"flags"`
+	Toml                     string `db:"toml"`
+	NumUnauthorizedAccounts  int32  `db:"num_unauthorized_accounts"`
+	UnauthorizedAmount       string `db:"unauthorized_amount"`
+	AmountInOffers           string `db:"amount_in_offers"`
+	NumHoldersAboveThreshold int32  `db:"num_holders_above_threshold"`
+	PaymentCount24h          int32  `db:"payment_count_24h"`
+	PaymentVolume24h         string `db:"payment_volume_24h"`
 }
 
 // PagingToken implementation for hal.Pageable
@@ -27,11 +35,25 @@ type AssetStatsQ struct {
 	AssetCode   *string
 	AssetIssuer *string
 	PageQuery   *db2.PageQuery
+	// Now is the end of the 24 hour window the payment stats are computed
+	// over.  The zero value uses the current time.
+	Now time.Time
 }
 
 // GetSQL allows this query to be executed by the caller
 func (q AssetStatsQ) GetSQL() (sq.SelectBuilder, error) {
-	sql := selectQuery
+	now := q.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	sql := selectQuery.LeftJoin(
+		"(SELECT asset_id, count(*) as payment_count, sum(amount) as payment_volume"+
+			" FROM history_asset_payments WHERE ledger_closed_at >= ? AND ledger_closed_at < ?"+
+			" GROUP BY asset_id) pay ON hist.id = pay.asset_id",
+		now.Add(-24*time.Hour).UTC(),
+		now.UTC(),
+	)
 	if q.AssetCode != nil && *q.AssetCode != "" {
 		sql = sql.Where("hist.asset_code = ?", *q.AssetCode)
 	}
@@ -67,6 +89,12 @@ var selectQuery = sq.
 		"stats.num_accounts",
 		"stats.flags",
 		"stats.toml",
+		"stats.num_unauthorized_accounts",
+		"stats.unauthorized_amount",
+		"stats.amount_in_offers",
+		"stats.num_holders_above_threshold",
+		"COALESCE(pay.payment_count, 0) as payment_count_24h",
+		"COALESCE(pay.payment_volume, 0) as payment_volume_24h",
 	).
 	From("history_assets hist").
 	Join("asset_stats stats ON hist.id = stats.id")
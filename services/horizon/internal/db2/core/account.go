@@ -2,6 +2,7 @@ package core
 
 import (
 	sq "github.com/Masterminds/squirrel"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
 	"github.com/lomocoin/stellar-go/xdr"
 )
 
@@ -39,6 +40,22 @@ func (q *Q) AccountByAddress(dest interface{}, addy string, protocolVersion int3
 	return q.Get(dest, sql)
 }
 
+// AccountsByAddresses loads the rows from `accounts` for every address in
+// `addys`.  Addresses without an account are skipped.
+func (q *Q) AccountsByAddresses(dest interface{}, addys []string, protocolVersion int32) error {
+	var selectQuery sq.SelectBuilder
+
+	if protocolVersion >= 10 {
+		selectQuery = selectAccount
+	} else {
+		selectQuery = selectAccountPreV10
+	}
+
+	sql := selectQuery.Where(sq.Eq{"accountid": addys}).OrderBy("accountid")
+
+	return q.Select(dest, sql)
+}
+
 // SequencesForAddresses loads the current sequence number for every accountid
 // specified in `addys`
 func (q *Q) SequencesForAddresses(dest interface{}, addys []string) error {
@@ -55,6 +72,15 @@ func (q *Q) SequenceProvider() *SequenceProvider {
 	return &SequenceProvider{Q: q}
 }
 
+// accountIDCursor returns the cursor of `page` to compare addresses against,
+// which sorts after every address when paging from the end.
+func accountIDCursor(page db2.PageQuery) string {
+	if page.Cursor == "" && page.Order == db2.OrderDescending {
+		return "Z"
+	}
+	return page.Cursor
+}
+
 // Get implements `txsub.SequenceProvider`
 func (sp *SequenceProvider) Get(addys []string) (map[string]uint64, error) {
 	rows := []struct {
@@ -83,6 +109,7 @@ var selectAccount = sq.Select(
 	"a.homedomain",
 	"a.thresholds",
 	"a.flags",
+	"a.lastmodified",
 	// Liabilities can be NULL so can error without `coalesce`:
 	// `Invalid value for xdr.Int64`
 	"coalesce(a.buyingliabilities, 0) as buyingliabilities",
@@ -98,4 +125,5 @@ var selectAccountPreV10 = sq.Select(
 	"a.homedomain",
 	"a.thresholds",
 	"a.flags",
+	"a.lastmodified",
 ).From("accounts a")
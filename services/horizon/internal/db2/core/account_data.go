@@ -26,6 +26,12 @@ func (q *Q) AllDataByAddress(dest interface{}, addy string) error {
 	return q.Select(dest, sql)
 }
 
+// AllDataByAddresses loads all data for every address in `addys`
+func (q *Q) AllDataByAddresses(dest interface{}, addys []string) error {
+	sql := selectAccountData.Where(sq.Eq{"accountid": addys}).OrderBy("accountid")
+	return q.Select(dest, sql)
+}
+
 var selectAccountData = sq.Select(
 	"ad.accountid",
 	"ad.dataname",
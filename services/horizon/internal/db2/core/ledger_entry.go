@@ -0,0 +1,77 @@
+package core
+
+import (
+	"strconv"
+
+	"github.com/guregu/null"
+	"github.com/lomocoin/stellar-go/xdr"
+)
+
+// AccountFromEntry builds an Account, as it would have been loaded from the
+// `accounts` table, from the provided account ledger entry.
+func AccountFromEntry(entry xdr.LedgerEntry) Account {
+	ae := entry.Data.MustAccount()
+
+	result := Account{
+		Accountid:     ae.AccountId.Address(),
+		Balance:       ae.Balance,
+		Seqnum:        strconv.FormatInt(int64(ae.SeqNum), 10),
+		Numsubentries: int32(ae.NumSubEntries),
+		HomeDomain:    null.StringFrom(string(ae.HomeDomain)),
+		Thresholds:    ae.Thresholds,
+		Flags:         xdr.AccountFlags(ae.Flags),
+		Lastmodified:  int32(entry.LastModifiedLedgerSeq),
+	}
+
+	if ae.InflationDest != nil {
+		result.Inflationdest = null.StringFrom(ae.InflationDest.Address())
+	}
+
+	if ae.Ext.V1 != nil {
+		result.BuyingLiabilities = ae.Ext.V1.Liabilities.Buying
+		result.SellingLiabilities = ae.Ext.V1.Liabilities.Selling
+	}
+
+	return result
+}
+
+// SignersFromEntry builds the Signers, as they would have been loaded from
+// the `signers` table, from the provided account ledger entry.
+func SignersFromEntry(entry xdr.LedgerEntry) []Signer {
+	ae := entry.Data.MustAccount()
+
+	result := make([]Signer, len(ae.Signers))
+	for i, s := range ae.Signers {
+		result[i] = Signer{
+			Accountid: ae.AccountId.Address(),
+			Publickey: s.Key.Address(),
+			Weight:    int32(s.Weight),
+		}
+	}
+
+	return result
+}
+
+// TrustlineFromEntry builds a Trustline, as it would have been loaded from
+// the `trustlines` table, from the provided trustline ledger entry.
+func TrustlineFromEntry(entry xdr.LedgerEntry) (result Trustline, err error) {
+	tl := entry.Data.MustTrustLine()
+
+	err = tl.Asset.Extract(&result.Assettype, &result.Assetcode, &result.Issuer)
+	if err != nil {
+		return
+	}
+
+	result.Accountid = tl.AccountId.Address()
+	result.Tlimit = tl.Limit
+	result.Balance = tl.Balance
+	result.Flags = int32(tl.Flags)
+	result.Lastmodified = int32(entry.LastModifiedLedgerSeq)
+
+	if tl.Ext.V1 != nil {
+		result.BuyingLiabilities = tl.Ext.V1.Liabilities.Buying
+		result.SellingLiabilities = tl.Ext.V1.Liabilities.Selling
+	}
+
+	return
+}
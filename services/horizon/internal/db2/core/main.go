@@ -21,6 +21,7 @@ type Account struct {
 	Flags              xdr.AccountFlags
 	BuyingLiabilities  xdr.Int64 `db:"buyingliabilities"`
 	SellingLiabilities xdr.Int64 `db:"sellingliabilities"`
+	Lastmodified       int32
 }
 
 // AccountData is a row of data from the `accountdata` table
@@ -72,6 +73,16 @@ type OrderBookSummaryPriceLevel struct {
 // counter currency
 type OrderBookSummary []OrderBookSummaryPriceLevel
 
+// OrderBookDepth is the total amount of the bids and asks of a whole order
+// book, and of those priced within given bounds.  Like the price levels of
+// OrderBookSummary, bid amounts are denominated in the counter currency.
+type OrderBookDepth struct {
+	BidDepth       int64 `db:"bid_depth"`
+	AskDepth       int64 `db:"ask_depth"`
+	BidDepthWithin int64 `db:"bid_depth_within"`
+	AskDepthWithin int64 `db:"ask_depth_within"`
+}
+
 // Q is a helper struct on which to hang common queries against a stellar
 // core database.
 type Q struct {
@@ -128,6 +139,16 @@ type Trustline struct {
 	Flags              int32
 	BuyingLiabilities  xdr.Int64 `db:"buyingliabilities"`
 	SellingLiabilities xdr.Int64 `db:"sellingliabilities"`
+	Lastmodified       int32
+}
+
+// TrustlineStats summarizes the trustlines of a single asset
+type TrustlineStats struct {
+	NumAuthorized            int32  `db:"num_authorized"`
+	AuthorizedAmount         string `db:"authorized_amount"`
+	NumUnauthorized          int32  `db:"num_unauthorized"`
+	UnauthorizedAmount       string `db:"unauthorized_amount"`
+	NumHoldersAboveThreshold int32  `db:"num_holders_above_threshold"`
 }
 
 // AssetFromDB produces an xdr.Asset by combining the constituent type, code and
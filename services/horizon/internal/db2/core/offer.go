@@ -20,11 +20,34 @@ func (r Offer) PriceAsString() string {
 	return big.NewRat(int64(r.Pricen), int64(r.Priced)).FloatString(7)
 }
 
+// Assets returns the assets sold and bought by the offer.
+func (r Offer) Assets() (selling xdr.Asset, buying xdr.Asset, err error) {
+	selling, err = AssetFromDB(r.SellingAssetType, r.SellingAssetCode.String, r.SellingIssuer.String)
+	if err != nil {
+		return
+	}
+
+	buying, err = AssetFromDB(r.BuyingAssetType, r.BuyingAssetCode.String, r.BuyingIssuer.String)
+	return
+}
+
 // ConnectedAssets loads xdr.Asset records for the purposes of path
 // finding.  Given the input asset type, a list of xdr.Assets is returned that
 // each have some available trades for the input asset.
 func (q *Q) ConnectedAssets(dest interface{}, selling xdr.Asset) error {
+	return q.connectedAssets(dest, selling, "selling", "buying")
+}
 
+// ConnectedSellingAssets loads xdr.Asset records for the purposes of
+// strict-send path finding.  Given the input asset type, a list of xdr.Assets
+// is returned that are each sold by some offers buying the input asset.
+func (q *Q) ConnectedSellingAssets(dest interface{}, buying xdr.Asset) error {
+	return q.connectedAssets(dest, buying, "buying", "selling")
+}
+
+// connectedAssets loads the `to` assets of the offers whose `from` asset,
+// either "selling" or "buying", is `asset`.
+func (q *Q) connectedAssets(dest interface{}, asset xdr.Asset, from, to string) error {
 	assets, ok := dest.(*[]xdr.Asset)
 	if !ok {
 		return errors.New("dest is not *[]xdr.Asset")
@@ -36,21 +59,21 @@ func (q *Q) ConnectedAssets(dest interface{}, selling xdr.Asset) error {
 		i string
 	)
 
-	err := selling.Extract(&t, &c, &i)
+	err := asset.Extract(&t, &c, &i)
 	if err != nil {
 		return err
 	}
 
 	sql := sq.Select(
-		"buyingassettype AS type",
-		"coalesce(buyingassetcode, '') AS code",
-		"coalesce(buyingissuer, '') AS issuer").
+		to+"assettype AS type",
+		"coalesce("+to+"assetcode, '') AS code",
+		"coalesce("+to+"issuer, '') AS issuer").
 		From("offers").
-		Where(sq.Eq{"sellingassettype": t}).
-		GroupBy("buyingassettype", "buyingassetcode", "buyingissuer")
+		Where(sq.Eq{from + "assettype": t}).
+		GroupBy(to+"assettype", to+"assetcode", to+"issuer")
 
 	if t != xdr.AssetTypeAssetTypeNative {
-		sql = sql.Where(sq.Eq{"sellingassetcode": c, "sellingissuer": i})
+		sql = sql.Where(sq.Eq{from + "assetcode": c, from + "issuer": i})
 	}
 
 	var rows []struct {
@@ -100,3 +123,91 @@ func (q *Q) OffersByAddress(dest interface{}, addy string, pq db2.PageQuery) err
 
 	return q.Select(dest, sql)
 }
+
+// AllOffers loads every offer of the order book, ordered by ascending price
+// within each pair of assets.  It is used to build the in-memory order book
+// graph used for path finding.
+func (q *Q) AllOffers(dest interface{}) error {
+	sql := sq.Select("co.*").
+		From("offers co").
+		OrderBy("co.price asc", "co.offerid asc")
+
+	return q.Select(dest, sql)
+}
+
+// BestOffers loads the cheapest offer of each of the order books identified by
+// a selling/buying pair in `pairs`, using a single query.  Order books without
+// any offer are missing from the results.
+func (q *Q) BestOffers(dest *[]Offer, pairs [][2]xdr.Asset) error {
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	books := sq.Or{}
+	for _, pair := range pairs {
+		book := sq.Eq{}
+		err := offerAssetFilter(book, "selling", pair[0])
+		if err != nil {
+			return err
+		}
+		err = offerAssetFilter(book, "buying", pair[1])
+		if err != nil {
+			return err
+		}
+		books = append(books, book)
+	}
+
+	pairColumns := "sellingassettype, sellingassetcode, sellingissuer, " +
+		"buyingassettype, buyingassetcode, buyingissuer"
+	sql := sq.Select("DISTINCT ON ("+pairColumns+") *").
+		From("offers").
+		Where(books).
+		OrderBy(pairColumns, "price asc", "offerid asc")
+
+	return q.Select(dest, sql)
+}
+
+// offerAssetFilter adds to `filter` the conditions matching the offers whose
+// `side`, either "selling" or "buying", is `asset`.
+func offerAssetFilter(filter sq.Eq, side string, asset xdr.Asset) error {
+	var (
+		t xdr.AssetType
+		c string
+		i string
+	)
+
+	err := asset.Extract(&t, &c, &i)
+	if err != nil {
+		return err
+	}
+
+	filter[side+"assettype"] = t
+	if t == xdr.AssetTypeAssetTypeNative {
+		filter[side+"assetcode"] = nil
+		filter[side+"issuer"] = nil
+	} else {
+		filter[side+"assetcode"] = c
+		filter[side+"issuer"] = i
+	}
+	return nil
+}
+
+// AmountInOffersForAsset returns the total amount of the asset identified by
+// type, code and issuer that is offered for sale in the order book.
+func (q *Q) AmountInOffersForAsset(
+	assetType int32,
+	assetCode string,
+	assetIssuer string,
+) (string, error) {
+	sql := sq.Select("COALESCE(SUM(amount), 0) as sum").
+		From("offers").
+		Where(sq.Eq{
+			"sellingassettype": assetType,
+			"sellingassetcode": assetCode,
+			"sellingissuer":    assetIssuer,
+		})
+
+	var sum string
+	err := q.Get(&sum, sql)
+	return sum, err
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/test"
+	"github.com/lomocoin/stellar-go/xdr"
 )
 
 func TestOffersByAddress(t *testing.T) {
@@ -69,3 +70,35 @@ func TestOffersByAddress(t *testing.T) {
 		tt.Assert.Equal(int64(2), offers[0].OfferID)
 	}
 }
+
+func TestBestOffers(t *testing.T) {
+	tt := test.Start(t).Scenario("order_books")
+	defer tt.Finish()
+	q := &Q{tt.CoreSession()}
+
+	usd, err := AssetFromDB(xdr.AssetTypeAssetTypeCreditAlphanum4, "USD", "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4")
+	tt.Require.NoError(err)
+	btc, err := AssetFromDB(xdr.AssetTypeAssetTypeCreditAlphanum4, "BTC", "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4")
+	tt.Require.NoError(err)
+	native, err := AssetFromDB(xdr.AssetTypeAssetTypeNative, "", "")
+	tt.Require.NoError(err)
+
+	var offers []Offer
+	err = q.BestOffers(&offers, [][2]xdr.Asset{
+		{usd, native},
+		{native, usd},
+		{usd, btc},
+		{btc, native},
+	})
+	tt.Require.NoError(err)
+
+	ids := map[int64]bool{}
+	for _, offer := range offers {
+		ids[offer.OfferID] = true
+	}
+	tt.Assert.Equal(map[int64]bool{1: true, 2: true, 7: true}, ids)
+
+	offers = nil
+	tt.Require.NoError(q.BestOffers(&offers, nil))
+	tt.Assert.Len(offers, 0)
+}
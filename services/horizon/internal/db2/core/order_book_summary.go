@@ -3,6 +3,8 @@ package core
 import (
 	"bytes"
 	"fmt"
+	"math"
+	"math/big"
 	"text/template"
 
 	"github.com/go-errors/errors"
@@ -16,10 +18,13 @@ type orderbookQueryBuilder struct {
 	BuyingType    xdr.AssetType
 	BuyingCode    string
 	BuyingIssuer  string
+	Low           *big.Rat
+	High          *big.Rat
 	args          []interface{}
 }
 
 var orderbookQueryTemplate *template.Template
+var orderbookDepthQueryTemplate *template.Template
 
 // Asks filters the summary into a slice of PriceLevelRecords where the type is 'ask'
 func (o *OrderBookSummary) Asks() []OrderBookSummaryPriceLevel {
@@ -50,6 +55,99 @@ func (o *OrderBookSummary) filter(typ string, prepend bool) []OrderBookSummaryPr
 	return result
 }
 
+// Aggregate groups the price levels of the summary in buckets `tick` wide.  The
+// price of ask buckets is rounded up, and the price of bid buckets rounded down,
+// to a multiple of `tick`.  Bids cheaper than `tick` are grouped in the `tick`
+// bucket rather than in a bucket priced at zero.
+func (o OrderBookSummary) Aggregate(tick *big.Rat) (OrderBookSummary, error) {
+	result := OrderBookSummary{}
+
+	for _, level := range o {
+		price := big.NewRat(int64(level.Pricen), int64(level.Priced))
+		ticks := new(big.Rat).Quo(price, tick)
+		count := new(big.Int).Quo(ticks.Num(), ticks.Denom())
+		if level.Type == "ask" && !ticks.IsInt() {
+			count.Add(count, big.NewInt(1))
+		}
+		if count.Sign() == 0 {
+			count.SetInt64(1)
+		}
+
+		bucket := new(big.Rat).Mul(new(big.Rat).SetInt(count), tick)
+		n, d := bucket.Num(), bucket.Denom()
+		if !n.IsInt64() || n.Int64() > math.MaxInt32 || !d.IsInt64() || d.Int64() > math.MaxInt32 {
+			return nil, errors.New("tick is too precise for the prices of the order book")
+		}
+
+		last := len(result) - 1
+		if last >= 0 &&
+			result[last].Type == level.Type &&
+			result[last].Pricen == int32(n.Int64()) &&
+			result[last].Priced == int32(d.Int64()) {
+			result[last].Amount += level.Amount
+			continue
+		}
+
+		pricef, _ := bucket.Float64()
+		result = append(result, OrderBookSummaryPriceLevel{
+			Type: level.Type,
+			PriceLevel: PriceLevel{
+				Pricen: int32(n.Int64()),
+				Priced: int32(d.Int64()),
+				Pricef: pricef,
+				Amount: level.Amount,
+			},
+		})
+	}
+
+	return result, nil
+}
+
+// MidPrice returns the average of the best bid and ask prices of the summary,
+// or nil if one of its sides is empty.
+func (o OrderBookSummary) MidPrice() *big.Rat {
+	bids, asks := o.Bids(), o.Asks()
+	if len(bids) == 0 || len(asks) == 0 {
+		return nil
+	}
+
+	mid := new(big.Rat).Add(
+		big.NewRat(int64(bids[0].Pricen), int64(bids[0].Priced)),
+		big.NewRat(int64(asks[0].Pricen), int64(asks[0].Priced)),
+	)
+	return mid.Quo(mid, big.NewRat(2, 1))
+}
+
+// DepthBounds returns the prices [low, high] within `percent` percent of `mid`,
+// to load the depth of an order book around its mid price with
+// GetOrderBookDepth.
+func DepthBounds(mid, percent *big.Rat) (low, high *big.Rat) {
+	delta := new(big.Rat).Mul(mid, percent)
+	delta.Quo(delta, big.NewRat(100, 1))
+	return new(big.Rat).Sub(mid, delta), new(big.Rat).Add(mid, delta)
+}
+
+// Truncate returns the summary keeping the `limit` best price levels of each
+// side, that is the cheapest asks and the highest bids.
+func (o OrderBookSummary) Truncate(limit int) OrderBookSummary {
+	asks := o.Asks()
+	if len(asks) > limit {
+		asks = asks[:limit]
+	}
+
+	bids := o.Bids()
+	if len(bids) > limit {
+		bids = bids[:limit]
+	}
+
+	result := OrderBookSummary(asks)
+	for i := len(bids) - 1; i >= 0; i-- {
+		result = append(result, bids[i])
+	}
+
+	return result
+}
+
 // GetOrderBookSummary loads a summary of an order book identified by a
 // selling/buying pair. It is designed to drive an order book summary client
 // interface (bid/ask spread, prices and volume, etc).
@@ -80,6 +178,35 @@ func (q *Q) GetOrderBookSummary(dest interface{}, selling xdr.Asset, buying xdr.
 	return nil
 }
 
+// GetOrderBookDepth loads the depth of the whole order book identified by a
+// selling/buying pair, regardless of the number of its price levels.  When
+// `low` and `high` are not nil, the depth of the bids priced at or above `low`
+// and of the asks priced at or below `high` is loaded too.
+func (q *Q) GetOrderBookDepth(dest *OrderBookDepth, selling xdr.Asset, buying xdr.Asset, low, high *big.Rat) error {
+	var sql bytes.Buffer
+	oq := orderbookQueryBuilder{Low: low, High: high}
+	err := selling.Extract(&oq.SellingType, &oq.SellingCode, &oq.SellingIssuer)
+	if err != nil {
+		return err
+	}
+	err = buying.Extract(&oq.BuyingType, &oq.BuyingCode, &oq.BuyingIssuer)
+	if err != nil {
+		return err
+	}
+
+	err = orderbookDepthQueryTemplate.Execute(&sql, &oq)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	err = q.GetRaw(dest, sql.String(), oq.args...)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	return nil
+}
+
 // Filter helps manage positional parameters and "IS NULL" checks for an order
 // book query. An empty string will be converted into a null comparison.
 func (q *orderbookQueryBuilder) Filter(col string, v interface{}) string {
@@ -93,6 +220,13 @@ func (q *orderbookQueryBuilder) Filter(col string, v interface{}) string {
 	return fmt.Sprintf("%s = $%d", col, n)
 }
 
+// Numeric pushes the integer `v` as an argument of the query, returning its
+// placeholder cast to numeric such that prices are compared exactly.
+func (q *orderbookQueryBuilder) Numeric(v *big.Int) string {
+	n := q.pushArg(v.String())
+	return fmt.Sprintf("$%d::numeric", n)
+}
+
 // pushArg appends the provided value to this queries argument list and returns
 // the placeholder position to use in a sql snippet
 func (q *orderbookQueryBuilder) pushArg(v interface{}) int {
@@ -165,5 +299,45 @@ FROM
 )) summary
 
 ORDER BY type, pricef
+`))
+
+	// The depth of the asks, then of the bids, whose where clauses and prices
+	// are inverted like in the summary: a bid priced priced/pricen is at or
+	// above low when priced * low.d >= low.n * pricen.
+	orderbookDepthQueryTemplate = template.Must(template.New("sql").Parse(`
+{{ define "asks" }}
+	FROM  offers co
+	WHERE 1=1
+	AND   {{ .Filter "co.sellingassettype" .SellingType }}
+	AND   {{ .Filter "co.sellingassetcode" .SellingCode}}
+	AND   {{ .Filter "co.sellingissuer"    .SellingIssuer}}
+	AND   {{ .Filter "co.buyingassettype"  .BuyingType }}
+	AND   {{ .Filter "co.buyingassetcode"  .BuyingCode}}
+	AND   {{ .Filter "co.buyingissuer"     .BuyingIssuer}}
+{{ end }}
+{{ define "bids" }}
+	FROM  offers co
+	WHERE 1=1
+	AND   {{ .Filter "co.sellingassettype" .BuyingType }}
+	AND   {{ .Filter "co.sellingassetcode" .BuyingCode}}
+	AND   {{ .Filter "co.sellingissuer"    .BuyingIssuer}}
+	AND   {{ .Filter "co.buyingassettype"  .SellingType }}
+	AND   {{ .Filter "co.buyingassetcode"  .SellingCode}}
+	AND   {{ .Filter "co.buyingissuer"     .SellingIssuer}}
+{{ end }}
+SELECT
+	(SELECT COALESCE(SUM(co.amount), 0) {{ template "asks" . }}) :: bigint as ask_depth,
+	(SELECT COALESCE(SUM(co.amount), 0) {{ template "bids" . }}) :: bigint as bid_depth,
+{{ if and .Low .High }}
+	(SELECT COALESCE(SUM(co.amount), 0) {{ template "asks" . }}
+		AND co.pricen::numeric * {{ .Numeric .High.Denom }} <= {{ .Numeric .High.Num }} * co.priced::numeric
+	) :: bigint as ask_depth_within,
+	(SELECT COALESCE(SUM(co.amount), 0) {{ template "bids" . }}
+		AND co.priced::numeric * {{ .Numeric .Low.Denom }} >= {{ .Numeric .Low.Num }} * co.pricen::numeric
+	) :: bigint as bid_depth_within
+{{ else }}
+	0 :: bigint as ask_depth_within,
+	0 :: bigint as bid_depth_within
+{{ end }}
 `))
 }
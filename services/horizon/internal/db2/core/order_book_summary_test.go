@@ -1,10 +1,13 @@
 package core
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/lomocoin/stellar-go/services/horizon/internal/test"
 	"github.com/lomocoin/stellar-go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetOrderBookSummary(t *testing.T) {
@@ -64,6 +67,40 @@ func TestGetOrderBookSummary(t *testing.T) {
 	tt.Assert.Equal(bids[2].Pricef, iasks[2].InvertPricef())
 }
 
+func TestGetOrderBookDepth(t *testing.T) {
+	tt := test.Start(t).Scenario("order_books")
+	defer tt.Finish()
+	q := &Q{tt.CoreSession()}
+
+	selling, err := AssetFromDB(xdr.AssetTypeAssetTypeCreditAlphanum4, "USD", "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4")
+	tt.Require.NoError(err)
+	buying, err := AssetFromDB(xdr.AssetTypeAssetTypeNative, "", "")
+	tt.Require.NoError(err)
+
+	var depth OrderBookDepth
+	err = q.GetOrderBookDepth(&depth, selling, buying, nil, nil)
+	tt.Require.NoError(err)
+	tt.Assert.Equal(int64(11100000000), depth.AskDepth)
+	tt.Assert.Equal(int64(60000000000), depth.BidDepth)
+	tt.Assert.Equal(int64(0), depth.AskDepthWithin)
+	tt.Assert.Equal(int64(0), depth.BidDepthWithin)
+
+	// within 25% of the mid price of 12.5, the asks at 15 and the bids at 10
+	var summary OrderBookSummary
+	err = q.GetOrderBookSummary(&summary, selling, buying, 1)
+	tt.Require.NoError(err)
+	low, high := DepthBounds(summary.MidPrice(), big.NewRat(25, 1))
+	tt.Assert.Equal(big.NewRat(75, 8), low)
+	tt.Assert.Equal(big.NewRat(125, 8), high)
+
+	err = q.GetOrderBookDepth(&depth, selling, buying, low, high)
+	tt.Require.NoError(err)
+	tt.Assert.Equal(int64(11100000000), depth.AskDepth)
+	tt.Assert.Equal(int64(60000000000), depth.BidDepth)
+	tt.Assert.Equal(int64(100000000), depth.AskDepthWithin)
+	tt.Assert.Equal(int64(1000000000), depth.BidDepthWithin)
+}
+
 // regression test for https://github.com/lomocoin/stellar-go/services/horizon/internal/issues/310
 func TestGetOrderBookSummary_Regress310(t *testing.T) {
 	tt := test.Start(t).Scenario("order_books_310")
@@ -98,3 +135,44 @@ func TestGetOrderBookSummary_Regress310(t *testing.T) {
 	tt.Assert.Equal(1.0/10.1, asks[1].Pricef)
 	tt.Assert.Equal(1.0/10.0, asks[2].Pricef)
 }
+
+func TestOrderBookSummary_AggregateAndTruncate(t *testing.T) {
+	level := func(typ string, n, d int32, amount int64) OrderBookSummaryPriceLevel {
+		return OrderBookSummaryPriceLevel{
+			Type:       typ,
+			PriceLevel: PriceLevel{Pricen: n, Priced: d, Amount: amount},
+		}
+	}
+
+	summary := OrderBookSummary{
+		level("ask", 11, 10, 1),
+		level("ask", 12, 10, 2),
+		level("ask", 21, 10, 4),
+		level("bid", 8, 10, 8),
+		level("bid", 9, 10, 16),
+	}
+
+	aggregated, err := summary.Aggregate(big.NewRat(1, 1))
+	require.NoError(t, err)
+
+	asks, bids := aggregated.Asks(), aggregated.Bids()
+	if assert.Len(t, asks, 2) {
+		assert.Equal(t, "2.0000000", asks[0].PriceAsString())
+		assert.Equal(t, int64(3), asks[0].Amount)
+		assert.Equal(t, "3.0000000", asks[1].PriceAsString())
+		assert.Equal(t, int64(4), asks[1].Amount)
+	}
+	if assert.Len(t, bids, 1) {
+		assert.Equal(t, "1.0000000", bids[0].PriceAsString())
+		assert.Equal(t, int64(24), bids[0].Amount)
+	}
+
+	truncated := summary.Truncate(1)
+	asks, bids = truncated.Asks(), truncated.Bids()
+	if assert.Len(t, asks, 1) {
+		assert.Equal(t, int64(1), asks[0].Amount)
+	}
+	if assert.Len(t, bids, 1) {
+		assert.Equal(t, int64(16), bids[0].Amount)
+	}
+}
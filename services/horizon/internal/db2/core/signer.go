@@ -2,14 +2,45 @@ package core
 
 import (
 	sq "github.com/Masterminds/squirrel"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
 )
 
+// AccountIDsForSigner loads a page of the addresses of the accounts `signer`
+// can sign for: the accounts listing it as a signer and, if its master key
+// has a non-zero weight, the account with the address `signer` itself.
+func (q *Q) AccountIDsForSigner(dest interface{}, signer string, page db2.PageQuery) error {
+	signed := sq.Select("accountid").
+		From("signers").
+		Where("publickey = ?", signer).
+		Suffix(
+			"UNION SELECT accountid FROM accounts WHERE accountid = ? AND get_byte(decode(thresholds, 'base64'), 0) > 0",
+			signer,
+		)
+
+	sql, err := page.ApplyToUsingCursor(
+		sq.Select("s.accountid").FromSelect(signed, "s"),
+		"s.accountid",
+		accountIDCursor(page),
+	)
+	if err != nil {
+		return err
+	}
+
+	return q.Select(dest, sql)
+}
+
 // SignersByAddress loads all signer rows for `addy`
 func (q *Q) SignersByAddress(dest interface{}, addy string) error {
 	sql := selectSigner.Where("accountid = ?", addy)
 	return q.Select(dest, sql)
 }
 
+// SignersByAddresses loads all signer rows for every address in `addys`
+func (q *Q) SignersByAddresses(dest interface{}, addys []string) error {
+	sql := selectSigner.Where(sq.Eq{"accountid": addys}).OrderBy("accountid")
+	return q.Select(dest, sql)
+}
+
 var selectSigner = sq.Select(
 	"si.accountid",
 	"si.publickey",
@@ -4,9 +4,27 @@ import (
 	"errors"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
 	"github.com/lomocoin/stellar-go/xdr"
 )
 
+// AccountIDsForAsset loads a page of the addresses of the accounts that have a
+// trustline to the asset identified by `code` and `issuer`, authorized or not.
+func (q *Q) AccountIDsForAsset(dest interface{}, code string, issuer string, page db2.PageQuery) error {
+	sql, err := page.ApplyToUsingCursor(
+		sq.Select("tl.accountid").
+			From("trustlines tl").
+			Where(sq.Eq{"tl.assetcode": code, "tl.issuer": issuer}),
+		"tl.accountid",
+		accountIDCursor(page),
+	)
+	if err != nil {
+		return err
+	}
+
+	return q.Select(dest, sql)
+}
+
 // AssetsForAddress loads `dest` as `[]xdr.Asset` with every asset the account
 // at `addy` can hold.
 func (q *Q) AssetsForAddress(dest interface{}, addy string, protocolVersion int32) error {
@@ -83,6 +101,20 @@ func (q *Q) TrustlinesByAddress(dest interface{}, addy string, protocolVersion i
 	return q.Select(dest, sql)
 }
 
+// TrustlinesByAddresses loads all trustlines for every address in `addys`
+func (q *Q) TrustlinesByAddresses(dest interface{}, addys []string, protocolVersion int32) error {
+	var selectQuery sq.SelectBuilder
+
+	if protocolVersion >= 10 {
+		selectQuery = selectTrustline
+	} else {
+		selectQuery = selectTrustlinePreV10
+	}
+
+	sql := selectQuery.Where(sq.Eq{"accountid": addys}).OrderBy("accountid")
+	return q.Select(dest, sql)
+}
+
 // BalancesForAsset returns all the balances by asset type, code, issuer
 func (q *Q) BalancesForAsset(
 	assetType int32,
@@ -103,6 +135,33 @@ func (q *Q) BalancesForAsset(
 	return result.Count, result.Sum, err
 }
 
+// TrustlineStatsForAsset loads the stats of the trustlines to the asset
+// identified by type, code and issuer.  Holders are counted as above the
+// threshold when their balance is at least `threshold`, authorized or not.
+func (q *Q) TrustlineStatsForAsset(
+	dest *TrustlineStats,
+	assetType int32,
+	assetCode string,
+	assetIssuer string,
+	threshold xdr.Int64,
+) error {
+	sql := sq.Select(
+		"COALESCE(SUM(CASE WHEN flags = 1 THEN 1 ELSE 0 END), 0) as num_authorized",
+		"COALESCE(SUM(CASE WHEN flags = 1 THEN balance ELSE 0 END), 0) as authorized_amount",
+		"COALESCE(SUM(CASE WHEN flags = 1 THEN 0 ELSE 1 END), 0) as num_unauthorized",
+		"COALESCE(SUM(CASE WHEN flags = 1 THEN 0 ELSE balance END), 0) as unauthorized_amount",
+	).
+		Column(sq.Expr("COALESCE(SUM(CASE WHEN balance >= ? THEN 1 ELSE 0 END), 0) as num_holders_above_threshold", threshold)).
+		From("trustlines").
+		Where(sq.Eq{
+			"assettype": assetType,
+			"assetcode": assetCode,
+			"issuer":    assetIssuer,
+		})
+
+	return q.Get(dest, sql)
+}
+
 var selectTrustline = sq.Select(
 	"tl.accountid",
 	"tl.assettype",
@@ -111,6 +170,7 @@ var selectTrustline = sq.Select(
 	"tl.tlimit",
 	"tl.balance",
 	"tl.flags",
+	"tl.lastmodified",
 	// Liabilities can be NULL so can error without `coalesce`:
 	// `Invalid value for xdr.Int64`
 	"coalesce(tl.buyingliabilities, 0) as buyingliabilities",
@@ -125,6 +185,7 @@ var selectTrustlinePreV10 = sq.Select(
 	"tl.tlimit",
 	"tl.balance",
 	"tl.flags",
+	"tl.lastmodified",
 ).From("trustlines tl")
 
 var selectBalances = sq.Select("COUNT(*)", "COALESCE(SUM(balance), 0) as sum").From("trustlines")
@@ -0,0 +1,28 @@
+package history
+
+import (
+	sq "github.com/Masterminds/squirrel"
+)
+
+// APIKeys loads every row of the `api_keys` table.
+func (q *Q) APIKeys(dest interface{}) error {
+	sql := sq.Select(
+		"ak.key_hash",
+		"ak.name",
+		"ak.per_hour_rate_limit",
+		"ak.burst",
+		"ak.routes",
+	).From("api_keys ak").OrderBy("ak.name")
+
+	return q.Select(dest, sql)
+}
+
+// InsertAPIKey inserts `key` into the `api_keys` table.
+func (q *Q) InsertAPIKey(key APIKey) error {
+	sql := sq.Insert("api_keys").
+		Columns("key_hash", "name", "per_hour_rate_limit", "burst", "routes").
+		Values(key.KeyHash, key.Name, key.PerHourRateLimit, key.Burst, key.Routes)
+
+	_, err := q.Exec(sql)
+	return err
+}
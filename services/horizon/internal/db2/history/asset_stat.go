@@ -0,0 +1,50 @@
+package history
+
+import (
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lomocoin/stellar-go/support/errors"
+)
+
+// UpdateAssetDailyStats records `stat` as the stats of its asset on the day
+// containing `at`, replacing any stats previously recorded for that day.
+func (q *Q) UpdateAssetDailyStats(stat AssetStat, at time.Time) error {
+	day := at.UTC().Format("2006-01-02")
+
+	// perform a delete first since upsert is not supported if postgres < 9.5
+	_, err := q.Exec(sq.Delete("asset_stats_daily").Where(sq.Eq{
+		"id":  stat.ID,
+		"day": day,
+	}))
+	if err != nil {
+		return errors.Wrap(err, "failed to delete asset_stats_daily row")
+	}
+
+	_, err = q.Exec(sq.Insert("asset_stats_daily").
+		Columns(
+			"id",
+			"day",
+			"amount",
+			"num_accounts",
+			"num_unauthorized_accounts",
+			"unauthorized_amount",
+			"amount_in_offers",
+			"num_holders_above_threshold",
+		).
+		Values(
+			stat.ID,
+			day,
+			stat.Amount,
+			stat.NumAccounts,
+			stat.NumUnauthorizedAccounts,
+			stat.UnauthorizedAmount,
+			stat.AmountInOffers,
+			stat.NumHoldersAboveThreshold,
+		))
+	if err != nil {
+		return errors.Wrap(err, "failed to insert asset_stats_daily row")
+	}
+
+	return nil
+}
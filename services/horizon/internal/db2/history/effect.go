@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
@@ -131,6 +132,28 @@ func (q *EffectsQ) ForTransaction(hash string) *EffectsQ {
 	return q
 }
 
+// ForTimeRange filters the query to only effects in ledgers that closed at or
+// after `since` and before `until`.  A zero time is ignored.
+func (q *EffectsQ) ForTimeRange(since, until time.Time) *EffectsQ {
+	if q.Err != nil {
+		return q
+	}
+
+	var start, end int64
+	start, end, q.Err = q.parent.IDRangeForTime(since, until)
+	if q.Err != nil {
+		return q
+	}
+
+	q.sql = q.sql.Where(
+		"heff.history_operation_id >= ? AND heff.history_operation_id < ?",
+		start,
+		end,
+	)
+
+	return q
+}
+
 // OfType filters the query to only effects of the given type.
 func (q *EffectsQ) OfType(typ EffectType) *EffectsQ {
 	q.sql = q.sql.Where("heff.type = ?", typ)
@@ -2,9 +2,12 @@ package history
 
 import (
 	"fmt"
+	"math"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/toid"
 	"github.com/lomocoin/stellar-go/support/errors"
 )
 
@@ -17,6 +20,60 @@ func (q *Q) LedgerBySequence(dest interface{}, seq int32) error {
 	return q.Get(dest, sql)
 }
 
+// LedgerSequenceAt loads the sequence of the latest ledger that closed at or
+// before `t`, or 0 if no such ledger exists in the history database.
+func (q *Q) LedgerSequenceAt(dest interface{}, t time.Time) error {
+	return q.GetRaw(dest, `
+		SELECT COALESCE(MAX(sequence), 0)
+		FROM history_ledgers
+		WHERE closed_at <= $1
+	`, t)
+}
+
+// IDRangeForTime returns the range [start, end) of the ids of the
+// transactions, operations and effects of the ledgers that closed at or after
+// `since` and before `until`.  A zero time leaves the range unbounded on that
+// side.
+func (q *Q) IDRangeForTime(since, until time.Time) (int64, int64, error) {
+	start := int64(0)
+	end := int64(math.MaxInt64)
+
+	if !since.IsZero() {
+		seq, err := q.firstLedgerClosedAt(since)
+		if err != nil {
+			return 0, 0, err
+		}
+		if seq == 0 {
+			return end, end, nil
+		}
+		start = toid.New(seq, 0, 0).ToInt64()
+	}
+
+	if !until.IsZero() {
+		seq, err := q.firstLedgerClosedAt(until)
+		if err != nil {
+			return 0, 0, err
+		}
+		if seq != 0 {
+			end = toid.New(seq, 0, 0).ToInt64()
+		}
+	}
+
+	return start, end, nil
+}
+
+// firstLedgerClosedAt returns the sequence of the earliest ledger that closed
+// at or after `t`, or 0 if no such ledger exists in the history database.
+func (q *Q) firstLedgerClosedAt(t time.Time) (int32, error) {
+	var seq int32
+	err := q.GetRaw(&seq, `
+		SELECT COALESCE(MIN(sequence), 0)
+		FROM history_ledgers
+		WHERE closed_at >= $1
+	`, t.UTC())
+	return seq, errors.Wrap(err, "failed to load ledger sequence")
+}
+
 // Ledgers provides a helper to filter rows from the `history_ledgers` table
 // with pre-defined filters.  See `LedgersQ` methods for the available filters.
 func (q *Q) Ledgers() *LedgersQ {
@@ -82,4 +139,5 @@ var selectLedger = sq.Select(
 	"hl.max_tx_set_size",
 	"hl.protocol_version",
 	"hl.ledger_header",
+	"hl.history_filtered",
 ).From("history_ledgers hl")
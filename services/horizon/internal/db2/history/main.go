@@ -106,12 +106,53 @@ const (
 
 )
 
+const (
+	// OfferEventCreated occurs when an offer is added to the order book
+	OfferEventCreated OfferEventType = 0 // from manage_offer, create_passive_offer
+
+	// OfferEventUpdated occurs when the seller changes an existing offer
+	OfferEventUpdated OfferEventType = 1 // from manage_offer, create_passive_offer
+
+	// OfferEventPartiallyFilled occurs when an offer is partially consumed by a
+	// trade and remains on the order book.
+	OfferEventPartiallyFilled OfferEventType = 2 // from manage_offer, create_passive_offer, path_payment
+
+	// OfferEventFilled occurs when an offer is completely consumed by trades
+	// and removed from the order book.
+	OfferEventFilled OfferEventType = 3 // from manage_offer, create_passive_offer, path_payment
+
+	// OfferEventCancelled occurs when the seller removes an offer from the
+	// order book.
+	OfferEventCancelled OfferEventType = 4 // from manage_offer, create_passive_offer
+)
+
+const (
+	// OfferStateActive is the state of an offer that is on the order book
+	OfferStateActive = "active"
+	// OfferStateFilled is the state of an offer that was completely consumed
+	OfferStateFilled = "filled"
+	// OfferStateCancelled is the state of an offer removed by its seller
+	OfferStateCancelled = "cancelled"
+)
+
 // Account is a row of data from the `history_accounts` table
 type Account struct {
 	ID      int64
 	Address string `db:"address"`
 }
 
+// APIKey is a row of data from the `api_keys` table.  KeyHash is the
+// hex-encoded SHA-256 hash of the key, which is not stored itself.  Routes is
+// a comma separated list of the paths the key may request, empty when every
+// path is allowed.
+type APIKey struct {
+	KeyHash          string `db:"key_hash"`
+	Name             string `db:"name"`
+	PerHourRateLimit int    `db:"per_hour_rate_limit"`
+	Burst            int    `db:"burst"`
+	Routes           string `db:"routes"`
+}
+
 // AccountsQ is a helper struct to aid in configuring queries that loads
 // slices of account structs.
 type AccountsQ struct {
@@ -120,6 +161,16 @@ type AccountsQ struct {
 	sql    sq.SelectBuilder
 }
 
+// AccountState is a row of data from the `history_account_states` table,
+// recording the state of an account as of a given ledger.  Entry is the
+// base64-encoded xdr.LedgerEntry and is null when the account did not exist.
+type AccountState struct {
+	AccountID      string      `db:"account_id"`
+	LedgerSequence int32       `db:"ledger_sequence"`
+	Balance        null.Int    `db:"balance"`
+	Entry          null.String `db:"entry"`
+}
+
 // Asset is a row of data from the `history_assets` table
 type Asset struct {
 	ID     int64  `db:"id"`
@@ -130,11 +181,15 @@ type Asset struct {
 
 // AssetStat is a row in the asset_stats table representing the stats per Asset
 type AssetStat struct {
-	ID          int64  `db:"id"`
-	Amount      string `db:"amount"`
-	NumAccounts int32  `db:"num_accounts"`
-	Flags       int8   `db:"flags"`
-	Toml        string `db:"toml"`
+	ID                       int64  `db:"id"`
+	Amount                   string `db:"amount"`
+	NumAccounts              int32  `db:"num_accounts"`
+	Flags                    int8   `db:"flags"`
+	Toml                     string `db:"toml"`
+	NumUnauthorizedAccounts  int32  `db:"num_unauthorized_accounts"`
+	UnauthorizedAmount       string `db:"unauthorized_amount"`
+	AmountInOffers           string `db:"amount_in_offers"`
+	NumHoldersAboveThreshold int32  `db:"num_holders_above_threshold"`
 }
 
 // Effect is a row of data from the `history_effects` table
@@ -192,6 +247,7 @@ type Ledger struct {
 	MaxTxSetSize       int32       `db:"max_tx_set_size"`
 	ProtocolVersion    int32       `db:"protocol_version"`
 	LedgerHeaderXDR    null.String `db:"ledger_header"`
+	HistoryFiltered    bool        `db:"history_filtered"`
 }
 
 // LedgerCache is a helper struct to load ledger data related to a batch of
@@ -211,6 +267,49 @@ type LedgersQ struct {
 	sql    sq.SelectBuilder
 }
 
+// OfferEvent is a row of data from the `history_offer_events` table, recording
+// the state of an offer after a single change to it.
+type OfferEvent struct {
+	OfferID            int64          `db:"offer_id"`
+	HistoryOperationID int64          `db:"history_operation_id"`
+	LedgerSequence     int32          `db:"ledger_sequence"`
+	Type               OfferEventType `db:"type"`
+	SellerID           string         `db:"seller_id"`
+	SellingAssetType   xdr.AssetType  `db:"selling_asset_type"`
+	SellingAssetCode   null.String    `db:"selling_asset_code"`
+	SellingAssetIssuer null.String    `db:"selling_asset_issuer"`
+	BuyingAssetType    xdr.AssetType  `db:"buying_asset_type"`
+	BuyingAssetCode    null.String    `db:"buying_asset_code"`
+	BuyingAssetIssuer  null.String    `db:"buying_asset_issuer"`
+	Amount             xdr.Int64      `db:"amount"`
+	Pricen             int32          `db:"pricen"`
+	Priced             int32          `db:"priced"`
+	Price              float64        `db:"price"`
+	Flags              int32          `db:"flags"`
+}
+
+// OfferEventsQ is a helper struct to aid in configuring queries that loads
+// slices of OfferEvent structs.
+type OfferEventsQ struct {
+	Err    error
+	parent *Q
+	sql    sq.SelectBuilder
+}
+
+// OfferEventType is the numeric type for an offer event, used as the `type`
+// field in the `history_offer_events` table.
+type OfferEventType int
+
+// OffersQ is a helper struct to aid in configuring queries that loads the
+// latest OfferEvent for each offer.  Filters on the immutable properties of
+// an offer are applied to `events`, the rest to `sql`.
+type OffersQ struct {
+	Err    error
+	parent *Q
+	events sq.SelectBuilder
+	sql    sq.SelectBuilder
+}
+
 // Operation is a row of data from the `history_operations` table
 type Operation struct {
 	TotalOrderID
@@ -307,11 +406,81 @@ type TransactionsQ struct {
 	sql    sq.SelectBuilder
 }
 
+// TrustlineState is a row of data from the `history_trustline_states` table,
+// recording the state of a trustline as of a given ledger.  Entry is the
+// base64-encoded xdr.LedgerEntry and is null when the trustline did not exist.
+type TrustlineState struct {
+	AccountID      string        `db:"account_id"`
+	AssetType      xdr.AssetType `db:"asset_type"`
+	AssetCode      string        `db:"asset_code"`
+	AssetIssuer    string        `db:"asset_issuer"`
+	LedgerSequence int32         `db:"ledger_sequence"`
+	Balance        null.Int      `db:"balance"`
+	Entry          null.String   `db:"entry"`
+}
+
+// Webhook is a row of data from the `webhooks` table.  Owner is the name of
+// the API key the webhook was registered with.  Accounts and EventTypes are
+// comma separated lists, EventTypes being empty when every type of event is
+// delivered.  LastLedger is the last ledger whose events were dispatched to
+// the webhook.
+type Webhook struct {
+	ID          int64       `db:"id"`
+	Owner       string      `db:"owner"`
+	URL         string      `db:"url"`
+	Secret      string      `db:"secret"`
+	Accounts    string      `db:"accounts"`
+	EventTypes  string      `db:"event_types"`
+	AssetType   null.String `db:"asset_type"`
+	AssetCode   null.String `db:"asset_code"`
+	AssetIssuer null.String `db:"asset_issuer"`
+	LastLedger  int32       `db:"last_ledger"`
+	CreatedAt   time.Time   `db:"created_at"`
+}
+
+// WebhookDelivery is a row of data from the `webhook_deliveries` table: an
+// event waiting to be delivered to a webhook.  URL and Secret are the ones of
+// the webhook.
+type WebhookDelivery struct {
+	ID            int64     `db:"id"`
+	WebhookID     int64     `db:"webhook_id"`
+	EventID       string    `db:"event_id"`
+	EventType     string    `db:"event_type"`
+	Payload       string    `db:"payload"`
+	Attempts      int32     `db:"attempts"`
+	NextAttemptAt time.Time `db:"next_attempt_at"`
+	LastError     string    `db:"last_error"`
+	URL           string    `db:"url"`
+	Secret        string    `db:"secret"`
+}
+
+// WebhookDeadLetter is a row of data from the `webhook_dead_letters` table:
+// an event that could not be delivered to a webhook after the maximum number
+// of attempts.
+type WebhookDeadLetter struct {
+	ID        int64     `db:"id"`
+	WebhookID int64     `db:"webhook_id"`
+	EventID   string    `db:"event_id"`
+	EventType string    `db:"event_type"`
+	Payload   string    `db:"payload"`
+	Attempts  int32     `db:"attempts"`
+	LastError string    `db:"last_error"`
+	FailedAt  time.Time `db:"failed_at"`
+}
+
 // ElderLedger loads the oldest ledger known to the history database
 func (q *Q) ElderLedger(dest interface{}) error {
 	return q.GetRaw(dest, `SELECT COALESCE(MIN(sequence), 0) FROM history_ledgers`)
 }
 
+// HistoryFiltered loads whether any ledger known to the history database was
+// ingested with an ingestion filter, recording only part of its history.
+func (q *Q) HistoryFiltered(dest interface{}) error {
+	return q.GetRaw(dest, `SELECT EXISTS(
+		SELECT 1 FROM history_ledgers WHERE history_filtered
+	)`)
+}
+
 // LatestLedger loads the latest known ledger
 func (q *Q) LatestLedger(dest interface{}) error {
 	return q.GetRaw(dest, `SELECT COALESCE(MAX(sequence), 0) FROM history_ledgers`)
@@ -0,0 +1,229 @@
+package history
+
+import (
+	"fmt"
+	"math/big"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
+	"github.com/lomocoin/stellar-go/support/errors"
+	"github.com/lomocoin/stellar-go/xdr"
+)
+
+// PagingToken returns a cursor for this offer event when listing the events
+// of a single offer.
+func (r OfferEvent) PagingToken() string {
+	return fmt.Sprintf("%d", r.HistoryOperationID)
+}
+
+// PriceAsString return the price fraction as a floating point approximate.
+func (r OfferEvent) PriceAsString() string {
+	return big.NewRat(int64(r.Pricen), int64(r.Priced)).FloatString(7)
+}
+
+// State returns the state of the offer after the event.
+func (r OfferEvent) State() string {
+	switch r.Type {
+	case OfferEventFilled:
+		return OfferStateFilled
+	case OfferEventCancelled:
+		return OfferStateCancelled
+	default:
+		return OfferStateActive
+	}
+}
+
+// String returns the name of the event type, as used in the event resources.
+func (t OfferEventType) String() string {
+	switch t {
+	case OfferEventCreated:
+		return "created"
+	case OfferEventUpdated:
+		return "updated"
+	case OfferEventPartiallyFilled:
+		return "partially_filled"
+	case OfferEventFilled:
+		return "filled"
+	case OfferEventCancelled:
+		return "cancelled"
+	default:
+		return "unknown"
+	}
+}
+
+// OfferByID loads the latest state of the offer identified by `id`.
+func (q *Q) OfferByID(dest interface{}, id int64) error {
+	sql := selectOfferEvent.
+		Where("hoe.offer_id = ?", id).
+		OrderBy("hoe.history_operation_id DESC").
+		Limit(1)
+
+	return q.Get(dest, sql)
+}
+
+// OfferEvents provides a helper to filter rows from the `history_offer_events`
+// table with pre-defined filters.  See `OfferEventsQ` methods for the
+// available filters.
+func (q *Q) OfferEvents() *OfferEventsQ {
+	return &OfferEventsQ{
+		parent: q,
+		sql:    selectOfferEvent,
+	}
+}
+
+// ForOffer filters the query results to the events of the offer `id`.
+func (q *OfferEventsQ) ForOffer(id int64) *OfferEventsQ {
+	q.sql = q.sql.Where("hoe.offer_id = ?", id)
+	return q
+}
+
+// Page specifies the paging constraints for the query being built by `q`.
+func (q *OfferEventsQ) Page(page db2.PageQuery) *OfferEventsQ {
+	if q.Err != nil {
+		return q
+	}
+
+	q.sql, q.Err = page.ApplyTo(q.sql, "hoe.history_operation_id")
+	return q
+}
+
+// Select loads the results of the query specified by `q` into `dest`.
+func (q *OfferEventsQ) Select(dest interface{}) error {
+	if q.Err != nil {
+		return q.Err
+	}
+
+	q.Err = q.parent.Select(dest, q.sql)
+	return q.Err
+}
+
+// Offers provides a helper to load the latest state of every offer recorded
+// in the `history_offer_events` table, including offers that are no longer on
+// the order book.  See `OffersQ` methods for the available filters.
+func (q *Q) Offers() *OffersQ {
+	return &OffersQ{
+		parent: q,
+		events: selectOfferEvent.
+			Options("DISTINCT ON (hoe.offer_id)").
+			OrderBy("hoe.offer_id", "hoe.history_operation_id DESC"),
+		sql: sq.Select("o.*"),
+	}
+}
+
+// ForBuyingAsset filters the query results to offers currently buying `asset`.
+func (q *OffersQ) ForBuyingAsset(asset xdr.Asset) *OffersQ {
+	q.sql = q.forAsset(q.sql, "buying", asset)
+	return q
+}
+
+// ForSeller filters the query results to offers made by the account at `addy`.
+func (q *OffersQ) ForSeller(addy string) *OffersQ {
+	// an offer's seller never changes, allowing the filter to be applied
+	// before finding the latest event of each offer.
+	q.events = q.events.Where("hoe.seller_id = ?", addy)
+	return q
+}
+
+// ForSellingAsset filters the query results to offers currently selling
+// `asset`.
+func (q *OffersQ) ForSellingAsset(asset xdr.Asset) *OffersQ {
+	q.sql = q.forAsset(q.sql, "selling", asset)
+	return q
+}
+
+// ForState filters the query results to offers in `state`, one of the
+// `OfferState*` constants.
+func (q *OffersQ) ForState(state string) *OffersQ {
+	switch state {
+	case OfferStateActive:
+		q.sql = q.sql.Where(sq.Eq{"o.type": []OfferEventType{
+			OfferEventCreated,
+			OfferEventUpdated,
+			OfferEventPartiallyFilled,
+		}})
+	case OfferStateFilled:
+		q.sql = q.sql.Where("o.type = ?", OfferEventFilled)
+	case OfferStateCancelled:
+		q.sql = q.sql.Where("o.type = ?", OfferEventCancelled)
+	default:
+		q.Err = errors.Errorf("invalid offer state: %s", state)
+	}
+	return q
+}
+
+// Page specifies the paging constraints for the query being built by `q`.
+func (q *OffersQ) Page(page db2.PageQuery) *OffersQ {
+	if q.Err != nil {
+		return q
+	}
+
+	cursor, err := page.CursorInt64()
+	if err != nil {
+		q.Err = err
+		return q
+	}
+
+	switch page.Order {
+	case "asc":
+		q.events = q.events.Where("hoe.offer_id > ?", cursor)
+		q.sql = q.sql.OrderBy("o.offer_id asc")
+	case "desc":
+		q.events = q.events.Where("hoe.offer_id < ?", cursor)
+		q.sql = q.sql.OrderBy("o.offer_id desc")
+	}
+
+	q.sql = q.sql.Limit(page.Limit)
+	return q
+}
+
+// Select loads the results of the query specified by `q` into `dest`.
+func (q *OffersQ) Select(dest interface{}) error {
+	if q.Err != nil {
+		return q.Err
+	}
+
+	q.Err = q.parent.Select(dest, q.sql.FromSelect(q.events, "o"))
+	return q.Err
+}
+
+func (q *OffersQ) forAsset(sql sq.SelectBuilder, prefix string, asset xdr.Asset) sq.SelectBuilder {
+	var (
+		typ    xdr.AssetType
+		code   string
+		issuer string
+	)
+
+	err := asset.Extract(&typ, &code, &issuer)
+	if err != nil {
+		q.Err = err
+		return sql
+	}
+
+	sql = sql.Where(fmt.Sprintf("o.%s_asset_type = ?", prefix), typ)
+	if typ != xdr.AssetTypeAssetTypeNative {
+		sql = sql.Where(sq.Eq{
+			fmt.Sprintf("o.%s_asset_code", prefix):   code,
+			fmt.Sprintf("o.%s_asset_issuer", prefix): issuer,
+		})
+	}
+	return sql
+}
+
+var selectOfferEvent = sq.Select(
+	"hoe.offer_id",
+	"hoe.history_operation_id",
+	"hoe.ledger_sequence",
+	"hoe.type",
+	"hoe.seller_id",
+	"hoe.selling_asset_type",
+	"hoe.selling_asset_code",
+	"hoe.selling_asset_issuer",
+	"hoe.buying_asset_type",
+	"hoe.buying_asset_code",
+	"hoe.buying_asset_issuer",
+	"hoe.amount",
+	"hoe.pricen",
+	"hoe.priced",
+	"hoe.price",
+	"hoe.flags",
+).From("history_offer_events hoe")
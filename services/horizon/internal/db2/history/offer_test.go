@@ -0,0 +1,33 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOfferEventState(t *testing.T) {
+	cases := []struct {
+		Type  OfferEventType
+		Name  string
+		State string
+	}{
+		{OfferEventCreated, "created", OfferStateActive},
+		{OfferEventUpdated, "updated", OfferStateActive},
+		{OfferEventPartiallyFilled, "partially_filled", OfferStateActive},
+		{OfferEventFilled, "filled", OfferStateFilled},
+		{OfferEventCancelled, "cancelled", OfferStateCancelled},
+	}
+
+	for _, kase := range cases {
+		event := OfferEvent{Type: kase.Type}
+		assert.Equal(t, kase.Name, event.Type.String())
+		assert.Equal(t, kase.State, event.State())
+	}
+}
+
+func TestOffersForStateInvalid(t *testing.T) {
+	q := &Q{}
+	offers := q.Offers().ForState("expired")
+	assert.Error(t, offers.Err)
+}
@@ -2,9 +2,12 @@ package history
 
 import (
 	"encoding/json"
+	"fmt"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/go-errors/errors"
+	"github.com/lomocoin/stellar-go/amount"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/toid"
 	"github.com/lomocoin/stellar-go/xdr"
@@ -109,6 +112,94 @@ func (q *OperationsQ) ForTransaction(hash string) *OperationsQ {
 	return q
 }
 
+// ForAsset filters the query to only operations whose details involve the
+// asset `a`, either as the asset paid, sent, sold, bought or trusted.
+func (q *OperationsQ) ForAsset(a xdr.Asset) *OperationsQ {
+	var typ, code, iss string
+	q.Err = a.Extract(&typ, &code, &iss)
+	if q.Err != nil {
+		return q
+	}
+
+	var clauses sq.Or
+	for _, prefix := range []string{"", "source_", "selling_", "buying_"} {
+		details := map[string]string{prefix + "asset_type": typ}
+		if a.Type != xdr.AssetTypeAssetTypeNative {
+			details[prefix+"asset_code"] = code
+			details[prefix+"asset_issuer"] = iss
+		}
+
+		clauses = append(clauses, detailsContain(details))
+	}
+	q.sql = q.sql.Where(clauses)
+
+	return q
+}
+
+// ForAmountRange filters the query to only operations moving an amount, the
+// starting balance of created accounts included, of at least `min` and at
+// most `max`.  A zero bound is ignored.
+func (q *OperationsQ) ForAmountRange(min, max xdr.Int64) *OperationsQ {
+	col := "COALESCE(hop.details->>'amount', hop.details->>'starting_balance')::numeric"
+	if min != 0 {
+		q.sql = q.sql.Where(col+" >= ?", amount.String(min))
+	}
+	if max != 0 {
+		q.sql = q.sql.Where(col+" <= ?", amount.String(max))
+	}
+
+	return q
+}
+
+// ForCounterparty filters the query to only operations paying to or from,
+// creating, or merging into the account `aid`.  Combined with `ForAccount`,
+// it selects the operations between two accounts.
+func (q *OperationsQ) ForCounterparty(aid string) *OperationsQ {
+	var clauses sq.Or
+	for _, field := range []string{"from", "to", "funder", "account", "into"} {
+		clauses = append(clauses, detailsContain(map[string]string{field: aid}))
+	}
+	q.sql = q.sql.Where(clauses)
+
+	return q
+}
+
+// ForMemo filters the query to only operations of transactions with the memo
+// `memo`, as recorded in the `memo` column of `history_transactions`.  An empty
+// `memoType` matches memos of any type.
+func (q *OperationsQ) ForMemo(memoType, memo string) *OperationsQ {
+	q.sql = q.sql.Where(memoFilter(memoType, memo))
+	return q
+}
+
+// ForTimeRange filters the query to only operations in ledgers that closed at
+// or after `since` and before `until`.  A zero time is ignored.
+func (q *OperationsQ) ForTimeRange(since, until time.Time) *OperationsQ {
+	if q.Err != nil {
+		return q
+	}
+
+	var start, end int64
+	start, end, q.Err = q.parent.IDRangeForTime(since, until)
+	if q.Err != nil {
+		return q
+	}
+
+	q.sql = q.sql.Where(
+		fmt.Sprintf("%s >= ? AND %s < ?", q.opIdCol, q.opIdCol),
+		start,
+		end,
+	)
+
+	return q
+}
+
+// OfType filters the query to only operations of the given type.
+func (q *OperationsQ) OfType(typ xdr.OperationType) *OperationsQ {
+	q.sql = q.sql.Where("hop.type = ?", typ)
+	return q
+}
+
 // OnlyPayments filters the query being built to only include operations that
 // are in the "payment" class of operations:  CreateAccountOps, Payments, and
 // PathPayments.
@@ -152,3 +243,14 @@ var selectOperation = sq.Select(
 		"ht.transaction_hash").
 	From("history_operations hop").
 	LeftJoin("history_transactions ht ON ht.id = hop.transaction_id")
+
+// detailsContain returns a clause matching the operations whose details
+// contain every field of `fields`, which is able to use the
+// `hop_by_details` index.
+func detailsContain(fields map[string]string) sq.Sqlizer {
+	enc, err := json.Marshal(fields)
+	if err != nil {
+		panic(err)
+	}
+	return sq.Expr("hop.details @> ?::jsonb", string(enc))
+}
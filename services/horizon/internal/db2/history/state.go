@@ -0,0 +1,110 @@
+package history
+
+import (
+	sq "github.com/Masterminds/squirrel"
+)
+
+// AccountHasState returns true if any state has been recorded for the account
+// at `addy`.
+func (q *Q) AccountHasState(addy string) (bool, error) {
+	var found bool
+	err := q.GetRaw(&found, `
+		SELECT EXISTS(
+			SELECT 1 FROM history_account_states WHERE account_id = $1
+		)`, addy)
+	return found, err
+}
+
+// AccountStateAt loads the state of the account at `addy` as of the ledger
+// `seq`, that is the latest recorded state at or before `seq`.
+func (q *Q) AccountStateAt(dest interface{}, addy string, seq int32) error {
+	sql := selectAccountState.
+		Where("has.account_id = ?", addy).
+		Where("has.ledger_sequence <= ?", seq).
+		OrderBy("has.ledger_sequence DESC").
+		Limit(1)
+
+	return q.Get(dest, sql)
+}
+
+// AccountStatesBySequences loads every recorded account state that belongs to
+// one of `addys` and was recorded at one of the ledgers in `seqs`.
+func (q *Q) AccountStatesBySequences(dest interface{}, addys []string, seqs []int32) error {
+	sql := selectAccountState.Where(sq.Eq{
+		"has.account_id":      addys,
+		"has.ledger_sequence": seqs,
+	})
+
+	return q.Select(dest, sql)
+}
+
+// TrustlineStateAssets loads one row for every asset the account at `addy`
+// has recorded trustline state for.  Only the asset columns are populated.
+func (q *Q) TrustlineStateAssets(dest interface{}, addy string) error {
+	sql := sq.
+		Select(
+			"DISTINCT hts.account_id",
+			"hts.asset_type",
+			"hts.asset_code",
+			"hts.asset_issuer",
+		).
+		From("history_trustline_states hts").
+		Where("hts.account_id = ?", addy)
+
+	return q.Select(dest, sql)
+}
+
+// TrustlineStatesAt loads the state of every trustline recorded for the
+// account at `addy` as of the ledger `seq`.  Rows with a null entry signify
+// the trustline did not exist at that ledger.
+func (q *Q) TrustlineStatesAt(dest interface{}, addy string, seq int32) error {
+	sql := sq.
+		Select(
+			"DISTINCT ON (hts.asset_type, hts.asset_code, hts.asset_issuer) hts.account_id",
+			"hts.asset_type",
+			"hts.asset_code",
+			"hts.asset_issuer",
+			"hts.ledger_sequence",
+			"hts.balance",
+			"hts.entry",
+		).
+		From("history_trustline_states hts").
+		Where("hts.account_id = ?", addy).
+		Where("hts.ledger_sequence <= ?", seq).
+		OrderBy(
+			"hts.asset_type",
+			"hts.asset_code",
+			"hts.asset_issuer",
+			"hts.ledger_sequence DESC",
+		)
+
+	return q.Select(dest, sql)
+}
+
+// TrustlineStatesBySequences loads every recorded trustline state that belongs
+// to one of `addys` and was recorded at one of the ledgers in `seqs`.
+func (q *Q) TrustlineStatesBySequences(dest interface{}, addys []string, seqs []int32) error {
+	sql := selectTrustlineState.Where(sq.Eq{
+		"hts.account_id":      addys,
+		"hts.ledger_sequence": seqs,
+	})
+
+	return q.Select(dest, sql)
+}
+
+var selectAccountState = sq.Select(
+	"has.account_id",
+	"has.ledger_sequence",
+	"has.balance",
+	"has.entry",
+).From("history_account_states has")
+
+var selectTrustlineState = sq.Select(
+	"hts.account_id",
+	"hts.asset_type",
+	"hts.asset_code",
+	"hts.asset_issuer",
+	"hts.ledger_sequence",
+	"hts.balance",
+	"hts.entry",
+).From("history_trustline_states hts")
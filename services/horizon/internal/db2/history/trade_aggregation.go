@@ -18,10 +18,20 @@ var AllowedResolutions = map[time.Duration]struct{}{
 	time.Minute * 5:    {}, //5 minutes
 	time.Minute * 15:   {}, //15 minutes
 	time.Hour:          {}, //1 hour
+	time.Hour * 4:      {}, //4 hours
 	time.Hour * 24:     {}, //day
 	time.Hour * 24 * 7: {}, //week
+	MonthResolution:    {}, //calendar month
 }
 
+// MonthResolution is the `resolution` used to request calendar month trade
+// aggregations. Month buckets start on the first day of each month, so they
+// span 28 to 31 days. The value is the length of the average Gregorian month,
+// which no other supported or custom resolution is likely to collide with.
+const MonthResolution = 2629746 * time.Second
+
+var monthMillis = int64(MonthResolution / time.Millisecond)
+
 // StrictResolutionFiltering represents a simple feature flag to determine whether only
 // predetermined resolutions of trade aggregations are allowed.
 var StrictResolutionFiltering = true
@@ -30,6 +40,8 @@ var StrictResolutionFiltering = true
 type TradeAggregation struct {
 	Timestamp     int64     `db:"timestamp"`
 	TradeCount    int64     `db:"count"`
+	BuyCount      int64     `db:"buy_count"`
+	SellCount     int64     `db:"sell_count"`
 	BaseVolume    int64     `db:"base_volume"`
 	CounterVolume int64     `db:"counter_volume"`
 	Average       float64   `db:"avg"`
@@ -88,7 +100,7 @@ func (q *TradeAggregationsQ) WithStartTime(startTime strtime.Millis) (*TradeAggr
 	if startTime < offsetMillis {
 		adjustedStartTime = offsetMillis
 	} else {
-		adjustedStartTime = (startTime - offsetMillis).RoundUp(q.resolution) + offsetMillis
+		adjustedStartTime = roundUp(startTime-offsetMillis, q.resolution) + offsetMillis
 	}
 	if !q.endTime.IsNil() && adjustedStartTime > q.endTime {
 		return &TradeAggregationsQ{}, errors.New("start time is not allowed")
@@ -107,7 +119,7 @@ func (q *TradeAggregationsQ) WithEndTime(endTime strtime.Millis) (*TradeAggregat
 	if endTime < offsetMillis {
 		return &TradeAggregationsQ{}, errors.New("end time is not allowed")
 	} else {
-		adjustedEndTime = (endTime - offsetMillis).RoundDown(q.resolution) + offsetMillis
+		adjustedEndTime = roundDown(endTime-offsetMillis, q.resolution) + offsetMillis
 	}
 	if adjustedEndTime < q.startTime {
 		return &TradeAggregationsQ{}, errors.New("end time is not allowed")
@@ -122,6 +134,10 @@ func (q *TradeAggregationsQ) GetSql() sq.SelectBuilder {
 	var orderPreserved bool
 	orderPreserved, q.baseAssetID, q.counterAssetID = getCanonicalAssetOrder(q.baseAssetID, q.counterAssetID)
 
+	if rollup, ok := q.rollupResolution(); ok {
+		return q.rollupSql(rollup, orderPreserved)
+	}
+
 	var bucketSQL sq.SelectBuilder
 	if orderPreserved {
 		bucketSQL = bucketTrades(q.resolution, q.offset)
@@ -144,6 +160,8 @@ func (q *TradeAggregationsQ) GetSql() sq.SelectBuilder {
 	return sq.Select(
 		"timestamp",
 		"count(*) as count",
+		"sum(CASE WHEN is_buy THEN 1 ELSE 0 END) as buy_count",
+		"sum(CASE WHEN is_buy THEN 0 ELSE 1 END) as sell_count",
 		"sum(base_amount) as base_volume",
 		"sum(counter_amount) as counter_volume",
 		"sum(counter_amount)/sum(base_amount) as avg",
@@ -158,12 +176,133 @@ func (q *TradeAggregationsQ) GetSql() sq.SelectBuilder {
 		OrderBy("timestamp " + q.pagingParams.Order)
 }
 
+// rollupResolution returns the coarsest rollup resolution whose buckets line
+// up with the buckets of this query, if any.
+func (q *TradeAggregationsQ) rollupResolution() (int64, bool) {
+	for i := len(TradeRollupResolutions) - 1; i >= 0; i-- {
+		rollup := TradeRollupResolutions[i]
+		if q.offset%rollup != 0 {
+			continue
+		}
+		if q.resolution%rollup == 0 || (q.resolution == monthMillis && dayMillis%rollup == 0) {
+			return rollup, true
+		}
+	}
+	return 0, false
+}
+
+// rollupSql generates a sql statement that aggregates the precomputed buckets
+// of the `history_trade_rollups` table rather than individual trades.
+func (q *TradeAggregationsQ) rollupSql(rollup int64, orderPreserved bool) sq.SelectBuilder {
+	var bucketSQL sq.SelectBuilder
+	if orderPreserved {
+		bucketSQL = sq.Select(
+			formatBucketTimestamp("timestamp", q.resolution, q.offset),
+			"count",
+			"buy_count",
+			"sell_count",
+			"base_volume",
+			"counter_volume",
+			"high",
+			"low",
+			"open",
+			"close",
+		)
+	} else {
+		bucketSQL = sq.Select(
+			formatBucketTimestamp("timestamp", q.resolution, q.offset),
+			"count",
+			"sell_count as buy_count",
+			"buy_count as sell_count",
+			"counter_volume as base_volume",
+			"base_volume as counter_volume",
+			"ARRAY[low[2], low[1]] as high",
+			"ARRAY[high[2], high[1]] as low",
+			"ARRAY[open[2], open[1]] as open",
+			"ARRAY[close[2], close[1]] as close",
+		)
+	}
+
+	bucketSQL = bucketSQL.From("history_trade_rollups").
+		Where(sq.Eq{
+			"resolution":       rollup,
+			"base_asset_id":    q.baseAssetID,
+			"counter_asset_id": q.counterAssetID,
+		}).
+		Where(sq.GtOrEq{"timestamp": q.startTime.ToInt64()})
+	if !q.endTime.IsNil() {
+		bucketSQL = bucketSQL.Where(sq.Lt{"timestamp": q.endTime.ToInt64()})
+	}
+	bucketSQL = bucketSQL.OrderBy("history_trade_rollups.timestamp")
+
+	return sq.Select(
+		"timestamp",
+		"sum(count) as count",
+		"sum(buy_count) as buy_count",
+		"sum(sell_count) as sell_count",
+		"sum(base_volume) as base_volume",
+		"sum(counter_volume) as counter_volume",
+		"sum(counter_volume)/sum(base_volume) as avg",
+		"max_price(high) as high",
+		"min_price(low) as low",
+		"first(open) as open",
+		"last(close) as close",
+	).
+		FromSelect(bucketSQL, "htrr").
+		GroupBy("timestamp").
+		Limit(q.pagingParams.Limit).
+		OrderBy("timestamp " + q.pagingParams.Order)
+}
+
+// BucketEnd returns the end, exclusive, of the trade aggregation bucket of the
+// given resolution starting at timestamp.
+func BucketEnd(timestamp int64, resolution int64) int64 {
+	if resolution == monthMillis {
+		return strtime.MillisFromInt64(timestamp).ToTime().AddDate(0, 1, 0).UnixNano() / int64(time.Millisecond)
+	}
+	return timestamp + resolution
+}
+
+// roundDown rounds t down to a bucket boundary of the given resolution.
+func roundDown(t strtime.Millis, resolution int64) strtime.Millis {
+	if resolution != monthMillis {
+		return t.RoundDown(resolution)
+	}
+	tt := t.ToTime()
+	start := time.Date(tt.Year(), tt.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return strtime.MillisFromInt64(start.UnixNano() / int64(time.Millisecond))
+}
+
+// roundUp rounds t up to a bucket boundary of the given resolution.
+func roundUp(t strtime.Millis, resolution int64) strtime.Millis {
+	if resolution != monthMillis {
+		return t.RoundUp(resolution)
+	}
+	start := roundDown(t, resolution)
+	if start == t {
+		return t
+	}
+	return strtime.MillisFromInt64(BucketEnd(start.ToInt64(), resolution))
+}
+
 // formatBucketTimestampSelect formats a sql select clause for a bucketed timestamp, based on given resolution
 // and the offset. Given a time t, it gives it a timestamp defined by
 // f(t) = ((t - offset)/resolution)*resolution + offset.
 func formatBucketTimestampSelect(resolution int64, offset int64) string {
-	return fmt.Sprintf("div((cast((extract(epoch from ledger_closed_at) * 1000 ) as bigint) - %d), %d)*%d + %d as timestamp",
-		offset, resolution, resolution, offset)
+	return formatBucketTimestamp("cast((extract(epoch from ledger_closed_at) * 1000 ) as bigint)", resolution, offset)
+}
+
+// formatBucketTimestamp formats a sql select clause that buckets the
+// millisecond timestamp `millis`.  Month buckets start at the first day of the
+// calendar month, shifted by the offset.
+func formatBucketTimestamp(millis string, resolution int64, offset int64) string {
+	if resolution == monthMillis {
+		return fmt.Sprintf("cast(extract(epoch from date_trunc('month', "+
+			"to_timestamp((%s - %d) / 1000.0) at time zone 'UTC')) * 1000 as bigint) + %d as timestamp",
+			millis, offset, offset)
+	}
+	return fmt.Sprintf("div((%s - %d), %d)*%d + %d as timestamp",
+		millis, offset, resolution, resolution, offset)
 }
 
 // bucketTrades generates a select statement to filter rows from the `history_trades` table in
@@ -178,6 +317,7 @@ func bucketTrades(resolution int64, offset int64) sq.SelectBuilder {
 		"counter_asset_id",
 		"counter_amount",
 		"ARRAY[price_n, price_d] as price",
+		"base_is_seller as is_buy",
 	)
 }
 
@@ -193,5 +333,6 @@ func reverseBucketTrades(resolution int64, offset int64) sq.SelectBuilder {
 		"base_asset_id as counter_asset_id",
 		"base_amount as counter_amount",
 		"ARRAY[price_d, price_n] as price",
+		"NOT base_is_seller as is_buy",
 	)
 }
@@ -0,0 +1,109 @@
+package history
+
+import (
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lomocoin/stellar-go/support/errors"
+	strtime "github.com/lomocoin/stellar-go/support/time"
+)
+
+var (
+	hourMillis = int64(time.Hour / time.Millisecond)
+	dayMillis  = int64(24 * time.Hour / time.Millisecond)
+)
+
+// TradeRollupResolutions are the resolutions, in milliseconds and from finest
+// to coarsest, at which trades are rolled up into the `history_trade_rollups`
+// table.  Trade aggregations whose buckets line up with a rollup resolution are
+// computed from the rollups rather than from the individual trades.
+var TradeRollupResolutions = []int64{hourMillis, dayMillis}
+
+// TradeRollupBucket identifies a single row of the `history_trade_rollups`
+// table.
+type TradeRollupBucket struct {
+	Resolution     int64 `db:"resolution"`
+	BaseAssetID    int64 `db:"base_asset_id"`
+	CounterAssetID int64 `db:"counter_asset_id"`
+	Timestamp      int64 `db:"timestamp"`
+}
+
+// TradeRollupBuckets loads the rollup buckets, at every rollup resolution,
+// that contain a trade from an operation in the range [start, end).
+func (q *Q) TradeRollupBuckets(dest *[]TradeRollupBucket, start int64, end int64) error {
+	for _, resolution := range TradeRollupResolutions {
+		var buckets []TradeRollupBucket
+		sql := sq.Select(
+			fmt.Sprintf("%d as resolution", resolution),
+			"base_asset_id",
+			"counter_asset_id",
+			formatBucketTimestampSelect(resolution, 0),
+		).
+			Distinct().
+			From("history_trades").
+			Where("history_operation_id >= ? AND history_operation_id < ?", start, end)
+
+		err := q.Select(&buckets, sql)
+		if err != nil {
+			return errors.Wrap(err, "failed to load trade rollup buckets")
+		}
+		*dest = append(*dest, buckets...)
+	}
+	return nil
+}
+
+// RebuildTradeRollup recalculates a rollup bucket from the trades it contains,
+// removing the bucket once it no longer contains any.
+func (q *Q) RebuildTradeRollup(bucket TradeRollupBucket) error {
+	_, err := q.Exec(sq.Delete("history_trade_rollups").Where(sq.Eq{
+		"resolution":       bucket.Resolution,
+		"base_asset_id":    bucket.BaseAssetID,
+		"counter_asset_id": bucket.CounterAssetID,
+		"timestamp":        bucket.Timestamp,
+	}))
+	if err != nil {
+		return errors.Wrap(err, "failed to delete trade rollup")
+	}
+
+	start := strtime.MillisFromInt64(bucket.Timestamp)
+	end := strtime.MillisFromInt64(bucket.Timestamp + bucket.Resolution)
+	_, err = q.ExecRaw(rebuildTradeRollupSQL,
+		bucket.Resolution, bucket.BaseAssetID, bucket.CounterAssetID, bucket.Timestamp,
+		bucket.BaseAssetID, bucket.CounterAssetID, start.ToTime(), end.ToTime(),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert trade rollup")
+	}
+	return nil
+}
+
+// rebuildTradeRollupSQL aggregates the trades of a single bucket into a row of
+// the `history_trade_rollups` table.  A trade counts as a buy when the taker
+// bought the base asset, i.e. the offer it crossed sold the base asset.
+const rebuildTradeRollupSQL = `
+INSERT INTO history_trade_rollups (
+	resolution, base_asset_id, counter_asset_id, timestamp,
+	count, buy_count, sell_count, base_volume, counter_volume,
+	high, low, open, close
+)
+SELECT
+	?, ?, ?, ?,
+	count(*),
+	sum(CASE WHEN base_is_seller THEN 1 ELSE 0 END),
+	sum(CASE WHEN base_is_seller THEN 0 ELSE 1 END),
+	sum(base_amount),
+	sum(counter_amount),
+	max_price(price),
+	min_price(price),
+	first(price),
+	last(price)
+FROM (
+	SELECT base_is_seller, base_amount, counter_amount, ARRAY[price_n, price_d] as price
+	FROM history_trades
+	WHERE base_asset_id = ? AND counter_asset_id = ?
+	AND ledger_closed_at >= ? AND ledger_closed_at < ?
+	ORDER BY history_operation_id, "order"
+) htrd
+HAVING count(*) > 0
+`
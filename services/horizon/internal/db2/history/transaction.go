@@ -72,6 +72,13 @@ func (q *TransactionsQ) ForLedger(seq int32) *TransactionsQ {
 	return q
 }
 
+// ForMemo filters the query to only transactions with the memo `memo`.  An
+// empty `memoType` matches memos of any type.
+func (q *TransactionsQ) ForMemo(memoType, memo string) *TransactionsQ {
+	q.sql = q.sql.Where(memoFilter(memoType, memo))
+	return q
+}
+
 // Page specifies the paging constraints for the query being built by `q`.
 func (q *TransactionsQ) Page(page db2.PageQuery) *TransactionsQ {
 	if q.Err != nil {
@@ -115,3 +122,12 @@ var selectTransaction = sq.Select(
 		"hl.closed_at AS ledger_close_time").
 	From("history_transactions ht").
 	LeftJoin("history_ledgers hl ON ht.ledger_sequence = hl.sequence")
+
+// memoFilter returns a clause matching the transactions with the memo `memo`
+// of type `memoType`, which is able to use the `htx_by_memo` index.
+func memoFilter(memoType, memo string) sq.Sqlizer {
+	if memoType == "" {
+		return sq.Eq{"ht.memo": memo}
+	}
+	return sq.Eq{"ht.memo": memo, "ht.memo_type": memoType}
+}
@@ -0,0 +1,247 @@
+package history
+
+import (
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
+)
+
+// AccountList returns the accounts of the webhook.
+func (r *Webhook) AccountList() []string {
+	return splitList(r.Accounts)
+}
+
+// EventTypeList returns the event types of the webhook, empty when every type
+// of event is delivered.
+func (r *Webhook) EventTypeList() []string {
+	return splitList(r.EventTypes)
+}
+
+// InsertWebhook inserts `webhook` into the `webhooks` table, setting its ID
+// and CreatedAt fields.
+func (q *Q) InsertWebhook(webhook *Webhook) error {
+	sql := sq.Insert("webhooks").
+		Columns(
+			"owner",
+			"url",
+			"secret",
+			"accounts",
+			"event_types",
+			"asset_type",
+			"asset_code",
+			"asset_issuer",
+			"last_ledger",
+		).
+		Values(
+			webhook.Owner,
+			webhook.URL,
+			webhook.Secret,
+			webhook.Accounts,
+			webhook.EventTypes,
+			webhook.AssetType,
+			webhook.AssetCode,
+			webhook.AssetIssuer,
+			webhook.LastLedger,
+		).
+		Suffix("RETURNING id, created_at")
+
+	return q.Get(webhook, sql)
+}
+
+// WebhookByID loads the webhook `id` registered by `owner` into `dest`.
+func (q *Q) WebhookByID(dest interface{}, owner string, id int64) error {
+	sql := selectWebhook.Limit(1).Where("wh.owner = ? AND wh.id = ?", owner, id)
+	return q.Get(dest, sql)
+}
+
+// WebhooksByOwner loads a page of the webhooks registered by `owner` into
+// `dest`.
+func (q *Q) WebhooksByOwner(dest interface{}, owner string, page db2.PageQuery) error {
+	sql, err := page.ApplyTo(selectWebhook.Where("wh.owner = ?", owner), "wh.id")
+	if err != nil {
+		return err
+	}
+
+	return q.Select(dest, sql)
+}
+
+// DeleteWebhook removes the webhook `id` registered by `owner`, along with
+// its pending deliveries and dead letters.  It returns the number of webhooks
+// removed.
+func (q *Q) DeleteWebhook(owner string, id int64) (int64, error) {
+	result, err := q.Exec(sq.Delete("webhooks").Where("owner = ? AND id = ?", owner, id))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// WebhooksBehind locks and loads into `dest` the webhooks whose events are
+// dispatched up to the ledger preceding `seq`, such that ledgers are dispatched
+// to each webhook in order even when concurrent dispatchers skip each other's
+// webhooks.  The webhooks further behind are only loaded when `seq` is
+// `elder`, the oldest ledger left in history, from which they resume.  It is
+// meant to be run within a transaction.
+func (q *Q) WebhooksBehind(dest interface{}, seq int32, elder int32) error {
+	sql := selectWebhook.OrderBy("wh.id").Suffix("FOR UPDATE SKIP LOCKED")
+	if seq <= elder {
+		sql = sql.Where("wh.last_ledger < ?", seq)
+	} else {
+		sql = sql.Where("wh.last_ledger = ?", seq-1)
+	}
+
+	return q.Select(dest, sql)
+}
+
+// OldestWebhookLedger loads into `dest` the latest ledger whose events were
+// dispatched to every webhook, or null if there are no webhooks.
+func (q *Q) OldestWebhookLedger(dest interface{}) error {
+	return q.GetRaw(dest, `SELECT MIN(last_ledger) FROM webhooks`)
+}
+
+// SetWebhooksLedger records that the events of ledger `seq` were dispatched
+// to the webhooks `ids`.
+func (q *Q) SetWebhooksLedger(ids []int64, seq int32) error {
+	_, err := q.Exec(sq.Update("webhooks").
+		Set("last_ledger", seq).
+		Where(sq.Eq{"id": ids}))
+	return err
+}
+
+// InsertWebhookDelivery queues the event `eventID` for delivery to the
+// webhook `webhookID`, unless it already was.
+func (q *Q) InsertWebhookDelivery(webhookID int64, eventID, eventType, payload string) error {
+	_, err := q.Exec(sq.Insert("webhook_deliveries").
+		Columns("webhook_id", "event_id", "event_type", "payload").
+		Values(webhookID, eventID, eventType, payload).
+		Suffix("ON CONFLICT (webhook_id, event_id) DO NOTHING"))
+	return err
+}
+
+// ClaimWebhookDeliveries loads into `dest` up to `limit` deliveries that are
+// due, along with the url and secret of their webhook, postponing their next
+// attempt by `lease` such that no other horizon instance attempts them
+// meanwhile.
+func (q *Q) ClaimWebhookDeliveries(dest interface{}, limit uint64, lease time.Duration) error {
+	return q.SelectRaw(dest, `
+		WITH claimed AS (
+			UPDATE webhook_deliveries SET next_attempt_at = ?
+			WHERE id IN (
+				SELECT id FROM webhook_deliveries
+				WHERE next_attempt_at <= ?
+				ORDER BY next_attempt_at
+				LIMIT ?
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT claimed.*, wh.url, wh.secret
+		FROM claimed
+		JOIN webhooks wh ON wh.id = claimed.webhook_id`,
+		time.Now().UTC().Add(lease),
+		time.Now().UTC(),
+		limit,
+	)
+}
+
+// DeleteWebhookDelivery removes the delivery `id`, once delivered.
+func (q *Q) DeleteWebhookDelivery(id int64) error {
+	_, err := q.Exec(sq.Delete("webhook_deliveries").Where("id = ?", id))
+	return err
+}
+
+// RetryWebhookDelivery records the failed attempt of the delivery `id`,
+// scheduling the next one at `next`.
+func (q *Q) RetryWebhookDelivery(id int64, lastError string, next time.Time) error {
+	_, err := q.Exec(sq.Update("webhook_deliveries").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("last_error", lastError).
+		Set("next_attempt_at", next.UTC()).
+		Where("id = ?", id))
+	return err
+}
+
+// DeadLetterWebhookDelivery moves the delivery `id`, whose last attempt failed
+// with `lastError`, to the `webhook_dead_letters` table.
+func (q *Q) DeadLetterWebhookDelivery(id int64, lastError string) error {
+	_, err := q.ExecRaw(`
+		WITH failed AS (
+			DELETE FROM webhook_deliveries WHERE id = ? RETURNING *
+		)
+		INSERT INTO webhook_dead_letters
+			(id, webhook_id, event_id, event_type, payload, attempts, last_error)
+		SELECT id, webhook_id, event_id, event_type, payload, attempts + 1, ?
+		FROM failed`,
+		id,
+		lastError,
+	)
+	return err
+}
+
+// WebhookDeadLetters loads a page of the dead letters of the webhook
+// `webhookID` into `dest`.
+func (q *Q) WebhookDeadLetters(dest interface{}, webhookID int64, page db2.PageQuery) error {
+	sql, err := page.ApplyTo(
+		sq.Select("wdl.*").
+			From("webhook_dead_letters wdl").
+			Where("wdl.webhook_id = ?", webhookID),
+		"wdl.id",
+	)
+	if err != nil {
+		return err
+	}
+
+	return q.Select(dest, sql)
+}
+
+// ReplayWebhookDeadLetters moves the dead letters of the webhook `webhookID`
+// back to the `webhook_deliveries` table, to be attempted anew.  A delivery of
+// the same event still queued is attempted anew in their place.  Only the
+// dead letter `id` is replayed when it is not 0.  It returns the number of
+// dead letters replayed.
+func (q *Q) ReplayWebhookDeadLetters(webhookID, id int64) (int64, error) {
+	where := "webhook_id = ?"
+	args := []interface{}{webhookID}
+	if id != 0 {
+		where += " AND id = ?"
+		args = append(args, id)
+	}
+
+	result, err := q.ExecRaw(`
+		WITH replayed AS (
+			DELETE FROM webhook_dead_letters WHERE `+where+` RETURNING *
+		)
+		INSERT INTO webhook_deliveries
+			(id, webhook_id, event_id, event_type, payload)
+		SELECT id, webhook_id, event_id, event_type, payload
+		FROM replayed
+		ON CONFLICT (webhook_id, event_id) DO UPDATE SET
+			attempts = 0,
+			last_error = '',
+			next_attempt_at = timezone('utc', now())`,
+		args...,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+var selectWebhook = sq.Select("wh.*").From("webhooks wh")
+
+// splitList splits a comma separated list, ignoring empty elements.
+func splitList(list string) []string {
+	var result []string
+	for _, element := range strings.Split(list, ",") {
+		element = strings.TrimSpace(element)
+		if element != "" {
+			result = append(result, element)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,127 @@
+// Package markets provides the query summarizing the recent trading activity
+// of every traded asset pair.
+package markets
+
+import (
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
+	"github.com/lomocoin/stellar-go/support/errors"
+	"github.com/lomocoin/stellar-go/xdr"
+)
+
+const (
+	// SortByPair sorts markets by the ids of their base and counter assets.
+	SortByPair = "pair"
+	// SortByTradeCount sorts markets by their number of trades.
+	SortByTradeCount = "trade_count"
+	// SortByBaseVolume sorts markets by the traded volume of their base asset.
+	SortByBaseVolume = "base_volume"
+	// SortByCounterVolume sorts markets by the traded volume of their counter
+	// asset.
+	SortByCounterVolume = "counter_volume"
+)
+
+// pairKey orders markets by base then counter asset id.  Every sort key is a
+// zero padded string ending with it, so that markets with equal statistics
+// still page consistently.
+const pairKey = "lpad(mkt.base_asset_id::text, 19, '0') || '_' || lpad(mkt.counter_asset_id::text, 19, '0')"
+
+var sortKeys = map[string]string{
+	SortByPair:          pairKey,
+	SortByTradeCount:    fmt.Sprintf("lpad(mkt.trade_count::text, 19, '0') || '_' || %s", pairKey),
+	SortByBaseVolume:    fmt.Sprintf("lpad(mkt.base_volume::text, 25, '0') || '_' || %s", pairKey),
+	SortByCounterVolume: fmt.Sprintf("lpad(mkt.counter_volume::text, 25, '0') || '_' || %s", pairKey),
+}
+
+// MarketR is the result from the MarketsQ query: the trading activity of an
+// asset pair, in the canonical base/counter order of the `history_trades`
+// table.  Prices are expressed in units of the counter asset per unit of the
+// base asset.
+type MarketR struct {
+	SortKey            string    `db:"sort_key"`
+	BaseAssetType      string    `db:"base_asset_type"`
+	BaseAssetCode      string    `db:"base_asset_code"`
+	BaseAssetIssuer    string    `db:"base_asset_issuer"`
+	CounterAssetType   string    `db:"counter_asset_type"`
+	CounterAssetCode   string    `db:"counter_asset_code"`
+	CounterAssetIssuer string    `db:"counter_asset_issuer"`
+	TradeCount         int64     `db:"trade_count"`
+	BaseVolume         int64     `db:"base_volume"`
+	CounterVolume      int64     `db:"counter_volume"`
+	Open               xdr.Price `db:"open"`
+	High               xdr.Price `db:"high"`
+	Low                xdr.Price `db:"low"`
+	Close              xdr.Price `db:"close"`
+}
+
+// MarketsQ is the query to fetch the asset pairs traded since a given time
+type MarketsQ struct {
+	Since     time.Time
+	Sort      string
+	PageQuery db2.PageQuery
+}
+
+// GetSQL allows this query to be executed by the caller
+func (q MarketsQ) GetSQL() (sq.SelectBuilder, error) {
+	sortKey, ok := sortKeys[q.Sort]
+	if !ok {
+		return sq.SelectBuilder{}, errors.Errorf("invalid sort: %s", q.Sort)
+	}
+
+	// ordered so that first and last find the open and close prices
+	trades := sq.Select(
+		"base_asset_id",
+		"counter_asset_id",
+		"base_amount",
+		"counter_amount",
+		"ARRAY[price_n, price_d] as price",
+	).
+		From("history_trades").
+		Where(sq.GtOrEq{"ledger_closed_at": q.Since}).
+		OrderBy("history_operation_id", "\"order\"")
+
+	pairs := sq.Select(
+		"base_asset_id",
+		"counter_asset_id",
+		"count(*) as trade_count",
+		"sum(base_amount) as base_volume",
+		"sum(counter_amount) as counter_volume",
+		"first(price) as open",
+		"max_price(price) as high",
+		"min_price(price) as low",
+		"last(price) as close",
+	).
+		FromSelect(trades, "htrd").
+		GroupBy("base_asset_id", "counter_asset_id")
+
+	markets := sq.Select(
+		sortKey+" as sort_key",
+		"base_assets.asset_type as base_asset_type",
+		"base_assets.asset_code as base_asset_code",
+		"base_assets.asset_issuer as base_asset_issuer",
+		"counter_assets.asset_type as counter_asset_type",
+		"counter_assets.asset_code as counter_asset_code",
+		"counter_assets.asset_issuer as counter_asset_issuer",
+		"mkt.trade_count",
+		"mkt.base_volume",
+		"mkt.counter_volume",
+		"mkt.open",
+		"mkt.high",
+		"mkt.low",
+		"mkt.close",
+	).
+		FromSelect(pairs, "mkt").
+		Join("history_assets base_assets ON base_assets.id = mkt.base_asset_id").
+		Join("history_assets counter_assets ON counter_assets.id = mkt.counter_asset_id")
+
+	// sort keys start with a digit, so "z" is greater than any of them
+	cursor := q.PageQuery.Cursor
+	if q.PageQuery.Order == db2.OrderDescending && cursor == "" {
+		cursor = "z"
+	}
+
+	return q.PageQuery.ApplyToUsingCursor(sq.Select("*").FromSelect(markets, "markets"), "sort_key", cursor)
+}
@@ -16,6 +16,9 @@ const (
 	DefaultPageSize = 10
 	// MaxPageSize is the max page size for db queries
 	MaxPageSize = 200
+	// MaxExportSize is the max number of records of an export, loaded in
+	// pages of at most MaxPageSize records
+	MaxExportSize = 10000
 
 	// OrderAscending is used to indicate an ascending order in request params
 	OrderAscending = "asc"
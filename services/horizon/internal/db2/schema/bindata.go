@@ -6,7 +6,16 @@
 // migrations/12_asset_stats_amount_string.sql
 // migrations/13_trade_offer_ids.sql
 // migrations/14_fix_asset_toml_field.sql
+// migrations/15_add_state_history.sql
+// migrations/16_add_offer_history.sql
+// migrations/17_add_ledger_history_filtered.sql
+// migrations/18_add_trade_rollups.sql
+// migrations/19_add_asset_stats_details.sql
 // migrations/1_initial_schema.sql
+// migrations/20_add_search_indexes.sql
+// migrations/21_add_memo_index.sql
+// migrations/22_add_api_keys.sql
+// migrations/23_add_webhooks.sql
 // migrations/2_index_participants_by_toid.sql
 // migrations/3_use_sequence_in_history_accounts.sql
 // migrations/4_add_protocol_version.sql
@@ -203,6 +212,106 @@ func migrations14_fix_asset_toml_fieldSql() (*asset, error) {
 	return a, nil
 }
 
+var _migrations15_add_state_historySql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\xbd\x93\x41\x4f\x83\x40\x10\x85\xef\xfb\x2b\xe6\x48\x23\x1c\x34\xda\x4b\x4f\xb4\x6c\x90\x84\x2c\x06\x17\xd3\x1b\x59\x96\x49\x4b\x82\x50\x77\x97\x18\xfe\xbd\x28\x29\xad\x05\xaa\xf1\xe0\x1e\x67\xbf\xcc\xbc\x79\x2f\xe3\x38\x70\xf3\x5a\xec\x94\x30\x08\xc9\x81\x90\x4d\x4c\x5d\x4e\x81\xbb\xeb\x90\xc2\xbe\xd0\xa6\x56\x6d\x2a\xa4\xac\x9b\xca\xa4\xda\x74\x98\x06\x8b\x40\xf7\x8e\xc5\x22\x87\x17\x37\xde\x3c\xba\xb1\xb5\xbc\x5f\x00\x8b\x38\xb0\x24\x0c\xed\x2f\xa8\xc4\x7c\x87\x2a\xd5\xf8\xd6\x60\x25\x11\x02\xc6\xa9\x4f\xe3\x0b\x2a\x13\xa5\xf8\xfc\x5d\x07\x7e\x07\xf4\x35\xac\x8c\x6a\x81\xd3\x2d\x27\x8b\xd5\xa0\x2b\x60\x1e\xdd\xc2\x5e\xe8\x34\x1b\x64\x41\xc4\xe6\x94\x26\xcf\x01\xf3\x21\x33\x0a\x11\xac\x93\x60\xfb\x52\x57\x37\x61\x6a\x40\x4f\xfd\xb6\xff\xb8\xe7\xb4\x9b\x46\x35\xda\x94\x45\x85\x7f\xf0\x53\x68\x8d\x26\x35\xed\x61\xce\xca\x1e\x90\x75\x8e\x43\x97\xdb\xbb\xe9\x2e\x85\xd6\x4d\xb7\xdd\x11\x7b\x58\xfe\x53\x78\x66\x2e\xbc\x91\x31\xb3\xf1\x9d\x6c\xb0\xcf\x36\xb6\xbf\x2d\xf6\x73\xc8\x66\x26\xe4\xeb\x3a\x26\x62\x76\xce\x8e\xc8\xab\xdf\x2b\x42\xbc\x38\x7a\xba\x7e\x44\x52\x68\x29\x72\x5c\x4d\xa1\x23\x01\x03\xfc\x01\xa7\x5c\xdc\x4d\xaf\x03\x00\x00")
+
+func migrations15_add_state_historySqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations15_add_state_historySql,
+		"migrations/15_add_state_history.sql",
+	)
+}
+
+func migrations15_add_state_historySql() (*asset, error) {
+	bytes, err := migrations15_add_state_historySqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/15_add_state_history.sql", size: 943, mode: os.FileMode(420), modTime: time.Unix(1792334700, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations16_add_offer_historySql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\x8d\x93\x41\x6f\x82\x30\x18\x86\xef\xfd\x15\xdf\x11\x32\x3d\x6c\xd9\xbc\x78\x42\x69\x5c\x13\x06\x0e\xc5\xec\x46\x10\x3e\xb0\x09\xb6\x8c\x96\x2d\xfe\xfb\x21\x6e\x44\x5d\x31\x92\x70\xe1\x7b\x78\xde\x2f\xcd\xdb\xf1\x18\x1e\xf6\xbc\xa8\x13\x8d\x10\x55\x84\xcc\x43\xea\xac\x29\xac\x9d\x99\x47\x61\xc7\x95\x96\xf5\x21\x96\x79\x8e\x75\x8c\x5f\x28\xb4\x02\x8b\x40\xfb\x9c\x3e\xf1\x0c\x66\x6c\xc1\xfc\x35\xf8\x41\xfb\x46\x9e\x37\xea\xa6\xfd\x8f\x15\xb6\x66\x2e\xc5\x20\x59\x62\x56\xb4\x22\x85\x9f\x0d\x8a\x14\xa1\x25\xe8\x82\x86\x57\x94\x3e\x54\x08\xab\x37\xc7\xf3\xfe\x1b\x14\x96\xe5\x69\x95\x8d\x13\xce\x5f\x9d\xd0\x9a\x3c\xdb\x06\x86\x8b\x22\x4e\x94\x42\x1d\x77\x36\x73\xd0\x25\x98\xca\x0c\x7b\xeb\xe3\x93\x6d\x62\xb8\x52\x0d\xd6\x3d\xf5\x32\xf9\xa5\xb6\xcd\xe1\xae\xc4\x0b\xce\x1c\x78\x81\x0c\xe5\x25\x7b\xd9\x08\x6d\x3e\xe4\xaa\xe6\x29\x8a\x81\x05\xba\x61\x76\x6b\x08\x6e\x10\x1d\xeb\xb0\x0c\xe9\x9c\xad\x58\xe0\x5f\x41\x79\x99\x14\x6a\x40\x10\xf9\xec\x3d\xa2\xd6\x5f\x5d\x46\xc6\x6a\xd8\xc4\x9e\xf6\xd5\x63\xbe\x4b\x3f\x60\x27\x31\xde\x9e\x51\xd0\xa6\x1a\xeb\x18\xad\x98\xbf\x80\xad\xae\x11\xc1\x32\xca\xa7\x46\xf3\xa9\x36\xf7\x69\xfb\x8a\x8d\xfa\xde\x1f\x17\x1e\x9f\xdd\x1d\x57\x7e\x0b\x42\xdc\x30\x58\xde\xba\x3b\x69\xa2\xd2\x24\xc3\x29\xf9\x01\xda\x66\x96\xc0\x77\x03\x00\x00")
+
+func migrations16_add_offer_historySqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations16_add_offer_historySql,
+		"migrations/16_add_offer_history.sql",
+	)
+}
+
+func migrations16_add_offer_historySql() (*asset, error) {
+	bytes, err := migrations16_add_offer_historySqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/16_add_offer_history.sql", size: 887, mode: os.FileMode(420), modTime: time.Unix(1792334956, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations17_add_ledger_history_filteredSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\x7d\xcf\xcd\x0a\xc2\x30\x10\x04\xe0\x7b\x9e\x62\x8f\x8a\xf4\x09\x7a\x4a\x9b\x55\x0b\x31\x91\x98\xa0\xb7\xd2\xd2\xed\x0f\x44\x2b\x69\x40\xfa\xf6\x82\x07\x11\x2a\xbd\x0e\xcc\x7c\x4c\x92\xc0\xee\x3e\x74\xa1\x8a\x04\xee\xc9\x18\x97\x16\x0d\x58\x9e\x49\x84\x7e\x98\xe2\x18\xe6\xd2\x53\xd3\x51\x98\x80\x0b\xf1\xcd\xda\xc1\x47\x0a\xd4\x40\xa6\xb5\x44\xae\x40\xe0\x9e\x3b\x69\xa1\xad\xfc\x44\xa0\xb4\x05\xe5\xa4\x4c\x59\x6e\x90\x5b\x84\x42\x09\xbc\x41\xef\xcb\x7a\x2e\x17\x1b\x5a\x2d\x2c\x77\x29\xd4\x01\xea\x18\x88\x60\x33\x34\x5b\xb8\x1e\xd1\xe0\x82\x4f\x19\x4b\x7e\x1e\x88\xf1\xf5\x60\x4c\x18\x7d\x5e\x05\xd3\xd5\x9b\x9f\x7a\xae\xa5\x3b\xa9\x3f\xde\x1b\x11\x58\x10\x2b\x31\x01\x00\x00")
+
+func migrations17_add_ledger_history_filteredSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations17_add_ledger_history_filteredSql,
+		"migrations/17_add_ledger_history_filtered.sql",
+	)
+}
+
+func migrations17_add_ledger_history_filteredSql() (*asset, error) {
+	bytes, err := migrations17_add_ledger_history_filteredSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/17_add_ledger_history_filtered.sql", size: 305, mode: os.FileMode(420), modTime: time.Unix(1792337379, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations18_add_trade_rollupsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\xa5\x54\x5d\x6f\xda\x30\x14\x7d\xf7\xaf\xb8\xe2\xc9\x61\x50\x81\x56\x55\x53\xfb\x14\xc0\xed\xd8\x20\x41\x0e\x6c\x42\x55\x15\xa5\x89\x4b\x2c\x25\x71\x64\x3b\x2d\xfc\xfb\x3a\x4e\x61\x81\x31\xb4\x69\x96\x12\xd9\xe7\x7e\xfa\xde\xe3\xdb\xef\xc3\xa7\x9c\x6f\x64\xa4\x19\xac\x4a\x84\xc6\x94\xb8\x4b\x02\x4b\x77\x34\x23\x90\x72\xa5\x85\xdc\x85\x5a\x46\x09\x0b\xa5\xc8\xb2\xaa\x54\x80\x11\x98\x25\x99\x12\x59\xa5\xb9\x28\x60\x34\x7d\x98\x7a\x4b\xf0\x7c\xf3\xad\x66\xb3\x9e\x95\x3f\x47\x8a\x85\x91\x52\x4c\x87\x3c\x39\x55\x01\x4a\xee\x09\x25\xde\x98\x04\x87\x20\x56\x57\x61\x9e\x38\x8d\x83\x58\x54\x85\x66\xf2\xbf\x7c\x74\x34\xcf\x99\xd2\x51\x5e\x76\xce\x67\x69\x83\xfc\xe1\x02\xd5\x2e\xbc\x20\x56\x2c\xcb\x2e\xc9\xed\xfd\x5f\x4d\x89\x72\x76\x21\xb4\xb9\xdf\x25\x9d\x94\x6f\x52\x73\x9c\x13\x3a\x1d\x3f\x3e\x35\x58\x26\xde\x4e\x21\x51\xb2\xe2\x14\x8b\x33\xa1\xd8\x29\xb8\xa0\xd3\xb9\x4b\xd7\xf0\x9d\xac\x01\xff\xea\x60\xef\xb8\x5b\xbd\xdf\x6a\xdf\x6b\x57\xd2\x41\xce\x1d\x42\xfd\x3e\x94\xa2\xac\xb2\x9a\x38\x3a\x65\x90\x8a\x4a\x66\x3b\x88\x8a\x04\x92\x88\x9b\xdd\x9e\x2e\x2f\x52\xe4\x56\x83\x6d\x4d\x97\x78\xb1\x01\x4b\x27\x85\xa6\x5e\x40\xe8\x12\xcc\xad\xfd\xf3\x4c\x43\x01\x99\x91\xf1\xf2\x84\x6e\x67\xe8\x75\x9e\x30\x0d\x9a\xf0\x57\x1c\x47\x4a\x63\xcc\xb6\xc6\x7b\xac\x31\x2b\x45\x9c\x36\x69\x65\x2c\xd9\x18\x0b\x5b\xab\x24\x8c\xb4\x03\x5d\x18\x0e\x06\x03\x07\x22\x05\xcf\x7c\xc3\x0b\xed\xf4\x5a\xb1\x6b\x79\x8b\xf8\x46\xa9\x55\x97\x56\x16\xb8\xfb\x41\x40\x55\xe5\x78\xec\x06\x04\x7e\x7e\x25\x5e\x93\x34\x57\x61\xcd\x1d\x26\x61\x59\x63\x43\x20\x33\x23\x1f\x00\xf1\x26\x7f\x6d\x34\x68\x8c\x86\x27\x46\x4d\x51\xf2\x3a\x83\x16\x7a\xa8\x4b\x5b\x90\x47\xdb\xb0\x94\x3c\x66\xd8\xfe\xf7\x28\x2f\xce\xa0\x2f\x5c\x9a\xfa\xb5\x91\x2c\x3a\x00\xe8\x9e\xfa\xf3\x8f\x99\xd0\xf4\x0b\xe4\x55\x9b\x5a\xa9\x96\xc9\x55\xb7\x07\x2e\xa5\xee\xfa\xd1\x9e\xac\x65\xb8\x97\x35\xa7\xe4\xa9\x2e\xa7\xdd\x5b\x5f\xd6\xed\x11\x2d\x94\x55\xb7\xc2\x31\xf5\x83\x00\xbe\xf9\x53\x0f\xf0\x0f\x77\xb6\x32\x23\x00\x7f\xbe\x19\xd4\xeb\xf6\xf6\xd0\x37\xfc\xe5\xe6\xfa\x18\x73\xc0\x0d\x40\xb6\x98\xef\x58\x77\x3e\x9d\x10\x0a\xa3\x75\x93\xcf\x3e\xa8\x79\x55\x66\x2c\x1a\x25\xfb\x00\xac\xa8\x23\x64\xc2\x64\x07\x39\x4d\x2a\x0f\xd4\x5f\x2d\x6a\xbb\x7f\x7a\x4a\xd7\xcd\xeb\x39\x8c\xdd\x89\x78\x2b\x10\x9a\x50\x7f\x71\x71\xec\x1a\x12\xc7\xe6\x7c\x87\xde\x01\x36\x7d\x87\xa2\xb3\x05\x00\x00")
+
+func migrations18_add_trade_rollupsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations18_add_trade_rollupsSql,
+		"migrations/18_add_trade_rollups.sql",
+	)
+}
+
+func migrations18_add_trade_rollupsSql() (*asset, error) {
+	bytes, err := migrations18_add_trade_rollupsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/18_add_trade_rollups.sql", size: 1459, mode: os.FileMode(420), modTime: time.Unix(1792338596, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations19_add_asset_stats_detailsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\xcd\x56\x4d\x73\x9b\x30\x10\xbd\xf3\x2b\xf6\x66\xbb\xc5\x9e\xa4\xc7\xa6\xcd\x0c\x01\x25\xa5\x75\x70\x06\xe3\x69\x72\x62\x64\x90\x03\x33\x18\x51\x24\x92\x3a\xbf\xbe\xfa\x32\xfe\xc0\x76\x92\x4e\x0f\xf5\x09\x4b\xfb\xde\xd3\xee\xdb\x15\x0c\x87\xf0\x71\x99\x3f\xd6\x98\x13\x98\x55\x96\xe5\x8c\x23\x14\x42\xe4\x5c\x8d\x11\x60\xc6\x08\x8f\x19\xc7\x9c\x59\x20\x7e\x8e\xe7\x81\x3b\x19\xcf\x6e\x03\x28\x9b\x65\xdc\x94\xb8\xe1\x19\xad\xf3\x17\x92\xc6\x38\x49\x68\x53\x72\x06\x7e\x10\xa1\x1b\x41\x11\x4c\x22\x08\x66\xe3\x31\x78\xe8\xda\x99\x8d\x23\x38\xb3\xf7\x49\x76\x09\x96\x12\x0f\x49\x86\x6b\x9c\x70\x52\xc3\x13\xae\x57\x79\xf9\xd8\x25\xea\x9d\xf5\x3a\x54\x1a\x1d\xe7\x65\x4c\x17\x0b\x52\xb3\xbf\xe5\x91\x79\x65\xb4\x48\x05\x45\x8c\xe7\xf4\x89\xc4\x3c\xab\x09\x93\x4b\x27\x32\xbb\xb0\x2c\x37\x44\x4e\x84\xba\x85\x8b\x53\x9c\x17\x2b\xe8\x2b\xa1\x3c\x85\x2b\xff\x46\xf0\x6c\x38\x42\x74\x8d\x42\x14\xb8\x68\x0a\x59\xce\x38\xad\x57\xb1\x42\x33\x98\x04\x42\x60\x8c\x04\xa9\xeb\x4c\x5d\xc7\x43\x72\x65\x76\xe7\x49\x99\x10\x4d\xa3\xd0\x77\x23\x7d\xfe\x14\xaf\x40\x2d\xaf\x59\xf5\xf2\xab\x15\xd5\x61\x32\xe5\xa3\xee\x6d\x42\xde\xe6\xb6\x8e\x7f\x9f\xb1\xdb\xa7\x7d\x93\x83\x9b\x43\xbd\xd5\x2a\x8d\xb8\x0b\xfd\x5b\x27\x7c\x80\x1f\xe8\x01\xfa\x79\x6a\xcb\xc2\x0d\xac\xc1\xbe\x7b\x3b\x3e\xc4\x15\x5e\x2d\x89\x4c\x55\x5b\xb8\xde\xa4\x15\x11\x33\x93\xd3\x32\x3e\x60\xea\x96\x90\x49\x4e\x51\xbd\xc7\x7e\x71\xbe\xc1\x8e\x8d\x7b\x48\xbd\x57\x90\xf4\x91\xd4\x71\x52\x50\x26\x2b\xcd\x81\xe7\x4b\x22\xfa\x6e\x59\xc1\x73\x2e\x1c\x68\xf4\x0a\xbc\xd0\x92\xb4\xd0\xed\x8c\xfd\xc0\x43\xf7\x90\xe1\x2a\x9e\x1b\x65\xd9\x65\x47\x2a\x30\x9b\xfa\xc1\x0d\xcc\x79\x4d\x08\xf4\xd7\x29\xd9\x9d\x43\x48\xfa\xe1\x10\x2a\x5a\x35\x85\xbc\x56\x78\x46\xa0\xe5\x58\xd4\x74\xa9\x56\xc8\x6f\x21\x22\x5d\x35\x5b\xd0\x3f\x1f\x00\x2e\x53\xf1\x9f\x67\x9b\xc5\x4f\x03\xc9\xd5\x56\x9b\xd9\xf0\x9c\x09\x1d\x5d\x51\x15\x6f\xea\x93\x12\x2e\x26\x8d\x01\xae\xa5\xa2\x0c\xa1\x0b\x25\x94\x12\xa9\xa3\xd0\x23\xcb\x0f\xa6\x28\x8c\x64\x7f\x4c\xf6\xe7\xcd\x64\xc4\x57\x15\xb1\x8d\x61\x09\x4d\xdb\xe7\x9c\xb1\x86\xd4\x03\x6b\x2a\x66\xd2\x8d\xc0\xf3\xa7\x91\x1f\xb8\x91\x6e\x0a\x5a\x8d\x8c\xfe\xf0\xf2\xb2\xb7\x21\x32\x17\xcc\xc1\x7d\x49\x7e\x6a\x5f\x0b\xf6\xac\xeb\x70\x72\xdb\x6d\x3b\x26\x41\xd6\xcf\x6f\xa2\x7b\x14\x5c\xaa\x89\xb4\x44\x15\x6d\x10\x35\x73\x02\xef\xc4\xa9\xe0\xcb\x25\xf4\x64\x49\x9e\x48\x4f\x85\xca\xd6\x40\xf7\x22\xa5\xa9\x69\x73\x93\xe5\x39\xec\xa8\x9b\x4a\x65\x58\xc5\x18\x71\x3c\xda\x30\xc3\xd7\x13\xaa\xfa\xae\x95\x07\x5b\x43\x64\x09\x8e\x41\x54\x79\xba\x10\x5d\x95\x63\xa0\x75\xcd\x64\x0b\x1e\xb5\xba\x6d\x68\xe3\x65\x6b\x81\x68\x66\xfd\x8c\xdb\xc7\x04\x33\xde\xef\xef\x6b\xa9\x8e\xeb\x0d\x3e\x7f\x16\x77\x10\xa9\xf3\x04\x3e\xc0\xf9\x99\xfe\x89\x6e\x81\x79\xfe\x98\x97\xdc\x8c\x6f\x56\x8c\xda\xc9\x38\xe9\xe5\xf7\x89\xbf\x99\x3c\x5e\xe3\x92\x89\xdb\x4f\xef\xea\xa1\xe4\xe2\x54\x26\xf1\xad\x6d\x31\x82\xbb\x50\x3d\x8e\x02\x55\x28\x54\x31\x62\xe4\x57\x43\xca\x44\x55\x9a\x8f\xcc\xb4\xae\x17\x77\xb1\xbb\x06\x4b\xf8\xff\xe8\xee\x3f\xe8\x7a\x7d\x45\xb5\x5f\x3e\x1e\x7d\x2e\x2d\xcb\x0b\x27\x77\xa7\xdf\x01\xa2\x1d\x12\x9c\x92\x8b\xed\xd0\xee\xcb\xbe\x8d\x3a\xf9\x35\xa5\x28\x5e\xfb\x9c\xb2\x3b\xa1\x07\xde\xad\xdd\xa0\xfd\x97\xa9\x7d\x50\xf1\xc8\xdb\xf3\xc2\xfa\x03\x9b\x8e\x05\x1a\x15\x0a\x00\x00")
+
+func migrations19_add_asset_stats_detailsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations19_add_asset_stats_detailsSql,
+		"migrations/19_add_asset_stats_details.sql",
+	)
+}
+
+func migrations19_add_asset_stats_detailsSql() (*asset, error) {
+	bytes, err := migrations19_add_asset_stats_detailsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/19_add_asset_stats_details.sql", size: 2581, mode: os.FileMode(420), modTime: time.Unix(1792339109, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 var _migrations1_initial_schemaSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xc4\x5a\x5f\x6f\xdb\xc8\x11\x7f\xf7\xa7\x18\xdc\x8b\x6c\xd4\x6a\x2f\xb8\xe2\x70\x95\xe1\x03\x14\x99\x69\x84\xca\x54\x22\x51\x4d\x82\xc3\x61\xb1\x22\x47\xd4\xd6\xe4\x2e\xb3\xbb\x74\xa4\x2b\xfa\xdd\x0b\x52\x24\xc5\xff\xa4\x1c\xc9\xf7\x28\xee\xec\xcc\xfc\x66\x66\x7f\x33\x5c\x6a\x38\x84\xbf\xf8\xcc\x95\x54\x23\xac\x82\xab\xe1\xf0\x6a\x38\x84\x0f\x42\x69\x57\xe2\xf2\xe3\x0c\x1c\xaa\xe9\x9a\x2a\x04\x27\xf4\xe3\xe5\xab\xa5\x61\x81\xd2\x54\xa3\x8f\x5c\x13\xcd\x7c\x14\xa1\x86\x7b\xf8\xf1\x2e\x5e\xf2\x84\xfd\x54\x7d\x6a\x7b\x2c\x92\x46\x6e\x0b\x87\x71\x17\xee\x61\xb0\xb2\xde\xfd\x32\xb8\x4b\xd5\x71\x87\x4a\x87\xd8\x82\x6f\x84\xf4\x19\x77\x89\xd2\x92\x71\x57\xc1\x3d\x08\x9e\xe8\xd8\xa2\xfd\x44\x36\x21\xb7\x35\x13\x9c\xac\x85\xc3\x30\x5a\xdf\x50\x4f\x61\xc1\x8c\xcf\x38\xf1\x51\x29\xea\xc6\x02\xdf\xa8\xe4\x8c\xbb\x77\x57\x09\x3c\x93\xfa\x38\x82\xc0\x0b\x5c\xf5\xd5\xbb\x03\x6b\x1f\xe0\x08\x8c\xcf\x96\x61\x2e\xa7\x73\xf3\x0e\x96\xf6\x16\x7d\x3a\x82\xe1\x1d\xcc\xbf\x71\x94\x23\x18\xc6\xc8\x27\x0b\x63\x6c\x19\x47\x49\x98\xbe\x03\x73\x6e\x81\xf1\x79\xba\xb4\x96\xa9\x42\xf8\x34\xb5\xde\xc3\x72\xf2\xde\x78\x1c\x43\xe0\x12\x9b\x6a\xea\x89\xc8\x7a\xc1\xfc\x51\x4b\xc9\x91\xc9\xfc\xf1\xd1\x30\xad\x16\x37\x0e\x02\x30\x37\xab\x4a\x60\xba\x84\xc1\x87\xd9\xdf\x02\x37\x4a\x5e\x20\x85\x8d\x4e\x28\xa9\x07\x1e\xe5\x6e\x48\x5d\x1c\x94\xfd\xd8\x2a\x2d\x24\x9e\x2f\x0a\x07\x7d\xc5\x20\x84\x6b\x8f\xd9\xcd\x01\x28\xba\xf0\x32\xfc\x89\xd9\x08\x7e\x54\xb2\xa0\xf7\x01\xc2\x46\x48\x88\x9e\x47\x15\xa7\x50\x2b\x10\x1b\xb8\x7e\xc2\xfd\x2d\x3c\x53\x2f\xc4\x1b\x08\x28\x93\x2a\x0e\x49\x5c\x86\x48\xa5\xbd\x25\x01\xd5\x5b\xb8\x4f\xbc\xbe\x2d\xa6\x30\x12\x73\x70\x43\x43\x4f\x13\x4d\xd7\x1e\xaa\x80\xda\x18\x95\xf3\xa0\xb4\xfa\x8d\xe9\x2d\x11\xcc\xc9\x55\x68\x31\xee\x2c\xf2\x6c\x4f\xa8\x6d\x8b\x90\x6b\x95\xc2\xb7\xc6\x6f\x67\xc6\x11\x7c\x12\xbb\x2c\x02\x77\x60\x65\x66\x47\xf9\x7c\xc4\xfb\x2a\x5a\xe1\xfa\x0a\x00\x80\x39\xb0\x66\x2e\xe3\x3a\xce\x94\xb9\x9a\xcd\x6e\xe3\xe7\xd4\x71\x24\x2a\x05\xf6\x96\x4a\x6a\x6b\x94\xf0\x4c\xe5\x9e\x71\xf7\xfa\xe7\xbf\xdf\x5c\xdd\x54\x6a\x25\xd1\x8e\x9b\x0d\xda\xe7\x76\x39\x51\x9a\x78\x5c\x02\x42\x9a\x10\xa4\x72\x22\x40\x49\x63\x5e\x68\x92\xfc\x41\x48\x07\xe5\x0f\xc0\xb8\x46\x17\x65\x69\x35\xae\x97\xfa\x25\x07\x35\x65\x9e\x82\xff\x28\xc1\xd7\xcd\x41\xf1\xd0\x71\x51\x9e\x39\x28\x89\xd2\x24\x28\x0a\xbf\x86\xc8\xed\x26\x47\x0f\xc2\x64\x4b\xd5\xb6\x3e\xa3\x25\xf9\x40\xe2\x33\x13\xa1\x22\x9d\x1b\x93\x18\x49\xca\x15\x3d\xb0\x6f\x9c\x95\xcc\x8f\x07\xe3\xdd\x78\x35\xb3\xe0\xc7\x92\x85\x63\x56\xfa\xc9\xdb\x9e\x50\xe8\x10\xaa\x21\xea\x20\x4a\x53\x3f\x80\xe8\x20\x45\xbd\x24\x7a\x02\x7f\x08\x8e\xe5\x3d\x12\xa9\xee\xdc\x74\x90\x0d\x03\xa7\xb7\x6c\x56\x47\xc9\x4f\x3f\x10\x52\xa3\x24\xcf\x28\x15\x13\xbc\x82\xe5\x4d\xb9\xa2\x84\xa6\x1e\xb1\x05\xe3\xaa\xbe\x20\x37\x88\x24\x10\xc2\xab\x5f\x8d\x9a\x2e\xd9\x60\x53\xae\xe3\x65\x89\x0a\xe5\x73\x93\x88\x4f\x77\x44\xef\x88\x42\x4d\x14\xfb\xa3\x2a\xd5\x5c\xca\xc7\xb4\x05\x54\x6a\x66\xb3\x80\x9e\x9d\xa1\xea\x6d\x1c\xf9\xaa\x1e\x53\xff\xe3\xde\x4d\x20\xa7\xe2\x27\xcc\x21\x0a\xbf\xa6\x61\x58\x1a\x1f\x57\x86\x39\x69\x89\x44\x1e\x7c\x2a\xdd\xcf\x46\x8c\x60\x69\x8d\x17\xd6\xa1\x91\xbe\x89\x1f\x4c\xcd\xc9\xc2\x88\x5b\xdf\xdb\x2f\xc9\x23\x73\x0e\x8f\x53\xf3\xdf\xe3\xd9\xca\xc8\x7e\x8f\x3f\x1f\x7f\x4f\xc6\x93\xf7\x06\xbc\x39\x0b\x50\x98\x7f\x32\x8d\x07\x78\xfb\xa5\x03\xf1\x78\x66\x19\x8b\x13\x01\x67\xba\x3b\xc4\xff\xca\x9c\x4e\x2c\x97\x2a\xd4\xae\x66\x9a\xa7\xc7\xc6\x86\x1b\x04\x1e\xb3\x0f\xb8\xe2\x7e\xf4\x9d\xed\xe8\xf0\x48\x89\x50\xda\x98\x96\x7a\x03\xf7\xa7\x3c\x35\x18\x8c\x46\x15\x89\x1e\x87\x22\x0f\xef\x72\xb4\xd0\x64\x25\x8e\x7d\x03\x2d\xd4\xed\xad\x4f\xc0\xf7\x90\x42\x93\x67\xe7\xa5\x85\x0e\x2b\xaf\x45\x0c\x27\x82\xfd\x4e\x6a\xe8\xb0\x56\x25\x87\xa6\x0d\x2d\xf4\x90\xdb\x72\xb9\x92\x4d\x29\x22\xef\x5f\xef\x71\x2c\x99\xc2\x3a\x86\xbc\xbe\x0c\xd2\x4e\x06\xb5\xb2\x47\xd3\xcd\xf3\x0a\x6d\x6c\xcd\x4d\xb3\xde\x9f\x32\xad\xe9\x1d\x41\xfe\x8c\x9e\x08\x10\x34\xee\x2a\x54\xbd\x8b\x66\xa7\xd0\xd3\x0d\x8b\x3e\x46\xaf\x90\xb5\x4b\x51\x14\x9a\x96\x15\x73\x39\xd5\xa1\xc4\xba\x37\xaa\x7f\xfc\x7c\xf3\xdb\xef\x47\x16\xfe\xef\xff\xea\x78\xf8\xb7\xdf\xcb\x43\x1c\xfa\x82\xc4\xdd\xa0\xca\xd9\x99\x2e\x2e\x38\xb6\xb2\xfa\x51\x57\x55\x4d\x82\x8c\xf9\x48\xd6\x22\xe4\x8e\x8a\x32\xf7\x8b\xa4\xdc\xc5\x98\x0c\xf3\x87\x89\x39\xe9\xd1\x49\x6c\xf7\x3a\xef\x87\xe3\x32\x37\x67\x5d\xdd\x1d\x0e\xf2\x93\xf9\x6c\xf5\x68\x46\x29\x8d\x5e\xa8\x53\x94\x1c\x77\xfa\x99\x7a\xd7\x83\x5e\x03\xc5\x60\x34\x92\xe8\xda\x1e\x55\xaa\xc2\xe8\x67\x43\xd1\xd8\xac\x4e\xc2\xd1\xc1\x7e\x6d\x48\x3a\x42\x11\x3c\xe1\xfe\x78\xad\x62\x2e\xad\xc5\x78\x6a\xb6\xa0\xad\x12\xde\x89\x09\x8c\x4b\x69\xfc\xf0\x90\xb3\xd6\xc7\x47\xf8\xb0\x98\x3e\x8e\x17\x5f\xe0\x5f\xc6\x17\xb8\x66\xce\xe9\x3d\xf8\x82\x48\x9b\x6c\xb6\x61\x6d\xf5\xb3\x13\xed\x3a\x1b\x50\x52\x48\x53\xf3\xc1\xf8\xfc\x82\x46\x15\xef\xcb\xe9\x83\xb9\x59\xdf\xb6\x56\xcb\xa9\xf9\x4f\x58\x6b\x89\x08\xd7\x89\xf0\x6d\xa5\x2f\xd4\x79\x1a\xb5\xb7\xb3\xb9\x19\xf7\xca\x5e\x3e\x96\x3b\x6c\x9d\x6b\x87\x86\x7a\x36\xe7\x0e\xea\xfa\xb9\x57\xea\xe5\xb7\xd5\xb6\x5d\x5b\xe3\x04\xc9\x7a\x7f\x58\xff\x5e\xb7\x57\xe6\xf4\xe3\x2a\xf5\xbe\xa4\x3b\x8f\x21\xbd\x76\x2b\xb8\x5f\xf7\x9a\x7d\x9b\xde\xa0\x35\x79\x7e\xa4\xd5\x73\xfa\xcc\x9c\xde\xde\x1e\xa7\xfa\xdb\xda\x8b\x82\x0e\x04\x22\x20\xc1\x45\x40\x24\x8a\xf3\x38\x1a\xfa\xdf\x8b\x60\x55\xd1\x64\x37\x7a\xeb\xfd\xd9\x01\x15\x75\xe7\x31\xa5\x77\x95\x05\x10\xf5\xee\xe5\x4f\xef\x45\x7c\xac\x18\xe8\x77\x6c\x6b\xbc\x65\xdc\xc1\x1d\x29\xdf\xab\x13\xc1\x49\x72\x79\x7e\x56\xd7\x3b\xad\xe5\x71\x64\x97\xfc\x45\xf6\x3e\x08\x9e\x00\xe4\xcc\xe1\x6f\x33\xd4\xed\x7e\x67\x0a\x12\x0a\x88\xf4\x45\x73\xf1\x79\xe8\xbd\xd5\x44\x27\x01\x45\x42\x1d\x5e\x27\x87\x23\x52\x99\x5d\x72\x5f\xc2\xf5\x3a\x3b\x9d\x87\x34\x93\xec\x0f\xe2\xa2\x35\x53\xb0\xf3\x12\x8a\x69\x56\x57\xba\xc5\xbf\x70\x0a\x2a\x1f\x0d\x3a\xb1\x94\x36\xf4\x47\x96\xfb\x86\xf3\x3a\x99\xc9\x7f\x34\xea\x82\x95\x93\xed\x8f\xa8\xee\xf3\xd4\xeb\x40\xab\xfd\x30\xd6\x85\xb1\x6e\x53\x7f\xb0\xe9\xa4\xf8\x3a\x00\xb3\x8b\x9e\x2e\x50\x8d\x93\x7f\x51\xf5\xf1\x8e\xfc\xe2\xdc\x50\x36\x55\x3b\x55\x9d\xca\x10\x45\xa5\xc5\x7b\xe4\x4b\x50\x44\x9b\xbd\x3e\x80\x8a\x3b\x4e\x03\x77\xa1\x9e\x59\xb5\xd2\x0b\x48\x5d\xe7\x8c\x87\x66\xbd\xbb\xd0\x34\x9e\x28\x6e\x18\x08\x5f\x38\x8f\x57\x13\xd2\x9c\x8f\xfc\xf8\x79\xf1\xe3\x52\x35\xf6\xe2\x49\x58\x4b\xea\x60\x36\x1b\xa5\xef\x92\x64\x2d\xc4\xd3\x79\x0a\xaa\xc5\x40\xe7\x08\x76\x7d\x9d\x7e\x17\x1b\xfe\xfa\x2b\x0c\x94\xf0\x1c\x42\x95\x42\x1d\x97\xe2\x60\x34\xd2\xb8\xd3\x37\x37\xb7\xd0\x2c\x68\x0b\xa7\x9f\x20\x53\x2a\x44\xd9\x2c\xba\x16\xa1\xbb\xd5\xbd\xcc\x17\x44\xdb\x1d\x28\x88\x96\x5c\xb8\x81\x4f\xef\x8d\x85\x71\x38\x4f\x70\x0f\x3f\xfd\x94\xcb\x5e\xd3\xbf\xf9\xc0\x16\x7e\xe0\xa1\xc6\x38\x13\xf9\x3f\x02\x3e\x88\x6f\xfc\xca\x91\x22\x80\xf8\x3f\x4e\xf5\xe5\x62\x53\x65\x53\x07\xef\x3a\x04\x8b\x07\xaa\x6d\x53\x8e\x23\x7a\x89\xf5\xd7\x9c\xb6\xb6\x36\x99\xb4\xaa\xda\x64\xb2\x37\x96\x4c\xe8\xff\x01\x00\x00\xff\xff\x5d\xb2\x1f\x7d\x3f\x29\x00\x00")
 
 func migrations1_initial_schemaSqlBytes() ([]byte, error) {
@@ -223,6 +332,86 @@ func migrations1_initial_schemaSql() (*asset, error) {
 	return a, nil
 }
 
+var _migrations20_add_search_indexesSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\x7d\x90\xc1\x0a\x82\x40\x14\x45\xf7\xf3\x15\x0f\x57\x46\xfa\x05\xae\x22\x25\xdc\x68\x58\x42\xbb\x41\x9b\xa7\x4e\xd4\xcc\x30\xf3\x20\xfc\xfb\x26\x28\x30\x1c\xda\xde\x77\x39\xef\x70\xd3\x14\xb6\x0f\x39\xda\x8e\x10\x5a\xc3\xd8\xbe\x29\x76\xe7\x02\xca\x2a\x2f\x2e\x30\x69\xc3\xfb\x99\xd3\x6c\x10\xea\x0a\x26\xe9\x48\xdb\x99\x6b\x83\xbe\x2f\xb5\x72\xd0\x9e\xca\xea\x00\x3d\x59\x44\x88\xdf\xbd\x04\xa4\xd8\x64\x41\x8c\x40\xea\xe4\xdd\xfd\x25\x8d\x52\x41\xfc\x2d\xde\x9c\x56\x3d\x37\x1d\x4d\xbe\xe8\x56\x54\x1c\x86\x90\x9d\x8f\xf1\x4a\x41\xb5\xd5\x5b\x2e\x45\x02\x91\xb6\x02\x6d\xe4\xf9\x2c\x5d\xac\x91\xeb\xa7\x62\x2c\x6f\xea\xe3\x7a\x8d\x2c\x90\x7f\xac\x7f\x4f\x0b\xc7\x8c\xbd\x00\xdd\xc4\x38\xeb\x6c\x01\x00\x00")
+
+func migrations20_add_search_indexesSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations20_add_search_indexesSql,
+		"migrations/20_add_search_indexes.sql",
+	)
+}
+
+func migrations20_add_search_indexesSql() (*asset, error) {
+	bytes, err := migrations20_add_search_indexesSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/20_add_search_indexes.sql", size: 364, mode: os.FileMode(420), modTime: time.Unix(1792339453, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations21_add_memo_indexSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\x65\x8d\xb1\x0a\xc2\x30\x18\x06\xf7\xff\x29\xbe\x51\xd1\x3c\x41\x27\xb1\x41\x03\x25\x2d\x69\x8b\x6e\xa1\x95\x60\x33\xa4\x29\xe9\x0f\x9a\xb7\x97\x3a\x09\x2e\x37\x1c\x07\x27\x04\x0e\xc1\x3f\xd3\xc0\x0e\xfd\x42\x74\x36\xf2\xd4\x49\x28\x5d\xca\x3b\x26\x7e\xdb\x31\xdb\xe0\x42\x44\xad\x31\xf9\x95\x63\xca\x96\xd3\x30\xaf\xc3\x83\x7d\x9c\x57\xf4\xad\xd2\x17\x8c\x9c\x9c\xc3\x6e\x2b\x8f\xd8\x68\x39\x2f\x6e\x8f\xdb\x55\x1a\xf9\x15\x50\x2d\x74\xdd\x41\xf7\x55\x55\x10\x89\x9f\x6f\x19\x5f\x33\x51\x69\xea\xe6\xff\x5b\xd0\x07\x69\x79\xe8\x08\xa2\x00\x00\x00")
+
+func migrations21_add_memo_indexSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations21_add_memo_indexSql,
+		"migrations/21_add_memo_index.sql",
+	)
+}
+
+func migrations21_add_memo_indexSql() (*asset, error) {
+	bytes, err := migrations21_add_memo_indexSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/21_add_memo_index.sql", size: 162, mode: os.FileMode(420), modTime: time.Unix(1792339620, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations22_add_api_keysSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\x85\x90\xbb\x0e\x82\x40\x14\x44\xfb\xfb\x15\xb7\x13\xa2\x24\x16\xc6\x86\x0a\x65\x4d\x88\x08\x48\xd8\x82\x6a\xb3\x92\x0d\x6c\x94\x47\x96\x45\xe5\xef\x45\x49\x7c\x16\x4e\x35\xc5\x39\xc9\x64\x2c\x0b\xa7\xa5\xcc\x15\xd7\x02\x69\x03\xb0\x8e\x89\x93\x10\x4c\x9c\x95\x4f\x90\x37\x92\x1d\x45\xdf\xa2\x01\x38\x64\xa8\xac\xe0\x6d\x81\x59\xc1\x15\xcf\xb4\x50\xc6\x72\x61\x62\x10\x26\x18\x50\xdf\xc7\x28\xf6\x76\x4e\x9c\xe2\x96\xa4\xb3\x87\x50\xf1\x52\xbc\x60\x3c\x73\xd5\xcb\x2a\xff\x94\x68\xe0\xed\x29\x19\xf9\x46\x28\x56\xd4\x9d\x62\xf7\x39\xec\x24\x4b\xa9\x51\x56\x5a\xe4\x83\xfc\x14\x5c\xb2\x71\xa8\x9f\xe0\x7c\x74\x0e\x9d\x6a\xff\x53\xaa\xee\xb4\x68\x51\x8b\xab\xfe\x65\x26\x13\x30\x6d\x00\xeb\xed\x0a\xb7\xbe\x54\x00\x6e\x1c\x46\x5f\x57\xd8\x70\x03\x6f\x3a\x34\x8f\x32\x01\x00\x00")
+
+func migrations22_add_api_keysSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations22_add_api_keysSql,
+		"migrations/22_add_api_keys.sql",
+	)
+}
+
+func migrations22_add_api_keysSql() (*asset, error) {
+	bytes, err := migrations22_add_api_keysSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/22_add_api_keys.sql", size: 306, mode: os.FileMode(420), modTime: time.Unix(1792340348, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations23_add_webhooksSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\xe5\x95\xc1\x6e\x9b\x40\x10\x86\xef\x3c\xc5\xdc\x8c\x55\x5b\x4a\xa2\x36\x97\x9c\xa8\xd9\x44\x56\x29\x4e\xb1\x91\x9a\x13\x5a\xc3\xd4\x5e\x05\xb3\x68\x77\xb0\xeb\x3e\x7d\x17\xe3\xd8\x8b\x8b\x5d\x1f\x9a\x53\xb9\x80\x66\x7e\x66\x77\xe6\xff\x58\x86\x43\xf8\xb0\x12\x0b\xc5\x09\x21\x2e\x1d\x67\x14\x31\x6f\xc6\x60\xe6\x7d\x0e\x18\x6c\x70\xbe\x94\xf2\x55\x83\xeb\x80\xb9\x44\x06\x73\xb1\xd0\xa8\x04\xcf\xe1\x39\x1a\x7f\xf5\xa2\x17\xf8\xc2\x5e\x06\xbb\xac\xdc\x14\xa8\x20\x5d\x72\xc5\x53\x32\x4f\x6b\xae\xb6\xa2\x58\xb8\xf7\x1f\xfb\x10\x4e\x66\x10\xc6\x41\xd0\x28\x2b\x95\x03\xe1\x4f\x3a\x09\x6b\x4c\x15\xd2\x55\x15\x78\x9a\xca\xaa\x20\xdd\x55\x06\xd7\x58\x50\x42\xdb\x12\x4f\xd2\xe0\xb3\x47\x2f\x0e\x66\xd0\xeb\xed\xab\x68\x8d\x8d\xb2\x7b\x51\x5b\x95\xca\xac\x4b\x75\x7b\xd7\x52\x09\xad\xab\xce\x21\x7c\xba\xdf\xeb\x72\xae\x29\xc9\x31\x5b\x98\xa4\x28\x08\xeb\x7b\x7b\xff\x66\x08\xc6\x8c\x2c\xe1\x04\x24\x56\xa8\x89\xaf\x4a\xd8\x08\x5a\xca\xaa\x89\xc0\x2f\x59\xe0\x9f\x5d\xd5\xa9\x3a\xe3\xf6\x2a\x4a\x7b\x03\x28\xe4\xc6\xed\xf7\x9d\xfe\xc3\xc1\xd4\x71\xe8\xb3\xef\x07\x53\x93\xf9\x36\x69\x3c\x9b\x84\x47\xa7\xe3\xe9\x38\x7c\x82\x39\x29\x44\x70\x77\xe9\x81\xf1\xdd\x14\x39\x5b\xc3\xee\xe8\x6c\x25\x4b\x64\x6d\xa8\x45\x59\x92\x61\x2e\xd6\x06\x2e\xbc\x8e\xb7\xb7\xd7\x1a\x95\x99\xe5\x71\x24\x11\x7b\x64\x11\x0b\x47\x6c\x6a\x21\x6c\xba\xa8\xf7\xe7\xb3\x80\x99\xa5\x47\xde\x74\xe4\xf9\xcc\x46\xc6\x14\xba\x86\xbd\x23\x5f\x57\xc9\x4b\xbe\xcd\x25\xcf\xba\x48\xe5\x44\xb8\x2a\x0d\xc5\xa7\x20\x1c\x3c\xbd\x69\x84\x85\x79\x37\xd9\xab\xff\x11\x17\x16\x8d\xa8\x94\x54\x7f\xf9\x54\xe2\x70\xfc\x2d\x66\xe0\x1e\x87\x3e\x38\x4c\xed\x3c\x63\x96\xa5\x35\x29\x76\x1b\x16\x2a\xb6\xf1\x2d\x68\x4e\xda\xbe\x00\x0e\xcf\x0c\x5c\x46\xa8\xda\xe8\xd4\x50\xfc\x4f\xdc\x5c\x36\xb5\xc9\xfe\xe0\x22\x7f\xff\xd3\xa5\xe5\x49\xed\xfd\x3e\xde\xb6\xdd\xb2\xad\x65\xbc\x4d\xd9\xee\xf0\x71\x86\xd6\x6f\xca\x37\xc7\x92\xe3\xf8\xd1\xe4\xf9\x02\x07\x0f\xdd\x82\x37\xd0\xba\xd2\x26\xf8\x1b\xf3\xd2\xd7\xb7\x0e\x07\x00\x00")
+
+func migrations23_add_webhooksSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations23_add_webhooksSql,
+		"migrations/23_add_webhooks.sql",
+	)
+}
+
+func migrations23_add_webhooksSql() (*asset, error) {
+	bytes, err := migrations23_add_webhooksSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/23_add_webhooks.sql", size: 1806, mode: os.FileMode(420), modTime: time.Unix(1792341729, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 var _migrations2_index_participants_by_toidSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x8f\xb1\xca\xc2\x50\x0c\x46\xf7\x3c\x45\xc6\xff\x47\xfa\x04\x9d\xc4\x16\xe9\xd2\x4a\xb5\xe0\x76\x49\xdb\x8b\xcd\xe0\xcd\x25\x37\x20\x7d\x7b\x41\x07\x5b\xbb\xb8\x86\x8f\x73\x72\xb2\x0c\x77\x77\xbe\x29\x99\xc7\x2e\x02\x1c\xda\x72\x7f\x29\xb1\xaa\x8b\xf2\x8a\x93\x44\xd7\xcf\x6e\x12\x1e\xb1\xa9\x71\xe2\x64\xa2\xb3\x93\xe8\x95\x8c\x25\xb8\x48\x6a\x3c\x70\xa4\x60\x09\xbb\x73\x55\x1f\xb1\x37\xf5\x1e\xff\xb6\x5b\x1e\xff\xf3\x2f\xbc\xbd\xf1\xb6\xc6\x9b\x52\x48\x34\xfc\x28\x58\xae\x5f\x0a\x58\x26\x15\xf2\x08\x00\x45\xdb\x9c\xb6\x49\xf9\xea\xfe\xf9\x25\x87\x67\x00\x00\x00\xff\xff\x33\xec\x54\x7a\x15\x01\x00\x00")
 
 func migrations2_index_participants_by_toidSqlBytes() ([]byte, error) {
@@ -461,7 +650,16 @@ var _bindata = map[string]func() (*asset, error){
 	"migrations/12_asset_stats_amount_string.sql": migrations12_asset_stats_amount_stringSql,
 	"migrations/13_trade_offer_ids.sql": migrations13_trade_offer_idsSql,
 	"migrations/14_fix_asset_toml_field.sql": migrations14_fix_asset_toml_fieldSql,
+	"migrations/15_add_state_history.sql": migrations15_add_state_historySql,
+	"migrations/16_add_offer_history.sql": migrations16_add_offer_historySql,
+	"migrations/17_add_ledger_history_filtered.sql": migrations17_add_ledger_history_filteredSql,
+	"migrations/18_add_trade_rollups.sql": migrations18_add_trade_rollupsSql,
+	"migrations/19_add_asset_stats_details.sql": migrations19_add_asset_stats_detailsSql,
 	"migrations/1_initial_schema.sql": migrations1_initial_schemaSql,
+	"migrations/20_add_search_indexes.sql": migrations20_add_search_indexesSql,
+	"migrations/21_add_memo_index.sql": migrations21_add_memo_indexSql,
+	"migrations/22_add_api_keys.sql": migrations22_add_api_keysSql,
+	"migrations/23_add_webhooks.sql": migrations23_add_webhooksSql,
 	"migrations/2_index_participants_by_toid.sql": migrations2_index_participants_by_toidSql,
 	"migrations/3_use_sequence_in_history_accounts.sql": migrations3_use_sequence_in_history_accountsSql,
 	"migrations/4_add_protocol_version.sql": migrations4_add_protocol_versionSql,
@@ -520,7 +718,16 @@ var _bintree = &bintree{nil, map[string]*bintree{
 		"12_asset_stats_amount_string.sql": &bintree{migrations12_asset_stats_amount_stringSql, map[string]*bintree{}},
 		"13_trade_offer_ids.sql": &bintree{migrations13_trade_offer_idsSql, map[string]*bintree{}},
 		"14_fix_asset_toml_field.sql": &bintree{migrations14_fix_asset_toml_fieldSql, map[string]*bintree{}},
+		"15_add_state_history.sql": &bintree{migrations15_add_state_historySql, map[string]*bintree{}},
+		"16_add_offer_history.sql": &bintree{migrations16_add_offer_historySql, map[string]*bintree{}},
+		"17_add_ledger_history_filtered.sql": &bintree{migrations17_add_ledger_history_filteredSql, map[string]*bintree{}},
+		"18_add_trade_rollups.sql": &bintree{migrations18_add_trade_rollupsSql, map[string]*bintree{}},
+		"19_add_asset_stats_details.sql": &bintree{migrations19_add_asset_stats_detailsSql, map[string]*bintree{}},
 		"1_initial_schema.sql": &bintree{migrations1_initial_schemaSql, map[string]*bintree{}},
+		"20_add_search_indexes.sql": &bintree{migrations20_add_search_indexesSql, map[string]*bintree{}},
+		"21_add_memo_index.sql": &bintree{migrations21_add_memo_indexSql, map[string]*bintree{}},
+		"22_add_api_keys.sql": &bintree{migrations22_add_api_keysSql, map[string]*bintree{}},
+		"23_add_webhooks.sql": &bintree{migrations23_add_webhooksSql, map[string]*bintree{}},
 		"2_index_participants_by_toid.sql": &bintree{migrations2_index_participants_by_toidSql, map[string]*bintree{}},
 		"3_use_sequence_in_history_accounts.sql": &bintree{migrations3_use_sequence_in_history_accountsSql, map[string]*bintree{}},
 		"4_add_protocol_version.sql": &bintree{migrations4_add_protocol_versionSql, map[string]*bintree{}},
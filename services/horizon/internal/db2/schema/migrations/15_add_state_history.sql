@@ -0,0 +1,29 @@
+-- +migrate Up
+
+CREATE TABLE history_account_states (
+    account_id VARCHAR(64) NOT NULL,
+    ledger_sequence INTEGER NOT NULL,
+    balance BIGINT,
+    entry TEXT
+);
+
+CREATE INDEX has_by_account ON history_account_states USING btree (account_id, ledger_sequence);
+CREATE INDEX has_by_ledger ON history_account_states USING btree (ledger_sequence);
+
+CREATE TABLE history_trustline_states (
+    account_id VARCHAR(64) NOT NULL,
+    asset_type INTEGER NOT NULL,
+    asset_code VARCHAR(12) NOT NULL,
+    asset_issuer VARCHAR(56) NOT NULL,
+    ledger_sequence INTEGER NOT NULL,
+    balance BIGINT,
+    entry TEXT
+);
+
+CREATE INDEX hts_by_account ON history_trustline_states USING btree (account_id, asset_type, asset_code, asset_issuer, ledger_sequence);
+CREATE INDEX hts_by_ledger ON history_trustline_states USING btree (ledger_sequence);
+
+-- +migrate Down
+
+DROP TABLE history_account_states cascade;
+DROP TABLE history_trustline_states cascade;
@@ -0,0 +1,28 @@
+-- +migrate Up
+
+CREATE TABLE history_offer_events (
+    offer_id BIGINT NOT NULL,
+    history_operation_id BIGINT NOT NULL,
+    ledger_sequence INTEGER NOT NULL,
+    type SMALLINT NOT NULL,
+    seller_id VARCHAR(64) NOT NULL,
+    selling_asset_type INTEGER NOT NULL,
+    selling_asset_code VARCHAR(12),
+    selling_asset_issuer VARCHAR(56),
+    buying_asset_type INTEGER NOT NULL,
+    buying_asset_code VARCHAR(12),
+    buying_asset_issuer VARCHAR(56),
+    amount BIGINT NOT NULL,
+    pricen INTEGER NOT NULL,
+    priced INTEGER NOT NULL,
+    price DOUBLE PRECISION NOT NULL,
+    flags INTEGER NOT NULL,
+    UNIQUE(offer_id, history_operation_id)
+);
+
+CREATE INDEX hoe_by_operation ON history_offer_events USING btree (history_operation_id);
+CREATE INDEX hoe_by_seller ON history_offer_events USING btree (seller_id, offer_id);
+
+-- +migrate Down
+
+DROP TABLE history_offer_events cascade;
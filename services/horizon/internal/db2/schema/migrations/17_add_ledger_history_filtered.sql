@@ -0,0 +1,9 @@
+-- +migrate Up
+
+ALTER TABLE history_ledgers ADD history_filtered BOOLEAN DEFAULT false NOT NULL;
+CREATE INDEX hl_by_history_filtered ON history_ledgers USING btree (id) WHERE history_filtered;
+
+-- +migrate Down
+
+DROP INDEX hl_by_history_filtered;
+ALTER TABLE history_ledgers DROP COLUMN history_filtered;
@@ -0,0 +1,46 @@
+-- +migrate Up
+
+CREATE TABLE history_trade_rollups (
+    resolution BIGINT NOT NULL,
+    base_asset_id BIGINT NOT NULL REFERENCES history_assets(id),
+    counter_asset_id BIGINT NOT NULL REFERENCES history_assets(id),
+    "timestamp" BIGINT NOT NULL,
+    count BIGINT NOT NULL,
+    buy_count BIGINT NOT NULL,
+    sell_count BIGINT NOT NULL,
+    base_volume BIGINT NOT NULL,
+    counter_volume BIGINT NOT NULL,
+    high NUMERIC[],
+    low NUMERIC[],
+    open NUMERIC[],
+    close NUMERIC[],
+    PRIMARY KEY (resolution, base_asset_id, counter_asset_id, "timestamp")
+);
+
+-- populate the hourly and daily rollups from the existing trades
+INSERT INTO history_trade_rollups
+SELECT
+    resolution,
+    base_asset_id,
+    counter_asset_id,
+    div(cast((extract(epoch from ledger_closed_at) * 1000) as bigint), resolution) * resolution as "timestamp",
+    count(*),
+    sum(CASE WHEN base_is_seller THEN 1 ELSE 0 END),
+    sum(CASE WHEN base_is_seller THEN 0 ELSE 1 END),
+    sum(base_amount),
+    sum(counter_amount),
+    max_price(price),
+    min_price(price),
+    first(price),
+    last(price)
+FROM (
+    SELECT r.resolution, htrd.*, ARRAY[htrd.price_n, htrd.price_d] as price
+    FROM history_trades htrd
+    CROSS JOIN (VALUES (3600000::bigint), (86400000::bigint)) AS r(resolution)
+    ORDER BY htrd.history_operation_id, htrd."order"
+) htrd
+GROUP BY resolution, base_asset_id, counter_asset_id, 4;
+
+-- +migrate Down
+
+DROP TABLE history_trade_rollups cascade;
@@ -0,0 +1,72 @@
+-- +migrate Up
+
+ALTER TABLE asset_stats
+    ADD COLUMN num_unauthorized_accounts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN unauthorized_amount character varying NOT NULL DEFAULT '0',
+    ADD COLUMN amount_in_offers character varying NOT NULL DEFAULT '0',
+    ADD COLUMN num_holders_above_threshold INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE asset_stats_daily (
+    id BIGINT NOT NULL REFERENCES history_assets ON DELETE CASCADE ON UPDATE RESTRICT,
+    day DATE NOT NULL,
+    amount character varying NOT NULL,
+    num_accounts INTEGER NOT NULL,
+    num_unauthorized_accounts INTEGER NOT NULL,
+    unauthorized_amount character varying NOT NULL,
+    amount_in_offers character varying NOT NULL,
+    num_holders_above_threshold INTEGER NOT NULL,
+    PRIMARY KEY (id, day)
+);
+
+CREATE TABLE history_asset_payments (
+    history_operation_id BIGINT NOT NULL PRIMARY KEY,
+    asset_id BIGINT NOT NULL REFERENCES history_assets(id),
+    amount BIGINT NOT NULL,
+    ledger_closed_at timestamp without time zone NOT NULL
+);
+
+CREATE INDEX hap_by_asset ON history_asset_payments USING btree (asset_id, ledger_closed_at);
+
+-- populate the payments from the existing payment (1) and path payment (2)
+-- operations, whose asset and amount details are those of the destination.
+INSERT INTO history_assets (asset_type, asset_code, asset_issuer)
+SELECT DISTINCT
+    hop.details->>'asset_type',
+    hop.details->>'asset_code',
+    hop.details->>'asset_issuer'
+FROM history_operations hop
+WHERE hop.type IN (1, 2)
+AND hop.details->>'asset_type' <> 'native'
+AND NOT EXISTS (
+    SELECT 1 FROM history_assets ha
+    WHERE ha.asset_type = hop.details->>'asset_type'
+    AND ha.asset_code = hop.details->>'asset_code'
+    AND ha.asset_issuer = hop.details->>'asset_issuer'
+);
+
+INSERT INTO history_asset_payments
+SELECT
+    hop.id,
+    ha.id,
+    cast((hop.details->>'amount')::numeric * 10000000 as bigint),
+    hl.closed_at
+FROM history_operations hop
+JOIN history_transactions ht ON ht.id = hop.transaction_id
+JOIN history_ledgers hl ON hl.sequence = ht.ledger_sequence
+JOIN history_assets ha
+    ON ha.asset_type = hop.details->>'asset_type'
+    AND ha.asset_code = hop.details->>'asset_code'
+    AND ha.asset_issuer = hop.details->>'asset_issuer'
+WHERE hop.type IN (1, 2)
+AND hop.details->>'asset_type' <> 'native';
+
+-- +migrate Down
+
+DROP TABLE history_asset_payments cascade;
+DROP TABLE asset_stats_daily cascade;
+
+ALTER TABLE asset_stats
+    DROP COLUMN num_unauthorized_accounts,
+    DROP COLUMN unauthorized_amount,
+    DROP COLUMN amount_in_offers,
+    DROP COLUMN num_holders_above_threshold;
@@ -0,0 +1,11 @@
+-- +migrate Up
+
+CREATE INDEX hop_by_type ON history_operations USING btree (type, id);
+CREATE INDEX hop_by_details ON history_operations USING gin (details jsonb_path_ops);
+CREATE INDEX heff_by_type ON history_effects USING btree (type, history_operation_id, "order");
+
+-- +migrate Down
+
+DROP INDEX hop_by_type;
+DROP INDEX hop_by_details;
+DROP INDEX heff_by_type;
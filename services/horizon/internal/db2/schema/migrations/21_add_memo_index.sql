@@ -0,0 +1,7 @@
+-- +migrate Up
+
+CREATE INDEX htx_by_memo ON history_transactions USING btree (memo, memo_type) WHERE memo IS NOT NULL;
+
+-- +migrate Down
+
+DROP INDEX htx_by_memo;
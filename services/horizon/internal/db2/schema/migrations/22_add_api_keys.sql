@@ -0,0 +1,13 @@
+-- +migrate Up
+
+CREATE TABLE api_keys (
+    key_hash character(64) NOT NULL PRIMARY KEY,
+    name character varying(64) NOT NULL UNIQUE,
+    per_hour_rate_limit integer NOT NULL DEFAULT 0,
+    burst integer NOT NULL DEFAULT 0,
+    routes text NOT NULL DEFAULT ''
+);
+
+-- +migrate Down
+
+DROP TABLE api_keys;
@@ -0,0 +1,51 @@
+-- +migrate Up
+
+CREATE TABLE webhooks (
+    id bigserial PRIMARY KEY,
+    owner character varying(64) NOT NULL,
+    url text NOT NULL,
+    secret character varying(64) NOT NULL,
+    accounts text NOT NULL,
+    event_types text NOT NULL DEFAULT '',
+    asset_type character varying(64),
+    asset_code character varying(12),
+    asset_issuer character varying(56),
+    last_ledger integer NOT NULL,
+    created_at timestamp without time zone NOT NULL DEFAULT timezone('utc', now())
+);
+
+CREATE INDEX webhooks_by_owner ON webhooks USING btree (owner, id);
+CREATE INDEX webhooks_by_last_ledger ON webhooks USING btree (last_ledger);
+
+CREATE TABLE webhook_deliveries (
+    id bigserial PRIMARY KEY,
+    webhook_id bigint NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id character varying(64) NOT NULL,
+    event_type character varying(64) NOT NULL,
+    payload text NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    next_attempt_at timestamp without time zone NOT NULL DEFAULT timezone('utc', now()),
+    last_error text NOT NULL DEFAULT '',
+    UNIQUE (webhook_id, event_id)
+);
+
+CREATE INDEX webhook_deliveries_by_next_attempt ON webhook_deliveries USING btree (next_attempt_at);
+
+CREATE TABLE webhook_dead_letters (
+    id bigint PRIMARY KEY,
+    webhook_id bigint NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id character varying(64) NOT NULL,
+    event_type character varying(64) NOT NULL,
+    payload text NOT NULL,
+    attempts integer NOT NULL,
+    last_error text NOT NULL,
+    failed_at timestamp without time zone NOT NULL DEFAULT timezone('utc', now())
+);
+
+CREATE INDEX webhook_dead_letters_by_webhook ON webhook_dead_letters USING btree (webhook_id, id);
+
+-- +migrate Down
+
+DROP TABLE webhook_dead_letters;
+DROP TABLE webhook_deliveries;
+DROP TABLE webhooks;
@@ -6,6 +6,7 @@ import (
 
 	"github.com/getsentry/raven-go"
 	"github.com/go-errors/errors"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/apikey"
 )
 
 // FromPanic extracts the err from the result of a recover() call.
@@ -28,6 +29,8 @@ func ReportToSentry(err error, r *http.Request) {
 	var packet *raven.Packet
 	if r != nil {
 		h := raven.NewHttp(r)
+		h.Query = apikey.StripURL(r.URL).RawQuery
+		delete(h.Headers, apikey.Header)
 		packet = raven.NewPacket(err.Error(), exc, h)
 	} else {
 		packet = raven.NewPacket(err.Error(), exc)
@@ -0,0 +1,226 @@
+package graphql
+
+import (
+	"context"
+	"sort"
+
+	gographql "github.com/graph-gophers/graphql-go"
+	"github.com/lomocoin/stellar-go/protocols/horizon"
+	"github.com/lomocoin/stellar-go/protocols/horizon/base"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/core"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/resourceadapter"
+)
+
+type accountResolver struct {
+	res horizon.Account
+}
+
+func (r *accountResolver) ID() gographql.ID              { return gographql.ID(r.res.ID) }
+func (r *accountResolver) PagingToken() string           { return r.res.PT }
+func (r *accountResolver) AccountID() string             { return r.res.AccountID }
+func (r *accountResolver) Sequence() string              { return r.res.Sequence }
+func (r *accountResolver) SubentryCount() int32          { return r.res.SubentryCount }
+func (r *accountResolver) InflationDestination() *string { return optional(r.res.InflationDestination) }
+func (r *accountResolver) HomeDomain() *string           { return optional(r.res.HomeDomain) }
+
+func (r *accountResolver) Thresholds() *thresholdsResolver {
+	return &thresholdsResolver{r.res.Thresholds}
+}
+
+func (r *accountResolver) Flags() *flagsResolver {
+	return &flagsResolver{r.res.Flags}
+}
+
+func (r *accountResolver) Balances() []*balanceResolver {
+	balances := make([]*balanceResolver, len(r.res.Balances))
+	for i := range r.res.Balances {
+		balances[i] = &balanceResolver{r.res.Balances[i]}
+	}
+
+	return balances
+}
+
+func (r *accountResolver) Signers() []*signerResolver {
+	signers := make([]*signerResolver, len(r.res.Signers))
+	for i := range r.res.Signers {
+		signers[i] = &signerResolver{r.res.Signers[i]}
+	}
+
+	return signers
+}
+
+// Data returns the data entries of the account, in the lexical order of their
+// keys.
+func (r *accountResolver) Data() []*dataEntryResolver {
+	keys := make([]string, 0, len(r.res.Data))
+	for key := range r.res.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	data := make([]*dataEntryResolver, len(keys))
+	for i, key := range keys {
+		data[i] = &dataEntryResolver{key: key, value: r.res.Data[key]}
+	}
+
+	return data
+}
+
+func (r *accountResolver) Transactions(ctx context.Context, args pageArgs) (*transactionConnection, error) {
+	return loadTransactions(ctx, args, func(q *history.TransactionsQ) {
+		q.ForAccount(r.res.AccountID)
+	})
+}
+
+func (r *accountResolver) Operations(ctx context.Context, args pageArgs) (*operationConnection, error) {
+	return loadOperations(ctx, args, func(q *history.OperationsQ) {
+		q.ForAccount(r.res.AccountID)
+	})
+}
+
+func (r *accountResolver) Payments(ctx context.Context, args pageArgs) (*operationConnection, error) {
+	return loadOperations(ctx, args, func(q *history.OperationsQ) {
+		q.ForAccount(r.res.AccountID).OnlyPayments()
+	})
+}
+
+func (r *accountResolver) Effects(ctx context.Context, args pageArgs) (*effectConnection, error) {
+	return loadEffects(ctx, args, func(q *history.EffectsQ) {
+		q.ForAccount(r.res.AccountID)
+	})
+}
+
+func (r *accountResolver) Offers(ctx context.Context, args pageArgs) (*offerConnection, error) {
+	return loadOffers(ctx, args, r.res.AccountID)
+}
+
+func (r *accountResolver) Trades(ctx context.Context, args pageArgs) (*tradeConnection, error) {
+	return loadTrades(ctx, args, func(q *history.TradesQ) {
+		q.ForAccount(r.res.AccountID)
+	})
+}
+
+// loadAccount loads the current state of the account whose address is
+// `address`, or nil if it does not exist.
+func loadAccount(ctx context.Context, address string) (*accountResolver, error) {
+	err := charge(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		e              = envFromContext(ctx)
+		coreRecord     core.Account
+		coreData       []core.AccountData
+		coreSigners    []core.Signer
+		coreTrustlines []core.Trustline
+		historyRecord  history.Account
+	)
+
+	err = e.coreQ.AccountByAddress(&coreRecord, address, e.protocolVersion)
+	if e.coreQ.NoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = e.coreQ.AllDataByAddress(&coreData, address)
+	if err != nil {
+		return nil, err
+	}
+
+	err = e.coreQ.SignersByAddress(&coreSigners, address)
+	if err != nil {
+		return nil, err
+	}
+
+	err = e.coreQ.TrustlinesByAddress(&coreTrustlines, address, e.protocolVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	// accounts created before the history of this server have no history
+	// record.
+	err = e.historyQ.AccountByAddress(&historyRecord, address)
+	if err != nil && !e.historyQ.NoRows(err) {
+		return nil, err
+	}
+
+	r := &accountResolver{}
+	err = resourceadapter.PopulateAccount(
+		ctx,
+		&r.res,
+		coreRecord,
+		coreData,
+		coreSigners,
+		coreTrustlines,
+		historyRecord,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+type thresholdsResolver struct {
+	res horizon.AccountThresholds
+}
+
+func (r *thresholdsResolver) LowThreshold() int32  { return int32(r.res.LowThreshold) }
+func (r *thresholdsResolver) MedThreshold() int32  { return int32(r.res.MedThreshold) }
+func (r *thresholdsResolver) HighThreshold() int32 { return int32(r.res.HighThreshold) }
+
+type flagsResolver struct {
+	res horizon.AccountFlags
+}
+
+func (r *flagsResolver) AuthRequired() bool  { return r.res.AuthRequired }
+func (r *flagsResolver) AuthRevocable() bool { return r.res.AuthRevocable }
+func (r *flagsResolver) AuthImmutable() bool { return r.res.AuthImmutable }
+
+type balanceResolver struct {
+	res horizon.Balance
+}
+
+func (r *balanceResolver) Balance() string            { return r.res.Balance }
+func (r *balanceResolver) Limit() *string             { return optional(r.res.Limit) }
+func (r *balanceResolver) BuyingLiabilities() string  { return r.res.BuyingLiabilities }
+func (r *balanceResolver) SellingLiabilities() string { return r.res.SellingLiabilities }
+func (r *balanceResolver) AssetType() string          { return r.res.Type }
+func (r *balanceResolver) AssetCode() *string         { return optional(r.res.Code) }
+func (r *balanceResolver) AssetIssuer() *string       { return optional(r.res.Issuer) }
+
+type signerResolver struct {
+	res horizon.Signer
+}
+
+func (r *signerResolver) PublicKey() string { return r.res.PublicKey }
+func (r *signerResolver) Weight() int32     { return r.res.Weight }
+func (r *signerResolver) Key() string       { return r.res.Key }
+func (r *signerResolver) Type() string      { return r.res.Type }
+
+type dataEntryResolver struct {
+	key   string
+	value string
+}
+
+func (r *dataEntryResolver) Key() string   { return r.key }
+func (r *dataEntryResolver) Value() string { return r.value }
+
+type assetResolver struct {
+	res base.Asset
+}
+
+func (r *assetResolver) AssetType() string    { return r.res.Type }
+func (r *assetResolver) AssetCode() *string   { return optional(r.res.Code) }
+func (r *assetResolver) AssetIssuer() *string { return optional(r.res.Issuer) }
+
+type priceResolver struct {
+	res horizon.Price
+}
+
+func (r *priceResolver) N() int32 { return r.res.N }
+func (r *priceResolver) D() int32 { return r.res.D }
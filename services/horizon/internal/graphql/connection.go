@@ -0,0 +1,153 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
+)
+
+// pageArgs are the arguments of the fields resolving connections.
+type pageArgs struct {
+	First *int32
+	After *string
+	Order *string
+}
+
+// pageQuery charges the cost of the connection `args` asks for and returns
+// its page query, which selects one more record than asked for to find out
+// whether there is a next page.
+func (args pageArgs) pageQuery(ctx context.Context) (db2.PageQuery, error) {
+	first := int32(db2.DefaultPageSize)
+	if args.First != nil {
+		first = *args.First
+	}
+	if first <= 0 {
+		return db2.PageQuery{}, db2.ErrInvalidLimit
+	}
+
+	var cursor, order string
+	if args.After != nil {
+		cursor = *args.After
+	}
+	if args.Order != nil {
+		order = *args.Order
+	}
+
+	pq, err := db2.NewPageQuery(cursor, true, order, uint64(first))
+	if err != nil {
+		return db2.PageQuery{}, err
+	}
+
+	err = charge(ctx, first)
+	if err != nil {
+		return db2.PageQuery{}, err
+	}
+
+	pq.Limit++
+	return pq, nil
+}
+
+// pageSize returns the number of the `loaded` records of `pq` that belong to
+// the page, and whether there is a next page.
+func pageSize(pq db2.PageQuery, loaded int) (int, bool) {
+	if uint64(loaded) < pq.Limit {
+		return loaded, false
+	}
+
+	return int(pq.Limit - 1), true
+}
+
+type pageInfoResolver struct {
+	startCursor *string
+	endCursor   *string
+	hasNextPage bool
+}
+
+func newPageInfo(cursors []string, hasNextPage bool) *pageInfoResolver {
+	info := &pageInfoResolver{hasNextPage: hasNextPage}
+	if len(cursors) > 0 {
+		info.startCursor = &cursors[0]
+		info.endCursor = &cursors[len(cursors)-1]
+	}
+
+	return info
+}
+
+func (r *pageInfoResolver) StartCursor() *string { return r.startCursor }
+func (r *pageInfoResolver) EndCursor() *string   { return r.endCursor }
+func (r *pageInfoResolver) HasNextPage() bool    { return r.hasNextPage }
+
+type transactionConnection struct {
+	edges    []*transactionEdge
+	pageInfo *pageInfoResolver
+}
+
+func (c *transactionConnection) Edges() []*transactionEdge   { return c.edges }
+func (c *transactionConnection) PageInfo() *pageInfoResolver { return c.pageInfo }
+
+type transactionEdge struct {
+	node *transactionResolver
+}
+
+func (e *transactionEdge) Cursor() string             { return e.node.PagingToken() }
+func (e *transactionEdge) Node() *transactionResolver { return e.node }
+
+type operationConnection struct {
+	edges    []*operationEdge
+	pageInfo *pageInfoResolver
+}
+
+func (c *operationConnection) Edges() []*operationEdge     { return c.edges }
+func (c *operationConnection) PageInfo() *pageInfoResolver { return c.pageInfo }
+
+type operationEdge struct {
+	node *operationResolver
+}
+
+func (e *operationEdge) Cursor() string           { return e.node.PagingToken() }
+func (e *operationEdge) Node() *operationResolver { return e.node }
+
+type effectConnection struct {
+	edges    []*effectEdge
+	pageInfo *pageInfoResolver
+}
+
+func (c *effectConnection) Edges() []*effectEdge        { return c.edges }
+func (c *effectConnection) PageInfo() *pageInfoResolver { return c.pageInfo }
+
+type effectEdge struct {
+	node *effectResolver
+}
+
+func (e *effectEdge) Cursor() string        { return e.node.PagingToken() }
+func (e *effectEdge) Node() *effectResolver { return e.node }
+
+type offerConnection struct {
+	edges    []*offerEdge
+	pageInfo *pageInfoResolver
+}
+
+func (c *offerConnection) Edges() []*offerEdge         { return c.edges }
+func (c *offerConnection) PageInfo() *pageInfoResolver { return c.pageInfo }
+
+type offerEdge struct {
+	node *offerResolver
+}
+
+func (e *offerEdge) Cursor() string       { return e.node.PagingToken() }
+func (e *offerEdge) Node() *offerResolver { return e.node }
+
+type tradeConnection struct {
+	edges    []*tradeEdge
+	pageInfo *pageInfoResolver
+}
+
+func (c *tradeConnection) Edges() []*tradeEdge         { return c.edges }
+func (c *tradeConnection) PageInfo() *pageInfoResolver { return c.pageInfo }
+
+type tradeEdge struct {
+	node *tradeResolver
+}
+
+func (e *tradeEdge) Cursor() string       { return e.node.PagingToken() }
+func (e *tradeEdge) Node() *tradeResolver { return e.node }
@@ -0,0 +1,162 @@
+// Package graphql contains the read-only GraphQL endpoint of horizon, which
+// resolves its queries with the same history and core queries as the REST
+// endpoints.  The records of lists are served as cursor based connections.
+//
+// Every query is given a cost budget: resolving a connection costs the number
+// of records it asks for, and any other field loading a record costs 1.  The
+// cost of each field is also charged against the rate limiter of the request
+// as it is resolved, so that a query counts for as many requests as the REST
+// calls it replaces.
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	gographql "github.com/graph-gophers/graphql-go"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/core"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
+	"github.com/lomocoin/stellar-go/support/errors"
+	"github.com/throttled/throttled"
+)
+
+// DefaultMaxCost is the default cost budget of a query.
+const DefaultMaxCost = 1000
+
+// ErrRateLimited is returned by the fields of a query resolved once the rate
+// limit of the request was exceeded.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// Request is a GraphQL request, as POSTed in a JSON body.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Params are the state a query is resolved with.
+type Params struct {
+	HistoryQ *history.Q
+	CoreQ    *core.Q
+
+	// ProtocolVersion is the protocol version of the connected stellar-core.
+	ProtocolVersion int32
+
+	// MaxCost is the cost budget of the query.
+	MaxCost int32
+
+	// RateLimiter, if not nil, is charged the cost of the fields of the query,
+	// as the requests of RateLimitKey.
+	RateLimiter  throttled.RateLimiter
+	RateLimitKey string
+}
+
+// Exec resolves `req` with `params`.  The cost of the query is reported in
+// the `cost` extension of the response.
+func Exec(ctx context.Context, params Params, req Request) *gographql.Response {
+	c := &cost{
+		max:     params.MaxCost,
+		limiter: params.RateLimiter,
+		key:     params.RateLimitKey,
+	}
+
+	ctx = context.WithValue(ctx, &envKey, &env{
+		historyQ:        params.HistoryQ,
+		coreQ:           params.CoreQ,
+		protocolVersion: params.ProtocolVersion,
+		cost:            c,
+	})
+
+	resp := schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	resp.Extensions = map[string]interface{}{
+		"cost": map[string]int32{
+			"total": c.Total(),
+			"max":   c.max,
+		},
+	}
+	return resp
+}
+
+var schema = gographql.MustParseSchema(schemaString, &queryResolver{})
+
+var envKey = 0
+
+// env is the state of the query being resolved, set on the context of its
+// resolvers.
+type env struct {
+	historyQ        *history.Q
+	coreQ           *core.Q
+	protocolVersion int32
+	cost            *cost
+}
+
+func envFromContext(ctx context.Context) *env {
+	return ctx.Value(&envKey).(*env)
+}
+
+// charge charges `quantity` to the cost of the query being resolved.
+func charge(ctx context.Context, quantity int32) error {
+	return envFromContext(ctx).cost.Charge(quantity)
+}
+
+// cost tracks the cost of a query against its budget and the rate limiter of
+// its request.  Fields are resolved concurrently.
+type cost struct {
+	lock    sync.Mutex
+	total   int32
+	max     int32
+	limiter throttled.RateLimiter
+	key     string
+}
+
+// Charge adds `quantity` to the cost of the query, failing if it exceeds
+// the budget of the query or the rate limit of its request.
+func (c *cost) Charge(quantity int32) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.total+quantity > c.max {
+		return errors.Errorf("query cost exceeds the maximum of %d", c.max)
+	}
+	c.total += quantity
+
+	if c.limiter == nil {
+		return nil
+	}
+
+	limited, _, err := c.limiter.RateLimit(c.key, int(quantity))
+	if err != nil {
+		return errors.Wrap(err, "RateLimiter error")
+	}
+	if limited {
+		return ErrRateLimited
+	}
+
+	return nil
+}
+
+// Total returns the cost charged so far.
+func (c *cost) Total() int32 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.total
+}
+
+// JSON is the `JSON` scalar of the schema, a resource encoded as JSON.
+type JSON json.RawMessage
+
+// ImplementsGraphQLType maps JSON to the `JSON` scalar.
+func (JSON) ImplementsGraphQLType(name string) bool {
+	return name == "JSON"
+}
+
+// UnmarshalGraphQL implements the scalar, which is only used in responses.
+func (j *JSON) UnmarshalGraphQL(input interface{}) error {
+	return errors.New("JSON cannot be used as an input")
+}
+
+// MarshalJSON returns `j` as is.
+func (j JSON) MarshalJSON() ([]byte, error) {
+	return json.RawMessage(j).MarshalJSON()
+}
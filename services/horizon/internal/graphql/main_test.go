@@ -0,0 +1,53 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2"
+	"github.com/stretchr/testify/assert"
+	"github.com/throttled/throttled"
+)
+
+func TestCost(t *testing.T) {
+	c := &cost{max: 10}
+	assert.NoError(t, c.Charge(4))
+	assert.NoError(t, c.Charge(6))
+	assert.Error(t, c.Charge(1))
+	assert.Equal(t, int32(10), c.Total())
+
+	// charges are rate limited
+	limiter, err := throttled.NewGCRARateLimiter(10, throttled.RateQuota{
+		MaxRate:  throttled.PerHour(1),
+		MaxBurst: 4,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	c = &cost{max: 100, limiter: limiter, key: "127.0.0.1"}
+	assert.NoError(t, c.Charge(3))
+	assert.Equal(t, ErrRateLimited, c.Charge(3))
+}
+
+func TestPageSize(t *testing.T) {
+	pq := db2.PageQuery{Limit: 11}
+
+	n, hasNextPage := pageSize(pq, 4)
+	assert.Equal(t, 4, n)
+	assert.False(t, hasNextPage)
+
+	n, hasNextPage = pageSize(pq, 11)
+	assert.Equal(t, 10, n)
+	assert.True(t, hasNextPage)
+}
+
+func TestNewPageInfo(t *testing.T) {
+	info := newPageInfo(nil, false)
+	assert.Nil(t, info.StartCursor())
+	assert.Nil(t, info.EndCursor())
+
+	info = newPageInfo([]string{"1", "2", "3"}, true)
+	assert.Equal(t, "1", *info.StartCursor())
+	assert.Equal(t, "3", *info.EndCursor())
+	assert.True(t, info.HasNextPage())
+}
@@ -0,0 +1,250 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+
+	gographql "github.com/graph-gophers/graphql-go"
+	"github.com/lomocoin/stellar-go/protocols/horizon/effects"
+	"github.com/lomocoin/stellar-go/protocols/horizon/operations"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/resourceadapter"
+	"github.com/lomocoin/stellar-go/support/errors"
+)
+
+type operationResolver struct {
+	row    history.Operation
+	base   operations.Base
+	record JSON
+}
+
+// newOperationResolver populates the resource of `row`, whose fields depend
+// on its type, and decodes its common fields.
+func newOperationResolver(
+	ctx context.Context,
+	row history.Operation,
+	ledger history.Ledger,
+) (*operationResolver, error) {
+	resource, err := resourceadapter.NewOperation(ctx, row, ledger)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &operationResolver{row: row}
+	r.record, err = json.Marshal(resource)
+	if err != nil {
+		return nil, errors.Wrap(err, "encode operation failed")
+	}
+
+	err = json.Unmarshal(r.record, &r.base)
+	return r, errors.Wrap(err, "decode operation failed")
+}
+
+func (r *operationResolver) ID() gographql.ID      { return gographql.ID(r.base.ID) }
+func (r *operationResolver) PagingToken() string   { return r.base.PT }
+func (r *operationResolver) SourceAccount() string { return r.base.SourceAccount }
+func (r *operationResolver) Type() string          { return r.base.Type }
+func (r *operationResolver) TypeI() int32          { return r.base.TypeI }
+func (r *operationResolver) CreatedAt() gographql.Time {
+	return gographql.Time{Time: r.base.LedgerCloseTime}
+}
+func (r *operationResolver) TransactionHash() string { return r.base.TransactionHash }
+func (r *operationResolver) Record() JSON            { return r.record }
+
+func (r *operationResolver) Transaction(ctx context.Context) (*transactionResolver, error) {
+	tx, err := loadTransaction(ctx, r.row.TransactionHash)
+	if err == nil && tx == nil {
+		err = errors.Errorf("transaction %s not found", r.row.TransactionHash)
+	}
+
+	return tx, err
+}
+
+func (r *operationResolver) Effects(ctx context.Context, args pageArgs) (*effectConnection, error) {
+	return loadEffects(ctx, args, func(q *history.EffectsQ) {
+		q.ForOperation(r.row.ID)
+	})
+}
+
+// loadOperation loads the operation whose id is `id`, or nil if it is not in
+// history.
+func loadOperation(ctx context.Context, id int64) (*operationResolver, error) {
+	err := charge(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	q := envFromContext(ctx).historyQ
+	var record history.Operation
+	err = q.OperationByID(&record, id)
+	if q.NoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ledger history.Ledger
+	err = q.LedgerBySequence(&ledger, record.LedgerSequence())
+	if err != nil {
+		return nil, errors.Wrap(err, "load ledger failed")
+	}
+
+	return newOperationResolver(ctx, record, ledger)
+}
+
+// loadOperations loads the connection of the operations selected by
+// `filter`.
+func loadOperations(
+	ctx context.Context,
+	args pageArgs,
+	filter func(*history.OperationsQ),
+) (*operationConnection, error) {
+	pq, err := args.pageQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q := envFromContext(ctx).historyQ
+	query := q.Operations()
+	filter(query)
+
+	var records []history.Operation
+	err = query.Page(pq).Select(&records)
+	if err != nil {
+		return nil, err
+	}
+
+	n, hasNextPage := pageSize(pq, len(records))
+	records = records[:n]
+
+	ledgers := &history.LedgerCache{}
+	for _, record := range records {
+		ledgers.Queue(record.LedgerSequence())
+	}
+	err = ledgers.Load(q)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &operationConnection{edges: make([]*operationEdge, 0, n)}
+	cursors := make([]string, n)
+	for i, record := range records {
+		ledger, found := ledgers.Records[record.LedgerSequence()]
+		if !found {
+			return nil, errors.Errorf("could not find ledger data for sequence %d", record.LedgerSequence())
+		}
+
+		r, err := newOperationResolver(ctx, record, ledger)
+		if err != nil {
+			return nil, err
+		}
+
+		conn.edges = append(conn.edges, &operationEdge{node: r})
+		cursors[i] = r.base.PT
+	}
+	conn.pageInfo = newPageInfo(cursors, hasNextPage)
+
+	return conn, nil
+}
+
+type effectResolver struct {
+	row    history.Effect
+	base   effects.Base
+	record JSON
+}
+
+// newEffectResolver populates the resource of `row`, whose fields depend on
+// its type, and decodes its common fields.
+func newEffectResolver(
+	ctx context.Context,
+	row history.Effect,
+	ledger history.Ledger,
+) (*effectResolver, error) {
+	resource, err := resourceadapter.NewEffect(ctx, row, ledger)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &effectResolver{row: row}
+	r.record, err = json.Marshal(resource)
+	if err != nil {
+		return nil, errors.Wrap(err, "encode effect failed")
+	}
+
+	err = json.Unmarshal(r.record, &r.base)
+	return r, errors.Wrap(err, "decode effect failed")
+}
+
+func (r *effectResolver) ID() gographql.ID    { return gographql.ID(r.base.ID) }
+func (r *effectResolver) PagingToken() string { return r.base.PT }
+func (r *effectResolver) Account() string     { return r.base.Account }
+func (r *effectResolver) Type() string        { return r.base.Type }
+func (r *effectResolver) TypeI() int32        { return r.base.TypeI }
+func (r *effectResolver) CreatedAt() gographql.Time {
+	return gographql.Time{Time: r.base.LedgerCloseTime}
+}
+func (r *effectResolver) Record() JSON { return r.record }
+
+func (r *effectResolver) Operation(ctx context.Context) (*operationResolver, error) {
+	op, err := loadOperation(ctx, r.row.HistoryOperationID)
+	if err == nil && op == nil {
+		err = errors.Errorf("operation %d not found", r.row.HistoryOperationID)
+	}
+
+	return op, err
+}
+
+// loadEffects loads the connection of the effects selected by `filter`.
+func loadEffects(
+	ctx context.Context,
+	args pageArgs,
+	filter func(*history.EffectsQ),
+) (*effectConnection, error) {
+	pq, err := args.pageQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q := envFromContext(ctx).historyQ
+	query := q.Effects()
+	filter(query)
+
+	var records []history.Effect
+	err = query.Page(pq).Select(&records)
+	if err != nil {
+		return nil, err
+	}
+
+	n, hasNextPage := pageSize(pq, len(records))
+	records = records[:n]
+
+	ledgers := &history.LedgerCache{}
+	for _, record := range records {
+		ledgers.Queue(record.LedgerSequence())
+	}
+	err = ledgers.Load(q)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &effectConnection{edges: make([]*effectEdge, 0, n)}
+	cursors := make([]string, n)
+	for i, record := range records {
+		ledger, found := ledgers.Records[record.LedgerSequence()]
+		if !found {
+			return nil, errors.Errorf("could not find ledger data for sequence %d", record.LedgerSequence())
+		}
+
+		r, err := newEffectResolver(ctx, record, ledger)
+		if err != nil {
+			return nil, err
+		}
+
+		conn.edges = append(conn.edges, &effectEdge{node: r})
+		cursors[i] = r.base.PT
+	}
+	conn.pageInfo = newPageInfo(cursors, hasNextPage)
+
+	return conn, nil
+}
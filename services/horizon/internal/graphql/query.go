@@ -0,0 +1,63 @@
+package graphql
+
+import (
+	"context"
+	"strconv"
+
+	gographql "github.com/graph-gophers/graphql-go"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
+	"github.com/lomocoin/stellar-go/strkey"
+	"github.com/lomocoin/stellar-go/support/errors"
+)
+
+// queryResolver resolves the root `Query` type of the schema.
+type queryResolver struct{}
+
+func (r *queryResolver) Account(ctx context.Context, args struct{ ID gographql.ID }) (*accountResolver, error) {
+	address := string(args.ID)
+	_, err := strkey.Decode(strkey.VersionByteAccountID, address)
+	if err != nil {
+		return nil, errors.Errorf("invalid address: %s", address)
+	}
+
+	return loadAccount(ctx, address)
+}
+
+func (r *queryResolver) Ledger(ctx context.Context, args struct{ Sequence int32 }) (*ledgerResolver, error) {
+	return loadLedger(ctx, args.Sequence)
+}
+
+func (r *queryResolver) Transaction(ctx context.Context, args struct{ Hash string }) (*transactionResolver, error) {
+	return loadTransaction(ctx, args.Hash)
+}
+
+func (r *queryResolver) Operation(ctx context.Context, args struct{ ID gographql.ID }) (*operationResolver, error) {
+	id, err := strconv.ParseInt(string(args.ID), 10, 64)
+	if err != nil {
+		return nil, errors.Errorf("invalid operation id: %s", args.ID)
+	}
+
+	return loadOperation(ctx, id)
+}
+
+func (r *queryResolver) Transactions(ctx context.Context, args pageArgs) (*transactionConnection, error) {
+	return loadTransactions(ctx, args, func(*history.TransactionsQ) {})
+}
+
+func (r *queryResolver) Operations(ctx context.Context, args pageArgs) (*operationConnection, error) {
+	return loadOperations(ctx, args, func(*history.OperationsQ) {})
+}
+
+func (r *queryResolver) Payments(ctx context.Context, args pageArgs) (*operationConnection, error) {
+	return loadOperations(ctx, args, func(q *history.OperationsQ) {
+		q.OnlyPayments()
+	})
+}
+
+func (r *queryResolver) Effects(ctx context.Context, args pageArgs) (*effectConnection, error) {
+	return loadEffects(ctx, args, func(*history.EffectsQ) {})
+}
+
+func (r *queryResolver) Trades(ctx context.Context, args pageArgs) (*tradeConnection, error) {
+	return loadTrades(ctx, args, func(*history.TradesQ) {})
+}
@@ -0,0 +1,275 @@
+package graphql
+
+// schemaString is the schema of the GraphQL endpoint.  Its types mirror the
+// resources of the REST endpoints, as defined in protocols/horizon, down to
+// the names of their fields.  Operations and effects, whose fields depend on
+// their type, expose their common fields and the resource as served by the
+// REST endpoints in their `record` field.
+const schemaString = `
+schema {
+	query: Query
+}
+
+# Time is an RFC 3339 timestamp.
+scalar Time
+
+# JSON is a resource encoded as JSON.
+scalar JSON
+
+# Order is the order of the records of a connection.
+enum Order {
+	asc
+	desc
+}
+
+type Query {
+	# An account, or null if it does not exist.
+	account(id: ID!): Account
+	# A ledger, or null if it is not in the history of this server.
+	ledger(sequence: Int!): Ledger
+	# A transaction, or null if it is not in the history of this server.
+	transaction(hash: String!): Transaction
+	# An operation, or null if it is not in the history of this server.
+	operation(id: ID!): Operation
+
+	transactions(first: Int, after: String, order: Order): TransactionConnection!
+	operations(first: Int, after: String, order: Order): OperationConnection!
+	payments(first: Int, after: String, order: Order): OperationConnection!
+	effects(first: Int, after: String, order: Order): EffectConnection!
+	trades(first: Int, after: String, order: Order): TradeConnection!
+}
+
+type PageInfo {
+	start_cursor: String
+	end_cursor: String
+	has_next_page: Boolean!
+}
+
+type Account {
+	id: ID!
+	paging_token: String!
+	account_id: String!
+	sequence: String!
+	subentry_count: Int!
+	inflation_destination: String
+	home_domain: String
+	thresholds: AccountThresholds!
+	flags: AccountFlags!
+	balances: [Balance!]!
+	signers: [Signer!]!
+	data: [DataEntry!]!
+
+	transactions(first: Int, after: String, order: Order): TransactionConnection!
+	operations(first: Int, after: String, order: Order): OperationConnection!
+	payments(first: Int, after: String, order: Order): OperationConnection!
+	effects(first: Int, after: String, order: Order): EffectConnection!
+	offers(first: Int, after: String, order: Order): OfferConnection!
+	trades(first: Int, after: String, order: Order): TradeConnection!
+}
+
+type AccountThresholds {
+	low_threshold: Int!
+	med_threshold: Int!
+	high_threshold: Int!
+}
+
+type AccountFlags {
+	auth_required: Boolean!
+	auth_revocable: Boolean!
+	auth_immutable: Boolean!
+}
+
+type Balance {
+	balance: String!
+	limit: String
+	buying_liabilities: String!
+	selling_liabilities: String!
+	asset_type: String!
+	asset_code: String
+	asset_issuer: String
+}
+
+type Signer {
+	public_key: String!
+	weight: Int!
+	key: String!
+	type: String!
+}
+
+# DataEntry is a key of the data of an account, whose value is base64 encoded.
+type DataEntry {
+	key: String!
+	value: String!
+}
+
+type Asset {
+	asset_type: String!
+	asset_code: String
+	asset_issuer: String
+}
+
+type Price {
+	n: Int!
+	d: Int!
+}
+
+type Ledger {
+	id: ID!
+	paging_token: String!
+	hash: String!
+	prev_hash: String
+	sequence: Int!
+	transaction_count: Int!
+	operation_count: Int!
+	closed_at: Time!
+	total_coins: String!
+	fee_pool: String!
+	base_fee_in_stroops: Int!
+	base_reserve_in_stroops: Int!
+	max_tx_set_size: Int!
+	protocol_version: Int!
+	header_xdr: String!
+
+	transactions(first: Int, after: String, order: Order): TransactionConnection!
+	operations(first: Int, after: String, order: Order): OperationConnection!
+	payments(first: Int, after: String, order: Order): OperationConnection!
+	effects(first: Int, after: String, order: Order): EffectConnection!
+}
+
+type Transaction {
+	id: ID!
+	paging_token: String!
+	hash: String!
+	ledger: Int!
+	created_at: Time!
+	source_account: String!
+	source_account_sequence: String!
+	fee_paid: Int!
+	operation_count: Int!
+	envelope_xdr: String!
+	result_xdr: String!
+	result_meta_xdr: String!
+	fee_meta_xdr: String!
+	memo_type: String!
+	memo: String
+	signatures: [String!]!
+	valid_after: String
+	valid_before: String
+
+	operations(first: Int, after: String, order: Order): OperationConnection!
+	payments(first: Int, after: String, order: Order): OperationConnection!
+	effects(first: Int, after: String, order: Order): EffectConnection!
+}
+
+type Operation {
+	id: ID!
+	paging_token: String!
+	source_account: String!
+	type: String!
+	type_i: Int!
+	created_at: Time!
+	transaction_hash: String!
+	# The operation as served by the REST endpoints.
+	record: JSON!
+
+	transaction: Transaction!
+	effects(first: Int, after: String, order: Order): EffectConnection!
+}
+
+type Effect {
+	id: ID!
+	paging_token: String!
+	account: String!
+	type: String!
+	type_i: Int!
+	created_at: Time!
+	# The effect as served by the REST endpoints.
+	record: JSON!
+
+	operation: Operation!
+}
+
+type Offer {
+	id: ID!
+	paging_token: String!
+	seller: String!
+	selling: Asset!
+	buying: Asset!
+	amount: String!
+	price_r: Price!
+	price: String!
+	last_modified_ledger: Int!
+	last_modified_time: Time
+}
+
+type Trade {
+	id: ID!
+	paging_token: String!
+	ledger_close_time: Time!
+	offer_id: String!
+	base_offer_id: String!
+	base_account: String!
+	base_amount: String!
+	base_asset_type: String!
+	base_asset_code: String
+	base_asset_issuer: String
+	counter_offer_id: String!
+	counter_account: String!
+	counter_amount: String!
+	counter_asset_type: String!
+	counter_asset_code: String
+	counter_asset_issuer: String
+	base_is_seller: Boolean!
+	price: Price
+}
+
+type TransactionConnection {
+	edges: [TransactionEdge!]!
+	page_info: PageInfo!
+}
+
+type TransactionEdge {
+	cursor: String!
+	node: Transaction!
+}
+
+type OperationConnection {
+	edges: [OperationEdge!]!
+	page_info: PageInfo!
+}
+
+type OperationEdge {
+	cursor: String!
+	node: Operation!
+}
+
+type EffectConnection {
+	edges: [EffectEdge!]!
+	page_info: PageInfo!
+}
+
+type EffectEdge {
+	cursor: String!
+	node: Effect!
+}
+
+type OfferConnection {
+	edges: [OfferEdge!]!
+	page_info: PageInfo!
+}
+
+type OfferEdge {
+	cursor: String!
+	node: Offer!
+}
+
+type TradeConnection {
+	edges: [TradeEdge!]!
+	page_info: PageInfo!
+}
+
+type TradeEdge {
+	cursor: String!
+	node: Trade!
+}
+`
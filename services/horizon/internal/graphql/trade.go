@@ -0,0 +1,154 @@
+package graphql
+
+import (
+	"context"
+	"strconv"
+
+	gographql "github.com/graph-gophers/graphql-go"
+	"github.com/lomocoin/stellar-go/protocols/horizon"
+	"github.com/lomocoin/stellar-go/protocols/horizon/base"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/core"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/resourceadapter"
+)
+
+type offerResolver struct {
+	res horizon.Offer
+}
+
+func (r *offerResolver) ID() gographql.ID {
+	return gographql.ID(strconv.FormatInt(r.res.ID, 10))
+}
+
+func (r *offerResolver) PagingToken() string       { return r.res.PT }
+func (r *offerResolver) Seller() string            { return r.res.Seller }
+func (r *offerResolver) Selling() *assetResolver   { return &assetResolver{base.Asset(r.res.Selling)} }
+func (r *offerResolver) Buying() *assetResolver    { return &assetResolver{base.Asset(r.res.Buying)} }
+func (r *offerResolver) Amount() string            { return r.res.Amount }
+func (r *offerResolver) PriceR() *priceResolver    { return &priceResolver{r.res.PriceR} }
+func (r *offerResolver) Price() string             { return r.res.Price }
+func (r *offerResolver) LastModifiedLedger() int32 { return r.res.LastModifiedLedger }
+
+func (r *offerResolver) LastModifiedTime() *gographql.Time {
+	if r.res.LastModifiedTime == nil {
+		return nil
+	}
+
+	return &gographql.Time{Time: *r.res.LastModifiedTime}
+}
+
+// loadOffers loads the connection of the offers of the account whose address
+// is `address`.
+func loadOffers(ctx context.Context, args pageArgs, address string) (*offerConnection, error) {
+	pq, err := args.pageQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	e := envFromContext(ctx)
+	var records []core.Offer
+	err = e.coreQ.OffersByAddress(&records, address, pq)
+	if err != nil {
+		return nil, err
+	}
+
+	n, hasNextPage := pageSize(pq, len(records))
+	records = records[:n]
+
+	ledgers := &history.LedgerCache{}
+	for _, record := range records {
+		ledgers.Queue(record.Lastmodified)
+	}
+	err = ledgers.Load(e.historyQ)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &offerConnection{edges: make([]*offerEdge, 0, n)}
+	cursors := make([]string, n)
+	for i, record := range records {
+		ledger, found := ledgers.Records[record.Lastmodified]
+		ledgerPtr := &ledger
+		if !found {
+			ledgerPtr = nil
+		}
+
+		r := &offerResolver{}
+		resourceadapter.PopulateOffer(ctx, &r.res, record, ledgerPtr)
+		conn.edges = append(conn.edges, &offerEdge{node: r})
+		cursors[i] = r.res.PT
+	}
+	conn.pageInfo = newPageInfo(cursors, hasNextPage)
+
+	return conn, nil
+}
+
+type tradeResolver struct {
+	res horizon.Trade
+}
+
+func (r *tradeResolver) ID() gographql.ID    { return gographql.ID(r.res.ID) }
+func (r *tradeResolver) PagingToken() string { return r.res.PT }
+func (r *tradeResolver) LedgerCloseTime() gographql.Time {
+	return gographql.Time{Time: r.res.LedgerCloseTime}
+}
+func (r *tradeResolver) OfferID() string             { return r.res.OfferID }
+func (r *tradeResolver) BaseOfferID() string         { return r.res.BaseOfferID }
+func (r *tradeResolver) BaseAccount() string         { return r.res.BaseAccount }
+func (r *tradeResolver) BaseAmount() string          { return r.res.BaseAmount }
+func (r *tradeResolver) BaseAssetType() string       { return r.res.BaseAssetType }
+func (r *tradeResolver) BaseAssetCode() *string      { return optional(r.res.BaseAssetCode) }
+func (r *tradeResolver) BaseAssetIssuer() *string    { return optional(r.res.BaseAssetIssuer) }
+func (r *tradeResolver) CounterOfferID() string      { return r.res.CounterOfferID }
+func (r *tradeResolver) CounterAccount() string      { return r.res.CounterAccount }
+func (r *tradeResolver) CounterAmount() string       { return r.res.CounterAmount }
+func (r *tradeResolver) CounterAssetType() string    { return r.res.CounterAssetType }
+func (r *tradeResolver) CounterAssetCode() *string   { return optional(r.res.CounterAssetCode) }
+func (r *tradeResolver) CounterAssetIssuer() *string { return optional(r.res.CounterAssetIssuer) }
+func (r *tradeResolver) BaseIsSeller() bool          { return r.res.BaseIsSeller }
+
+func (r *tradeResolver) Price() *priceResolver {
+	if r.res.Price == nil {
+		return nil
+	}
+
+	return &priceResolver{*r.res.Price}
+}
+
+// loadTrades loads the connection of the trades selected by `filter`.
+func loadTrades(
+	ctx context.Context,
+	args pageArgs,
+	filter func(*history.TradesQ),
+) (*tradeConnection, error) {
+	pq, err := args.pageQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := envFromContext(ctx).historyQ.Trades()
+	filter(query)
+
+	var records []history.Trade
+	err = query.Page(pq).Select(&records)
+	if err != nil {
+		return nil, err
+	}
+
+	n, hasNextPage := pageSize(pq, len(records))
+	conn := &tradeConnection{edges: make([]*tradeEdge, 0, n)}
+	cursors := make([]string, n)
+	for i, record := range records[:n] {
+		r := &tradeResolver{}
+		err = resourceadapter.PopulateTrade(ctx, &r.res, record)
+		if err != nil {
+			return nil, err
+		}
+
+		conn.edges = append(conn.edges, &tradeEdge{node: r})
+		cursors[i] = r.res.PT
+	}
+	conn.pageInfo = newPageInfo(cursors, hasNextPage)
+
+	return conn, nil
+}
@@ -0,0 +1,195 @@
+package graphql
+
+import (
+	"context"
+
+	gographql "github.com/graph-gophers/graphql-go"
+	"github.com/lomocoin/stellar-go/protocols/horizon"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/resourceadapter"
+)
+
+type transactionResolver struct {
+	res horizon.Transaction
+}
+
+func (r *transactionResolver) ID() gographql.ID    { return gographql.ID(r.res.ID) }
+func (r *transactionResolver) PagingToken() string { return r.res.PT }
+func (r *transactionResolver) Hash() string        { return r.res.Hash }
+func (r *transactionResolver) Ledger() int32       { return r.res.Ledger }
+func (r *transactionResolver) CreatedAt() gographql.Time {
+	return gographql.Time{Time: r.res.LedgerCloseTime}
+}
+func (r *transactionResolver) SourceAccount() string         { return r.res.Account }
+func (r *transactionResolver) SourceAccountSequence() string { return r.res.AccountSequence }
+func (r *transactionResolver) FeePaid() int32                { return r.res.FeePaid }
+func (r *transactionResolver) OperationCount() int32         { return r.res.OperationCount }
+func (r *transactionResolver) EnvelopeXdr() string           { return r.res.EnvelopeXdr }
+func (r *transactionResolver) ResultXdr() string             { return r.res.ResultXdr }
+func (r *transactionResolver) ResultMetaXdr() string         { return r.res.ResultMetaXdr }
+func (r *transactionResolver) FeeMetaXdr() string            { return r.res.FeeMetaXdr }
+func (r *transactionResolver) MemoType() string              { return r.res.MemoType }
+func (r *transactionResolver) Memo() *string                 { return optional(r.res.Memo) }
+func (r *transactionResolver) Signatures() []string          { return r.res.Signatures }
+func (r *transactionResolver) ValidAfter() *string           { return optional(r.res.ValidAfter) }
+func (r *transactionResolver) ValidBefore() *string          { return optional(r.res.ValidBefore) }
+
+func (r *transactionResolver) Operations(ctx context.Context, args pageArgs) (*operationConnection, error) {
+	return loadOperations(ctx, args, func(q *history.OperationsQ) {
+		q.ForTransaction(r.res.Hash)
+	})
+}
+
+func (r *transactionResolver) Payments(ctx context.Context, args pageArgs) (*operationConnection, error) {
+	return loadOperations(ctx, args, func(q *history.OperationsQ) {
+		q.ForTransaction(r.res.Hash).OnlyPayments()
+	})
+}
+
+func (r *transactionResolver) Effects(ctx context.Context, args pageArgs) (*effectConnection, error) {
+	return loadEffects(ctx, args, func(q *history.EffectsQ) {
+		q.ForTransaction(r.res.Hash)
+	})
+}
+
+// loadTransaction loads the transaction whose hash is `hash`, or nil if it is
+// not in history.
+func loadTransaction(ctx context.Context, hash string) (*transactionResolver, error) {
+	err := charge(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	q := envFromContext(ctx).historyQ
+	var record history.Transaction
+	err = q.TransactionByHash(&record, hash)
+	if q.NoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r := &transactionResolver{}
+	err = resourceadapter.PopulateTransaction(ctx, &r.res, record)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// loadTransactions loads the connection of the transactions selected by
+// `filter`.
+func loadTransactions(
+	ctx context.Context,
+	args pageArgs,
+	filter func(*history.TransactionsQ),
+) (*transactionConnection, error) {
+	pq, err := args.pageQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := envFromContext(ctx).historyQ.Transactions()
+	filter(txs)
+
+	var records []history.Transaction
+	err = txs.Page(pq).Select(&records)
+	if err != nil {
+		return nil, err
+	}
+
+	n, hasNextPage := pageSize(pq, len(records))
+	conn := &transactionConnection{edges: make([]*transactionEdge, 0, n)}
+	cursors := make([]string, n)
+	for i, record := range records[:n] {
+		r := &transactionResolver{}
+		err = resourceadapter.PopulateTransaction(ctx, &r.res, record)
+		if err != nil {
+			return nil, err
+		}
+
+		conn.edges = append(conn.edges, &transactionEdge{node: r})
+		cursors[i] = r.res.PT
+	}
+	conn.pageInfo = newPageInfo(cursors, hasNextPage)
+
+	return conn, nil
+}
+
+type ledgerResolver struct {
+	res horizon.Ledger
+}
+
+func (r *ledgerResolver) ID() gographql.ID            { return gographql.ID(r.res.ID) }
+func (r *ledgerResolver) PagingToken() string         { return r.res.PT }
+func (r *ledgerResolver) Hash() string                { return r.res.Hash }
+func (r *ledgerResolver) PrevHash() *string           { return optional(r.res.PrevHash) }
+func (r *ledgerResolver) Sequence() int32             { return r.res.Sequence }
+func (r *ledgerResolver) TransactionCount() int32     { return r.res.TransactionCount }
+func (r *ledgerResolver) OperationCount() int32       { return r.res.OperationCount }
+func (r *ledgerResolver) ClosedAt() gographql.Time    { return gographql.Time{Time: r.res.ClosedAt} }
+func (r *ledgerResolver) TotalCoins() string          { return r.res.TotalCoins }
+func (r *ledgerResolver) FeePool() string             { return r.res.FeePool }
+func (r *ledgerResolver) BaseFeeInStroops() int32     { return r.res.BaseFee }
+func (r *ledgerResolver) BaseReserveInStroops() int32 { return r.res.BaseReserve }
+func (r *ledgerResolver) MaxTxSetSize() int32         { return r.res.MaxTxSetSize }
+func (r *ledgerResolver) ProtocolVersion() int32      { return r.res.ProtocolVersion }
+func (r *ledgerResolver) HeaderXdr() string           { return r.res.HeaderXDR }
+
+func (r *ledgerResolver) Transactions(ctx context.Context, args pageArgs) (*transactionConnection, error) {
+	return loadTransactions(ctx, args, func(q *history.TransactionsQ) {
+		q.ForLedger(r.res.Sequence)
+	})
+}
+
+func (r *ledgerResolver) Operations(ctx context.Context, args pageArgs) (*operationConnection, error) {
+	return loadOperations(ctx, args, func(q *history.OperationsQ) {
+		q.ForLedger(r.res.Sequence)
+	})
+}
+
+func (r *ledgerResolver) Payments(ctx context.Context, args pageArgs) (*operationConnection, error) {
+	return loadOperations(ctx, args, func(q *history.OperationsQ) {
+		q.ForLedger(r.res.Sequence).OnlyPayments()
+	})
+}
+
+func (r *ledgerResolver) Effects(ctx context.Context, args pageArgs) (*effectConnection, error) {
+	return loadEffects(ctx, args, func(q *history.EffectsQ) {
+		q.ForLedger(r.res.Sequence)
+	})
+}
+
+// loadLedger loads the ledger whose sequence is `seq`, or nil if it is not in
+// history.
+func loadLedger(ctx context.Context, seq int32) (*ledgerResolver, error) {
+	err := charge(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	q := envFromContext(ctx).historyQ
+	var record history.Ledger
+	err = q.LedgerBySequence(&record, seq)
+	if q.NoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r := &ledgerResolver{}
+	resourceadapter.PopulateLedger(ctx, &r.res, record)
+	return r, nil
+}
+
+// optional returns nil for the empty string, which resources omit.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
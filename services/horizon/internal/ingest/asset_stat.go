@@ -3,6 +3,7 @@ package ingest
 import (
 	"database/sql"
 	"strings"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/core"
@@ -21,6 +22,10 @@ func (assetStats *AssetStats) init() {
 			"num_accounts",
 			"flags",
 			"toml",
+			"num_unauthorized_accounts",
+			"unauthorized_amount",
+			"amount_in_offers",
+			"num_holders_above_threshold",
 		},
 	}
 
@@ -102,11 +107,13 @@ func (assetStats *AssetStats) IngestOperation(op *xdr.Operation, source *xdr.Acc
 	return nil
 }
 
-// UpdateAssetStats updates the db with the latest asset stats for the assets that were modified
+// UpdateAssetStats updates the db with the latest asset stats for the assets
+// that were modified, recording them as well as today's stats in the daily
+// history of each asset.
 func (assetStats *AssetStats) UpdateAssetStats() error {
 	assetStats.initOnce.Do(assetStats.init)
 
-	hasValue := false
+	var stats []*history.AssetStat
 	for _, asset := range assetStats.toUpdate {
 		assetStat, err := assetStats.computeAssetStat(&asset)
 		if err != nil {
@@ -114,28 +121,48 @@ func (assetStats *AssetStats) UpdateAssetStats() error {
 		}
 
 		if assetStat != nil {
-			hasValue = true
+			stats = append(stats, assetStat)
 			assetStats.batchInsertBuilder.Values(
 				assetStat.ID,
 				assetStat.Amount,
 				assetStat.NumAccounts,
 				assetStat.Flags,
 				assetStat.Toml,
+				assetStat.NumUnauthorizedAccounts,
+				assetStat.UnauthorizedAmount,
+				assetStat.AmountInOffers,
+				assetStat.NumHoldersAboveThreshold,
 			)
 		}
 	}
 
-	if hasValue {
-		// perform a delete first since upsert is not supported if postgres < 9.5
-		err := errors.Wrap(assetStats.deleteRows(assetStats.HistorySession), "Error deleting asset_stats row")
+	if len(stats) == 0 {
+		return nil
+	}
+
+	// perform a delete first since upsert is not supported if postgres < 9.5
+	err := errors.Wrap(assetStats.deleteRows(assetStats.HistorySession), "Error deleting asset_stats row")
+	if err != nil {
+		return err
+	}
+
+	// can perform a direct upsert if postgres > 9.4
+	// is.Ingestion.assetStats = is.Ingestion.assetStats.
+	// 	Suffix("ON CONFLICT (id) DO UPDATE SET (amount, num_accounts, flags, toml) = (excluded.amount, excluded.num_accounts, excluded.flags, excluded.toml)")
+	err = errors.Wrap(assetStats.batchInsertBuilder.Exec(assetStats.HistorySession), "Error inserting asset_stats row")
+	if err != nil {
+		return err
+	}
+
+	// stellar-core only knows the current state of the ledger, so the stats
+	// are recorded as those of the current day, whichever ledgers were ingested.
+	historyQ := &history.Q{Session: assetStats.HistorySession}
+	now := time.Now()
+	for _, stat := range stats {
+		err = historyQ.UpdateAssetDailyStats(*stat, now)
 		if err != nil {
-			return err
+			return errors.Wrap(err, "Error updating daily asset stats")
 		}
-
-		// can perform a direct upsert if postgres > 9.4
-		// is.Ingestion.assetStats = is.Ingestion.assetStats.
-		// 	Suffix("ON CONFLICT (id) DO UPDATE SET (amount, num_accounts, flags, toml) = (excluded.amount, excluded.num_accounts, excluded.flags, excluded.toml)")
-		return errors.Wrap(assetStats.batchInsertBuilder.Exec(assetStats.HistorySession), "Error inserting asset_stats row")
 	}
 
 	return nil
@@ -211,29 +238,53 @@ func (assetStats *AssetStats) computeAssetStat(asset *xdr.Asset) (*history.Asset
 		return nil, errors.Wrap(err, "asset.Extract error")
 	}
 
-	numAccounts, amount, err := statTrustlinesInfo(assetStats.CoreSession, assetType, assetCode, assetIssuer)
+	trustlines, err := statTrustlinesInfo(
+		assetStats.CoreSession,
+		assetType,
+		assetCode,
+		assetIssuer,
+		assetStats.HolderThreshold,
+	)
 	if err != nil {
 		return nil, errors.Wrap(err, "statTrustlinesInfo error")
 	}
 
+	coreQ := &core.Q{Session: assetStats.CoreSession}
+	amountInOffers, err := coreQ.AmountInOffersForAsset(int32(assetType), assetCode, assetIssuer)
+	if err != nil {
+		return nil, errors.Wrap(err, "coreQ.AmountInOffersForAsset error")
+	}
+
 	flags, toml, err := statAccountInfo(assetStats.CoreSession, assetIssuer)
 	if err != nil {
 		return nil, errors.Wrap(err, "statAccountInfo error")
 	}
 
 	return &history.AssetStat{
-		ID:          assetID,
-		Amount:      amount,
-		NumAccounts: numAccounts,
-		Flags:       flags,
-		Toml:        toml,
+		ID:                       assetID,
+		Amount:                   trustlines.AuthorizedAmount,
+		NumAccounts:              trustlines.NumAuthorized,
+		Flags:                    flags,
+		Toml:                     toml,
+		NumUnauthorizedAccounts:  trustlines.NumUnauthorized,
+		UnauthorizedAmount:       trustlines.UnauthorizedAmount,
+		AmountInOffers:           amountInOffers,
+		NumHoldersAboveThreshold: trustlines.NumHoldersAboveThreshold,
 	}, nil
 }
 
 // statTrustlinesInfo fetches all the stats from the trustlines table
-func statTrustlinesInfo(coreSession *db.Session, assetType xdr.AssetType, assetCode string, assetIssuer string) (int32, string, error) {
+func statTrustlinesInfo(
+	coreSession *db.Session,
+	assetType xdr.AssetType,
+	assetCode string,
+	assetIssuer string,
+	holderThreshold xdr.Int64,
+) (core.TrustlineStats, error) {
+	var stats core.TrustlineStats
 	coreQ := &core.Q{Session: coreSession}
-	return coreQ.BalancesForAsset(int32(assetType), assetCode, assetIssuer)
+	err := coreQ.TrustlineStatsForAsset(&stats, int32(assetType), assetCode, assetIssuer, holderThreshold)
+	return stats, err
 }
 
 // statAccountInfo fetches all the stats from the accounts table
@@ -120,11 +120,11 @@ func TestStatTrustlinesInfo(t *testing.T) {
 			session := &db.Session{DB: tt.CoreDB}
 
 			for i, asset := range kase.assetState {
-				numAccounts, amount, err := statTrustlinesInfo(session, asset.assetType, asset.assetCode, asset.assetIssuer)
+				stats, err := statTrustlinesInfo(session, asset.assetType, asset.assetCode, asset.assetIssuer, 1)
 
 				tt.Require.NoError(err)
-				tt.Assert.Equal(asset.wantNumAccounts, numAccounts, fmt.Sprintf("asset index: %d", i))
-				tt.Assert.Equal(asset.wantAmount, amount, fmt.Sprintf("asset index: %d", i))
+				tt.Assert.Equal(asset.wantNumAccounts, stats.NumAuthorized, fmt.Sprintf("asset index: %d", i))
+				tt.Assert.Equal(asset.wantAmount, stats.AuthorizedAmount, fmt.Sprintf("asset index: %d", i))
 			}
 		})
 	}
@@ -54,6 +54,23 @@ func (c *Cursor) Ledger() *core.LedgerHeader {
 	return &c.data.Header
 }
 
+// LedgerEntryChanges returns every ledger entry change that occurred in the
+// current ledger, in the order stellar-core applied them: the fees for every
+// transaction are charged first, followed by the changes produced by each
+// transaction (failed transactions included).
+func (c *Cursor) LedgerEntryChanges() (ret []xdr.LedgerEntryChange) {
+	for i := range c.data.TransactionFees {
+		ret = append(ret, c.data.TransactionFees[i].Changes...)
+	}
+
+	for i := range c.data.Transactions {
+		m := meta.Bundle{TransactionMeta: c.data.Transactions[i].ResultMeta}
+		ret = append(ret, m.TransactionChanges()...)
+	}
+
+	return
+}
+
 // LedgerID returns the current ledger's id, as used by the history system.
 func (c *Cursor) LedgerID() int64 {
 	return toid.New(c.lg, 0, 0).ToInt64()
@@ -227,6 +244,15 @@ func (c *Cursor) TransactionMetaBundle() *meta.Bundle {
 	}
 }
 
+// Remaining returns the number of ledgers in the cursor's range that come
+// after the current ledger.
+func (c *Cursor) Remaining() int32 {
+	if c.FirstLedger > c.LastLedger {
+		return c.lg - c.LastLedger
+	}
+	return c.LastLedger - c.lg
+}
+
 // SuccessfulLedgerOperationCount returns the count of operations in the current ledger
 func (c *Cursor) SuccessfulLedgerOperationCount() (ret int) {
 	for i := range c.data.Transactions {
@@ -66,12 +66,14 @@ func TestCursor(t *testing.T) {
 
 	tt.Require.True(c.NextLedger())
 	tt.Require.Equal(uint32(10), c.Ledger().Sequence)
+	tt.Require.Equal(int32(3), c.Remaining())
 	tt.Require.True(c.NextLedger())
 	tt.Require.Equal(uint32(9), c.Ledger().Sequence)
 	tt.Require.True(c.NextLedger())
 	tt.Require.Equal(uint32(8), c.Ledger().Sequence)
 	tt.Require.True(c.NextLedger())
 	tt.Require.Equal(uint32(7), c.Ledger().Sequence)
+	tt.Require.Equal(int32(0), c.Remaining())
 
 	tt.Require.False(c.NextLedger())
 
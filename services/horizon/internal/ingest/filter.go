@@ -0,0 +1,189 @@
+package ingest
+
+import (
+	"strings"
+
+	"github.com/lomocoin/stellar-go/meta"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/core"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/ingest/participants"
+	"github.com/lomocoin/stellar-go/support/errors"
+	"github.com/lomocoin/stellar-go/xdr"
+)
+
+// NewFilter builds a filter allowing the provided account addresses and
+// assets.  Assets are given either as `native` or as `CODE:ISSUER`.
+func NewFilter(accounts, assets []string) (Filter, error) {
+	var result Filter
+
+	for _, addy := range accounts {
+		var aid xdr.AccountId
+		err := aid.SetAddress(addy)
+		if err != nil {
+			return Filter{}, errors.Wrapf(err, "invalid account %q", addy)
+		}
+
+		if result.Accounts == nil {
+			result.Accounts = map[string]bool{}
+		}
+		result.Accounts[addy] = true
+	}
+
+	for _, s := range assets {
+		var asset xdr.Asset
+
+		if s == "native" {
+			asset.SetNative()
+			result.Assets = append(result.Assets, asset)
+			continue
+		}
+
+		parts := strings.Split(s, ":")
+		if len(parts) != 2 {
+			return Filter{}, errors.Errorf("invalid asset %q: expected native or CODE:ISSUER", s)
+		}
+
+		var issuer xdr.AccountId
+		err := issuer.SetAddress(parts[1])
+		if err != nil {
+			return Filter{}, errors.Wrapf(err, "invalid asset issuer %q", parts[1])
+		}
+
+		err = asset.SetCredit(parts[0], issuer)
+		if err != nil {
+			return Filter{}, errors.Wrapf(err, "invalid asset code %q", parts[0])
+		}
+		result.Assets = append(result.Assets, asset)
+	}
+
+	return result, nil
+}
+
+// AllowsAccount returns true if the history of `aid` is recorded.
+func (f Filter) AllowsAccount(aid xdr.AccountId) bool {
+	if !f.Enabled() {
+		return true
+	}
+
+	return f.Accounts[aid.Address()]
+}
+
+// AllowsAsset returns true if the history of `asset` is recorded.
+func (f Filter) AllowsAsset(asset xdr.Asset) bool {
+	if !f.Enabled() {
+		return true
+	}
+
+	for _, allowed := range f.Assets {
+		if allowed.Equals(asset) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsStateChange returns true if the state history of the account or
+// trustline entry identified by `key` is recorded.
+func (f Filter) AllowsStateChange(key xdr.LedgerKey) bool {
+	if !f.Enabled() {
+		return true
+	}
+
+	if f.AllowsAccount(stateAccountID(key)) {
+		return true
+	}
+
+	return key.Type == xdr.LedgerEntryTypeTrustline &&
+		f.AllowsAsset(key.MustTrustLine().Asset)
+}
+
+// AllowsTransaction returns true if the history of `tx` is recorded, that is
+// when one of its participants is an allowed account or one of the assets it
+// involves is allowed.
+func (f Filter) AllowsTransaction(tx *core.Transaction, fee *core.TransactionFee) (bool, error) {
+	if !f.Enabled() {
+		return true, nil
+	}
+
+	if len(f.Accounts) > 0 {
+		p, err := participants.ForTransaction(&tx.Envelope.Tx, &tx.ResultMeta, &fee.Changes)
+		if err != nil {
+			return false, errors.Wrap(err, "participants.ForTransaction error")
+		}
+
+		for _, aid := range p {
+			if f.Accounts[aid.Address()] {
+				return true, nil
+			}
+		}
+	}
+
+	if len(f.Assets) > 0 {
+		for _, asset := range transactionAssets(tx) {
+			if f.AllowsAsset(asset) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// Enabled returns true if the filter restricts the history recorded.
+func (f Filter) Enabled() bool {
+	return len(f.Accounts) > 0 || len(f.Assets) > 0
+}
+
+// transactionAssets returns the assets involved in `tx`: those named by its
+// operations, as well as those of the trustlines and offers it changed.
+func transactionAssets(tx *core.Transaction) []xdr.Asset {
+	var result []xdr.Asset
+
+	for _, op := range tx.Envelope.Tx.Operations {
+		source := tx.Envelope.Tx.SourceAccount
+		if op.SourceAccount != nil {
+			source = *op.SourceAccount
+		}
+
+		switch op.Body.Type {
+		case xdr.OperationTypePayment:
+			result = append(result, op.Body.MustPaymentOp().Asset)
+		case xdr.OperationTypePathPayment:
+			pp := op.Body.MustPathPaymentOp()
+			result = append(result, pp.SendAsset, pp.DestAsset)
+			result = append(result, pp.Path...)
+		case xdr.OperationTypeManageOffer:
+			mo := op.Body.MustManageOfferOp()
+			result = append(result, mo.Selling, mo.Buying)
+		case xdr.OperationTypeCreatePassiveOffer:
+			po := op.Body.MustCreatePassiveOfferOp()
+			result = append(result, po.Selling, po.Buying)
+		case xdr.OperationTypeChangeTrust:
+			result = append(result, op.Body.MustChangeTrustOp().Line)
+		case xdr.OperationTypeAllowTrust:
+			result = append(result, op.Body.MustAllowTrustOp().Asset.ToAsset(source))
+		}
+	}
+
+	m := meta.Bundle{TransactionMeta: tx.ResultMeta}
+	for _, change := range m.TransactionChanges() {
+		var entry *xdr.LedgerEntry
+		switch change.Type {
+		case xdr.LedgerEntryChangeTypeLedgerEntryCreated:
+			entry = change.Created
+		case xdr.LedgerEntryChangeTypeLedgerEntryUpdated:
+			entry = change.Updated
+		case xdr.LedgerEntryChangeTypeLedgerEntryState:
+			entry = change.State
+		}
+
+		switch {
+		case change.EntryType() == xdr.LedgerEntryTypeTrustline:
+			result = append(result, change.LedgerKey().MustTrustLine().Asset)
+		case entry != nil && entry.Data.Type == xdr.LedgerEntryTypeOffer:
+			offer := entry.Data.MustOffer()
+			result = append(result, offer.Selling, offer.Buying)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,64 @@
+package ingest
+
+import (
+	"testing"
+
+	"github.com/lomocoin/stellar-go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+	const (
+		allowed = "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"
+		other   = "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU"
+	)
+
+	var aid, oid xdr.AccountId
+	require.NoError(t, aid.SetAddress(allowed))
+	require.NoError(t, oid.SetAddress(other))
+
+	var usd, eur, native xdr.Asset
+	require.NoError(t, usd.SetCredit("USD", oid))
+	require.NoError(t, eur.SetCredit("EUR", oid))
+	require.NoError(t, native.SetNative())
+
+	// the zero value allows everything
+	var f Filter
+	assert.False(t, f.Enabled())
+	assert.True(t, f.AllowsAccount(oid))
+	assert.True(t, f.AllowsAsset(eur))
+
+	f, err := NewFilter([]string{allowed}, []string{"USD:" + other})
+	require.NoError(t, err)
+	assert.True(t, f.Enabled())
+
+	assert.True(t, f.AllowsAccount(aid))
+	assert.False(t, f.AllowsAccount(oid))
+	assert.True(t, f.AllowsAsset(usd))
+	assert.False(t, f.AllowsAsset(eur))
+	assert.False(t, f.AllowsAsset(native))
+
+	assert.True(t, f.AllowsStateChange(aid.LedgerKey()))
+	assert.False(t, f.AllowsStateChange(oid.LedgerKey()))
+
+	trustline := func(account xdr.AccountId, asset xdr.Asset) xdr.LedgerKey {
+		var key xdr.LedgerKey
+		require.NoError(t, key.SetTrustline(account, asset))
+		return key
+	}
+	assert.True(t, f.AllowsStateChange(trustline(aid, eur)))
+	assert.True(t, f.AllowsStateChange(trustline(oid, usd)))
+	assert.False(t, f.AllowsStateChange(trustline(oid, eur)))
+
+	f, err = NewFilter(nil, []string{"native"})
+	require.NoError(t, err)
+	assert.True(t, f.AllowsAsset(native))
+
+	_, err = NewFilter([]string{"GABC"}, nil)
+	assert.Error(t, err)
+	_, err = NewFilter(nil, []string{"USD"})
+	assert.Error(t, err)
+	_, err = NewFilter(nil, []string{"TOOLONGASSETCODE:" + other})
+	assert.Error(t, err)
+}
@@ -12,18 +12,40 @@ import (
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/core"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/sqx"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/toid"
 	"github.com/lomocoin/stellar-go/support/errors"
 	"github.com/lomocoin/stellar-go/xdr"
 )
 
 // ClearAll clears the entire history database
 func (ingest *Ingestion) ClearAll() error {
-	return ingest.Clear(0, math.MaxInt64)
+	err := ingest.clear(0, math.MaxInt64)
+	if err != nil {
+		return err
+	}
+
+	_, err = ingest.DB.ExecRaw("DELETE FROM history_trade_rollups")
+	if err != nil {
+		return errors.Wrap(err, "Error clearing history_trade_rollups")
+	}
+
+	return nil
 }
 
 // Clear removes a range of data from the history database, exclusive of the end
 // id provided.
 func (ingest *Ingestion) Clear(start int64, end int64) error {
+	// the rollups of the cleared trades are rebuilt once the range has been
+	// ingested again
+	err := ingest.markTradeRollups(start, end)
+	if err != nil {
+		return errors.Wrap(err, "Error loading trade rollups")
+	}
+
+	return ingest.clear(start, end)
+}
+
+func (ingest *Ingestion) clear(start int64, end int64) error {
 	clear := ingest.DB.DeleteRange
 
 	err := clear(start, end, "history_effects", "history_operation_id")
@@ -54,15 +76,92 @@ func (ingest *Ingestion) Clear(start int64, end int64) error {
 	if err != nil {
 		return errors.Wrap(err, "Error clearing history_trades")
 	}
+	err = clear(start, end, "history_offer_events", "history_operation_id")
+	if err != nil {
+		return errors.Wrap(err, "Error clearing history_offer_events")
+	}
+	err = clear(start, end, "history_asset_payments", "history_operation_id")
+	if err != nil {
+		return errors.Wrap(err, "Error clearing history_asset_payments")
+	}
 
+	// state history is keyed by ledger sequence rather than by toid
+	startSeq := int64(toid.Parse(start).LedgerSequence)
+	endSeq := int64(toid.Parse(end).LedgerSequence)
+	err = clear(startSeq, endSeq, "history_account_states", "ledger_sequence")
+	if err != nil {
+		return errors.Wrap(err, "Error clearing history_account_states")
+	}
+	err = clear(startSeq, endSeq, "history_trustline_states", "ledger_sequence")
+	if err != nil {
+		return errors.Wrap(err, "Error clearing history_trustline_states")
+	}
+
+	return nil
+}
+
+// AccountState adds a new row into the `history_account_states` table,
+// recording the state of the account identified by `key` as of ledger `seq`.
+// A nil `entry` records that the account did not exist.
+func (ingest *Ingestion) AccountState(seq int32, key xdr.LedgerKey, entry *xdr.LedgerEntry) error {
+	var (
+		balance interface{}
+		data    interface{}
+	)
+
+	if entry != nil {
+		enc, err := xdr.MarshalBase64(entry)
+		if err != nil {
+			return errors.Wrap(err, "Error marshaling ledger entry")
+		}
+
+		balance = int64(entry.Data.MustAccount().Balance)
+		data = enc
+	}
+
+	aid := key.MustAccount().AccountId
+	ingest.builders[AccountStatesTableName].Values(
+		aid.Address(),
+		seq,
+		balance,
+		data,
+	)
 	return nil
 }
 
 // Close finishes the current transaction and finishes this ingestion.
 func (ingest *Ingestion) Close() error {
+	err := ingest.rebuildTradeRollups()
+	if err != nil {
+		return errors.Wrap(err, "Error rebuilding trade rollups")
+	}
+
 	return ingest.commit()
 }
 
+// AssetPayment adds a new row into the `history_asset_payments` table,
+// recording that the operation `opid` paid `amount` of `asset`.
+func (ingest *Ingestion) AssetPayment(
+	opid int64,
+	asset xdr.Asset,
+	amount xdr.Int64,
+	ledgerClosedAt int64,
+) error {
+	q := history.Q{Session: ingest.DB}
+	assetID, err := q.GetCreateAssetID(asset)
+	if err != nil {
+		return errors.Wrap(err, "failed to get asset id")
+	}
+
+	ingest.builders[AssetPaymentsTableName].Values(
+		opid,
+		assetID,
+		amount,
+		time.Unix(ledgerClosedAt, 0).UTC(),
+	)
+	return nil
+}
+
 // Effect adds a new row into the `history_effects` table.
 func (ingest *Ingestion) Effect(address Address, opid int64, order int, typ history.EffectType, details interface{}) error {
 	djson, err := json.Marshal(details)
@@ -74,36 +173,59 @@ func (ingest *Ingestion) Effect(address Address, opid int64, order int, typ hist
 	return nil
 }
 
+// flushedTables are the history tables written to by an ingestion, in the
+// order they are flushed.
+var flushedTables = []TableName{
+	EffectsTableName,
+	LedgersTableName,
+	OperationParticipantsTableName,
+	OperationsTableName,
+	OfferEventsTableName,
+	TradesTableName,
+	TransactionParticipantsTableName,
+	TransactionsTableName,
+	AccountStatesTableName,
+	TrustlineStatesTableName,
+	AssetPaymentsTableName,
+}
+
 // Flush writes the currently buffered rows to the db, and if successful
 // starts a new transaction.
 func (ingest *Ingestion) Flush() error {
-	tables := []TableName{
-		EffectsTableName,
-		LedgersTableName,
-		OperationParticipantsTableName,
-		OperationsTableName,
-		TradesTableName,
-		TransactionParticipantsTableName,
-		TransactionsTableName,
-	}
 	// Update IDs for accounts
-	err := ingest.UpdateAccountIDs(tables)
+	err := ingest.UpdateAccountIDs(flushedTables)
 	if err != nil {
 		return errors.Wrap(err, "Error while updating account ids")
 	}
 
-	for _, tableName := range tables {
+	written := map[TableName]int{}
+	for _, tableName := range flushedTables {
+		written[tableName] = len(ingest.builders[tableName].rows)
+
 		err = ingest.builders[tableName].Exec(ingest.DB)
 		if err != nil {
 			return errors.Wrap(err, fmt.Sprintf("Error adding values while inserting to %s", tableName))
 		}
 	}
 
+	err = ingest.rebuildTradeRollups()
+	if err != nil {
+		return errors.Wrap(err, "Error rebuilding trade rollups")
+	}
+
 	err = ingest.commit()
 	if err != nil {
 		return errors.Wrap(err, "ingest.commit error")
 	}
 
+	if ingest.Metrics != nil {
+		for tableName, count := range written {
+			if counter, ok := ingest.Metrics.RowsWritten[tableName]; ok {
+				counter.Inc(int64(count))
+			}
+		}
+	}
+
 	return ingest.Start()
 }
 
@@ -175,6 +297,7 @@ func (ingest *Ingestion) Ledger(
 	header *core.LedgerHeader,
 	txs int,
 	ops int,
+	filtered bool,
 ) {
 	ingest.builders[LedgersTableName].Values(
 		CurrentVersion,
@@ -194,7 +317,52 @@ func (ingest *Ingestion) Ledger(
 		ops,
 		header.Data.LedgerVersion,
 		header.DataXDR(),
+		filtered,
+	)
+}
+
+// OfferEvent adds a new row into the `history_offer_events` table, recording
+// the state of `offer` after it was changed by the operation `opid`.
+func (ingest *Ingestion) OfferEvent(
+	opid int64,
+	seq int32,
+	typ history.OfferEventType,
+	offer xdr.OfferEntry,
+) error {
+	var (
+		sellingType, buyingType     xdr.AssetType
+		sellingCode, buyingCode     string
+		sellingIssuer, buyingIssuer string
 	)
+
+	err := offer.Selling.Extract(&sellingType, &sellingCode, &sellingIssuer)
+	if err != nil {
+		return errors.Wrap(err, "Error extracting selling asset")
+	}
+	err = offer.Buying.Extract(&buyingType, &buyingCode, &buyingIssuer)
+	if err != nil {
+		return errors.Wrap(err, "Error extracting buying asset")
+	}
+
+	ingest.builders[OfferEventsTableName].Values(
+		int64(offer.OfferId),
+		opid,
+		seq,
+		typ,
+		offer.SellerId.Address(),
+		sellingType,
+		null.NewString(sellingCode, sellingCode != ""),
+		null.NewString(sellingIssuer, sellingIssuer != ""),
+		buyingType,
+		null.NewString(buyingCode, buyingCode != ""),
+		null.NewString(buyingIssuer, buyingIssuer != ""),
+		offer.Amount,
+		offer.Price.N,
+		offer.Price.D,
+		float64(offer.Price.N)/float64(offer.Price.D),
+		int32(offer.Flags),
+	)
+	return nil
 }
 
 // Operation ingests the provided operation data into a new row in the
@@ -240,6 +408,7 @@ func (ingest *Ingestion) Start() (err error) {
 	}
 
 	ingest.createInsertBuilders()
+	ingest.staleRollups = map[history.TradeRollupBucket]bool{}
 
 	return
 }
@@ -299,6 +468,17 @@ func (ingest *Ingestion) Trade(
 		counterAmount,
 		soldAssetId < boughtAssetId,
 	)
+
+	// the rollups containing the trade are rebuilt once it has been written
+	ms := ledgerClosedAt * 1000
+	for _, resolution := range history.TradeRollupResolutions {
+		ingest.staleRollups[history.TradeRollupBucket{
+			Resolution:     resolution,
+			BaseAssetID:    baseAssetId,
+			CounterAssetID: counterAssetId,
+			Timestamp:      ms - ms%resolution,
+		}] = true
+	}
 	return nil
 }
 
@@ -343,6 +523,46 @@ func (ingest *Ingestion) TransactionParticipants(tx int64, aids []xdr.AccountId)
 	}
 }
 
+// TrustlineState adds a new row into the `history_trustline_states` table,
+// recording the state of the trustline identified by `key` as of ledger
+// `seq`.  A nil `entry` records that the trustline did not exist.
+func (ingest *Ingestion) TrustlineState(seq int32, key xdr.LedgerKey, entry *xdr.LedgerEntry) error {
+	var (
+		balance     interface{}
+		data        interface{}
+		assetType   xdr.AssetType
+		assetCode   string
+		assetIssuer string
+	)
+
+	tl := key.MustTrustLine()
+	err := tl.Asset.Extract(&assetType, &assetCode, &assetIssuer)
+	if err != nil {
+		return errors.Wrap(err, "Error extracting asset")
+	}
+
+	if entry != nil {
+		enc, err := xdr.MarshalBase64(entry)
+		if err != nil {
+			return errors.Wrap(err, "Error marshaling ledger entry")
+		}
+
+		balance = int64(entry.Data.MustTrustLine().Balance)
+		data = enc
+	}
+
+	ingest.builders[TrustlineStatesTableName].Values(
+		tl.AccountId.Address(),
+		int32(assetType),
+		assetCode,
+		assetIssuer,
+		seq,
+		balance,
+		data,
+	)
+	return nil
+}
+
 func (ingest *Ingestion) createInsertBuilders() {
 	ingest.builders = make(map[TableName]*BatchInsertBuilder)
 
@@ -366,6 +586,7 @@ func (ingest *Ingestion) createInsertBuilders() {
 			"operation_count",
 			"protocol_version",
 			"ledger_header",
+			"history_filtered",
 		},
 	}
 
@@ -448,6 +669,91 @@ func (ingest *Ingestion) createInsertBuilders() {
 			"base_is_seller",
 		},
 	}
+
+	ingest.builders[OfferEventsTableName] = &BatchInsertBuilder{
+		TableName: OfferEventsTableName,
+		Columns: []string{
+			"offer_id",
+			"history_operation_id",
+			"ledger_sequence",
+			"type",
+			"seller_id",
+			"selling_asset_type",
+			"selling_asset_code",
+			"selling_asset_issuer",
+			"buying_asset_type",
+			"buying_asset_code",
+			"buying_asset_issuer",
+			"amount",
+			"pricen",
+			"priced",
+			"price",
+			"flags",
+		},
+	}
+
+	ingest.builders[AssetPaymentsTableName] = &BatchInsertBuilder{
+		TableName: AssetPaymentsTableName,
+		Columns: []string{
+			"history_operation_id",
+			"asset_id",
+			"amount",
+			"ledger_closed_at",
+		},
+	}
+
+	ingest.builders[AccountStatesTableName] = &BatchInsertBuilder{
+		TableName: AccountStatesTableName,
+		Columns: []string{
+			"account_id",
+			"ledger_sequence",
+			"balance",
+			"entry",
+		},
+	}
+
+	ingest.builders[TrustlineStatesTableName] = &BatchInsertBuilder{
+		TableName: TrustlineStatesTableName,
+		Columns: []string{
+			"account_id",
+			"asset_type",
+			"asset_code",
+			"asset_issuer",
+			"ledger_sequence",
+			"balance",
+			"entry",
+		},
+	}
+}
+
+// rebuildTradeRollups rebuilds the trade rollups marked as stale.
+func (ingest *Ingestion) rebuildTradeRollups() error {
+	q := history.Q{Session: ingest.DB}
+	for bucket := range ingest.staleRollups {
+		err := q.RebuildTradeRollup(bucket)
+		if err != nil {
+			return err
+		}
+	}
+
+	ingest.staleRollups = map[history.TradeRollupBucket]bool{}
+	return nil
+}
+
+// markTradeRollups marks the rollup buckets containing trades from the
+// operations in the range [start, end) as needing to be rebuilt.
+func (ingest *Ingestion) markTradeRollups(start int64, end int64) error {
+	q := history.Q{Session: ingest.DB}
+	var buckets []history.TradeRollupBucket
+	err := q.TradeRollupBuckets(&buckets, start, end)
+	if err != nil {
+		return err
+	}
+
+	for _, bucket := range buckets {
+		ingest.staleRollups[bucket] = true
+	}
+	return nil
 }
 
 func (ingest *Ingestion) commit() error {
@@ -194,3 +194,29 @@ func TestTradeIngestTimestamp(t *testing.T) {
 
 	tt.Require.Equal(trades[len(trades)-1].LedgerCloseTime, ledgers[len(ledgers)-1].ClosedAt)
 }
+
+func TestTradeIngestRollups(t *testing.T) {
+	//ingest trade scenario and verify that every trade is counted in the
+	//rollups of each rollup resolution
+	tt := test.Start(t).ScenarioWithoutHorizon("trades")
+	defer tt.Finish()
+	s := ingest(tt, false)
+	tt.Require.NoError(s.Err)
+	q := history.Q{Session: s.Ingestion.DB}
+
+	var trades int64
+	err := q.GetRaw(&trades, "SELECT COUNT(*) FROM history_trades")
+	tt.Require.NoError(err)
+	tt.Require.True(trades > 0)
+
+	for _, resolution := range history.TradeRollupResolutions {
+		var rolledUp int64
+		err = q.GetRaw(
+			&rolledUp,
+			"SELECT COALESCE(SUM(count), 0) FROM history_trade_rollups WHERE resolution = ?",
+			resolution,
+		)
+		tt.Require.NoError(err)
+		tt.Assert.Equal(trades, rolledUp, "resolution %d", resolution)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	metrics "github.com/rcrowley/go-metrics"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/core"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
 	"github.com/lomocoin/stellar-go/support/db"
 	ilog "github.com/lomocoin/stellar-go/support/log"
 	"github.com/lomocoin/stellar-go/xdr"
@@ -25,7 +26,7 @@ const (
 	// Scripts, that have yet to be ported to this codebase can then be leveraged
 	// to re-ingest old data with the new algorithm, providing a seamless
 	// transition when the ingested data's structure changes.
-	CurrentVersion = 15
+	CurrentVersion = 16
 )
 
 // Address is a type of a param provided to BatchInsertBuilder that gets exchanged
@@ -35,14 +36,18 @@ type Address string
 type TableName string
 
 const (
+	AccountStatesTableName           TableName = "history_account_states"
+	AssetPaymentsTableName           TableName = "history_asset_payments"
 	AssetStatsTableName              TableName = "asset_stats"
 	EffectsTableName                 TableName = "history_effects"
 	LedgersTableName                 TableName = "history_ledgers"
+	OfferEventsTableName             TableName = "history_offer_events"
 	OperationParticipantsTableName   TableName = "history_operation_participants"
 	OperationsTableName              TableName = "history_operations"
 	TradesTableName                  TableName = "history_trades"
 	TransactionParticipantsTableName TableName = "history_transaction_participants"
 	TransactionsTableName            TableName = "history_transactions"
+	TrustlineStatesTableName         TableName = "history_trustline_states"
 )
 
 // Cursor iterates through a stellar core database's ledgers
@@ -74,6 +79,14 @@ type Config struct {
 	// EnableAssetStats is a feature flag that determines whether to calculate
 	// asset stats in this ingestion system.
 	EnableAssetStats bool
+
+	// AssetHolderThreshold is the balance from which an account holding
+	// an asset counts towards its `num_holders_above_threshold` stat.
+	AssetHolderThreshold xdr.Int64
+
+	// Filter restricts the history recorded by the ingestion system.  The zero
+	// value records the history of every transaction.
+	Filter Filter
 }
 
 // EffectIngestion is a helper struct to smooth the ingestion of effects.  this
@@ -87,6 +100,16 @@ type EffectIngestion struct {
 	parent      *Ingestion
 }
 
+// Filter restricts the history recorded by the ingestion system to the
+// transactions involving an allowed account or asset.  Ledgers are recorded
+// regardless, flagged as filtered when the filter is enabled.
+type Filter struct {
+	// Accounts is the set of allowed account addresses.
+	Accounts map[string]bool
+	// Assets is the list of allowed assets.
+	Assets []xdr.Asset
+}
+
 // LedgerBundle represents a single ledger's worth of novelty created by one
 // ledger close
 type LedgerBundle struct {
@@ -96,6 +119,23 @@ type LedgerBundle struct {
 	Transactions    []core.Transaction
 }
 
+// StateChange represents the net effect of a single ledger on a single account
+// or trustline ledger entry.  Before is the state of the entry prior to the
+// ledger and After is its state once the ledger closed; either is nil when the
+// entry did not exist at that point.
+type StateChange struct {
+	Key    xdr.LedgerKey
+	Before *xdr.LedgerEntry
+	After  *xdr.LedgerEntry
+}
+
+// StateChanges accumulates the account and trustline ledger entry changes
+// that occur within a ledger, collapsing them into one StateChange per entry.
+type StateChanges struct {
+	keys    []string
+	changes map[string]*StateChange
+}
+
 // System represents the data ingestion subsystem of horizon.
 type System struct {
 	// Config allows passing some configuration values to System.
@@ -120,8 +160,10 @@ type System struct {
 	// ledger.  0 represents "all ledgers".
 	HistoryRetentionCount uint
 
-	lock    sync.Mutex
-	current *Session
+	lock         sync.Mutex
+	current      *Session
+	running      sync.WaitGroup
+	shuttingDown bool
 }
 
 // IngesterMetrics tracks all the metrics for the ingestion subsystem
@@ -129,6 +171,23 @@ type IngesterMetrics struct {
 	ClearLedgerTimer  metrics.Timer
 	IngestLedgerTimer metrics.Timer
 	LoadLedgerTimer   metrics.Timer
+
+	// The phase timers below record the time spent in each phase of ingesting
+	// a single operation, transaction or ledger.
+	EffectsTimer                 metrics.Timer
+	FlushTimer                   metrics.Timer
+	OfferEventsTimer             metrics.Timer
+	OperationParticipantsTimer   metrics.Timer
+	StateChangesTimer            metrics.Timer
+	TradesTimer                  metrics.Timer
+	TransactionParticipantsTimer metrics.Timer
+
+	// RowsWritten counts the rows written to each history table.
+	RowsWritten map[TableName]metrics.Counter
+
+	// ReingestRemainingGauge is the number of ledgers left to be reingested by
+	// the running reingestion, if any.
+	ReingestRemainingGauge metrics.Gauge
 }
 
 // BatchInsertBuilder works like sq.InsertBuilder but has a better support for batching
@@ -147,6 +206,10 @@ type AssetStats struct {
 	CoreSession    *db.Session
 	HistorySession *db.Session
 
+	// HolderThreshold is the balance from which an account holding an asset
+	// counts towards its `num_holders_above_threshold` stat.
+	HolderThreshold xdr.Int64
+
 	batchInsertBuilder *BatchInsertBuilder
 	toUpdate           map[string]xdr.Asset
 	initOnce           sync.Once
@@ -156,8 +219,13 @@ type AssetStats struct {
 type Ingestion struct {
 	// DB is the sql connection to be used for writing any rows into the horizon
 	// database.
-	DB       *db.Session
+	DB *db.Session
+	// Metrics, when not nil, is where the count of rows written is recorded.
+	Metrics  *IngesterMetrics
 	builders map[TableName]*BatchInsertBuilder
+	// staleRollups are the trade rollup buckets that must be rebuilt before
+	// the next commit.
+	staleRollups map[history.TradeRollupBucket]bool
 }
 
 // Session represents a single attempt at ingesting data into the history
@@ -184,6 +252,9 @@ type Session struct {
 	// AssetStats calculates asset stats
 	AssetStats *AssetStats
 
+	// system is the ingestion system that started the session, if any.
+	system *System
+
 	//
 	// Results fields
 	//
@@ -209,6 +280,18 @@ func New(network string, coreURL string, core, horizon *db.Session, config Confi
 	i.Metrics.ClearLedgerTimer = metrics.NewTimer()
 	i.Metrics.IngestLedgerTimer = metrics.NewTimer()
 	i.Metrics.LoadLedgerTimer = metrics.NewTimer()
+	i.Metrics.EffectsTimer = metrics.NewTimer()
+	i.Metrics.FlushTimer = metrics.NewTimer()
+	i.Metrics.OfferEventsTimer = metrics.NewTimer()
+	i.Metrics.OperationParticipantsTimer = metrics.NewTimer()
+	i.Metrics.StateChangesTimer = metrics.NewTimer()
+	i.Metrics.TradesTimer = metrics.NewTimer()
+	i.Metrics.TransactionParticipantsTimer = metrics.NewTimer()
+	i.Metrics.ReingestRemainingGauge = metrics.NewGauge()
+	i.Metrics.RowsWritten = map[TableName]metrics.Counter{}
+	for _, table := range flushedTables {
+		i.Metrics.RowsWritten[table] = metrics.NewCounter()
+	}
 	return i
 }
 
@@ -230,15 +313,18 @@ func NewSession(i *System) *Session {
 	return &Session{
 		Config: i.Config,
 		Ingestion: &Ingestion{
-			DB: hdb,
+			DB:      hdb,
+			Metrics: &i.Metrics,
 		},
 		Network:          i.Network,
 		StellarCoreURL:   i.StellarCoreURL,
 		SkipCursorUpdate: i.SkipCursorUpdate,
 		Metrics:          &i.Metrics,
 		AssetStats: &AssetStats{
-			CoreSession:    cdb,
-			HistorySession: hdb,
+			CoreSession:     cdb,
+			HistorySession:  hdb,
+			HolderThreshold: i.Config.AssetHolderThreshold,
 		},
+		system: i,
 	}
 }
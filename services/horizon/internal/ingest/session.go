@@ -64,6 +64,16 @@ func (is *Session) Run() {
 		if is.Err != nil {
 			break
 		}
+
+		if is.system != nil && is.system.isShuttingDown() && is.Cursor.Remaining() > 0 {
+			// Horizon is shutting down: end the session with the ledger just
+			// ingested, committing the ledgers ingested so far rather than
+			// leaving them to be rolled back.
+			is.Cursor.LastLedger = is.Cursor.LedgerSequence()
+			log.WithField("last_ledger", is.Cursor.LastLedger).
+				Info("ingest: shutting down, committing the ledgers ingested so far")
+			break
+		}
 	}
 
 	if is.Config.EnableAssetStats && is.Err == nil {
@@ -132,9 +142,16 @@ func (is *Session) flush() {
 	if is.Err != nil {
 		return
 	}
+
+	start := time.Now()
 	is.Err = is.Ingestion.Flush()
 	if is.Err != nil {
 		is.Err = errors.Wrap(is.Err, "Ingestion.Flush error")
+		return
+	}
+
+	if is.Metrics != nil {
+		is.Metrics.FlushTimer.Update(time.Since(start))
 	}
 }
 
@@ -393,15 +410,26 @@ func (is *Session) ingestLedger() {
 		is.Cursor.Ledger(),
 		is.Cursor.SuccessfulTransactionCount(),
 		is.Cursor.SuccessfulLedgerOperationCount(),
+		is.Config.Filter.Enabled(),
 	)
 
 	for is.Cursor.NextTx() {
 		is.ingestTransaction()
 	}
 
+	stateStart := time.Now()
+	is.ingestStateChanges()
+	if is.Metrics != nil {
+		is.Metrics.StateChangesTimer.Update(time.Since(stateStart))
+	}
+
 	is.Ingested++
 	if is.Metrics != nil {
 		is.Metrics.IngestLedgerTimer.Update(time.Since(start))
+
+		if is.ClearExisting {
+			is.Metrics.ReingestRemainingGauge.Update(int64(is.Cursor.Remaining()))
+		}
 	}
 
 	return
@@ -425,9 +453,30 @@ func (is *Session) ingestOperation() {
 		return
 	}
 
+	start := time.Now()
 	is.ingestOperationParticipants()
+	if is.Metrics != nil {
+		is.Metrics.OperationParticipantsTimer.Update(time.Since(start))
+	}
+
+	start = time.Now()
 	is.ingestEffects()
+	if is.Metrics != nil {
+		is.Metrics.EffectsTimer.Update(time.Since(start))
+	}
+
+	start = time.Now()
 	is.ingestTrades()
+	if is.Metrics != nil {
+		is.Metrics.TradesTimer.Update(time.Since(start))
+	}
+
+	start = time.Now()
+	is.ingestOfferEvents()
+	if is.Metrics != nil {
+		is.Metrics.OfferEventsTimer.Update(time.Since(start))
+	}
+
 	if is.Config.EnableAssetStats && is.Err == nil {
 		is.Err = is.AssetStats.IngestOperation(
 			is.Cursor.Operation(),
@@ -439,6 +488,125 @@ func (is *Session) ingestOperation() {
 		is.Err = errors.Wrap(is.Err, "Cursor.AssetsModified.IngestOperation error")
 		return
 	}
+
+	is.ingestAssetPayment()
+}
+
+// ingestAssetPayment records the asset and amount received by the destination
+// of the current operation, if it is a payment of a non-native asset.  Unlike
+// the asset stats, payments are part of the history and so are recorded
+// whether asset stats are enabled or not.
+func (is *Session) ingestAssetPayment() {
+	if is.Err != nil {
+		return
+	}
+
+	var (
+		asset  xdr.Asset
+		amount xdr.Int64
+	)
+	switch is.Cursor.OperationType() {
+	case xdr.OperationTypePayment:
+		op := is.Cursor.Operation().Body.MustPaymentOp()
+		asset, amount = op.Asset, op.Amount
+	case xdr.OperationTypePathPayment:
+		op := is.Cursor.Operation().Body.MustPathPaymentOp()
+		asset, amount = op.DestAsset, op.DestAmount
+	default:
+		return
+	}
+
+	if asset.Type == xdr.AssetTypeAssetTypeNative {
+		return
+	}
+
+	is.Err = is.Ingestion.AssetPayment(
+		is.Cursor.OperationID(),
+		asset,
+		amount,
+		is.Cursor.Ledger().CloseTime,
+	)
+	if is.Err != nil {
+		is.Err = errors.Wrap(is.Err, "Ingestion.AssetPayment error")
+	}
+}
+
+// ingestOfferEvents records every change the current operation made to an
+// offer, classifying each change using the operation that caused it.
+func (is *Session) ingestOfferEvents() {
+	if is.Err != nil {
+		return
+	}
+
+	// the offer the source account asked to change, if any
+	var (
+		managedID uint64
+		cancelled bool
+	)
+	if is.Cursor.OperationType() == xdr.OperationTypeManageOffer {
+		op := is.Cursor.Operation().Body.MustManageOfferOp()
+		managedID = uint64(op.OfferId)
+		cancelled = op.Amount == 0
+	}
+	source := is.Cursor.OperationSourceAccount()
+	isManaged := func(offer xdr.OfferEntry) bool {
+		return managedID != 0 &&
+			uint64(offer.OfferId) == managedID &&
+			offer.SellerId.Equals(source)
+	}
+
+	states := map[uint64]xdr.OfferEntry{}
+	for _, change := range is.Cursor.OperationChanges() {
+		if change.EntryType() != xdr.LedgerEntryTypeOffer {
+			continue
+		}
+
+		var (
+			typ   history.OfferEventType
+			offer xdr.OfferEntry
+		)
+
+		switch change.Type {
+		case xdr.LedgerEntryChangeTypeLedgerEntryState:
+			state := change.MustState().Data.MustOffer()
+			states[uint64(state.OfferId)] = state
+			continue
+		case xdr.LedgerEntryChangeTypeLedgerEntryCreated:
+			typ = history.OfferEventCreated
+			offer = change.MustCreated().Data.MustOffer()
+		case xdr.LedgerEntryChangeTypeLedgerEntryUpdated:
+			typ = history.OfferEventPartiallyFilled
+			offer = change.MustUpdated().Data.MustOffer()
+			if isManaged(offer) {
+				typ = history.OfferEventUpdated
+			}
+		case xdr.LedgerEntryChangeTypeLedgerEntryRemoved:
+			key := change.MustRemoved().MustOffer()
+			state, ok := states[uint64(key.OfferId)]
+			if !ok {
+				is.Err = fmt.Errorf("no state found for removed offer %d", key.OfferId)
+				return
+			}
+
+			typ = history.OfferEventFilled
+			offer = state
+			offer.Amount = 0
+			if cancelled && isManaged(offer) {
+				typ = history.OfferEventCancelled
+			}
+		}
+
+		is.Err = is.Ingestion.OfferEvent(
+			is.Cursor.OperationID(),
+			is.Cursor.LedgerSequence(),
+			typ,
+			offer,
+		)
+		if is.Err != nil {
+			is.Err = errors.Wrap(is.Err, "Ingestion.OfferEvent error")
+			return
+		}
+	}
 }
 
 func (is *Session) ingestOperationParticipants() {
@@ -516,6 +684,101 @@ func (is *Session) ingestSignerEffects(effects *EffectIngestion, op xdr.SetOptio
 
 }
 
+// ingestStateChanges records the state of every account and trustline
+// modified in the current ledger.  In addition to the state after the ledger,
+// the state prior to the ledger is recorded at the ledger it was last modified
+// in, unless that row already exists, so that the state of an entry is known
+// for every ledger since it was first seen by the ingestion system (including
+// when ledgers are ingested in reverse order by a backfill).
+func (is *Session) ingestStateChanges() {
+	if is.Err != nil {
+		return
+	}
+
+	var sc StateChanges
+	for _, change := range is.Cursor.LedgerEntryChanges() {
+		is.Err = sc.Add(change)
+		if is.Err != nil {
+			is.Err = errors.Wrap(is.Err, "StateChanges.Add error")
+			return
+		}
+	}
+
+	var changes []StateChange
+	for _, c := range sc.All() {
+		if is.Config.Filter.AllowsStateChange(c.Key) {
+			changes = append(changes, c)
+		}
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	seq := is.Cursor.LedgerSequence()
+
+	var (
+		addys    []string
+		seqs     []int32
+		accounts []history.AccountState
+		lines    []history.TrustlineState
+	)
+	for _, c := range changes {
+		if c.Before == nil || int32(c.Before.LastModifiedLedgerSeq) >= seq {
+			continue
+		}
+		aid := stateAccountID(c.Key)
+		addys = append(addys, aid.Address())
+		seqs = append(seqs, int32(c.Before.LastModifiedLedgerSeq))
+	}
+
+	if len(addys) > 0 {
+		q := history.Q{Session: is.Ingestion.DB}
+
+		is.Err = q.AccountStatesBySequences(&accounts, addys, seqs)
+		if is.Err != nil {
+			is.Err = errors.Wrap(is.Err, "q.AccountStatesBySequences error")
+			return
+		}
+
+		is.Err = q.TrustlineStatesBySequences(&lines, addys, seqs)
+		if is.Err != nil {
+			is.Err = errors.Wrap(is.Err, "q.TrustlineStatesBySequences error")
+			return
+		}
+	}
+
+	recorded := map[string]bool{}
+	for _, row := range accounts {
+		recorded[fmt.Sprintf("%s:%d", row.AccountID, row.LedgerSequence)] = true
+	}
+	for _, row := range lines {
+		id := fmt.Sprintf("%s:%d:%s:%s", row.AccountID, row.AssetType, row.AssetCode, row.AssetIssuer)
+		recorded[fmt.Sprintf("%s:%d", id, row.LedgerSequence)] = true
+	}
+
+	for _, c := range changes {
+		record := is.Ingestion.AccountState
+		if c.Key.Type == xdr.LedgerEntryTypeTrustline {
+			record = is.Ingestion.TrustlineState
+		}
+
+		if c.Before != nil && int32(c.Before.LastModifiedLedgerSeq) < seq {
+			before := int32(c.Before.LastModifiedLedgerSeq)
+			if !recorded[fmt.Sprintf("%s:%d", stateID(c.Key), before)] {
+				is.Err = record(before, c.Key, c.Before)
+				if is.Err != nil {
+					return
+				}
+			}
+		}
+
+		is.Err = record(seq, c.Key, c.After)
+		if is.Err != nil {
+			return
+		}
+	}
+}
+
 func (is *Session) ingestTrades() {
 	if is.Err != nil {
 		return
@@ -637,6 +900,18 @@ func (is *Session) ingestTransaction() {
 	if !is.Cursor.Transaction().IsSuccessful() {
 		return
 	}
+
+	allowed, err := is.Config.Filter.AllowsTransaction(
+		is.Cursor.Transaction(),
+		is.Cursor.TransactionFee(),
+	)
+	if err != nil {
+		is.Err = errors.Wrap(err, "Filter.AllowsTransaction error")
+		return
+	}
+	if !allowed {
+		return
+	}
 	is.Ingestion.Transaction(
 		is.Cursor.TransactionID(),
 		is.Cursor.Transaction(),
@@ -647,7 +922,11 @@ func (is *Session) ingestTransaction() {
 		is.ingestOperation()
 	}
 
+	start := time.Now()
 	is.ingestTransactionParticipants()
+	if is.Metrics != nil {
+		is.Metrics.TransactionParticipantsTimer.Update(time.Since(start))
+	}
 }
 
 func (is *Session) ingestTransactionParticipants() {
@@ -0,0 +1,105 @@
+package ingest
+
+import (
+	"fmt"
+
+	"github.com/lomocoin/stellar-go/xdr"
+)
+
+// Add folds `change` into the accumulated changes.  Changes to ledger entries
+// other than accounts and trustlines are ignored.  Changes must be added in
+// the order they were applied by stellar-core.
+func (sc *StateChanges) Add(change xdr.LedgerEntryChange) error {
+	key := change.LedgerKey()
+
+	switch key.Type {
+	case xdr.LedgerEntryTypeAccount, xdr.LedgerEntryTypeTrustline:
+	default:
+		return nil
+	}
+
+	id, err := xdr.MarshalBase64(key)
+	if err != nil {
+		return err
+	}
+
+	if sc.changes == nil {
+		sc.changes = map[string]*StateChange{}
+	}
+
+	sc.init(id, key, change)
+	c := sc.changes[id]
+
+	switch change.Type {
+	case xdr.LedgerEntryChangeTypeLedgerEntryState:
+		// a state entry only ever describes the entry before it gets modified,
+		// which is already recorded when the entry is first seen.
+	case xdr.LedgerEntryChangeTypeLedgerEntryCreated:
+		entry := change.MustCreated()
+		c.After = &entry
+	case xdr.LedgerEntryChangeTypeLedgerEntryUpdated:
+		entry := change.MustUpdated()
+		c.After = &entry
+	case xdr.LedgerEntryChangeTypeLedgerEntryRemoved:
+		c.After = nil
+	default:
+		return fmt.Errorf("Unknown change type: %v", change.Type)
+	}
+
+	return nil
+}
+
+// All returns the accumulated changes, ordered by when each entry was first
+// changed.
+func (sc *StateChanges) All() []StateChange {
+	ret := make([]StateChange, len(sc.keys))
+	for i, id := range sc.keys {
+		ret[i] = *sc.changes[id]
+	}
+	return ret
+}
+
+// init records the first time an entry is seen, capturing its state prior to
+// the ledger.
+func (sc *StateChanges) init(id string, key xdr.LedgerKey, change xdr.LedgerEntryChange) {
+	if _, ok := sc.changes[id]; ok {
+		return
+	}
+
+	c := &StateChange{Key: key}
+
+	if change.Type == xdr.LedgerEntryChangeTypeLedgerEntryState {
+		entry := change.MustState()
+		c.Before = &entry
+		c.After = &entry
+	}
+
+	sc.keys = append(sc.keys, id)
+	sc.changes[id] = c
+}
+
+// stateAccountID returns the account that owns the account or trustline entry
+// identified by `key`.
+func stateAccountID(key xdr.LedgerKey) xdr.AccountId {
+	if key.Type == xdr.LedgerEntryTypeTrustline {
+		return key.MustTrustLine().AccountId
+	}
+	return key.MustAccount().AccountId
+}
+
+// stateID returns a string identifying the account or trustline entry
+// identified by `key`, matching the columns of the state history tables.
+func stateID(key xdr.LedgerKey) string {
+	aid := stateAccountID(key)
+	if key.Type != xdr.LedgerEntryTypeTrustline {
+		return aid.Address()
+	}
+
+	var (
+		typ    xdr.AssetType
+		code   string
+		issuer string
+	)
+	key.MustTrustLine().Asset.MustExtract(&typ, &code, &issuer)
+	return fmt.Sprintf("%s:%d:%s:%s", aid.Address(), typ, code, issuer)
+}
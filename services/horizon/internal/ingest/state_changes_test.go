@@ -0,0 +1,68 @@
+package ingest
+
+import (
+	"testing"
+
+	"github.com/lomocoin/stellar-go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateChanges(t *testing.T) {
+	var aid, other xdr.AccountId
+	require.NoError(t, aid.SetAddress("GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"))
+	require.NoError(t, other.SetAddress("GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU"))
+
+	account := func(id xdr.AccountId, seq xdr.Uint32, balance xdr.Int64) *xdr.LedgerEntry {
+		return &xdr.LedgerEntry{
+			LastModifiedLedgerSeq: seq,
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeAccount,
+				Account: &xdr.AccountEntry{
+					AccountId: id,
+					Balance:   balance,
+				},
+			},
+		}
+	}
+
+	var sc StateChanges
+	changes := []xdr.LedgerEntryChange{
+		// fee charged from an existing account
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: account(aid, 3, 100)},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: account(aid, 10, 90)},
+		// new account funded by the existing account
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: account(aid, 10, 90)},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: account(aid, 10, 40)},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated, Created: account(other, 10, 50)},
+	}
+	for _, change := range changes {
+		require.NoError(t, sc.Add(change))
+	}
+
+	all := sc.All()
+	require.Len(t, all, 2)
+
+	assert.True(t, all[0].Key.Equals(aid.LedgerKey()))
+	if assert.NotNil(t, all[0].Before) {
+		assert.Equal(t, xdr.Uint32(3), all[0].Before.LastModifiedLedgerSeq)
+		assert.Equal(t, xdr.Int64(100), all[0].Before.Data.MustAccount().Balance)
+	}
+	if assert.NotNil(t, all[0].After) {
+		assert.Equal(t, xdr.Int64(40), all[0].After.Data.MustAccount().Balance)
+	}
+
+	assert.True(t, all[1].Key.Equals(other.LedgerKey()))
+	assert.Nil(t, all[1].Before)
+	if assert.NotNil(t, all[1].After) {
+		assert.Equal(t, xdr.Int64(50), all[1].After.Data.MustAccount().Balance)
+	}
+
+	// removing the account records a nil state after the ledger
+	removed := other.LedgerKey()
+	require.NoError(t, sc.Add(xdr.LedgerEntryChange{
+		Type:    xdr.LedgerEntryChangeTypeLedgerEntryRemoved,
+		Removed: &removed,
+	}))
+	assert.Nil(t, sc.All()[1].After)
+}
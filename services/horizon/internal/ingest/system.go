@@ -1,6 +1,7 @@
 package ingest
 
 import (
+	"context"
 	"time"
 
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/core"
@@ -209,6 +210,12 @@ func (i *System) ReingestSingle(sequence int32) error {
 // that there currently is not an import session in progress.
 func (i *System) Tick() *Session {
 	i.lock.Lock()
+	if i.shuttingDown {
+		log.Debug("ingest: shutting down")
+		i.lock.Unlock()
+		return nil
+	}
+
 	if i.current != nil {
 		log.Info("ingest: already in progress")
 		i.lock.Unlock()
@@ -217,12 +224,48 @@ func (i *System) Tick() *Session {
 
 	is := NewSession(i)
 	i.current = is
+	i.running.Add(1)
 	i.lock.Unlock()
 
+	defer i.running.Done()
 	i.runOnce()
 	return is
 }
 
+// Shutdown stops the ingestion system from starting new sessions and makes
+// the current session, if any, stop after the ledger it is ingesting,
+// committing the ledgers ingested so far.  Use Wait to wait for the session to
+// be committed or rolled back.
+func (i *System) Shutdown() {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.shuttingDown = true
+}
+
+// Wait waits for the current session, if any, to be committed or rolled back.
+// It returns ctx's error if ctx is done first.
+func (i *System) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		i.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isShuttingDown returns true once Shutdown has been called.
+func (i *System) isShuttingDown() bool {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	return i.shuttingDown
+}
+
 // run causes the importer to check stellar-core to see if we can import new
 // data.
 func (i *System) runOnce() {
@@ -1,6 +1,7 @@
 package ingest
 
 import (
+	"context"
 	"testing"
 
 	"github.com/lomocoin/stellar-go/network"
@@ -47,6 +48,29 @@ func TestClearAll(t *testing.T) {
 	tt.Assert.Equal(0, found)
 }
 
+func TestShutdown(t *testing.T) {
+	tt := test.Start(t).ScenarioWithoutHorizon("kahuna")
+	defer tt.Finish()
+	is := sys(tt, false)
+
+	is.Shutdown()
+	tt.Assert.Nil(is.Tick())
+	tt.Assert.NoError(is.Wait(context.Background()))
+
+	// a session running when the system shuts down stops after the ledger it
+	// is ingesting, and commits it.
+	session := NewSession(is)
+	session.Cursor = NewCursor(2, 10, is)
+	session.Run()
+	tt.Require.NoError(session.Err)
+	tt.Assert.Equal(int32(2), session.Cursor.LastLedger)
+
+	var found int
+	err := tt.HorizonSession().GetRaw(&found, "SELECT COUNT(*) FROM history_ledgers")
+	tt.Require.NoError(err)
+	tt.Assert.Equal(1, found)
+}
+
 func TestValidation(t *testing.T) {
 	tt := test.Start(t).Scenario("kahuna")
 	defer tt.Finish()
@@ -0,0 +1,181 @@
+package horizon
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/rcrowley/go-metrics"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/apikey"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
+	"github.com/lomocoin/stellar-go/support/errors"
+	"github.com/lomocoin/stellar-go/support/log"
+	"github.com/throttled/throttled"
+)
+
+// apiKeyClient is an API key along with the rate limiter enforcing its quota
+// and the metrics counting the requests made with it.
+type apiKeyClient struct {
+	apikey.Key
+	rateLimiter *throttled.HTTPRateLimiter
+	requests    metrics.Counter
+	limited     metrics.Counter
+}
+
+// VaryByAPIKey rate limits every request made with an API key together.
+type VaryByAPIKey struct {
+	Name string
+}
+
+func (v VaryByAPIKey) Key(r *http.Request) string {
+	return v.Name
+}
+
+// initAPIKeys loads the API keys listed in the API keys file and in the
+// database.
+func initAPIKeys(app *App) {
+	clients, err := loadAPIKeyClients(app, app.config.APIKeysFile, nil)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	app.web.apiKeys.Store(clients)
+	if len(clients) > 0 {
+		log.WithField("count", len(clients)).Info("Loaded API keys")
+	}
+}
+
+// loadAPIKeyClients loads the API keys listed in the API keys file at `path`,
+// if any, and in the database.  The clients of `previous` whose key is
+// unchanged are kept, along with the requests counted against their quota.
+func loadAPIKeyClients(
+	app *App,
+	path string,
+	previous map[string]*apiKeyClient,
+) (map[string]*apiKeyClient, error) {
+	keys, err := loadAPIKeys(app, path)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := apikey.NewSet(keys)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid api keys")
+	}
+
+	clients := make(map[string]*apiKeyClient, len(set))
+	for hash, key := range set {
+		if client, ok := previous[hash]; ok && reflect.DeepEqual(client.Key, key) {
+			clients[hash] = client
+			continue
+		}
+		clients[hash] = newAPIKeyClient(app, key)
+	}
+	return clients, nil
+}
+
+// apiKeyClients returns the clients of the API keys, by the hash of their
+// token.
+func (web *Web) apiKeyClients() map[string]*apiKeyClient {
+	clients, _ := web.apiKeys.Load().(map[string]*apiKeyClient)
+	return clients
+}
+
+// apiKey returns the client of the API key `token`, if it exists.
+func (web *Web) apiKey(token string) (*apiKeyClient, bool) {
+	client, ok := web.apiKeyClients()[apikey.Hash(token)]
+	return client, ok
+}
+
+func loadAPIKeys(app *App, path string) ([]apikey.Key, error) {
+	var keys []apikey.Key
+
+	if path != "" {
+		fileKeys, err := apikey.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, fileKeys...)
+	}
+
+	var rows []history.APIKey
+	err := app.HistoryQ().APIKeys(&rows)
+	if err != nil {
+		// the api keys stored in the database are optional: keep serving the
+		// anonymous requests and the keys of the file when they can't be loaded.
+		log.WithField("err", err).Error("failed to load api keys from the database")
+		return keys, nil
+	}
+
+	for _, row := range rows {
+		keys = append(keys, apikey.Key{
+			Hash:             row.KeyHash,
+			Name:             row.Name,
+			PerHourRateLimit: row.PerHourRateLimit,
+			Burst:            row.Burst,
+			Routes:           apikey.SplitRoutes(row.Routes),
+		})
+	}
+
+	return keys, nil
+}
+
+func newAPIKeyClient(app *App, key apikey.Key) *apiKeyClient {
+	client := &apiKeyClient{
+		Key: key,
+		requests: metrics.GetOrRegisterCounter(
+			fmt.Sprintf("api_keys.requests{key=%q}", key.Name), app.metrics,
+		),
+		limited: metrics.GetOrRegisterCounter(
+			fmt.Sprintf("api_keys.rate_limited{key=%q}", key.Name), app.metrics,
+		),
+	}
+
+	quota := key.Quota()
+	if quota == nil {
+		return client
+	}
+
+	var rateLimiter throttled.RateLimiter
+	if app.redis != nil {
+		prefix := "api_key:"
+		if app.config.RateLimitRedisKey != "" {
+			prefix = app.config.RateLimitRedisKey + ":" + prefix
+		}
+
+		rateLimiter = &apikey.RedisRateLimiter{
+			Pool:    app.redis,
+			Prefix:  prefix,
+			PerHour: key.PerHourRateLimit,
+			Burst:   key.Burst,
+		}
+	} else {
+		var err error
+		rateLimiter, err = throttled.NewGCRARateLimiter(1, *quota)
+		if err != nil {
+			panic(fmt.Errorf("unable to create RateLimiter"))
+		}
+	}
+
+	client.rateLimiter = &throttled.HTTPRateLimiter{
+		RateLimiter: rateLimiter,
+		DeniedHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client.limited.Inc(1)
+			RateLimitExceededAction{App: app, Action: Action{}}.ServeHTTP(w, r)
+		}),
+		VaryBy: VaryByAPIKey{Name: key.Name},
+	}
+	return client
+}
+
+func init() {
+	appInit.Add(
+		"web.api-keys",
+		initAPIKeys,
+
+		"web.init",
+		"horizon-db",
+		"redis",
+		"metrics",
+	)
+}
@@ -6,6 +6,7 @@ import (
 
 func initAppContext(app *App) {
 	app.ctx, app.cancel = context.WithCancel(context.Background())
+	app.draining, app.drain = context.WithCancel(app.ctx)
 }
 
 func init() {
@@ -1,6 +1,7 @@
 package horizon
 
 import (
+	"github.com/rcrowley/go-metrics"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/core"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
 	"github.com/lomocoin/stellar-go/support/db"
@@ -20,6 +21,7 @@ func initHorizonDb(app *App) {
 	session.DB.SetMaxOpenConns(app.config.MaxDBConnections)
 
 	app.historyQ = &history.Q{session}
+	app.historyReplicas = openReplicas(app, app.config.HistoryReplicaURLs)
 }
 
 func initCoreDb(app *App) {
@@ -34,6 +36,33 @@ func initCoreDb(app *App) {
 	session.DB.SetMaxIdleConns(app.config.MaxDBConnections)
 	session.DB.SetMaxOpenConns(app.config.MaxDBConnections)
 	app.coreQ = &core.Q{session}
+	app.coreReplicas = openReplicas(app, app.config.CoreReplicaURLs)
+}
+
+// openReplicas opens the read replicas at `urls`, returning nil if there are
+// none.
+func openReplicas(app *App, urls []string) *replicaSet {
+	if len(urls) == 0 {
+		return nil
+	}
+
+	replicas := &replicaSet{}
+	for _, url := range urls {
+		session, err := db.Open("postgres", url)
+
+		if err != nil {
+			log.Panic(err)
+		}
+
+		session.DB.SetMaxIdleConns(app.config.MaxDBConnections)
+		session.DB.SetMaxOpenConns(app.config.MaxDBConnections)
+		replicas.replicas = append(replicas.replicas, &replica{
+			session:  session,
+			lagGauge: metrics.NewGauge(),
+		})
+	}
+
+	return replicas
 }
 
 func init() {
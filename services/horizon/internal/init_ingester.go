@@ -21,7 +21,9 @@ func initIngester(app *App) {
 		app.CoreSession(nil),
 		app.HorizonSession(nil),
 		ingest.Config{
-			EnableAssetStats: app.config.EnableAssetStats,
+			EnableAssetStats:     !app.config.DisableAssetStats,
+			AssetHolderThreshold: app.config.AssetHolderThreshold,
+			Filter:               app.config.IngestFilter,
 		},
 	)
 
@@ -2,6 +2,7 @@ package horizon
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/rcrowley/go-metrics"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/logmetrics"
@@ -15,6 +16,7 @@ func initDbMetrics(app *App) {
 	app.historyLatestLedgerGauge = metrics.NewGauge()
 	app.historyElderLedgerGauge = metrics.NewGauge()
 	app.coreLatestLedgerGauge = metrics.NewGauge()
+	app.ledgersBehindCoreGauge = metrics.NewGauge()
 
 	app.horizonConnGauge = metrics.NewGauge()
 	app.coreConnGauge = metrics.NewGauge()
@@ -22,9 +24,38 @@ func initDbMetrics(app *App) {
 	app.metrics.Register("history.latest_ledger", app.historyLatestLedgerGauge)
 	app.metrics.Register("history.elder_ledger", app.historyElderLedgerGauge)
 	app.metrics.Register("stellar_core.latest_ledger", app.coreLatestLedgerGauge)
+	app.metrics.Register("history.ledgers_behind_core", app.ledgersBehindCoreGauge)
 	app.metrics.Register("history.open_connections", app.horizonConnGauge)
 	app.metrics.Register("stellar_core.open_connections", app.coreConnGauge)
 	app.metrics.Register("goroutines", app.goroutineGauge)
+
+	app.historyQ.Session.ObserveQuery = queryObserver(app, "history")
+	app.coreQ.Session.ObserveQuery = queryObserver(app, "core")
+
+	initReplicasMetrics(app, app.historyReplicas, "history")
+	initReplicasMetrics(app, app.coreReplicas, "core")
+}
+
+// initReplicasMetrics registers the metrics of the read replicas of the
+// database `db`, identified by their position in the configured list.
+func initReplicasMetrics(app *App, replicas *replicaSet, db string) {
+	if replicas == nil {
+		return
+	}
+
+	for i, r := range replicas.replicas {
+		r.session.ObserveQuery = queryObserver(app, fmt.Sprintf("%s_replica_%d", db, i))
+		app.metrics.Register(fmt.Sprintf("db.replica_lag{db=%q,replica=\"%d\"}", db, i), r.lagGauge)
+	}
+}
+
+// queryObserver returns a function recording the latency of the queries run
+// against the database `db`, by query type.
+func queryObserver(app *App, db string) func(string, time.Duration) {
+	return func(typ string, duration time.Duration) {
+		name := fmt.Sprintf("db.query_duration{db=%q,type=%q}", db, typ)
+		metrics.GetOrRegisterTimer(name, app.metrics).Update(duration)
+	}
 }
 
 func initIngesterMetrics(app *App) {
@@ -35,6 +66,28 @@ func initIngesterMetrics(app *App) {
 		app.ingester.Metrics.IngestLedgerTimer)
 	app.metrics.Register("ingester.clear_ledger",
 		app.ingester.Metrics.ClearLedgerTimer)
+	app.metrics.Register("ingester.load_ledger",
+		app.ingester.Metrics.LoadLedgerTimer)
+	app.metrics.Register("ingester.flush",
+		app.ingester.Metrics.FlushTimer)
+	app.metrics.Register("ingester.phase.effects",
+		app.ingester.Metrics.EffectsTimer)
+	app.metrics.Register("ingester.phase.offer_events",
+		app.ingester.Metrics.OfferEventsTimer)
+	app.metrics.Register("ingester.phase.operation_participants",
+		app.ingester.Metrics.OperationParticipantsTimer)
+	app.metrics.Register("ingester.phase.state_changes",
+		app.ingester.Metrics.StateChangesTimer)
+	app.metrics.Register("ingester.phase.trades",
+		app.ingester.Metrics.TradesTimer)
+	app.metrics.Register("ingester.phase.transaction_participants",
+		app.ingester.Metrics.TransactionParticipantsTimer)
+	app.metrics.Register("ingester.reingest_remaining",
+		app.ingester.Metrics.ReingestRemainingGauge)
+
+	for table, counter := range app.ingester.Metrics.RowsWritten {
+		app.metrics.Register(fmt.Sprintf("ingester.rows.%s", table), counter)
+	}
 }
 
 func initLogMetrics(app *App) {
@@ -51,6 +104,10 @@ func initTxSubMetrics(app *App) {
 	app.metrics.Register("txsub.succeeded", app.submitter.Metrics.SuccessfulSubmissionsMeter)
 	app.metrics.Register("txsub.failed", app.submitter.Metrics.FailedSubmissionsMeter)
 	app.metrics.Register("txsub.total", app.submitter.Metrics.SubmissionTimer)
+
+	for outcome, counter := range app.submitter.Metrics.ResultCounters {
+		app.metrics.Register(fmt.Sprintf("txsub.results{outcome=%q}", outcome), counter)
+	}
 }
 
 // initWebMetrics registers the metrics for the web server into the provided
@@ -59,6 +116,10 @@ func initWebMetrics(app *App) {
 	app.metrics.Register("requests.total", app.web.requestTimer)
 	app.metrics.Register("requests.succeeded", app.web.successMeter)
 	app.metrics.Register("requests.failed", app.web.failureMeter)
+	app.metrics.Register("requests.open_streams", app.web.streamsGauge)
+	app.metrics.Register("requests.open_websockets", app.web.websocketsGauge)
+	app.metrics.Register("response_cache.hits", app.web.cacheHits)
+	app.metrics.Register("response_cache.misses", app.web.cacheMisses)
 }
 
 func init() {
@@ -1,11 +1,32 @@
 package horizon
 
 import (
+	"github.com/lomocoin/stellar-go/services/horizon/internal/orderbook"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/simplepath"
+	"github.com/lomocoin/stellar-go/support/log"
+)
+
+// PathFinder names
+const (
+	PathFinderSimple = "simple"
+	PathFinderGraph  = "graph"
 )
 
 func initPathFinding(app *App) {
-	app.paths = &simplepath.Finder{app.CoreQ()}
+	switch app.config.PathFinder {
+	case PathFinderGraph:
+		app.orderBookGraph = &orderbook.Graph{}
+		app.paths = &orderbook.Finder{
+			Graph:      app.orderBookGraph,
+			MaxResults: int(app.config.PathFinderMaxResults),
+			Timeout:    app.config.PathFinderTimeout,
+		}
+		app.UpdateOrderBookGraph()
+	case PathFinderSimple, "":
+		app.paths = &simplepath.Finder{app.CoreQ()}
+	default:
+		log.Panicf("unknown path finder: %s", app.config.PathFinder)
+	}
 }
 
 func init() {
@@ -1,11 +1,23 @@
 package horizon
 
 import (
+	"log"
+
 	"github.com/lomocoin/stellar-go/services/horizon/internal/reap"
 )
 
 func initReaper(app *App) {
 	app.reaper = reap.New(app.config.HistoryRetentionCount, app.HorizonSession(nil))
+
+	if app.config.ReaperArchiveURL == "" {
+		return
+	}
+
+	archive, err := reap.NewBackend(app.config.ReaperArchiveURL)
+	if err != nil {
+		log.Fatalf("Cannot open reaper archive: %s", err)
+	}
+	app.reaper.Archive = archive
 }
 
 func init() {
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync/atomic"
 
 	"github.com/go-chi/chi"
 	chimiddleware "github.com/go-chi/chi/middleware"
@@ -24,19 +25,40 @@ import (
 type Web struct {
 	router      *chi.Mux
 	rateLimiter *throttled.HTTPRateLimiter
+	// apiKeys holds the map[string]*apiKeyClient of the API keys by token,
+	// replaced when the config is reloaded.
+	apiKeys atomic.Value
+
+	// quotaLimiter enforces the quota of rateLimiter, replaced when the
+	// config is reloaded.
+	quotaLimiter *reloadableRateLimiter
+
+	responseCache responseCache
+	cacheHits     metrics.Counter
+	cacheMisses   metrics.Counter
 
 	requestTimer metrics.Timer
 	failureMeter metrics.Meter
 	successMeter metrics.Meter
+	streamsGauge metrics.Gauge
+	openStreams  int64
+
+	websocketsGauge metrics.Gauge
+	openWebsockets  int64
 }
 
 // initWeb installed a new Web instance onto the provided app object.
 func initWeb(app *App) {
 	app.web = &Web{
 		router:       chi.NewRouter(),
-		requestTimer: metrics.NewTimer(),
+		requestTimer: newRequestTimer(),
 		failureMeter: metrics.NewMeter(),
 		successMeter: metrics.NewMeter(),
+		streamsGauge: metrics.NewGauge(),
+		cacheHits:    metrics.NewCounter(),
+		cacheMisses:  metrics.NewCounter(),
+
+		websocketsGauge: metrics.NewGauge(),
 	}
 
 	// register problems
@@ -52,7 +74,7 @@ func initWeb(app *App) {
 func initWebMiddleware(app *App) {
 
 	r := app.web.router
-	r.Use(chimiddleware.Timeout(app.config.ConnectionTimeout))
+	r.Use(timeoutMiddleware(app.config.ConnectionTimeout))
 	r.Use(chimiddleware.StripSlashes)
 	r.Use(app.Middleware)
 	r.Use(requestCacheHeadersMiddleware)
@@ -60,6 +82,7 @@ func initWebMiddleware(app *App) {
 	r.Use(contextMiddleware(app.ctx))
 	r.Use(xff.Handler)
 	r.Use(LoggerMiddleware)
+	r.Use(shutdownMiddleware(app.draining))
 	r.Use(requestMetricsMiddleware)
 	r.Use(RecoverMiddleware)
 	r.Use(chimiddleware.Compress(flate.DefaultCompression, "application/hal+json"))
@@ -69,8 +92,6 @@ func initWebMiddleware(app *App) {
 		AllowedHeaders: []string{"*"},
 	})
 	r.Use(c.Handler)
-
-	r.Use(app.web.RateLimitMiddleware)
 }
 
 // initWebActions installs the routing configuration of horizon onto the
@@ -78,89 +99,127 @@ func initWebMiddleware(app *App) {
 func initWebActions(app *App) {
 
 	r := app.web.router
-	r.Get("/", RootAction{}.Handle)
-	r.Get("/metrics", MetricsAction{}.Handle)
-
-	// ledger actions
-	r.Route("/ledgers", func(r chi.Router) {
-		r.Get("/", LedgerIndexAction{}.Handle)
-		r.Route("/{ledger_id}", func(r chi.Router) {
-			r.Get("/", LedgerShowAction{}.Handle)
-			r.Get("/transactions", TransactionIndexAction{}.Handle)
-			r.Get("/operations", OperationIndexAction{}.Handle)
-			r.Get("/payments", PaymentsIndexAction{}.Handle)
-			r.Get("/effects", EffectIndexAction{}.Handle)
+
+	// the health checks are neither rate limited nor subject to the API keys,
+	// so that load balancers can always probe horizon.
+	r.Get("/health", HealthAction{}.Handle)
+	r.Get("/ready", ReadyAction{}.Handle)
+
+	r.Group(func(r chi.Router) {
+		r.Use(app.web.RateLimitMiddleware)
+		r.Use(app.web.ResponseCacheMiddleware)
+
+		r.Get("/", RootAction{}.Handle)
+		r.Get("/metrics", MetricsAction{}.Handle)
+
+		// websocket subscriptions to the streaming endpoints
+		r.Get(websocketPath, app.web.ServeWebsocket)
+
+		// ledger actions
+		r.Route("/ledgers", func(r chi.Router) {
+			r.Get("/", LedgerIndexAction{}.Handle)
+			r.Route("/{ledger_id}", func(r chi.Router) {
+				r.Get("/", LedgerShowAction{}.Handle)
+				r.Get("/transactions", TransactionIndexAction{}.Handle)
+				r.Get("/operations", OperationIndexAction{}.Handle)
+				r.Get("/payments", PaymentsIndexAction{}.Handle)
+				r.Get("/effects", EffectIndexAction{}.Handle)
+			})
 		})
-	})
 
-	// account actions
-	r.Route("/accounts", func(r chi.Router) {
-		r.Route("/{account_id}", func(r chi.Router) {
-			r.Get("/", AccountShowAction{}.Handle)
-			r.Get("/transactions", TransactionIndexAction{}.Handle)
-			r.Get("/operations", OperationIndexAction{}.Handle)
-			r.Get("/payments", PaymentsIndexAction{}.Handle)
-			r.Get("/effects", EffectIndexAction{}.Handle)
-			r.Get("/offers", OffersByAccountAction{}.Handle)
-			r.Get("/trades", TradeIndexAction{}.Handle)
-			r.Get("/data/{key}", DataShowAction{}.Handle)
+		// account actions
+		r.Route("/accounts", func(r chi.Router) {
+			r.Get("/", AccountIndexAction{}.Handle)
+			r.Post("/", AccountIndexAction{}.Handle)
+			r.Route("/{account_id}", func(r chi.Router) {
+				r.Get("/", AccountShowAction{}.Handle)
+				r.Get("/transactions", TransactionIndexAction{}.Handle)
+				r.Get("/operations", OperationIndexAction{}.Handle)
+				r.Get("/payments", PaymentsIndexAction{}.Handle)
+				r.Get("/effects", EffectIndexAction{}.Handle)
+				r.Get("/offers", OffersByAccountAction{}.Handle)
+				r.Get("/trades", TradeIndexAction{}.Handle)
+				r.Get("/data/{key}", DataShowAction{}.Handle)
+			})
 		})
-	})
 
-	// transaction history actions
-	r.Route("/transactions", func(r chi.Router) {
-		r.Get("/", TransactionIndexAction{}.Handle)
-		r.Route("/{tx_id}", func(r chi.Router) {
-			r.Get("/", TransactionShowAction{}.Handle)
-			r.Get("/operations", OperationIndexAction{}.Handle)
-			r.Get("/payments", PaymentsIndexAction{}.Handle)
-			r.Get("/effects", EffectIndexAction{}.Handle)
+		// transaction history actions
+		r.Route("/transactions", func(r chi.Router) {
+			r.Get("/", TransactionIndexAction{}.Handle)
+			r.Route("/{tx_id}", func(r chi.Router) {
+				r.Get("/", TransactionShowAction{}.Handle)
+				r.Get("/operations", OperationIndexAction{}.Handle)
+				r.Get("/payments", PaymentsIndexAction{}.Handle)
+				r.Get("/effects", EffectIndexAction{}.Handle)
+			})
 		})
-	})
 
-	// operation actions
-	r.Route("/operations", func(r chi.Router) {
-		r.Get("/", OperationIndexAction{}.Handle)
-		r.Get("/{id}", OperationShowAction{}.Handle)
-		r.Get("/{op_id}/effects", EffectIndexAction{}.Handle)
-	})
+		// operation actions
+		r.Route("/operations", func(r chi.Router) {
+			r.Get("/", OperationIndexAction{}.Handle)
+			r.Get("/{id}", OperationShowAction{}.Handle)
+			r.Get("/{op_id}/effects", EffectIndexAction{}.Handle)
+		})
 
-	// payment actions
-	r.Get("/payments", PaymentsIndexAction{}.Handle)
+		// payment actions
+		r.Get("/payments", PaymentsIndexAction{}.Handle)
 
-	// effect actions
-	r.Get("/effects", EffectIndexAction{}.Handle)
+		// effect actions
+		r.Get("/effects", EffectIndexAction{}.Handle)
 
-	// trading related endpoints
-	r.Get("/trades", TradeIndexAction{}.Handle)
-	r.Get("/trade_aggregations", TradeAggregateIndexAction{}.Handle)
-	r.Route("/offers", func(r chi.Router) {
-		r.Get("/{id}", NotImplementedAction{}.Handle)
-		r.Get("/{offer_id}/trades", TradeIndexAction{}.Handle)
-	})
-	r.Get("/order_book", OrderBookShowAction{}.Handle)
+		// trading related endpoints
+		r.Get("/trades", TradeIndexAction{}.Handle)
+		r.Get("/trade_aggregations", TradeAggregateIndexAction{}.Handle)
+		r.Route("/offers", func(r chi.Router) {
+			r.Get("/", OfferIndexAction{}.Handle)
+			r.Get("/{id}", OfferShowAction{}.Handle)
+			r.Get("/{offer_id}/events", OfferEventIndexAction{}.Handle)
+			r.Get("/{offer_id}/trades", TradeIndexAction{}.Handle)
+		})
+		r.Get("/order_book", OrderBookShowAction{}.Handle)
+		r.Get("/markets", MarketsAction{}.Handle)
 
-	// Transaction submission API
-	r.Post("/transactions", TransactionCreateAction{}.Handle)
-	r.Get("/paths", PathIndexAction{}.Handle)
+		// Transaction submission API
+		r.Post("/transactions", TransactionCreateAction{}.Handle)
+		r.Get("/paths", PathIndexAction{}.Handle)
 
-	if app.config.EnableAssetStats {
-		// Asset related endpoints
-		r.Get("/assets", AssetsAction{}.Handle)
-	}
+		if !app.config.DisableAssetStats {
+			// Asset related endpoints
+			r.Get("/assets", AssetsAction{}.Handle)
+			r.Get("/assets/daily_stats", AssetDailyStatsAction{}.Handle)
+		}
 
-	// Network state related endpoints
-	r.Get("/operation_fee_stats", OperationFeeStatsAction{}.Handle)
+		if app.config.EnableWebhooks {
+			// Webhooks of the API key of the request
+			r.Route("/webhooks", func(r chi.Router) {
+				r.Get("/", WebhookIndexAction{}.Handle)
+				r.Post("/", WebhookCreateAction{}.Handle)
+				r.Route("/{id}", func(r chi.Router) {
+					r.Get("/", WebhookShowAction{}.Handle)
+					r.Delete("/", WebhookDeleteAction{}.Handle)
+					r.Get("/dead_letters", WebhookDeadLetterIndexAction{}.Handle)
+					r.Post("/replay", WebhookReplayAction{}.Handle)
+				})
+			})
+		}
 
-	// friendbot
-	if app.config.FriendbotURL != nil {
-		redirectFriendbot := func(w http.ResponseWriter, r *http.Request) {
-			redirectURL := app.config.FriendbotURL.String() + "?" + r.URL.RawQuery
-			http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+		// GraphQL queries over the history and core databases
+		r.Get("/graphql", GraphQLAction{}.Handle)
+		r.Post("/graphql", GraphQLAction{}.Handle)
+
+		// Network state related endpoints
+		r.Get("/operation_fee_stats", OperationFeeStatsAction{}.Handle)
+
+		// friendbot
+		if app.config.FriendbotURL != nil {
+			redirectFriendbot := func(w http.ResponseWriter, r *http.Request) {
+				redirectURL := app.config.FriendbotURL.String() + "?" + r.URL.RawQuery
+				http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+			}
+			r.Post("/friendbot", redirectFriendbot)
+			r.Get("/friendbot", redirectFriendbot)
 		}
-		r.Post("/friendbot", redirectFriendbot)
-		r.Get("/friendbot", redirectFriendbot)
-	}
+	})
 
 	r.NotFound(NotFoundAction{}.Handle)
 }
@@ -171,7 +230,8 @@ func initWebRateLimiter(app *App) {
 		return
 	}
 
-	rateLimiter, err := throttled.NewGCRARateLimiter(50000, *app.config.RateLimit)
+	rateLimiter := &reloadableRateLimiter{}
+	err := rateLimiter.SetQuota(*app.config.RateLimit)
 	if err != nil {
 		panic(fmt.Errorf("unable to create RateLimiter"))
 	}
@@ -182,6 +242,7 @@ func initWebRateLimiter(app *App) {
 	}
 	httpRateLimiter.VaryBy = VaryByRemoteIP{}
 	app.web.rateLimiter = &httpRateLimiter
+	app.web.quotaLimiter = rateLimiter
 }
 
 type VaryByRemoteIP struct{}
@@ -223,12 +284,15 @@ func init() {
 		initWebMiddleware,
 
 		"web.init",
-		"web.rate-limiter",
 	)
 	appInit.Add(
 		"web.actions",
 		initWebActions,
 
 		"web.init",
+		"web.middleware",
+		"web.rate-limiter",
+		"web.api-keys",
+		"web.response-cache",
 	)
 }
@@ -0,0 +1,17 @@
+package horizon
+
+import (
+	"github.com/lomocoin/stellar-go/services/horizon/internal/webhooks"
+)
+
+func initWebhooks(app *App) {
+	if !app.config.EnableWebhooks {
+		return
+	}
+
+	app.webhooks = webhooks.New(app.config.WebhookMaxAttempts, app.HorizonSession(nil))
+}
+
+func init() {
+	appInit.Add("webhooks", initWebhooks, "app-context", "log", "horizon-db")
+}
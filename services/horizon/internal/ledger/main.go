@@ -15,6 +15,9 @@ type State struct {
 	CoreLatest    int32 `db:"core_latest"`
 	HistoryLatest int32 `db:"history_latest"`
 	HistoryElder  int32 `db:"history_elder"`
+	// HistoryFiltered is true when part of the history was not recorded
+	// because of an ingestion filter.
+	HistoryFiltered bool `db:"history_filtered"`
 }
 
 // CurrentState returns the cached snapshot of ledger state
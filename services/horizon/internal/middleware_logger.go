@@ -9,6 +9,7 @@ import (
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 	chimiddleware "github.com/go-chi/chi/middleware"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/apikey"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/render"
 	"github.com/lomocoin/stellar-go/support/log"
 )
@@ -63,7 +64,7 @@ func logStartOfRequest(ctx context.Context, r *http.Request, streaming bool) {
 		"ip":             remoteAddrIP(r),
 		"ip_port":        r.RemoteAddr,
 		"method":         r.Method,
-		"path":           r.URL.String(),
+		"path":           apikey.StripURL(r.URL).String(),
 		"streaming":      streaming,
 	}).Info("Starting request")
 }
@@ -86,7 +87,7 @@ func logEndOfRequest(ctx context.Context, r *http.Request, duration time.Duratio
 		"ip":             remoteAddrIP(r),
 		"ip_port":        r.RemoteAddr,
 		"method":         r.Method,
-		"path":           r.URL.String(),
+		"path":           apikey.StripURL(r.URL).String(),
 		"route":          chi.RouteContext(r.Context()).RoutePattern(),
 		"status":         mw.Status(),
 		"streaming":      streaming,
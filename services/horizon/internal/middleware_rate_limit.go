@@ -2,11 +2,46 @@ package horizon
 
 import (
 	"net/http"
+
+	"github.com/lomocoin/stellar-go/services/horizon/internal/apikey"
+	hProblem "github.com/lomocoin/stellar-go/services/horizon/internal/render/problem"
+	"github.com/lomocoin/stellar-go/support/render/problem"
 )
 
+// RateLimitMiddleware rate limits the requests made with an API key by the
+// quota of their key and the anonymous requests by remote IP address.
+// Requests made with an unknown key, or to a route their key is not allowed to
+// request, are rejected.
 func (web *Web) RateLimitMiddleware(next http.Handler) http.Handler {
-	if web.rateLimiter == nil {
-		return next
+	anonymous := next
+	if web.rateLimiter != nil {
+		anonymous = web.rateLimiter.RateLimit(next)
 	}
-	return web.rateLimiter.RateLimit(next)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// API keys are ignored unless some are configured
+		token := apikey.FromRequest(r)
+		if token == "" || len(web.apiKeyClients()) == 0 {
+			anonymous.ServeHTTP(w, r)
+			return
+		}
+
+		client, ok := web.apiKey(token)
+		if !ok {
+			problem.Render(r.Context(), w, hProblem.InvalidAPIKey)
+			return
+		}
+
+		if !client.Allows(r.URL.Path) {
+			problem.Render(r.Context(), w, hProblem.RouteNotAllowed)
+			return
+		}
+
+		client.requests.Inc(1)
+		if client.rateLimiter == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		client.rateLimiter.RateLimit(next).ServeHTTP(w, r)
+	})
 }
@@ -1,10 +1,17 @@
 package horizon
 
 import (
+	"io/ioutil"
+	"net/http"
+	"os"
 	"strconv"
 	"testing"
 
+	"github.com/lomocoin/stellar-go/services/horizon/internal/apikey"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/schema"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/test"
+	"github.com/rcrowley/go-metrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"github.com/throttled/throttled"
@@ -142,3 +149,178 @@ func TestRateLimit_Redis(t *testing.T) {
 	w = rh.Get("/", test.RequestHelperRemoteAddr("127.0.0.2"))
 	assert.Equal(t, 200, w.Code)
 }
+
+// API keys have their own quota and allowed routes
+func TestRateLimit_APIKeys(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	file := writeAPIKeysFile(t)
+	defer os.Remove(file)
+
+	c := NewTestConfig()
+	c.RateLimit = &throttled.RateQuota{
+		MaxRate:  throttled.PerHour(10),
+		MaxBurst: 0,
+	}
+	c.APIKeysFile = file
+	app, _ := NewApp(c)
+	defer app.Close()
+	rh := NewRequestHelper(app)
+
+	// requests made with a key are limited by the quota of the key
+	for i := 0; i < 2; i++ {
+		w := rh.Get("/", requestHelperAPIKey("partner-secret"))
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+	}
+	w := rh.Get("/?api_key=partner-secret")
+	assert.Equal(t, 429, w.Code)
+
+	// anonymous requests keep the default quota
+	w = rh.Get("/")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Limit"))
+	w = rh.Get("/")
+	assert.Equal(t, 429, w.Code)
+
+	// keys without a quota are not limited, but may be restricted to routes
+	for i := 0; i < 3; i++ {
+		w = rh.Get("/ledgers", requestHelperAPIKey("ledgers-secret"))
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, "", w.Header().Get("X-RateLimit-Limit"))
+	}
+	w = rh.Get("/transactions", requestHelperAPIKey("ledgers-secret"))
+	assert.Equal(t, 403, w.Code)
+
+	// unknown keys are rejected
+	w = rh.Get("/", requestHelperAPIKey("unknown"))
+	assert.Equal(t, 401, w.Code)
+
+	requests := app.metrics.Get(`api_keys.requests{key="partner"}`).(metrics.Counter)
+	assert.Equal(t, int64(3), requests.Count())
+	limited := app.metrics.Get(`api_keys.rate_limited{key="partner"}`).(metrics.Counter)
+	assert.Equal(t, int64(1), limited.Count())
+}
+
+// API keys sent in the query string are not echoed in the links of pages
+func TestRateLimit_APIKeysNotInLinks(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	file := writeAPIKeysFile(t)
+	defer os.Remove(file)
+
+	c := NewTestConfig()
+	c.APIKeysFile = file
+	app, _ := NewApp(c)
+	defer app.Close()
+	rh := NewRequestHelper(app)
+
+	w := rh.Get("/ledgers?limit=1&api_key=partner-secret")
+	if assert.Equal(t, 200, w.Code) {
+		assert.NotContains(t, w.Body.String(), "partner-secret")
+		assert.Contains(t, w.Body.String(), "limit=1")
+	}
+
+	// nor in the request logs
+	assert.Contains(t, ht.LogBuffer.String(), "/ledgers?limit=1")
+	assert.NotContains(t, ht.LogBuffer.String(), "partner-secret")
+}
+
+// API keys are also read from the database, which only stores their hash
+func TestRateLimit_APIKeysDatabase(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	// the api_keys table is newer than the scenario
+	_, err := schema.Migrate(ht.HorizonDB.DB, schema.MigrateUp, 0)
+	ht.Require.NoError(err)
+
+	q := &history.Q{Session: ht.HorizonSession()}
+	ht.Require.NoError(q.InsertAPIKey(history.APIKey{
+		KeyHash:          apikey.Hash("database-secret"),
+		Name:             "database",
+		PerHourRateLimit: 2,
+	}))
+
+	app, _ := NewApp(NewTestConfig())
+	defer app.Close()
+	rh := NewRequestHelper(app)
+
+	w := rh.Get("/", requestHelperAPIKey("database-secret"))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Limit"))
+
+	w = rh.Get("/", requestHelperAPIKey(apikey.Hash("database-secret")))
+	assert.Equal(t, 401, w.Code)
+}
+
+// API keys quotas are shared through redis
+func TestRateLimit_APIKeysRedis(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	file := writeAPIKeysFile(t)
+	defer os.Remove(file)
+
+	c := NewTestConfig()
+	c.RedisURL = "redis://127.0.0.1:6379/"
+	c.APIKeysFile = file
+	app, _ := NewApp(c)
+	defer app.Close()
+	rh := NewRequestHelper(app)
+
+	redis := app.redis.Get()
+	_, err := redis.Do("FLUSHDB")
+	assert.Nil(t, err)
+
+	w := rh.Get("/", requestHelperAPIKey("partner-secret"))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "36", w.Header().Get("X-RateLimit-Reset"))
+
+	// another horizon sharing the redis store shares the quota
+	other, _ := NewApp(c)
+	defer other.Close()
+
+	w = NewRequestHelper(other).Get("/", requestHelperAPIKey("partner-secret"))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+
+	w = rh.Get("/", requestHelperAPIKey("partner-secret"))
+	assert.Equal(t, 429, w.Code)
+}
+
+func requestHelperAPIKey(key string) func(r *http.Request) {
+	return func(r *http.Request) {
+		r.Header.Set(apikey.Header, key)
+	}
+}
+
+func writeAPIKeysFile(t *testing.T) string {
+	file, err := ioutil.TempFile("", "api-keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(`
+[[keys]]
+key = "partner-secret"
+name = "partner"
+per-hour-rate-limit = 100
+burst = 1
+
+[[keys]]
+key = "ledgers-secret"
+name = "ledgers-only"
+routes = ["/ledgers"]
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return file.Name()
+}
@@ -1,22 +1,44 @@
 package horizon
 
 import (
+	"fmt"
 	"net/http"
+	"sort"
+	"sync/atomic"
+	"time"
 
+	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
+	"github.com/rcrowley/go-metrics"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/render"
 )
 
 // Middleware that records metrics.
 //
-// It records success and failures using a meter, and times every request
+// It records success and failures using a meter, times every request, counts
+// the open streams and times the requests that are neither streams nor
+// websocket connections per route.
 func requestMetricsMiddleware(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		app := AppFromContext(r.Context())
 		mw := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 
-		app.web.requestTimer.Time(func() {
-			h.ServeHTTP(mw.(http.ResponseWriter), r)
-		})
+		stream := render.Negotiate(r) == render.MimeEventStream
+		if stream {
+			app.web.streamsGauge.Update(atomic.AddInt64(&app.web.openStreams, 1))
+			defer func() {
+				app.web.streamsGauge.Update(atomic.AddInt64(&app.web.openStreams, -1))
+			}()
+		}
+
+		start := time.Now()
+		h.ServeHTTP(mw.(http.ResponseWriter), r)
+		duration := time.Since(start)
+
+		app.web.requestTimer.Update(duration)
+		if !stream && !isWebsocketUpgrade(r) {
+			routeTimer(app, r).Update(duration)
+		}
 
 		if 200 <= mw.Status() && mw.Status() < 400 {
 			// a success is in [200, 400)
@@ -28,3 +50,74 @@ func requestMetricsMiddleware(h http.Handler) http.Handler {
 
 	})
 }
+
+// routeTimer returns the timer of the requests to the route matched by `r`.
+func routeTimer(app *App, r *http.Request) metrics.Timer {
+	route := chi.RouteContext(r.Context()).RoutePattern()
+	if route == "" {
+		route = "unmatched"
+	}
+
+	name := fmt.Sprintf("requests.duration{route=%q,method=%q}", route, r.Method)
+	return app.metrics.GetOrRegister(name, newRequestTimer).(metrics.Timer)
+}
+
+// requestDurationBuckets are the upper bounds, in seconds, of the buckets
+// request durations are counted in.
+var requestDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// requestTimer is a timer that also counts the durations it is updated with
+// in the fixed requestDurationBuckets, along with their exact sum.
+// It is exposed to Prometheus as a histogram, whose buckets can be summed
+// across instances unlike the quantiles of the sampled timer.
+type requestTimer struct {
+	metrics.Timer
+
+	// buckets holds the number of durations within each bucket and, last,
+	// of those above every bucket.
+	buckets []int64
+	sum     int64
+}
+
+func newRequestTimer() metrics.Timer {
+	return &requestTimer{
+		Timer:   metrics.NewTimer(),
+		buckets: make([]int64, len(requestDurationBuckets)+1),
+	}
+}
+
+// Time records the duration of the execution of `f`.
+func (t *requestTimer) Time(f func()) {
+	start := time.Now()
+	f()
+	t.UpdateSince(start)
+}
+
+// Update records the duration `d`.
+func (t *requestTimer) Update(d time.Duration) {
+	t.Timer.Update(d)
+
+	i := sort.SearchFloat64s(requestDurationBuckets, d.Seconds())
+	atomic.AddInt64(&t.buckets[i], 1)
+	atomic.AddInt64(&t.sum, int64(d))
+}
+
+// UpdateSince records the duration since `start`.
+func (t *requestTimer) UpdateSince(start time.Time) {
+	t.Update(time.Since(start))
+}
+
+// Histogram returns the cumulative count of the durations up to each of the
+// requestDurationBuckets, along with the count and sum, in seconds, of every
+// duration.
+func (t *requestTimer) Histogram() (cumulative []int64, count int64, sum float64) {
+	cumulative = make([]int64, len(requestDurationBuckets))
+	for i := range cumulative {
+		count += atomic.LoadInt64(&t.buckets[i])
+		cumulative[i] = count
+	}
+
+	count += atomic.LoadInt64(&t.buckets[len(cumulative)])
+	sum = float64(atomic.LoadInt64(&t.sum)) / 1e9
+	return cumulative, count, sum
+}
@@ -0,0 +1,157 @@
+package horizon
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/lomocoin/stellar-go/services/horizon/internal/apikey"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/httpx"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/render"
+)
+
+// maxBufferedResponseSize is the size above which a response is written as it
+// is produced rather than buffered to be tagged and cached.
+const maxBufferedResponseSize = 2 << 20
+
+// ResponseCacheMiddleware sets the ETag of successful GET responses, replying
+// with a 304 Not Modified response to requests whose If-None-Match header
+// matches it, and serves the responses marked immutable from the response
+// cache, when one is configured.  Streams and exports, written as they are
+// loaded, and responses larger than maxBufferedResponseSize are neither cached
+// nor tagged.
+func (web *Web) ResponseCacheMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mime := render.Negotiate(r)
+		switch {
+		case r.Method != http.MethodGet,
+			isWebsocketUpgrade(r),
+			mime == render.MimeEventStream,
+			mime == render.MimeCSV,
+			mime == render.MimeNDJSON:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := responseCacheKey(r, mime)
+		if web.responseCache != nil {
+			if cached, ok := web.responseCache.Get(key); ok {
+				web.cacheHits.Inc(1)
+				writeCachedResponse(w, r, cached)
+				return
+			}
+			web.cacheMisses.Inc(1)
+		}
+
+		buffered := &bufferedResponseWriter{
+			ResponseWriter: w,
+			status:         http.StatusOK,
+			limit:          maxBufferedResponseSize,
+		}
+		next.ServeHTTP(buffered, r)
+
+		if buffered.passThrough {
+			return
+		}
+
+		if buffered.status != http.StatusOK {
+			w.WriteHeader(buffered.status)
+			w.Write(buffered.body.Bytes())
+			return
+		}
+
+		sum := sha256.Sum256(buffered.body.Bytes())
+		response := &cachedResponse{
+			ContentType:  w.Header().Get("Content-Type"),
+			CacheControl: w.Header().Get("Cache-Control"),
+			ETag:         `W/"` + hex.EncodeToString(sum[:16]) + `"`,
+			Body:         buffered.body.Bytes(),
+		}
+
+		if web.responseCache != nil && strings.HasPrefix(response.CacheControl, "public") {
+			web.responseCache.Add(key, response)
+		}
+
+		writeCachedResponse(w, r, response)
+	})
+}
+
+// bufferedResponseWriter buffers the response written by a handler, up to
+// `limit` bytes.  Past the limit, the buffered response is written out and
+// the rest of the response passes through.
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	limit       int
+	body        bytes.Buffer
+	passThrough bool
+}
+
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	if w.passThrough {
+		return w.ResponseWriter.Write(b)
+	}
+
+	if w.body.Len()+len(b) <= w.limit {
+		return w.body.Write(b)
+	}
+
+	w.passThrough = true
+	w.ResponseWriter.WriteHeader(w.status)
+	_, err := w.ResponseWriter.Write(w.body.Bytes())
+	w.body = bytes.Buffer{}
+	if err != nil {
+		return 0, err
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// writeCachedResponse writes `response` to `w`, or a 304 Not Modified
+// response if the client already has it.
+func writeCachedResponse(w http.ResponseWriter, r *http.Request, response *cachedResponse) {
+	header := w.Header()
+	header.Set("Content-Type", response.ContentType)
+	header.Set("Cache-Control", response.CacheControl)
+	header.Set("ETag", response.ETag)
+
+	if etagMatches(r.Header.Get("If-None-Match"), response.ETag) {
+		header.Del("Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(response.Body)
+}
+
+// etagMatches returns true if the If-None-Match header value `ifNoneMatch`
+// matches `etag`, using the weak comparison.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" ||
+			strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// responseCacheKey returns the key the response to `r`, rendered as `mime`,
+// is cached at.  Responses contain absolute links built from the request url,
+// so the key includes the base url and the query of the request, less the API
+// key which links leave out too.
+func responseCacheKey(r *http.Request, mime string) string {
+	return mime + " " + httpx.BaseURL(r.Context()).String() + apikey.StripURL(r.URL).RequestURI()
+}
@@ -0,0 +1,126 @@
+package horizon
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lomocoin/stellar-go/services/horizon/internal/httpx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseCacheMiddleware(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	// immutable resources are public and tagged
+	w := ht.Get("/ledgers/1")
+	ht.Require.Equal(200, w.Code)
+	ht.Assert.Equal("public, max-age=0", w.Header().Get("Cache-Control"))
+	etag := w.Header().Get("ETag")
+	ht.Assert.NotEmpty(etag)
+
+	w = ht.Get("/ledgers/1", func(r *http.Request) {
+		r.Header.Set("If-None-Match", etag)
+	})
+	ht.Assert.Equal(304, w.Code)
+	ht.Assert.Equal(0, w.Body.Len())
+	ht.Assert.Equal(etag, w.Header().Get("ETag"))
+
+	// mutable resources are tagged but not public
+	w = ht.Get("/")
+	ht.Require.Equal(200, w.Code)
+	ht.Assert.Equal("no-cache, no-store, max-age=0", w.Header().Get("Cache-Control"))
+	ht.Assert.NotEmpty(w.Header().Get("ETag"))
+
+	// errors are neither
+	w = ht.Get("/ledgers/100")
+	ht.Assert.Equal(404, w.Code)
+	ht.Assert.Empty(w.Header().Get("ETag"))
+}
+
+func TestResponseCacheMiddleware_Cache(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	c := NewTestConfig()
+	c.ResponseCacheSize = 10
+	c.ResponseCacheMaxAge = time.Hour
+	app, err := NewApp(c)
+	ht.Require.NoError(err)
+	defer app.Close()
+	rh := NewRequestHelper(app)
+
+	w := rh.Get("/ledgers/1")
+	ht.Require.Equal(200, w.Code)
+	ht.Assert.Equal("public, max-age=3600", w.Header().Get("Cache-Control"))
+	body := w.Body.String()
+
+	w = rh.Get("/ledgers/1")
+	ht.Require.Equal(200, w.Code)
+	ht.Assert.Equal(body, w.Body.String())
+	ht.Assert.Equal("public, max-age=3600", w.Header().Get("Cache-Control"))
+	ht.Assert.Equal(int64(1), app.web.cacheHits.Count())
+	ht.Assert.Equal(int64(1), app.web.cacheMisses.Count())
+
+	// mutable resources are not cached
+	rh.Get("/")
+	rh.Get("/")
+	ht.Assert.Equal(int64(1), app.web.cacheHits.Count())
+	ht.Assert.Equal(int64(3), app.web.cacheMisses.Count())
+}
+
+func TestResponseCacheMiddleware_LargeResponse(t *testing.T) {
+	chunk := bytes.Repeat([]byte("a"), 64<<10)
+	chunks := maxBufferedResponseSize/len(chunk) + 2
+	handler := (&Web{}).ResponseCacheMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=0")
+		for i := 0; i < chunks; i++ {
+			w.Write(chunk)
+		}
+	}))
+
+	// responses past the size limit are streamed rather than tagged
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/ledgers", nil)
+	ctx, cancel := httpx.RequestContext(r.Context(), w, r)
+	defer cancel()
+	handler.ServeHTTP(w, r.WithContext(ctx))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, chunks*len(chunk), w.Body.Len())
+	assert.Empty(t, w.Header().Get("ETag"))
+}
+
+func TestEtagMatches(t *testing.T) {
+	etag := `W/"abc"`
+	cases := []struct {
+		ifNoneMatch string
+		expected    bool
+	}{
+		{"", false},
+		{`W/"abc"`, true},
+		{`"abc"`, true},
+		{`"def", W/"abc"`, true},
+		{`"def"`, false},
+		{"*", true},
+	}
+
+	for _, c := range cases {
+		if actual := etagMatches(c.ifNoneMatch, etag); actual != c.expected {
+			t.Errorf("etagMatches(%q, %q) = %v, expected %v", c.ifNoneMatch, etag, actual, c.expected)
+		}
+	}
+}
+
+func TestResponseCacheKey(t *testing.T) {
+	r := httptest.NewRequest("GET", "/ledgers?limit=1&api_key=partner-secret", nil)
+	ctx, cancel := httpx.RequestContext(r.Context(), httptest.NewRecorder(), r)
+	defer cancel()
+	r = r.WithContext(ctx)
+
+	key := responseCacheKey(r, "application/hal+json")
+	assert.NotContains(t, key, "partner-secret")
+	assert.Contains(t, key, "/ledgers?limit=1")
+}
@@ -0,0 +1,26 @@
+package horizon
+
+import (
+	"context"
+	"net/http"
+
+	hProblem "github.com/lomocoin/stellar-go/services/horizon/internal/render/problem"
+	"github.com/lomocoin/stellar-go/support/render/problem"
+)
+
+// shutdownMiddleware rejects the requests received once `draining` is done,
+// that is once horizon has started shutting down, asking clients to retry them
+// against another horizon while the requests in flight finish.
+func shutdownMiddleware(draining context.Context) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if draining.Err() != nil {
+				w.Header().Set("Connection", "close")
+				problem.Render(r.Context(), w, hProblem.ShuttingDown)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
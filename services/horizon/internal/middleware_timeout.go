@@ -0,0 +1,29 @@
+package horizon
+
+import (
+	"net/http"
+	"time"
+
+	chimiddleware "github.com/go-chi/chi/middleware"
+)
+
+// timeoutMiddleware cancels the context of requests after `timeout`, like
+// chimiddleware.Timeout, except for websocket connections: they are served
+// for as long as the client keeps them open, their subscriptions being
+// requests of their own, each bounded by the timeout.
+func timeoutMiddleware(timeout time.Duration) func(next http.Handler) http.Handler {
+	withTimeout := chimiddleware.Timeout(timeout)
+
+	return func(next http.Handler) http.Handler {
+		timed := withTimeout(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isWebsocketUpgrade(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			timed.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,418 @@
+package orderbook
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/lomocoin/stellar-go/services/horizon/internal/paths"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/simplepath"
+	"github.com/lomocoin/stellar-go/support/errors"
+	"github.com/lomocoin/stellar-go/support/log"
+	"github.com/lomocoin/stellar-go/xdr"
+)
+
+// DefaultMaxResults is the number of paths returned for each asset when
+// Finder.MaxResults is zero.
+const DefaultMaxResults = 20
+
+// ensure the struct is paths.Finder compliant
+var _ paths.Finder = &Finder{}
+
+// node is a path being searched as a linked list, along with the amount of its
+// head asset: for strict-receive queries the head is the source asset and
+// Amount is the cost of the path, for strict-send queries the head is the
+// destination asset and Amount is the amount received.
+type node struct {
+	Asset   xdr.Asset
+	AssetID string
+	Amount  xdr.Int64
+	Tail    *node
+	Depth   uint
+}
+
+// Find performs a breadth first search of the graph, starting from the
+// destination asset, and returns the cheapest paths found for each source
+// asset.
+func (f *Finder) Find(q paths.Query, maxLength uint) ([]paths.Path, error) {
+	if q.StrictSend {
+		return f.findStrictSend(q, maxLength)
+	}
+
+	log.WithField("source_assets", q.SourceAssets).
+		WithField("destination_asset", q.DestinationAsset).
+		WithField("destination_amount", q.DestinationAmount).
+		Info("Starting pathfind")
+
+	if len(q.SourceAssets) == 0 {
+		return nil, errors.New("No source assets")
+	}
+
+	s, maxLength, err := f.prepare(maxLength)
+	if err != nil {
+		return nil, err
+	}
+
+	start := &node{
+		Asset:   q.DestinationAsset,
+		AssetID: q.DestinationAsset.String(),
+		Amount:  q.DestinationAmount,
+		Depth:   1,
+	}
+
+	// The cheapest paths come first.
+	cheaper := func(a, b xdr.Int64) bool { return a < b }
+	results, rank, timedOut, err := f.search(s, start, q.SourceAssets, maxLength, false, cheaper)
+	if err != nil {
+		return nil, err
+	}
+
+	found := f.top(results, rank, cheaper)
+
+	log.WithField("found", len(found)).
+		WithField("ledger", s.Ledger).
+		WithField("timed_out", timedOut).
+		Info("Finished pathfind")
+	return toPaths(found, false, func(n *node, p *paths.Path) {
+		p.Cost = n.Amount
+	}), nil
+}
+
+// findStrictSend performs a breadth first search of the graph, starting from
+// the source asset, and returns the paths delivering the largest amounts for
+// each destination asset.
+func (f *Finder) findStrictSend(q paths.Query, maxLength uint) ([]paths.Path, error) {
+	log.WithField("source_asset", q.SourceAsset).
+		WithField("source_amount", q.SourceAmount).
+		WithField("destination_assets", q.DestinationAssets).
+		Info("Starting strict-send pathfind")
+
+	if len(q.DestinationAssets) == 0 {
+		return nil, errors.New("No destination assets")
+	}
+
+	s, maxLength, err := f.prepare(maxLength)
+	if err != nil {
+		return nil, err
+	}
+
+	start := &node{
+		Asset:   q.SourceAsset,
+		AssetID: q.SourceAsset.String(),
+		Amount:  q.SourceAmount,
+		Depth:   1,
+	}
+
+	// The paths delivering the most come first.
+	larger := func(a, b xdr.Int64) bool { return a > b }
+	results, rank, timedOut, err := f.search(s, start, q.DestinationAssets, maxLength, true, larger)
+	if err != nil {
+		return nil, err
+	}
+
+	found := f.top(results, rank, larger)
+
+	log.WithField("found", len(found)).
+		WithField("ledger", s.Ledger).
+		WithField("timed_out", timedOut).
+		Info("Finished strict-send pathfind")
+	return toPaths(found, true, func(n *node, p *paths.Path) {
+		p.Cost = q.SourceAmount
+		p.DestinationAmount = n.Amount
+	}), nil
+}
+
+// prepare validates `maxLength` and returns the snapshot to search.
+func (f *Finder) prepare(maxLength uint) (*snapshot, uint, error) {
+	if maxLength == 0 {
+		maxLength = simplepath.MaxPathLength
+	}
+
+	if maxLength < 2 || maxLength > simplepath.MaxPathLength {
+		return nil, 0, errors.New("invalid value of maxLength")
+	}
+
+	s := f.Graph.current()
+	if s == nil {
+		return nil, 0, ErrNotLoaded
+	}
+
+	return s, maxLength, nil
+}
+
+// search runs a breadth first search from `start` until every path of at most
+// `maxLength` assets has been explored or the timeout elapses.  It returns the
+// paths ending with one of `targets`, along with the position of each target in
+// `targets`.  Strict-receive searches extend paths with the order books selling
+// their head asset, strict-send searches with the order books buying it.
+//
+// A path is pruned when its head asset was already reached by as many paths as
+// are returned for each target, all of them with a better amount according to
+// `better`: extending it could only lead to paths worse than the ones extending
+// those.
+func (f *Finder) search(
+	s *snapshot,
+	start *node,
+	targets []xdr.Asset,
+	maxLength uint,
+	strictSend bool,
+	better func(a, b xdr.Int64) bool,
+) ([]*node, map[string]int, bool, error) {
+	rank := map[string]int{}
+	for i, a := range targets {
+		rank[a.String()] = i
+	}
+
+	best := bestAmounts{
+		Size:   f.maxResults(),
+		Better: better,
+		Assets: map[string][]xdr.Int64{},
+	}
+
+	var deadline time.Time
+	if f.Timeout > 0 {
+		deadline = time.Now().Add(f.Timeout)
+	}
+
+	var results []*node
+	queue := []*node{start}
+
+	for len(queue) > 0 {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return results, rank, true, nil
+		}
+
+		cur := queue[0]
+		queue = queue[1:]
+
+		if _, ok := rank[cur.AssetID]; ok {
+			results = append(results, cur)
+		}
+
+		if cur.Depth == maxLength {
+			continue
+		}
+
+		edges := s.Edges[cur.AssetID]
+		if strictSend {
+			edges = s.Buyers[cur.AssetID]
+		}
+
+		for _, e := range edges {
+			asset, id := e.Buying, e.BuyingID
+			if strictSend {
+				asset, id = e.Selling, e.SellingID
+			}
+
+			// Do not extend the path with an asset already on it, buying then
+			// selling the same asset is a bad deal in most cases.
+			if cur.isOnPath(id) {
+				continue
+			}
+
+			// The last asset of a path must be one of the targets.
+			if _, ok := rank[id]; !ok && cur.Depth == maxLength-1 {
+				continue
+			}
+
+			var (
+				amount xdr.Int64
+				err    error
+			)
+			if strictSend {
+				amount, err = e.amountToReceive(cur.Amount)
+			} else {
+				amount, err = e.costToConsumeLiquidity(cur.Amount)
+			}
+			if err == simplepath.ErrNotEnough {
+				continue
+			}
+			if err != nil {
+				return nil, nil, false, err
+			}
+
+			if !best.Add(id, amount) {
+				continue
+			}
+
+			queue = append(queue, &node{
+				Asset:   asset,
+				AssetID: id,
+				Amount:  amount,
+				Tail:    cur,
+				Depth:   cur.Depth + 1,
+			})
+		}
+	}
+
+	return results, rank, false, nil
+}
+
+// top orders `results` by target, then using `better`, and keeps the first
+// MaxResults paths of each target.
+func (f *Finder) top(results []*node, rank map[string]int, better func(a, b xdr.Int64) bool) []*node {
+	// A stable sort keeps the shortest paths first among equivalent paths.
+	sort.SliceStable(results, func(i, j int) bool {
+		ri, rj := rank[results[i].AssetID], rank[results[j].AssetID]
+		if ri != rj {
+			return ri < rj
+		}
+		return better(results[i].Amount, results[j].Amount)
+	})
+
+	found := []*node{}
+	perTarget := map[string]int{}
+	for _, n := range results {
+		if perTarget[n.AssetID] >= f.maxResults() {
+			continue
+		}
+		perTarget[n.AssetID]++
+		found = append(found, n)
+	}
+	return found
+}
+
+// maxResults returns the number of paths returned for each target.
+func (f *Finder) maxResults() int {
+	if f.MaxResults <= 0 {
+		return DefaultMaxResults
+	}
+	return f.MaxResults
+}
+
+// bestAmounts records, for each asset, the best amounts of the paths reaching
+// it during a search.
+type bestAmounts struct {
+	// Size is the number of amounts kept for each asset.
+	Size int
+	// Better returns true if `a` is a better amount than `b`.
+	Better func(a, b xdr.Int64) bool
+	// Assets maps the string representation of an asset to its best amounts,
+	// the best first.
+	Assets map[string][]xdr.Int64
+}
+
+// Add records that a path reaches the asset identified by `id` with `amount`.
+// It returns false, recording nothing, if Size paths already reached the asset
+// with an amount at least as good.
+func (b *bestAmounts) Add(id string, amount xdr.Int64) bool {
+	amounts := b.Assets[id]
+	i := sort.Search(len(amounts), func(i int) bool {
+		return b.Better(amount, amounts[i])
+	})
+	if i >= b.Size {
+		return false
+	}
+
+	amounts = append(amounts, 0)
+	copy(amounts[i+1:], amounts[i:])
+	amounts[i] = amount
+	if len(amounts) > b.Size {
+		amounts = amounts[:b.Size]
+	}
+	b.Assets[id] = amounts
+	return true
+}
+
+// costToConsumeLiquidity returns the units of e.Buying needed to buy
+// `sellingAmount` units of e.Selling from the offers of the edge.
+func (e *edge) costToConsumeLiquidity(sellingAmount xdr.Int64) (xdr.Int64, error) {
+	remaining := int64(sellingAmount)
+	var buyingAmount int64
+
+	for _, offer := range e.Offers {
+		buyingUnits, sellingUnits, err := simplepath.ConvertToBuyingUnits(
+			int64(offer.Amount),
+			remaining,
+			int64(offer.Pricen),
+			int64(offer.Priced),
+		)
+		if err != nil {
+			return 0, err
+		}
+
+		if buyingAmount > math.MaxInt64-buyingUnits {
+			return 0, errors.Errorf("adding these two values will cause an integer overflow: %d, %d", buyingAmount, buyingUnits)
+		}
+		buyingAmount += buyingUnits
+		remaining -= sellingUnits
+
+		if remaining <= 0 {
+			return xdr.Int64(buyingAmount), nil
+		}
+	}
+
+	return 0, simplepath.ErrNotEnough
+}
+
+// amountToReceive returns the units of e.Selling received when spending
+// `buyingAmount` units of e.Buying on the offers of the edge.
+func (e *edge) amountToReceive(buyingAmount xdr.Int64) (xdr.Int64, error) {
+	remaining := int64(buyingAmount)
+	var sellingAmount int64
+
+	for _, offer := range e.Offers {
+		sellingUnits, buyingUnits, err := simplepath.ConvertToSellingUnits(
+			int64(offer.Amount),
+			remaining,
+			int64(offer.Pricen),
+			int64(offer.Priced),
+		)
+		if err != nil {
+			return 0, err
+		}
+
+		if sellingAmount > math.MaxInt64-sellingUnits {
+			return 0, errors.Errorf("adding these two values will cause an integer overflow: %d, %d", sellingAmount, sellingUnits)
+		}
+		sellingAmount += sellingUnits
+		remaining -= buyingUnits
+
+		if remaining <= 0 {
+			return xdr.Int64(sellingAmount), nil
+		}
+	}
+
+	return 0, simplepath.ErrNotEnough
+}
+
+// isOnPath returns true if the asset identified by `id` is on the path.
+func (n *node) isOnPath(id string) bool {
+	for cur := n; cur != nil; cur = cur.Tail {
+		if cur.AssetID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// toPaths converts the paths found by a search into paths.Path, `reverse` being
+// set when the head of the paths is their destination asset.  `fill` sets the
+// amounts of each path.
+func toPaths(found []*node, reverse bool, fill func(*node, *paths.Path)) []paths.Path {
+	result := make([]paths.Path, 0, len(found))
+	for _, n := range found {
+		var assets []xdr.Asset
+		for cur := n; cur != nil; cur = cur.Tail {
+			assets = append(assets, cur.Asset)
+		}
+
+		if reverse {
+			for i, j := 0, len(assets)-1; i < j; i, j = i+1, j-1 {
+				assets[i], assets[j] = assets[j], assets[i]
+			}
+		}
+
+		p := paths.Path{
+			Source:      assets[0],
+			Destination: assets[len(assets)-1],
+		}
+		if len(assets) > 2 {
+			p.Path = assets[1 : len(assets)-1]
+		}
+
+		fill(n, &p)
+		result = append(result, p)
+	}
+	return result
+}
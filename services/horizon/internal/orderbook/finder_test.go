@@ -0,0 +1,194 @@
+package orderbook
+
+import (
+	"testing"
+
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/core"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/paths"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/simplepath"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/test"
+	"github.com/lomocoin/stellar-go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFinder(t *testing.T) {
+	tt := test.Start(t).Scenario("paths")
+	defer tt.Finish()
+
+	graph := &Graph{}
+	finder := &Finder{Graph: graph}
+
+	issuer := "GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN"
+	usd := makeAsset("USD", issuer)
+	eur := makeAsset("EUR", issuer)
+	inter1 := makeAsset("1", issuer)
+	inter21 := makeAsset("21", issuer)
+	inter22 := makeAsset("22", issuer)
+
+	query := paths.Query{
+		DestinationAddress: "GAEDTJ4PPEFVW5XV2S7LUXBEHNQMX5Q2GM562RJGOQG7GVCE5H3HIB4V",
+		DestinationAsset:   eur,
+		DestinationAmount:  xdr.Int64(200000000), // 20.0000000
+		SourceAssets:       []xdr.Asset{usd},
+	}
+
+	_, err := finder.Find(query, simplepath.MaxPathLength)
+	tt.Assert.Equal(ErrNotLoaded, err)
+
+	err = graph.Refresh(&core.Q{Session: tt.CoreSession()}, 3)
+	tt.Require.NoError(err)
+	tt.Assert.Equal(int32(3), graph.Ledger())
+
+	p, err := finder.Find(query, simplepath.MaxPathLength)
+	if tt.Assert.NoError(err) && tt.Assert.Len(p, 3) {
+		tt.Assert.Equal(usd.String(), p[0].Source.String())
+		tt.Assert.Equal(eur.String(), p[0].Destination.String())
+		tt.Assert.Equal(xdr.Int64(100000000), p[0].Cost) // 10.0000000
+		tt.Assert.Len(p[0].Path, 0)
+
+		tt.Assert.Equal(xdr.Int64(200000000), p[1].Cost)
+		if tt.Assert.Len(p[1].Path, 1) {
+			tt.Assert.Equal(inter1.String(), p[1].Path[0].String())
+		}
+
+		tt.Assert.Equal(xdr.Int64(200000000), p[2].Cost)
+		if tt.Assert.Len(p[2].Path, 2) {
+			tt.Assert.Equal(inter21.String(), p[2].Path[0].String())
+			tt.Assert.Equal(inter22.String(), p[2].Path[1].String())
+		}
+	}
+
+	// only the cheapest paths are returned
+	finder.MaxResults = 1
+	p, err = finder.Find(query, simplepath.MaxPathLength)
+	if tt.Assert.NoError(err) && tt.Assert.Len(p, 1) {
+		tt.Assert.Equal(xdr.Int64(100000000), p[0].Cost)
+	}
+
+	query.DestinationAmount = xdr.Int64(500000001)
+	p, err = finder.Find(query, simplepath.MaxPathLength)
+	if tt.Assert.NoError(err) {
+		tt.Assert.Len(p, 0)
+	}
+
+	// strict-send: sending 10 USD, the direct path delivers the most EUR
+	query = paths.Query{
+		StrictSend:        true,
+		SourceAsset:       usd,
+		SourceAmount:      xdr.Int64(100000000), // 10.0000000
+		DestinationAssets: []xdr.Asset{eur},
+	}
+	p, err = finder.Find(query, simplepath.MaxPathLength)
+	if tt.Assert.NoError(err) && tt.Assert.Len(p, 1) {
+		tt.Assert.Equal(usd.String(), p[0].Source.String())
+		tt.Assert.Equal(eur.String(), p[0].Destination.String())
+		tt.Assert.Equal(xdr.Int64(100000000), p[0].Cost)
+		tt.Assert.Equal(xdr.Int64(200000000), p[0].DestinationAmount) // 20.0000000
+		tt.Assert.Len(p[0].Path, 0)
+	}
+}
+
+func TestFinderOrder(t *testing.T) {
+	graph := &Graph{}
+	loadGraph(t, graph, []core.Offer{
+		// 10 EUR cost 20 USD directly...
+		makeOffer(t, 1, eur, usd, 1000000000, 2, 1),
+		// ...10 USD through BTC...
+		makeOffer(t, 2, eur, btc, 1000000000, 1, 1),
+		makeOffer(t, 3, btc, usd, 1000000000, 1, 1),
+		// ...and 15 USD through ETH.
+		makeOffer(t, 4, eur, eth, 1000000000, 1, 2),
+		makeOffer(t, 5, eth, usd, 1000000000, 3, 1),
+	})
+	finder := &Finder{Graph: graph}
+
+	query := paths.Query{
+		DestinationAsset:  eur,
+		DestinationAmount: xdr.Int64(100000000), // 10.0000000
+		SourceAssets:      []xdr.Asset{usd},
+	}
+
+	p, err := finder.Find(query, 3)
+	if assert.NoError(t, err) && assert.Len(t, p, 3) {
+		assert.Equal(t, xdr.Int64(100000000), p[0].Cost)
+		assertPath(t, p[0], usd, btc, eur)
+		assert.Equal(t, xdr.Int64(150000000), p[1].Cost)
+		assertPath(t, p[1], usd, eth, eur)
+		assert.Equal(t, xdr.Int64(200000000), p[2].Cost)
+		assertPath(t, p[2], usd, eur)
+	}
+
+	// paths longer than maxLength are not searched
+	p, err = finder.Find(query, 2)
+	if assert.NoError(t, err) && assert.Len(t, p, 1) {
+		assertPath(t, p[0], usd, eur)
+	}
+
+	// the paths costlier than the cheapest one are pruned
+	finder.MaxResults = 1
+	p, err = finder.Find(query, 3)
+	if assert.NoError(t, err) && assert.Len(t, p, 1) {
+		assert.Equal(t, xdr.Int64(100000000), p[0].Cost)
+		assertPath(t, p[0], usd, btc, eur)
+	}
+
+	// strict-send: sending 10 USD delivers 10 EUR through BTC, 6.6666666
+	// through ETH and 5 directly.
+	finder.MaxResults = 0
+	query = paths.Query{
+		StrictSend:        true,
+		SourceAsset:       usd,
+		SourceAmount:      xdr.Int64(100000000), // 10.0000000
+		DestinationAssets: []xdr.Asset{eur},
+	}
+	p, err = finder.Find(query, 3)
+	if assert.NoError(t, err) && assert.Len(t, p, 3) {
+		assert.Equal(t, xdr.Int64(100000000), p[0].DestinationAmount)
+		assertPath(t, p[0], usd, btc, eur)
+		assert.Equal(t, xdr.Int64(66666666), p[1].DestinationAmount)
+		assertPath(t, p[1], usd, eth, eur)
+		assert.Equal(t, xdr.Int64(50000000), p[2].DestinationAmount)
+		assertPath(t, p[2], usd, eur)
+	}
+}
+
+func TestBestAmounts(t *testing.T) {
+	best := bestAmounts{
+		Size:   2,
+		Better: func(a, b xdr.Int64) bool { return a < b },
+		Assets: map[string][]xdr.Int64{},
+	}
+
+	assert.True(t, best.Add("usd", 30))
+	assert.True(t, best.Add("usd", 20))
+	assert.False(t, best.Add("usd", 30))
+	assert.True(t, best.Add("usd", 10))
+	assert.Equal(t, []xdr.Int64{10, 20}, best.Assets["usd"])
+	assert.False(t, best.Add("usd", 20))
+	assert.True(t, best.Add("eur", 40))
+}
+
+func assertPath(t *testing.T, p paths.Path, assets ...xdr.Asset) {
+	assert.Equal(t, assets[0].String(), p.Source.String())
+	assert.Equal(t, assets[len(assets)-1].String(), p.Destination.String())
+	if assert.Len(t, p.Path, len(assets)-2) {
+		for i, asset := range assets[1 : len(assets)-1] {
+			assert.Equal(t, asset.String(), p.Path[i].String())
+		}
+	}
+}
+
+func makeAsset(code string, issuer string) xdr.Asset {
+	var aid xdr.AccountId
+	err := aid.SetAddress(issuer)
+	if err != nil {
+		panic(err)
+	}
+
+	var result xdr.Asset
+	err = result.SetCredit(code, aid)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
@@ -0,0 +1,409 @@
+package orderbook
+
+import (
+	"sort"
+
+	"github.com/guregu/null"
+	"github.com/lomocoin/stellar-go/meta"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/core"
+	"github.com/lomocoin/stellar-go/support/errors"
+	"github.com/lomocoin/stellar-go/xdr"
+)
+
+// maxIncrementalLedgers is the largest number of ledgers whose offer changes
+// are applied to the graph.  The graph is reloaded from the offers table when
+// it falls further behind stellar-core.
+const maxIncrementalLedgers = 10
+
+// Ledger returns the sequence of the ledger the graph was last loaded at, or
+// 0 if it was never loaded.
+func (g *Graph) Ledger() int32 {
+	s := g.current()
+	if s == nil {
+		return 0
+	}
+	return s.Ledger
+}
+
+// Refresh brings the graph up to date with ledger `seq`.  The first time, or
+// when the graph is more than maxIncrementalLedgers behind, every offer is
+// loaded from stellar-core's database.  Otherwise only the offers changed by
+// the transactions of the ledgers closed since the last refresh are updated.
+func (g *Graph) Refresh(q *core.Q, seq int32) error {
+	g.refreshLock.Lock()
+	defer g.refreshLock.Unlock()
+
+	s := g.current()
+	if s != nil && s.Ledger == seq {
+		return nil
+	}
+
+	if s == nil || seq < s.Ledger || seq-s.Ledger > maxIncrementalLedgers {
+		return g.reload(q, seq)
+	}
+
+	for l := s.Ledger + 1; l <= seq; l++ {
+		var txs []core.Transaction
+		err := q.TransactionsByLedger(&txs, l)
+		if err != nil {
+			return g.reload(q, seq)
+		}
+
+		var changes []xdr.LedgerEntryChange
+		for _, tx := range txs {
+			m := meta.Bundle{TransactionMeta: tx.ResultMeta}
+			changes = append(changes, m.TransactionChanges()...)
+		}
+
+		err = g.apply(l, changes)
+		if err != nil {
+			// the offers held by the graph may be partially updated: start
+			// over from the offers table.
+			return g.reload(q, seq)
+		}
+	}
+
+	return nil
+}
+
+// reload replaces the graph with every offer of stellar-core's database.
+func (g *Graph) reload(q *core.Q, seq int32) error {
+	var offers []core.Offer
+	err := q.AllOffers(&offers)
+	if err != nil {
+		return errors.Wrap(err, "failed to load offers")
+	}
+
+	next, err := newSnapshot(seq, offers)
+	if err != nil {
+		return errors.Wrap(err, "failed to build order book graph")
+	}
+
+	g.offers = make(map[int64]core.Offer, len(offers))
+	for _, offer := range offers {
+		g.offers[offer.OfferID] = offer
+	}
+
+	g.lock.Lock()
+	g.snapshot = next
+	g.lock.Unlock()
+	return nil
+}
+
+// apply updates the graph with the offer changes of ledger `seq`, in the order
+// stellar-core applied them.  Only the order books with a changed offer are
+// rebuilt, the others are shared with the previous snapshot.
+func (g *Graph) apply(seq int32, changes []xdr.LedgerEntryChange) error {
+	prev := g.current()
+	if prev == nil {
+		return ErrNotLoaded
+	}
+
+	// touched maps the order books with a changed offer to the ids of those
+	// offers.
+	touched := map[string]map[int64]bool{}
+	touch := func(offer core.Offer) error {
+		key, err := pairKey(offer)
+		if err != nil {
+			return err
+		}
+		if touched[key] == nil {
+			touched[key] = map[int64]bool{}
+		}
+		touched[key][offer.OfferID] = true
+		return nil
+	}
+
+	for _, change := range changes {
+		key := change.LedgerKey()
+		if key.Type != xdr.LedgerEntryTypeOffer {
+			continue
+		}
+		id := int64(key.MustOffer().OfferId)
+
+		if old, ok := g.offers[id]; ok {
+			if err := touch(old); err != nil {
+				return err
+			}
+		}
+
+		var entry xdr.LedgerEntry
+		switch change.Type {
+		case xdr.LedgerEntryChangeTypeLedgerEntryState:
+			continue
+		case xdr.LedgerEntryChangeTypeLedgerEntryRemoved:
+			delete(g.offers, id)
+			continue
+		case xdr.LedgerEntryChangeTypeLedgerEntryCreated:
+			entry = change.MustCreated()
+		case xdr.LedgerEntryChangeTypeLedgerEntryUpdated:
+			entry = change.MustUpdated()
+		default:
+			return errors.Errorf("unknown change type: %v", change.Type)
+		}
+
+		offer, err := offerFromEntry(entry)
+		if err != nil {
+			return err
+		}
+		if err = touch(offer); err != nil {
+			return err
+		}
+		g.offers[id] = offer
+	}
+
+	next, err := prev.update(seq, touched, g.offers)
+	if err != nil {
+		return err
+	}
+
+	g.lock.Lock()
+	g.snapshot = next
+	g.lock.Unlock()
+	return nil
+}
+
+func (g *Graph) current() *snapshot {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	return g.snapshot
+}
+
+// newSnapshot groups `offers`, expected to be ordered by ascending price, by
+// pair of assets.
+func newSnapshot(seq int32, offers []core.Offer) (*snapshot, error) {
+	result := &snapshot{
+		Ledger: seq,
+		Pairs:  map[string]*edge{},
+		Edges:  map[string][]*edge{},
+		Buyers: map[string][]*edge{},
+	}
+
+	for _, offer := range offers {
+		key, err := pairKey(offer)
+		if err != nil {
+			return nil, err
+		}
+
+		e, ok := result.Pairs[key]
+		if !ok {
+			e, err = newEdge(offer)
+			if err != nil {
+				return nil, err
+			}
+			result.Pairs[key] = e
+			result.Edges[e.SellingID] = append(result.Edges[e.SellingID], e)
+			result.Buyers[e.BuyingID] = append(result.Buyers[e.BuyingID], e)
+		}
+		e.Offers = append(e.Offers, offer)
+	}
+
+	for _, edges := range result.Edges {
+		sortBy(edges, func(e *edge) string { return e.BuyingID })
+	}
+
+	for _, edges := range result.Buyers {
+		sortBy(edges, func(e *edge) string { return e.SellingID })
+	}
+
+	return result, nil
+}
+
+// update returns a copy of the snapshot at ledger `seq` whose order books
+// listed in `touched` are rebuilt from the current version of their changed
+// offers, as found in `offers`.
+func (s *snapshot) update(
+	seq int32,
+	touched map[string]map[int64]bool,
+	offers map[int64]core.Offer,
+) (*snapshot, error) {
+	result := &snapshot{
+		Ledger: seq,
+		Pairs:  make(map[string]*edge, len(s.Pairs)),
+		Edges:  make(map[string][]*edge, len(s.Edges)),
+		Buyers: make(map[string][]*edge, len(s.Buyers)),
+	}
+	for key, e := range s.Pairs {
+		result.Pairs[key] = e
+	}
+
+	// the current version of the changed offers, by order book
+	changed := map[string][]core.Offer{}
+	seen := map[int64]bool{}
+	for _, ids := range touched {
+		for id := range ids {
+			offer, ok := offers[id]
+			if !ok || seen[id] {
+				continue
+			}
+			seen[id] = true
+			key, err := pairKey(offer)
+			if err != nil {
+				return nil, err
+			}
+			changed[key] = append(changed[key], offer)
+		}
+	}
+
+	for key, ids := range touched {
+		var (
+			e   *edge
+			err error
+		)
+		if old, ok := s.Pairs[key]; ok {
+			e = &edge{
+				Selling:   old.Selling,
+				SellingID: old.SellingID,
+				Buying:    old.Buying,
+				BuyingID:  old.BuyingID,
+			}
+			for _, offer := range old.Offers {
+				if !ids[offer.OfferID] {
+					e.Offers = append(e.Offers, offer)
+				}
+			}
+		} else if len(changed[key]) > 0 {
+			e, err = newEdge(changed[key][0])
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			continue
+		}
+
+		e.Offers = append(e.Offers, changed[key]...)
+		sort.Slice(e.Offers, func(i, j int) bool {
+			if e.Offers[i].Price != e.Offers[j].Price {
+				return e.Offers[i].Price < e.Offers[j].Price
+			}
+			return e.Offers[i].OfferID < e.Offers[j].OfferID
+		})
+
+		if len(e.Offers) == 0 {
+			delete(result.Pairs, key)
+		} else {
+			result.Pairs[key] = e
+		}
+	}
+
+	// rebuild the lists of order books of every asset, sharing the lists left
+	// unchanged.
+	sellers, buyers := map[string]bool{}, map[string]bool{}
+	for key := range touched {
+		if e, ok := s.Pairs[key]; ok {
+			sellers[e.SellingID], buyers[e.BuyingID] = true, true
+		}
+		if e, ok := result.Pairs[key]; ok {
+			sellers[e.SellingID], buyers[e.BuyingID] = true, true
+		}
+	}
+
+	for id, edges := range s.Edges {
+		if !sellers[id] {
+			result.Edges[id] = edges
+		}
+	}
+	for id, edges := range s.Buyers {
+		if !buyers[id] {
+			result.Buyers[id] = edges
+		}
+	}
+	for _, e := range result.Pairs {
+		if sellers[e.SellingID] {
+			result.Edges[e.SellingID] = append(result.Edges[e.SellingID], e)
+		}
+		if buyers[e.BuyingID] {
+			result.Buyers[e.BuyingID] = append(result.Buyers[e.BuyingID], e)
+		}
+	}
+	for id := range sellers {
+		sortBy(result.Edges[id], func(e *edge) string { return e.BuyingID })
+	}
+	for id := range buyers {
+		sortBy(result.Buyers[id], func(e *edge) string { return e.SellingID })
+	}
+
+	return result, nil
+}
+
+// newEdge returns an empty order book for the pair of assets of `offer`.
+func newEdge(offer core.Offer) (*edge, error) {
+	selling, buying, err := offerAssets(offer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &edge{
+		Selling:   selling,
+		SellingID: selling.String(),
+		Buying:    buying,
+		BuyingID:  buying.String(),
+	}, nil
+}
+
+// pairKey identifies the order book `offer` belongs to.
+func pairKey(offer core.Offer) (string, error) {
+	selling, buying, err := offerAssets(offer)
+	if err != nil {
+		return "", err
+	}
+	return selling.String() + "/" + buying.String(), nil
+}
+
+func offerAssets(offer core.Offer) (selling xdr.Asset, buying xdr.Asset, err error) {
+	selling, err = core.AssetFromDB(
+		offer.SellingAssetType,
+		offer.SellingAssetCode.String,
+		offer.SellingIssuer.String,
+	)
+	if err != nil {
+		return
+	}
+
+	buying, err = core.AssetFromDB(
+		offer.BuyingAssetType,
+		offer.BuyingAssetCode.String,
+		offer.BuyingIssuer.String,
+	)
+	return
+}
+
+// offerFromEntry converts an offer ledger entry to the row stellar-core
+// stores for it in the offers table.
+func offerFromEntry(entry xdr.LedgerEntry) (core.Offer, error) {
+	o := entry.Data.MustOffer()
+	result := core.Offer{
+		SellerID:     o.SellerId.Address(),
+		OfferID:      int64(o.OfferId),
+		Amount:       o.Amount,
+		Pricen:       int32(o.Price.N),
+		Priced:       int32(o.Price.D),
+		Price:        float64(o.Price.N) / float64(o.Price.D),
+		Flags:        int32(o.Flags),
+		Lastmodified: int32(entry.LastModifiedLedgerSeq),
+	}
+
+	var code, issuer string
+	err := o.Selling.Extract(&result.SellingAssetType, &code, &issuer)
+	if err != nil {
+		return result, err
+	}
+	result.SellingAssetCode = null.NewString(code, code != "")
+	result.SellingIssuer = null.NewString(issuer, issuer != "")
+
+	code, issuer = "", ""
+	err = o.Buying.Extract(&result.BuyingAssetType, &code, &issuer)
+	if err != nil {
+		return result, err
+	}
+	result.BuyingAssetCode = null.NewString(code, code != "")
+	result.BuyingIssuer = null.NewString(issuer, issuer != "")
+
+	return result, nil
+}
+
+func sortBy(edges []*edge, id func(*edge) string) {
+	sort.Slice(edges, func(i, j int) bool {
+		return id(edges[i]) < id(edges[j])
+	})
+}
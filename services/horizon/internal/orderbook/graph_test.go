@@ -0,0 +1,154 @@
+package orderbook
+
+import (
+	"testing"
+
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/core"
+	"github.com/lomocoin/stellar-go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	issuer = "GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN"
+	usd    = makeAsset("USD", issuer)
+	eur    = makeAsset("EUR", issuer)
+	btc    = makeAsset("BTC", issuer)
+	eth    = makeAsset("ETH", issuer)
+)
+
+func TestGraphApply(t *testing.T) {
+	graph := &Graph{}
+	assert.Equal(t, ErrNotLoaded, graph.apply(2, nil))
+
+	offers := []core.Offer{
+		makeOffer(t, 1, eur, usd, 1000000000, 2, 1),
+		makeOffer(t, 2, eur, btc, 1000000000, 1, 1),
+		makeOffer(t, 3, btc, usd, 1000000000, 1, 1),
+	}
+	first := loadGraph(t, graph, offers)
+
+	changes := []xdr.LedgerEntryChange{
+		// offer 1 is partially filled
+		offerState(offerEntry(1, eur, usd, 1000000000, 2, 1)),
+		offerUpdated(offerEntry(1, eur, usd, 400000000, 2, 1)),
+		// offer 3 is cancelled
+		offerState(offerEntry(3, btc, usd, 1000000000, 1, 1)),
+		offerRemoved(3),
+		// offers 4 and 5 are created, 4 being cheaper than 1
+		offerCreated(offerEntry(4, eur, usd, 100000000, 1, 1)),
+		offerCreated(offerEntry(5, btc, eth, 100000000, 1, 1)),
+	}
+	require.NoError(t, graph.apply(3, changes))
+	assert.Equal(t, int32(3), graph.Ledger())
+
+	s := graph.current()
+	if e := s.Pairs[eur.String()+"/"+usd.String()]; assert.NotNil(t, e) && assert.Len(t, e.Offers, 2) {
+		assert.Equal(t, int64(4), e.Offers[0].OfferID)
+		assert.Equal(t, int64(1), e.Offers[1].OfferID)
+		assert.Equal(t, xdr.Int64(400000000), e.Offers[1].Amount)
+	}
+	assert.Nil(t, s.Pairs[btc.String()+"/"+usd.String()])
+	assert.NotNil(t, s.Pairs[btc.String()+"/"+eth.String()])
+
+	// the order books of each asset
+	assertEdges(t, s.Edges[btc.String()], eth)
+	assertEdges(t, s.Edges[eur.String()], btc, usd)
+	assert.Len(t, s.Buyers[usd.String()], 1)
+	assert.Len(t, s.Buyers[eth.String()], 1)
+
+	// untouched order books are shared, previous snapshots are left unchanged
+	assert.True(t, s.Pairs[eur.String()+"/"+btc.String()] == first.Pairs[eur.String()+"/"+btc.String()])
+	assert.Len(t, first.Pairs, 3)
+	assert.Len(t, first.Pairs[eur.String()+"/"+usd.String()].Offers, 1)
+	assert.Equal(t, xdr.Int64(1000000000), first.Pairs[eur.String()+"/"+usd.String()].Offers[0].Amount)
+
+	// an offer can change its assets
+	changes = []xdr.LedgerEntryChange{
+		offerUpdated(offerEntry(5, btc, usd, 100000000, 1, 1)),
+	}
+	require.NoError(t, graph.apply(4, changes))
+	s = graph.current()
+	assert.Nil(t, s.Pairs[btc.String()+"/"+eth.String()])
+	assert.Nil(t, s.Edges[eth.String()])
+	assert.Nil(t, s.Buyers[eth.String()])
+	assertEdges(t, s.Edges[btc.String()], usd)
+}
+
+func assertEdges(t *testing.T, edges []*edge, buying ...xdr.Asset) {
+	if assert.Len(t, edges, len(buying)) {
+		for i, asset := range buying {
+			assert.Equal(t, asset.String(), edges[i].BuyingID)
+		}
+	}
+}
+
+// loadGraph loads `offers` into `graph` as if stellar-core was at ledger 2.
+func loadGraph(t *testing.T, graph *Graph, offers []core.Offer) *snapshot {
+	s, err := newSnapshot(2, offers)
+	require.NoError(t, err)
+
+	graph.snapshot = s
+	graph.offers = map[int64]core.Offer{}
+	for _, offer := range offers {
+		graph.offers[offer.OfferID] = offer
+	}
+	return s
+}
+
+func makeOffer(t *testing.T, id int64, selling, buying xdr.Asset, amount xdr.Int64, n, d int32) core.Offer {
+	offer, err := offerFromEntry(offerEntry(id, selling, buying, amount, n, d))
+	require.NoError(t, err)
+	return offer
+}
+
+func offerEntry(id int64, selling, buying xdr.Asset, amount xdr.Int64, n, d int32) xdr.LedgerEntry {
+	var seller xdr.AccountId
+	err := seller.SetAddress(issuer)
+	if err != nil {
+		panic(err)
+	}
+
+	return xdr.LedgerEntry{
+		LastModifiedLedgerSeq: 2,
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeOffer,
+			Offer: &xdr.OfferEntry{
+				SellerId: seller,
+				OfferId:  xdr.Uint64(id),
+				Selling:  selling,
+				Buying:   buying,
+				Amount:   amount,
+				Price:    xdr.Price{N: xdr.Int32(n), D: xdr.Int32(d)},
+			},
+		},
+	}
+}
+
+func offerState(entry xdr.LedgerEntry) xdr.LedgerEntryChange {
+	return xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &entry}
+}
+
+func offerCreated(entry xdr.LedgerEntry) xdr.LedgerEntryChange {
+	return xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated, Created: &entry}
+}
+
+func offerUpdated(entry xdr.LedgerEntry) xdr.LedgerEntryChange {
+	return xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: &entry}
+}
+
+func offerRemoved(id int64) xdr.LedgerEntryChange {
+	var seller xdr.AccountId
+	err := seller.SetAddress(issuer)
+	if err != nil {
+		panic(err)
+	}
+
+	return xdr.LedgerEntryChange{
+		Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved,
+		Removed: &xdr.LedgerKey{
+			Type:  xdr.LedgerEntryTypeOffer,
+			Offer: &xdr.LedgerKeyOffer{SellerId: seller, OfferId: xdr.Uint64(id)},
+		},
+	}
+}
@@ -0,0 +1,70 @@
+// Package orderbook provides an in-memory graph of stellar-core's order book
+// and an implementation of paths.Finder that searches it.  The graph is
+// updated with the offers changed by every ledger stellar-core closes so that,
+// unlike simplepath, finding paths does not issue any query against the
+// database.
+package orderbook
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/core"
+	"github.com/lomocoin/stellar-go/support/errors"
+	"github.com/lomocoin/stellar-go/xdr"
+)
+
+// ErrNotLoaded is returned when searching a graph that has not been loaded
+// from stellar-core's database yet.
+var ErrNotLoaded = errors.New("order book graph is not loaded")
+
+// Graph is an in-memory view of every offer of stellar-core's order book. It
+// is safe for concurrent use: Refresh replaces the whole graph at once while
+// readers keep using the snapshot they started with.
+type Graph struct {
+	lock     sync.RWMutex
+	snapshot *snapshot
+
+	// refreshLock serializes refreshes, which own `offers`.
+	refreshLock sync.Mutex
+	// offers is every offer of the current snapshot, by id.
+	offers map[int64]core.Offer
+}
+
+// snapshot is the order book as of a single ledger.
+type snapshot struct {
+	Ledger int32
+	// Pairs maps the string representations of the selling and buying assets,
+	// joined by a slash, to their one-way order book.
+	Pairs map[string]*edge
+	// Edges maps the string representation of an asset to the one-way order
+	// books selling it, ordered by buying asset.
+	Edges map[string][]*edge
+	// Buyers maps the string representation of an asset to the one-way order
+	// books buying it, ordered by selling asset.
+	Buyers map[string][]*edge
+}
+
+// edge is a one-way order book: the offers selling `Selling` in exchange for
+// `Buying`, ordered by ascending price.  Edges are never modified once part of
+// a snapshot.
+type edge struct {
+	Selling   xdr.Asset
+	SellingID string
+	Buying    xdr.Asset
+	BuyingID  string
+	Offers    []core.Offer
+}
+
+// Finder implements the paths.Finder interface and searches for payment paths
+// in an in-memory Graph, returning the cheapest paths found for each source
+// asset within a time budget.
+type Finder struct {
+	Graph *Graph
+	// MaxResults is the maximum number of paths returned for each source
+	// asset, DefaultMaxResults when zero.
+	MaxResults int
+	// Timeout bounds the time spent searching, the paths found when it
+	// elapses are returned.  Zero means no limit.
+	Timeout time.Duration
+}
@@ -10,6 +10,16 @@ type Query struct {
 	DestinationAsset   xdr.Asset
 	DestinationAmount  xdr.Int64
 	SourceAssets       []xdr.Asset
+
+	// StrictSend switches the query to the strict-send mode: instead of
+	// looking for the cost, in each of SourceAssets, of delivering
+	// DestinationAmount of DestinationAsset, the finder looks for the amount
+	// of each of DestinationAssets received when sending SourceAmount of
+	// SourceAsset.
+	StrictSend        bool
+	SourceAsset       xdr.Asset
+	SourceAmount      xdr.Int64
+	DestinationAssets []xdr.Asset
 }
 
 // Path is the result returned by a path finder and is tied to the DestinationAmount used in the input query
@@ -19,6 +29,9 @@ type Path struct {
 	Destination xdr.Asset
 	// represents the source assets to be used as `sendMax` field for a `PathPaymentOp` struct
 	Cost xdr.Int64
+	// represents the amount of the destination asset received by a strict-send
+	// query path, which is tied to the SourceAmount used in the input query
+	DestinationAmount xdr.Int64
 }
 
 // Finder finds paths.
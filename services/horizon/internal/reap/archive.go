@@ -0,0 +1,370 @@
+package reap
+
+import (
+	"bufio"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/lomocoin/stellar-go/services/horizon/internal/toid"
+	"github.com/lomocoin/stellar-go/support/errors"
+	"github.com/lomocoin/stellar-go/support/log"
+)
+
+// restoreBatchSize is the number of archived rows inserted per statement when
+// restoring a segment.
+const restoreBatchSize = 1000
+
+// Restore loads every archived segment that overlaps the ledgers `start`
+// through `end`, inclusive, back into the history database.  Segments are
+// restored whole, and rows already present in the database are skipped,
+// making it safe to restore the same segment more than once.  Returns the
+// number of segments restored.
+func (r *System) Restore(start, end int32) (int, error) {
+	if r.Archive == nil {
+		return 0, errors.New("no archive configured")
+	}
+
+	segments, err := r.archivedSegments()
+	if err != nil {
+		return 0, errors.Wrap(err, "list segments failed")
+	}
+
+	restored := 0
+	for _, name := range segments {
+		var manifest segmentManifest
+		err = r.readManifest(name, &manifest)
+		if err != nil {
+			return restored, errors.Wrap(err, "read manifest failed")
+		}
+
+		if manifest.From > end || manifest.To < start {
+			continue
+		}
+
+		err = r.restoreSegment(name)
+		if err != nil {
+			return restored, errors.Wrapf(err, "restore segment %s failed", name)
+		}
+
+		log.
+			WithField("from", manifest.From).
+			WithField("to", manifest.To).
+			Info("reaper: restored segment")
+		restored++
+	}
+
+	return restored, nil
+}
+
+// archiveBefore exports every row that will be removed when clearing the
+// history prior to the ledger `seq` to a new segment in the archive.
+func (r *System) archiveBefore(elder, seq int32) error {
+	name := segmentName(elder, seq-1)
+	manifest := segmentManifest{
+		From: elder,
+		To:   seq - 1,
+		Rows: map[string]int64{},
+	}
+
+	for _, table := range reapedTables {
+		count, err := r.archiveTable(name, table, seq)
+		if err != nil {
+			return errors.Wrapf(err, "archive %s failed", table.Name)
+		}
+		manifest.Rows[table.Name] = count
+	}
+
+	js, err := json.Marshal(manifest)
+	if err != nil {
+		return errors.Wrap(err, "marshal manifest failed")
+	}
+
+	err = r.Archive.PutFile(path.Join(name, "manifest.json"), strings.NewReader(string(js)))
+	if err != nil {
+		return errors.Wrap(err, "write manifest failed")
+	}
+
+	log.WithField("segment", name).Info("reaper: archived")
+	return nil
+}
+
+// archiveTable writes the rows of `table` that precede the ledger `seq` to the
+// segment `name` as gzipped NDJSON, returning the number of rows written.
+func (r *System) archiveTable(name string, table archivedTable, seq int32) (int64, error) {
+	// segments are buffered through a temporary file so that backends can
+	// upload them without holding the whole segment in memory.
+	tmp, err := ioutil.TempFile("", "horizon-reap-")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	where, args := table.Where(seq)
+	rows, err := r.HorizonDB.QueryRaw(fmt.Sprintf(
+		"SELECT row_to_json(t) FROM %s t WHERE %s", table.Name, where,
+	), args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	gz := gzip.NewWriter(tmp)
+	var count int64
+	for rows.Next() {
+		var row string
+		err = rows.Scan(&row)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = io.WriteString(gz, row+"\n")
+		if err != nil {
+			return 0, err
+		}
+		count++
+	}
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+	if err = gz.Close(); err != nil {
+		return 0, err
+	}
+
+	_, err = tmp.Seek(0, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+
+	err = r.Archive.PutFile(path.Join(name, table.Name+".ndjson.gz"), tmp)
+	return count, err
+}
+
+// archivedSegments returns the names of every complete segment in the
+// archive, ordered by ledger.
+func (r *System) archivedSegments() ([]string, error) {
+	files, err := r.Archive.ListFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, f := range files {
+		if path.Base(f) != "manifest.json" {
+			continue
+		}
+		result = append(result, path.Dir(f))
+	}
+
+	sort.Strings(result)
+	return result, nil
+}
+
+func (r *System) readManifest(name string, dest *segmentManifest) error {
+	in, err := r.Archive.GetFile(path.Join(name, "manifest.json"))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	return json.NewDecoder(in).Decode(dest)
+}
+
+// restoreSegment inserts the rows of the segment `name` that are not already
+// present back into the history database, in a single transaction.
+func (r *System) restoreSegment(name string) error {
+	q := r.HorizonDB.Clone()
+	err := q.Begin()
+	if err != nil {
+		return err
+	}
+	defer q.Rollback()
+
+	// tables are restored in the opposite order to which they are cleared.
+	for i := len(reapedTables) - 1; i >= 0; i-- {
+		table := reapedTables[i]
+
+		in, err := r.Archive.GetFile(path.Join(name, table.Name+".ndjson.gz"))
+		if err != nil {
+			return errors.Wrapf(err, "open %s failed", table.Name)
+		}
+
+		err = restoreTable(q.ExecRaw, table, in)
+		in.Close()
+		if err != nil {
+			return errors.Wrapf(err, "restore %s failed", table.Name)
+		}
+	}
+
+	return q.Commit()
+}
+
+// restoreTable inserts the gzipped NDJSON rows read from `in` into `table` in
+// batches, skipping rows whose keys are already present.
+func restoreTable(
+	exec func(string, ...interface{}) (sql.Result, error),
+	table archivedTable,
+	in io.Reader,
+) error {
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	conds := make([]string, len(table.Keys))
+	for i, key := range table.Keys {
+		conds[i] = fmt.Sprintf(`x."%s" = r."%s"`, key, key)
+	}
+
+	insert := fmt.Sprintf(`
+		INSERT INTO %[1]s
+		SELECT r.* FROM json_populate_recordset(NULL::%[1]s, ?) r
+		WHERE NOT EXISTS (SELECT 1 FROM %[1]s x WHERE %[2]s)`,
+		table.Name,
+		strings.Join(conds, " AND "),
+	)
+
+	flush := func(batch []string) error {
+		if len(batch) == 0 {
+			return nil
+		}
+		_, err := exec(insert, "["+strings.Join(batch, ",")+"]")
+		return err
+	}
+
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	batch := make([]string, 0, restoreBatchSize)
+	for scanner.Scan() {
+		batch = append(batch, scanner.Text())
+		if len(batch) < restoreBatchSize {
+			continue
+		}
+
+		err = flush(batch)
+		if err != nil {
+			return err
+		}
+		batch = batch[:0]
+	}
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+
+	return flush(batch)
+}
+
+// segmentName returns the name of the segment holding the ledgers `from`
+// through `to`, padded such that names sort in ledger order.
+func segmentName(from, to int32) string {
+	return fmt.Sprintf("%010d-%010d", from, to)
+}
+
+// beforeOperation returns a condition matching the rows of a table whose
+// `col` is an operation, transaction or ledger id preceding the ledger `seq`.
+func beforeOperation(col string) func(seq int32) (string, []interface{}) {
+	return func(seq int32) (string, []interface{}) {
+		return fmt.Sprintf("t.%s < ?", col), []interface{}{toid.New(seq, 0, 0).ToInt64()}
+	}
+}
+
+// supersededState returns a condition matching the rows of a state history
+// table that precede the ledger `seq` and have been superseded by a later row
+// for the same entry at or before `seq`.  The latest state of every entry prior
+// to `seq` is kept, as it is still needed to answer queries about the retained
+// ledgers.
+func supersededState(table string, keys ...string) func(seq int32) (string, []interface{}) {
+	conds := make([]string, len(keys))
+	for i, key := range keys {
+		conds[i] = fmt.Sprintf("n.%s = t.%s", key, key)
+	}
+
+	return func(seq int32) (string, []interface{}) {
+		return fmt.Sprintf(`t.ledger_sequence < ? AND EXISTS (
+			SELECT 1 FROM %s n
+			WHERE %s
+			AND n.ledger_sequence > t.ledger_sequence
+			AND n.ledger_sequence <= ?
+		)`, table, strings.Join(conds, " AND ")), []interface{}{seq, seq}
+	}
+}
+
+// reapedTables are the history tables cleared by the reaper, in the order they
+// are cleared.
+var reapedTables = []archivedTable{
+	{
+		Name:  "history_effects",
+		Keys:  []string{"history_operation_id", "order"},
+		Where: beforeOperation("history_operation_id"),
+	},
+	{
+		Name:  "history_offer_events",
+		Keys:  []string{"offer_id", "history_operation_id"},
+		Where: beforeOperation("history_operation_id"),
+	},
+	{
+		Name:  "history_operation_participants",
+		Keys:  []string{"id"},
+		Where: beforeOperation("history_operation_id"),
+	},
+	{
+		Name:  "history_asset_payments",
+		Keys:  []string{"history_operation_id"},
+		Where: beforeOperation("history_operation_id"),
+	},
+	{
+		Name:  "history_operations",
+		Keys:  []string{"id"},
+		Where: beforeOperation("id"),
+	},
+	{
+		Name:  "history_transaction_participants",
+		Keys:  []string{"id"},
+		Where: beforeOperation("history_transaction_id"),
+	},
+	{
+		Name:  "history_transactions",
+		Keys:  []string{"id"},
+		Where: beforeOperation("id"),
+	},
+	{
+		Name:  "history_ledgers",
+		Keys:  []string{"id"},
+		Where: beforeOperation("id"),
+	},
+	{
+		Name: "history_account_states",
+		Keys: []string{"account_id", "ledger_sequence"},
+		Where: supersededState(
+			"history_account_states",
+			"account_id",
+		),
+	},
+	{
+		Name: "history_trustline_states",
+		Keys: []string{
+			"account_id",
+			"asset_type",
+			"asset_code",
+			"asset_issuer",
+			"ledger_sequence",
+		},
+		Where: supersededState(
+			"history_trustline_states",
+			"account_id",
+			"asset_type",
+			"asset_code",
+			"asset_issuer",
+		),
+	},
+}
@@ -0,0 +1,155 @@
+package reap
+
+import (
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/lomocoin/stellar-go/support/errors"
+)
+
+// NewBackend returns the archive backend described by `u`, either a
+// `file:///path/to/dir` or an `s3://bucket/prefix` url.  The region and
+// credentials of S3 backends are loaded from the standard AWS environment
+// variables.
+func NewBackend(u string) (Backend, error) {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse archive url failed")
+	}
+
+	switch parsed.Scheme {
+	case "file":
+		return &FsBackend{Path: path.Join(parsed.Host, parsed.Path)}, nil
+	case "s3":
+		sess, err := session.NewSession()
+		if err != nil {
+			return nil, errors.Wrap(err, "create aws session failed")
+		}
+
+		return &S3Backend{
+			Bucket: parsed.Host,
+			Prefix: strings.TrimPrefix(parsed.Path, "/"),
+			svc:    s3.New(sess),
+		}, nil
+	default:
+		return nil, errors.Errorf("unknown archive url scheme: %q", parsed.Scheme)
+	}
+}
+
+// GetFile implements Backend
+func (b *FsBackend) GetFile(pth string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(b.Path, pth))
+}
+
+// ListFiles implements Backend
+func (b *FsBackend) ListFiles() ([]string, error) {
+	var result []string
+
+	err := filepath.Walk(b.Path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(b.Path, p)
+		if err != nil {
+			return err
+		}
+		result = append(result, filepath.ToSlash(rel))
+		return nil
+	})
+
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	return result, err
+}
+
+// PutFile implements Backend
+func (b *FsBackend) PutFile(pth string, in io.ReadSeeker) error {
+	dest := filepath.Join(b.Path, pth)
+
+	err := os.MkdirAll(filepath.Dir(dest), 0755)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so that a partially written file is
+	// never mistaken for a complete one.
+	tmp := dest + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, dest)
+}
+
+// GetFile implements Backend
+func (b *S3Backend) GetFile(pth string) (io.ReadCloser, error) {
+	resp, err := b.svc.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(b.Bucket),
+		Key:    aws.String(path.Join(b.Prefix, pth)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// ListFiles implements Backend
+func (b *S3Backend) ListFiles() ([]string, error) {
+	var result []string
+
+	params := &s3.ListObjectsInput{
+		Bucket: aws.String(b.Bucket),
+		Prefix: aws.String(b.Prefix),
+	}
+
+	for {
+		resp, err := b.svc.ListObjects(params)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range resp.Contents {
+			params.Marker = c.Key
+			key := strings.TrimPrefix(*c.Key, b.Prefix)
+			result = append(result, strings.TrimPrefix(key, "/"))
+		}
+
+		if resp.IsTruncated == nil || !*resp.IsTruncated {
+			return result, nil
+		}
+	}
+}
+
+// PutFile implements Backend
+func (b *S3Backend) PutFile(pth string, in io.ReadSeeker) error {
+	_, err := b.svc.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(b.Bucket),
+		Key:    aws.String(path.Join(b.Prefix, pth)),
+		Body:   in,
+	})
+	return err
+}
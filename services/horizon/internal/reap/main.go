@@ -2,19 +2,55 @@
 // is designed to remove data from the history database such that it does not
 // grow indefinitely.  The system can be configured with a number of ledgers to
 // maintain at a minimum.
+//
+// When configured with an archive backend, the reaper exports every row it is
+// about to remove to compressed NDJSON files before deleting them, such that
+// the removed history can later be loaded back using `Restore`.
 package reap
 
 import (
+	"io"
 	"time"
 
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/lomocoin/stellar-go/support/db"
 )
 
+// Backend represents a place reaped history segments can be archived to, such
+// as a local directory or an S3 bucket.
+type Backend interface {
+	// GetFile opens the file at `path` for reading.
+	GetFile(path string) (io.ReadCloser, error)
+	// ListFiles returns the path of every file stored in the backend.
+	ListFiles() ([]string, error)
+	// PutFile stores the contents of `in` at `path`, replacing any existing
+	// file.
+	PutFile(path string, in io.ReadSeeker) error
+}
+
+// FsBackend is a Backend that stores archived segments in a directory on the
+// local filesystem.
+type FsBackend struct {
+	Path string
+}
+
+// S3Backend is a Backend that stores archived segments in an S3 bucket.
+type S3Backend struct {
+	Bucket string
+	Prefix string
+
+	svc *s3.S3
+}
+
 // System represents the history reaping subsystem of horizon.
 type System struct {
 	HorizonDB      *db.Session
 	RetentionCount uint
 
+	// Archive, when not nil, is the backend unretained history is exported to
+	// before it is deleted.
+	Archive Backend
+
 	nextRun time.Time
 }
 
@@ -29,3 +65,28 @@ func New(retention uint, horizon *db.Session) *System {
 	r.nextRun = time.Now().Add(1 * time.Hour)
 	return r
 }
+
+// archivedTable describes a history table whose rows get archived when they
+// are reaped.
+type archivedTable struct {
+	// Name is the name of the table.
+	Name string
+	// Keys are the columns identifying a single row, used to skip rows that
+	// are already present when restoring an archived segment.
+	Keys []string
+	// Where returns the sql condition, and its arguments, matching the rows of
+	// the table that are removed when clearing history prior to `seq`.
+	Where func(seq int32) (string, []interface{})
+}
+
+// segmentManifest is the last file written when archiving a segment,
+// describing the ledgers and rows it contains.  A segment without a manifest
+// is incomplete and ignored when restoring.
+type segmentManifest struct {
+	// From and To are the first and last ledgers, inclusive, that were
+	// removed from the history database when the segment was written.
+	From int32 `json:"from"`
+	To   int32 `json:"to"`
+	// Rows is the count of rows archived, by table name.
+	Rows map[string]int64 `json:"rows"`
+}
@@ -1,15 +1,17 @@
 package reap
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/lomocoin/stellar-go/services/horizon/internal/errors"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/ledger"
-	"github.com/lomocoin/stellar-go/services/horizon/internal/toid"
 	"github.com/lomocoin/stellar-go/support/log"
 )
 
 // DeleteUnretainedHistory removes all data associated with unretained ledgers.
+// If an archive is configured, the data is exported to it before being
+// removed, and nothing is removed if exporting fails.
 func (r *System) DeleteUnretainedHistory() error {
 	// RetentionCount of 0 indicates "keep all history"
 	if r.RetentionCount == 0 {
@@ -25,6 +27,13 @@ func (r *System) DeleteUnretainedHistory() error {
 		return nil
 	}
 
+	if r.Archive != nil && targetElder > latest.HistoryElder {
+		err := r.archiveBefore(latest.HistoryElder, targetElder)
+		if err != nil {
+			return err
+		}
+	}
+
 	err := r.clearBefore(targetElder)
 	if err != nil {
 		return err
@@ -66,32 +75,15 @@ func (r *System) runOnce() {
 func (r *System) clearBefore(seq int32) error {
 	log.WithField("new_elder", seq).Info("reaper: clearing")
 
-	clear := r.HorizonDB.DeleteRange
-	end := toid.New(seq, 0, 0).ToInt64()
-
-	err := clear(0, end, "history_effects", "history_operation_id")
-	if err != nil {
-		return err
-	}
-	err = clear(0, end, "history_operation_participants", "history_operation_id")
-	if err != nil {
-		return err
-	}
-	err = clear(0, end, "history_operations", "id")
-	if err != nil {
-		return err
-	}
-	err = clear(0, end, "history_transaction_participants", "history_transaction_id")
-	if err != nil {
-		return err
-	}
-	err = clear(0, end, "history_transactions", "id")
-	if err != nil {
-		return err
-	}
-	err = clear(0, end, "history_ledgers", "id")
-	if err != nil {
-		return err
+	for _, table := range reapedTables {
+		where, args := table.Where(seq)
+		_, err := r.HorizonDB.ExecRaw(
+			fmt.Sprintf("DELETE FROM %s t WHERE %s", table.Name, where),
+			args...,
+		)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -1,8 +1,11 @@
 package reap
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
 
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/schema"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/test"
 )
 
@@ -10,6 +13,10 @@ func TestDeleteUnretainedHistory(t *testing.T) {
 	tt := test.Start(t).Scenario("kahuna")
 	defer tt.Finish()
 
+	// the offer, state and asset payment tables are newer than the scenario
+	_, err := schema.Migrate(tt.HorizonDB.DB, schema.MigrateUp, 0)
+	tt.Require.NoError(err)
+
 	db := tt.HorizonSession()
 
 	sys := New(0, db)
@@ -18,7 +25,7 @@ func TestDeleteUnretainedHistory(t *testing.T) {
 		prev int
 		cur  int
 	)
-	err := db.GetRaw(&prev, `SELECT COUNT(*) FROM history_ledgers`)
+	err = db.GetRaw(&prev, `SELECT COUNT(*) FROM history_ledgers`)
 	tt.Require.NoError(err)
 
 	err = sys.DeleteUnretainedHistory()
@@ -37,6 +44,16 @@ func TestDeleteUnretainedHistory(t *testing.T) {
 		tt.Assert.Equal(10, cur)
 	}
 
+	// an offer event recorded by an operation of the reaped ledgers
+	_, err = db.ExecRaw(`
+		INSERT INTO history_offer_events (
+			offer_id, history_operation_id, ledger_sequence, type, seller_id,
+			selling_asset_type, buying_asset_type, amount, pricen, priced, price, flags
+		) VALUES (1, (SELECT MIN(id) FROM history_operations), 2, 0,
+			'GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2', 0, 0, 1, 1, 1, 1, 0)
+	`)
+	tt.Require.NoError(err)
+
 	tt.UpdateLedgerState()
 	sys.RetentionCount = 1
 	err = sys.DeleteUnretainedHistory()
@@ -44,5 +61,82 @@ func TestDeleteUnretainedHistory(t *testing.T) {
 		err = db.GetRaw(&cur, `SELECT COUNT(*) FROM history_ledgers`)
 		tt.Require.NoError(err)
 		tt.Assert.Equal(1, cur)
+
+		err = db.GetRaw(&cur, `SELECT COUNT(*) FROM history_offer_events`)
+		tt.Require.NoError(err)
+		tt.Assert.Equal(0, cur)
+
+		// no asset payment outlives its operation
+		err = db.GetRaw(&cur, `
+			SELECT COUNT(*) FROM history_asset_payments hap
+			WHERE NOT EXISTS (
+				SELECT 1 FROM history_operations hop
+				WHERE hop.id = hap.history_operation_id
+			)
+		`)
+		tt.Require.NoError(err)
+		tt.Assert.Equal(0, cur)
+	}
+}
+
+func TestArchiveAndRestore(t *testing.T) {
+	tt := test.Start(t).Scenario("kahuna")
+	defer tt.Finish()
+
+	// the offer, state and asset payment tables are newer than the scenario
+	_, err := schema.Migrate(tt.HorizonDB.DB, schema.MigrateUp, 0)
+	tt.Require.NoError(err)
+
+	dir, err := ioutil.TempDir("", "horizon-reap-test")
+	tt.Require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	db := tt.HorizonSession()
+	sys := New(10, db)
+	sys.Archive = &FsBackend{Path: dir}
+
+	var prev, cur, prevPayments, curPayments int
+	err = db.GetRaw(&prev, `SELECT COUNT(*) FROM history_operations`)
+	tt.Require.NoError(err)
+	err = db.GetRaw(&prevPayments, `SELECT COUNT(*) FROM history_asset_payments`)
+	tt.Require.NoError(err)
+	tt.Require.NotZero(prevPayments)
+
+	tt.UpdateLedgerState()
+	err = sys.DeleteUnretainedHistory()
+	tt.Require.NoError(err)
+
+	segments, err := sys.archivedSegments()
+	tt.Require.NoError(err)
+	tt.Require.Len(segments, 1)
+
+	var manifest segmentManifest
+	tt.Require.NoError(sys.readManifest(segments[0], &manifest))
+
+	err = db.GetRaw(&cur, `SELECT COUNT(*) FROM history_operations`)
+	tt.Require.NoError(err)
+	tt.Assert.True(cur < prev, "operations were not reaped")
+
+	err = db.GetRaw(&curPayments, `SELECT COUNT(*) FROM history_asset_payments`)
+	tt.Require.NoError(err)
+	tt.Assert.Equal(int64(prevPayments-curPayments), manifest.Rows["history_asset_payments"])
+
+	// restoring twice must not duplicate rows
+	for i := 0; i < 2; i++ {
+		restored, err := sys.Restore(manifest.From, manifest.From)
+		tt.Require.NoError(err)
+		tt.Assert.Equal(1, restored)
 	}
+
+	err = db.GetRaw(&cur, `SELECT COUNT(*) FROM history_operations`)
+	tt.Require.NoError(err)
+	tt.Assert.Equal(prev, cur)
+	err = db.GetRaw(&curPayments, `SELECT COUNT(*) FROM history_asset_payments`)
+	tt.Require.NoError(err)
+	tt.Assert.Equal(prevPayments, curPayments)
+
+	// segments outside of the range are not restored
+	restored, err := sys.Restore(manifest.To+1, manifest.To+1)
+	tt.Require.NoError(err)
+	tt.Assert.Equal(0, restored)
 }
@@ -0,0 +1,87 @@
+package horizon
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lomocoin/stellar-go/support/log"
+	"github.com/throttled/throttled"
+)
+
+// Reload applies the settings of `config` that can change while horizon is
+// running: the log level, the quota of the rate limit by remote ip address,
+// the API keys, re-read from the API keys file and the database, and the
+// frequency at which streams check for new ledgers.  Other settings only take
+// effect once horizon is restarted.
+func (a *App) Reload(config Config) {
+	log.DefaultLogger.SetLevel(config.LogLevel)
+
+	clients, err := loadAPIKeyClients(a, config.APIKeysFile, a.web.apiKeyClients())
+	if err != nil {
+		log.WithField("err", err).Error("failed to reload the api keys, keeping the current ones")
+	} else {
+		a.web.apiKeys.Store(clients)
+	}
+
+	switch {
+	case (a.web.quotaLimiter == nil) != (config.RateLimit == nil):
+		log.Warn("per-hour-rate-limit ignored: enabling or disabling the rate limit requires a restart")
+	case config.RateLimit != nil:
+		err := a.web.quotaLimiter.SetQuota(*config.RateLimit)
+		if err != nil {
+			log.WithField("err", err).Error("failed to reload the rate limit")
+		}
+	}
+
+	atomic.StoreInt64(&a.sseUpdateFrequency, int64(config.SSEUpdateFrequency))
+
+	log.WithFields(log.F{
+		"log_level":            config.LogLevel.String(),
+		"sse_update_frequency": config.SSEUpdateFrequency.String(),
+		"api_keys":             len(a.web.apiKeyClients()),
+	}).Info("Reloaded config")
+}
+
+// SSEUpdateFrequency returns how often streams check for a new ledger.
+func (a *App) SSEUpdateFrequency() time.Duration {
+	return time.Duration(atomic.LoadInt64(&a.sseUpdateFrequency))
+}
+
+// reloadableRateLimiter is a GCRA rate limiter whose quota can be replaced
+// while it is rate limiting requests.  Replacing the quota forgets the
+// requests counted so far.
+type reloadableRateLimiter struct {
+	lock    sync.RWMutex
+	quota   throttled.RateQuota
+	limiter throttled.RateLimiter
+}
+
+// RateLimit implements throttled.RateLimiter.
+func (l *reloadableRateLimiter) RateLimit(key string, quantity int) (bool, throttled.RateLimitResult, error) {
+	l.lock.RLock()
+	limiter := l.limiter
+	l.lock.RUnlock()
+
+	return limiter.RateLimit(key, quantity)
+}
+
+// SetQuota replaces the quota enforced by the rate limiter, unless it is
+// unchanged.
+func (l *reloadableRateLimiter) SetQuota(quota throttled.RateQuota) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.limiter != nil && l.quota == quota {
+		return nil
+	}
+
+	limiter, err := throttled.NewGCRARateLimiter(50000, quota)
+	if err != nil {
+		return err
+	}
+
+	l.quota = quota
+	l.limiter = limiter
+	return nil
+}
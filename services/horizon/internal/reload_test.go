@@ -0,0 +1,72 @@
+package horizon
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/lomocoin/stellar-go/support/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/throttled/throttled"
+)
+
+func TestReloadableRateLimiter(t *testing.T) {
+	limiter := &reloadableRateLimiter{}
+	quota := throttled.RateQuota{MaxRate: throttled.PerHour(1), MaxBurst: 1}
+	if !assert.NoError(t, limiter.SetQuota(quota)) {
+		return
+	}
+
+	limited, _, err := limiter.RateLimit("127.0.0.1", 2)
+	assert.NoError(t, err)
+	assert.False(t, limited)
+	limited, _, _ = limiter.RateLimit("127.0.0.1", 1)
+	assert.True(t, limited)
+
+	// setting the same quota keeps the requests counted so far
+	assert.NoError(t, limiter.SetQuota(quota))
+	limited, _, _ = limiter.RateLimit("127.0.0.1", 1)
+	assert.True(t, limited)
+
+	// a new quota applies at once
+	assert.NoError(t, limiter.SetQuota(throttled.RateQuota{MaxRate: throttled.PerHour(1), MaxBurst: 10}))
+	limited, _, _ = limiter.RateLimit("127.0.0.1", 5)
+	assert.False(t, limited)
+}
+
+func TestAppReload(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+	defer log.DefaultLogger.SetLevel(log.InfoLevel)
+
+	c := NewTestConfig()
+	c.LogLevel = log.DebugLevel
+	c.SSEUpdateFrequency = 2 * time.Second
+	c.RateLimit = &throttled.RateQuota{MaxRate: throttled.PerHour(10), MaxBurst: 5}
+	ht.App.Reload(c)
+
+	ht.Assert.Equal(log.DebugLevel, log.DefaultLogger.Logger.Level)
+	ht.Assert.Equal(2*time.Second, ht.App.SSEUpdateFrequency())
+	ht.Assert.Equal(*c.RateLimit, ht.App.web.quotaLimiter.quota)
+
+	// the rate limit can't be disabled without a restart
+	c.RateLimit = nil
+	ht.App.Reload(c)
+	ht.Assert.Equal(throttled.PerHour(10), ht.App.web.quotaLimiter.quota.MaxRate)
+
+	// the api keys file is read again
+	file := writeAPIKeysFile(t)
+	defer os.Remove(file)
+	ht.Assert.Equal(200, ht.Get("/", requestHelperAPIKey("unknown")).Code)
+
+	c.APIKeysFile = file
+	ht.App.Reload(c)
+	ht.Assert.Equal(200, ht.Get("/", requestHelperAPIKey("partner-secret")).Code)
+	ht.Assert.Equal(401, ht.Get("/", requestHelperAPIKey("unknown")).Code)
+	partner, _ := ht.App.web.apiKey("partner-secret")
+
+	// unchanged keys keep their rate limiter
+	ht.App.Reload(c)
+	reloaded, _ := ht.App.web.apiKey("partner-secret")
+	ht.Assert.True(partner == reloaded)
+}
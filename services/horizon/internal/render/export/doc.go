@@ -0,0 +1,3 @@
+// This package contains the CSV and NDJSON streams used by horizon to export
+// collections row by row.
+package export
@@ -0,0 +1,203 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/lomocoin/stellar-go/services/horizon/internal/render"
+	"github.com/lomocoin/stellar-go/support/log"
+)
+
+// ErrorTrailer is the HTTP trailer carrying the error that interrupted a CSV
+// export, which has no room for errors in its rows.
+const ErrorTrailer = "X-Export-Error"
+
+// Stream represents an export that records can be written to, one row at a
+// time: a line of JSON for NDJSON exports or a row of the columns set with
+// SetColumns for CSV exports.
+type Stream interface {
+	SetColumns(columns ...string)
+	Send(record interface{})
+	SentCount() int
+	Flush()
+	Done()
+	Err(error)
+}
+
+// NewStream creates a new stream of `contentType`, either render.MimeCSV or
+// render.MimeNDJSON, against the provided response writer.
+func NewStream(ctx context.Context, w http.ResponseWriter, contentType string) Stream {
+	result := &stream{
+		ctx:         ctx,
+		w:           w,
+		contentType: contentType,
+	}
+
+	if contentType == render.MimeCSV {
+		result.csv = csv.NewWriter(w)
+	}
+
+	return result
+}
+
+type stream struct {
+	ctx         context.Context
+	w           http.ResponseWriter
+	contentType string
+	csv         *csv.Writer
+	columns     []string
+	started     bool
+	sent        int
+}
+
+// SetColumns sets the columns of a CSV export.  A column is the name of a
+// field of the JSON form of the records, nested fields being joined by a dot
+// (e.g. `price.n`).
+func (s *stream) SetColumns(columns ...string) {
+	s.columns = columns
+}
+
+// Send writes `record` to the export.  Rows are buffered until the next call
+// to Flush.
+func (s *stream) Send(record interface{}) {
+	s.init()
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		log.Ctx(s.ctx).WithField("err", err.Error()).Error("failed to encode exported record")
+		return
+	}
+
+	if s.csv == nil {
+		_, err = s.w.Write(append(data, '\n'))
+		if err != nil {
+			log.Ctx(s.ctx).WithField("err", err.Error()).Error("failed to write exported record")
+		}
+		s.sent++
+		return
+	}
+
+	s.csv.Write(csvRow(data, s.columns))
+	s.sent++
+}
+
+func (s *stream) SentCount() int {
+	return s.sent
+}
+
+// Flush sends the buffered rows to the client.
+func (s *stream) Flush() {
+	if s.csv != nil {
+		s.csv.Flush()
+		if err := s.csv.Error(); err != nil {
+			log.Ctx(s.ctx).WithField("err", err.Error()).Error("failed to write exported records")
+		}
+	}
+
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Done ends the export, writing the header of an empty CSV export.
+func (s *stream) Done() {
+	s.init()
+	s.Flush()
+}
+
+// Err ends an export interrupted by `err`.  NDJSON exports end with an
+// `{"error": ...}` line; CSV exports, which have no room for errors, are
+// truncated and carry the error in their ErrorTrailer.
+func (s *stream) Err(err error) {
+	s.init()
+	log.Ctx(s.ctx).WithField("err", err.Error()).Error("export interrupted")
+
+	if s.csv == nil {
+		data, _ := json.Marshal(map[string]string{"error": err.Error()})
+		s.w.Write(append(data, '\n'))
+	}
+
+	s.Flush()
+
+	if s.csv != nil {
+		s.w.Header().Set(ErrorTrailer, err.Error())
+	}
+}
+
+// init writes the response headers, and the header row of CSV exports, the
+// first time it is called.
+func (s *stream) init() {
+	if s.started {
+		return
+	}
+	s.started = true
+
+	s.w.Header().Set("Content-Type", s.contentType+"; charset=utf-8")
+	s.w.Header().Set("Cache-Control", "no-cache")
+	if s.csv != nil {
+		s.w.Header().Set("Trailer", ErrorTrailer)
+	}
+	s.w.WriteHeader(http.StatusOK)
+
+	if s.csv != nil {
+		s.csv.Write(s.columns)
+	}
+}
+
+// csvRow returns the values of the `columns` of the JSON object `data`, as they
+// appear in a CSV export: strings, numbers and booleans as is, missing fields
+// and nulls as empty strings, and objects and arrays as JSON.  Strings that a
+// spreadsheet would evaluate as a formula are escaped with a leading quote.
+func csvRow(data []byte, columns []string) []string {
+	var object map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	decoder.Decode(&object)
+
+	row := make([]string, len(columns))
+	for i, column := range columns {
+		var value interface{} = object
+		for _, name := range strings.Split(column, ".") {
+			fields, ok := value.(map[string]interface{})
+			if !ok {
+				value = nil
+				break
+			}
+			value = fields[name]
+		}
+
+		switch value := value.(type) {
+		case nil:
+		case string:
+			row[i] = csvEscape(value)
+		case json.Number:
+			row[i] = value.String()
+		case bool:
+			if value {
+				row[i] = "true"
+			} else {
+				row[i] = "false"
+			}
+		default:
+			encoded, _ := json.Marshal(value)
+			row[i] = string(encoded)
+		}
+	}
+
+	return row
+}
+
+// csvEscape prefixes with a quote the values starting with a character that
+// makes spreadsheets evaluate them as formulas (e.g. a memo of
+// `=HYPERLINK(...)`), such that they are shown as text.
+func csvEscape(value string) string {
+	if value != "" && strings.IndexByte("=+-@\t\r", value[0]) >= 0 {
+		return "'" + value
+	}
+
+	return value
+}
@@ -0,0 +1,116 @@
+package export
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lomocoin/stellar-go/services/horizon/internal/render"
+	"github.com/lomocoin/stellar-go/support/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRecord struct {
+	ID     string            `json:"id"`
+	Amount string            `json:"amount,omitempty"`
+	Count  int               `json:"count"`
+	Flag   bool              `json:"flag"`
+	Price  map[string]int    `json:"price"`
+	Links  map[string]string `json:"_links"`
+}
+
+func TestStream_CSV(t *testing.T) {
+	ctx, _ := test.ContextWithLogBuffer()
+	w := httptest.NewRecorder()
+	stream := NewStream(ctx, w, render.MimeCSV)
+
+	stream.SetColumns("id", "amount", "count", "flag", "price.n", "price")
+	stream.Send(testRecord{ID: "1", Amount: "10.0000000", Count: 2, Flag: true, Price: map[string]int{"n": 1}})
+	stream.Send(testRecord{ID: "2, \"quoted\""})
+	stream.Done()
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, 2, stream.SentCount())
+	assert.Equal(t,
+		"id,amount,count,flag,price.n,price\n"+
+			"1,10.0000000,2,true,1,\"{\"\"n\"\":1}\"\n"+
+			"\"2, \"\"quoted\"\"\",,0,false,,\n",
+		w.Body.String(),
+	)
+}
+
+func TestStream_CSVEmpty(t *testing.T) {
+	ctx, _ := test.ContextWithLogBuffer()
+	w := httptest.NewRecorder()
+	stream := NewStream(ctx, w, render.MimeCSV)
+
+	stream.SetColumns("id", "amount")
+	stream.Done()
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "id,amount\n", w.Body.String())
+}
+
+func TestStream_CSVFormulas(t *testing.T) {
+	ctx, _ := test.ContextWithLogBuffer()
+	w := httptest.NewRecorder()
+	stream := NewStream(ctx, w, render.MimeCSV)
+
+	stream.SetColumns("memo")
+	for _, memo := range []string{
+		`=HYPERLINK("http://evil.example.com","click")`,
+		"+cmd|' /C calc'!A0",
+		"-2+3",
+		"@SUM(1)",
+		"\tTAB",
+		"\rCR",
+		"plain",
+	} {
+		stream.Send(map[string]string{"memo": memo})
+	}
+	stream.Done()
+
+	assert.Equal(t,
+		"memo\n"+
+			"\"'=HYPERLINK(\"\"http://evil.example.com\"\",\"\"click\"\")\"\n"+
+			"'+cmd|' /C calc'!A0\n"+
+			"'-2+3\n"+
+			"'@SUM(1)\n"+
+			"'\tTAB\n"+
+			"\"'\rCR\"\n"+
+			"plain\n",
+		w.Body.String(),
+	)
+}
+
+func TestStream_CSVErr(t *testing.T) {
+	ctx, logs := test.ContextWithLogBuffer()
+	w := httptest.NewRecorder()
+	stream := NewStream(ctx, w, render.MimeCSV)
+
+	stream.SetColumns("id")
+	stream.Send(map[string]string{"id": "1"})
+	stream.Err(errors.New("boom"))
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "id\n1\n", w.Body.String())
+	assert.Equal(t, ErrorTrailer, w.Header().Get("Trailer"))
+	assert.Equal(t, "boom", w.Result().Trailer.Get(ErrorTrailer))
+	assert.Contains(t, logs.String(), "export interrupted")
+}
+
+func TestStream_NDJSON(t *testing.T) {
+	ctx, _ := test.ContextWithLogBuffer()
+	w := httptest.NewRecorder()
+	stream := NewStream(ctx, w, render.MimeNDJSON)
+
+	stream.SetColumns("id")
+	stream.Send(map[string]string{"id": "1"})
+	stream.Send(map[string]string{"id": "2"})
+	stream.Err(errors.New("boom"))
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "application/x-ndjson; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "{\"id\":\"1\"}\n{\"id\":\"2\"}\n{\"error\":\"boom\"}\n", w.Body.String())
+}
@@ -7,13 +7,25 @@ import (
 	"github.com/lomocoin/stellar-go/support/log"
 )
 
-// Negotiate inspects the Accept header of the provided request and determines
-// what the most appropriate response type should be.  Defaults to HAL.
+// formats maps the values of the `format` parameter to the response types
+// they select.
+var formats = map[string]string{
+	"csv":    MimeCSV,
+	"ndjson": MimeNDJSON,
+}
+
+// Negotiate inspects the `format` parameter and the Accept header of the
+// provided request and determines what the most appropriate response type
+// should be.  Defaults to HAL.
 func Negotiate(r *http.Request) string {
 	ctx := r.Context()
-	alternatives := []string{MimeHal, MimeJSON, MimeEventStream, MimeRaw}
+	alternatives := []string{MimeHal, MimeJSON, MimeEventStream, MimeRaw, MimeText, MimeCSV, MimeNDJSON}
 	accept := r.Header.Get("Accept")
 
+	if format, ok := formats[r.URL.Query().Get("format")]; ok {
+		return format
+	}
+
 	if accept == "" {
 		return MimeHal
 	}
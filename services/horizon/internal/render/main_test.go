@@ -23,8 +23,11 @@ func TestNegotiate(t *testing.T) {
 		// Defaults to HAL
 		{"text/event-stream;q=0.5,application/hal+json", MimeHal},
 		{"", MimeHal},
+		{"text/plain;version=0.0.4;q=0.5,*/*;q=0.1", MimeText},
+		{"text/csv", MimeCSV},
+		{"application/x-ndjson", MimeNDJSON},
 		// Returns empty string for invalid type
-		{"text/plain", ""},
+		{"text/html", ""},
 	}
 	for _, tc := range testCases {
 		t.Run("", func(t *testing.T) {
@@ -36,4 +39,14 @@ func TestNegotiate(t *testing.T) {
 	// Defaults to MimeHal even with no Accept key set
 	r.Header.Del("Accept")
 	assert.Equal(t, MimeHal, Negotiate(r))
+
+	// The format parameter takes precedence over the Accept header
+	r, err = http.NewRequest("GET", "/ledgers?format=csv", nil)
+	assert.Nil(t, err)
+	r.Header.Set("Accept", "application/hal+json")
+	assert.Equal(t, MimeCSV, Negotiate(r))
+
+	r, err = http.NewRequest("GET", "/ledgers?format=ndjson", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, MimeNDJSON, Negotiate(r))
 }
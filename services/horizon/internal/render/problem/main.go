@@ -29,6 +29,37 @@ var (
 			"headers.",
 	}
 
+	// InvalidAPIKey is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	InvalidAPIKey = problem.P{
+		Type:   "invalid_api_key",
+		Title:  "Invalid API Key",
+		Status: http.StatusUnauthorized,
+		Detail: "The API key sent with the request, in the 'X-Api-Key' header or " +
+			"the 'api_key' parameter, is not known to this horizon instance.  " +
+			"Requests made without an API key are rate limited by IP address.",
+	}
+
+	// APIKeyRequired is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	APIKeyRequired = problem.P{
+		Type:   "api_key_required",
+		Title:  "API Key Required",
+		Status: http.StatusUnauthorized,
+		Detail: "This resource requires an API key, sent in the 'X-Api-Key' " +
+			"header or the 'api_key' parameter of the request.",
+	}
+
+	// RouteNotAllowed is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	RouteNotAllowed = problem.P{
+		Type:   "forbidden",
+		Title:  "Forbidden",
+		Status: http.StatusForbidden,
+		Detail: "The API key sent with the request is not allowed to request " +
+			"this resource.",
+	}
+
 	// NotImplemented is a well-known problem type.  Use it as a shortcut
 	// in your actions.
 	NotImplemented = problem.P{
@@ -59,6 +90,17 @@ var (
 			"several minutes before trying your request again.",
 	}
 
+	// ShuttingDown is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	ShuttingDown = problem.P{
+		Type:   "shutting_down",
+		Title:  "Server Shutting Down",
+		Status: http.StatusServiceUnavailable,
+		Detail: "This horizon server is shutting down and no longer accepts " +
+			"requests.  Please try your request again: it will be served by " +
+			"another horizon server.",
+	}
+
 	// Timeout is a well-known problem type.  Use it as a shortcut
 	// in your actions.
 	Timeout = problem.P{
@@ -92,6 +92,15 @@ var goodbyeEvent = Event{
 	Retry: 10,
 }
 
+// When horizon shuts down, we send this event to inform the client that it
+// should reconnect after 1 second, by which time the load balancer will have
+// stopped routing requests to this horizon.
+var shutdownEvent = Event{
+	Data:  "shutdown",
+	Event: "close",
+	Retry: 1000,
+}
+
 // Upon initial stream creation, we send this event to inform the client
 // that they may retry an errored connection after 1 second.
 var helloEvent = Event{
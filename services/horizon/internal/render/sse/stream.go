@@ -13,6 +13,7 @@ type Stream interface {
 	Send(Event)
 	SentCount() int
 	Done()
+	Shutdown()
 	SetLimit(limit int)
 	IsDone() bool
 	Err(error)
@@ -79,6 +80,17 @@ func (s *stream) Done() {
 	s.done = true
 }
 
+// Shutdown ends the stream with an event telling the client that horizon is
+// shutting down, and to reconnect, to another horizon, once the retry delay
+// has elapsed.
+func (s *stream) Shutdown() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Init()
+	WriteEvent(s.ctx, s.w, shutdownEvent)
+	s.done = true
+}
+
 // isDone checks to see if the stream is done. Not safe to call concurrently
 // and meant for internal use.
 func (s *stream) isDone() bool {
@@ -103,3 +115,18 @@ func (s *stream) Err(err error) {
 	WriteEvent(s.ctx, s.w, Event{Error: err})
 	s.done = true
 }
+
+type streamKey struct{}
+
+// WithStream returns a context making the actions serving requests made with
+// it send their events to `stream` rather than to the response writer, e.g. to
+// multiplex several streams over a websocket connection.
+func WithStream(ctx context.Context, stream Stream) context.Context {
+	return context.WithValue(ctx, streamKey{}, stream)
+}
+
+// StreamFromContext returns the stream set with WithStream, if any.
+func StreamFromContext(ctx context.Context) (Stream, bool) {
+	stream, ok := ctx.Value(streamKey{}).(Stream)
+	return stream, ok
+}
@@ -57,6 +57,15 @@ func (suite *StreamTestSuite) TestStream_Err() {
 	assert.True(suite.T(), suite.stream.IsDone())
 }
 
+// Tests that Shutdown sends the shutdown event and ends the stream.
+func (suite *StreamTestSuite) TestStream_Shutdown() {
+	suite.stream.Shutdown()
+	suite.checkHeadersAndPreamble()
+	assert.Contains(suite.T(), suite.w.Body.String(), "retry: 1000\nevent: close\ndata: \"shutdown\"\n\n")
+	assert.True(suite.T(), suite.stream.IsDone())
+	assert.Equal(suite.T(), 0, suite.stream.SentCount())
+}
+
 // Tests that SetLimit sets stream.done to true after the limit has been reached.
 func (suite *StreamTestSuite) TestStream_SetLimit() {
 	suite.stream.SetLimit(3)
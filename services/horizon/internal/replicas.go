@@ -0,0 +1,72 @@
+package horizon
+
+import (
+	"sync/atomic"
+
+	"github.com/rcrowley/go-metrics"
+	"github.com/lomocoin/stellar-go/support/db"
+)
+
+// replica is a read replica of the horizon or the stellar-core database.
+type replica struct {
+	session *db.Session
+	// latest is the sequence of the latest ledger found in the replica, 0 when
+	// it could not be loaded.
+	latest   int32
+	lagGauge metrics.Gauge
+}
+
+// replicaSet is the set of read replicas of a database.  Read-only requests
+// are spread over the replicas that are no more than `StaleThreshold` ledgers
+// behind the primary database.
+type replicaSet struct {
+	replicas []*replica
+	next     uint32
+}
+
+// Latest returns the sequence of the latest ledger found in the replica.
+func (r *replica) Latest() int32 {
+	return atomic.LoadInt32(&r.latest)
+}
+
+// SetLatest records the sequence of the latest ledger found in the replica.
+func (r *replica) SetLatest(latest int32) {
+	atomic.StoreInt32(&r.latest, latest)
+}
+
+// IsFresh returns true if the replica is no more than `threshold` ledgers
+// behind the primary database, whose latest ledger is `primaryLatest`.
+func (r *replica) IsFresh(primaryLatest int32, threshold uint) bool {
+	latest := r.Latest()
+	return latest > 0 && int64(primaryLatest)-int64(latest) <= int64(threshold)
+}
+
+// Session returns the session of a fresh replica, picked in a round-robin
+// fashion, or nil if no replica is fresh.
+func (s *replicaSet) Session(primaryLatest int32, threshold uint) *db.Session {
+	if s == nil || len(s.replicas) == 0 {
+		return nil
+	}
+
+	n := uint32(len(s.replicas))
+	start := atomic.AddUint32(&s.next, 1)
+	for i := uint32(0); i < n; i++ {
+		r := s.replicas[(start+i)%n]
+		if r.IsFresh(primaryLatest, threshold) {
+			return r.session
+		}
+	}
+
+	return nil
+}
+
+// Close closes the connections to every replica.
+func (s *replicaSet) Close() {
+	if s == nil {
+		return
+	}
+
+	for _, r := range s.replicas {
+		r.session.DB.Close()
+	}
+}
@@ -0,0 +1,58 @@
+package horizon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lomocoin/stellar-go/services/horizon/internal/test"
+	"github.com/lomocoin/stellar-go/support/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplicaSet(t *testing.T) {
+	stale := &replica{session: &db.Session{}, latest: 5}
+	fresh := &replica{session: &db.Session{}, latest: 10}
+	down := &replica{session: &db.Session{}}
+	set := &replicaSet{replicas: []*replica{stale, fresh, down}}
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, set.Session(12, 2) == fresh.session)
+	}
+	assert.Nil(t, set.Session(13, 2))
+	assert.Nil(t, (*replicaSet)(nil).Session(12, 2))
+
+	// requests are spread over the fresh replicas
+	other := &replica{session: &db.Session{}, latest: 12}
+	set.replicas = append(set.replicas, other)
+	seen := map[*db.Session]bool{}
+	for i := 0; i < len(set.replicas); i++ {
+		seen[set.Session(12, 2)] = true
+	}
+	assert.Len(t, seen, 2)
+	assert.True(t, seen[fresh.session])
+	assert.True(t, seen[other.session])
+}
+
+func TestReplicaSessions(t *testing.T) {
+	tt := test.Start(t).Scenario("base")
+	defer tt.Finish()
+
+	c := NewTestConfig()
+	c.HistoryReplicaURLs = []string{test.DatabaseURL()}
+	c.CoreReplicaURLs = []string{test.StellarCoreDatabaseURL()}
+	app, _ := NewApp(c)
+	defer app.Close()
+
+	// replicas are not used until their state is known
+	ctx := context.Background()
+	app.UpdateLedgerState()
+	tt.Assert.True(app.HorizonReadSession(ctx).DB == app.historyQ.Session.DB)
+	tt.Assert.True(app.CoreReadSession(ctx).DB == app.coreQ.Session.DB)
+
+	app.UpdateReplicasState()
+	tt.Assert.True(app.HorizonReadSession(ctx).DB == app.historyReplicas.replicas[0].session.DB)
+	tt.Assert.True(app.CoreReadSession(ctx).DB == app.coreReplicas.replicas[0].session.DB)
+
+	// writes always use the primary database
+	tt.Assert.True(app.HorizonSession(ctx).DB == app.historyQ.Session.DB)
+}
@@ -0,0 +1,42 @@
+package resourceadapter
+
+import (
+	"context"
+
+	"github.com/lomocoin/stellar-go/amount"
+	. "github.com/lomocoin/stellar-go/protocols/horizon"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/assets"
+	"github.com/lomocoin/stellar-go/support/errors"
+)
+
+// PopulateAssetDailyStat fills out the details of the stats of an asset on a
+// given day
+func PopulateAssetDailyStat(
+	ctx context.Context,
+	res *AssetDailyStat,
+	row assets.AssetDailyStatsR,
+) (err error) {
+	res.PT = row.Day
+	res.Day = row.Day
+	res.Amount, err = amount.IntStringToAmount(row.Amount)
+	if err != nil {
+		return errors.Wrap(err, "Invalid amount in PopulateAssetDailyStat")
+	}
+	res.NumAccounts = row.NumAccounts
+	res.NumUnauthorizedAccounts = row.NumUnauthorizedAccounts
+	res.UnauthorizedAmount, err = amount.IntStringToAmount(row.UnauthorizedAmount)
+	if err != nil {
+		return errors.Wrap(err, "Invalid unauthorized amount in PopulateAssetDailyStat")
+	}
+	res.AmountInOffers, err = amount.IntStringToAmount(row.AmountInOffers)
+	if err != nil {
+		return errors.Wrap(err, "Invalid amount in offers in PopulateAssetDailyStat")
+	}
+	res.NumHoldersAboveThreshold = row.NumHoldersAboveThreshold
+	res.PaymentCount = row.PaymentCount
+	res.PaymentVolume, err = amount.IntStringToAmount(row.PaymentVolume)
+	if err != nil {
+		return errors.Wrap(err, "Invalid payment volume in PopulateAssetDailyStat")
+	}
+	return
+}
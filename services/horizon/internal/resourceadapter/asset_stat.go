@@ -26,6 +26,21 @@ func PopulateAssetStat(
 		return errors.Wrap(err, "Invalid amount in PopulateAssetStat")
 	}
 	res.NumAccounts = row.NumAccounts
+	res.NumUnauthorizedAccounts = row.NumUnauthorizedAccounts
+	res.UnauthorizedAmount, err = amount.IntStringToAmount(row.UnauthorizedAmount)
+	if err != nil {
+		return errors.Wrap(err, "Invalid unauthorized amount in PopulateAssetStat")
+	}
+	res.AmountInOffers, err = amount.IntStringToAmount(row.AmountInOffers)
+	if err != nil {
+		return errors.Wrap(err, "Invalid amount in offers in PopulateAssetStat")
+	}
+	res.NumHoldersAboveThreshold = row.NumHoldersAboveThreshold
+	res.PaymentCount24h = row.PaymentCount24h
+	res.PaymentVolume24h, err = amount.IntStringToAmount(row.PaymentVolume24h)
+	if err != nil {
+		return errors.Wrap(err, "Invalid payment volume in PopulateAssetStat")
+	}
 	res.Flags = AccountFlags{
 		(row.Flags & int8(xdr.AccountFlagsAuthRequiredFlag)) != 0,
 		(row.Flags & int8(xdr.AccountFlagsAuthRevocableFlag)) != 0,
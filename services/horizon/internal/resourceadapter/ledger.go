@@ -27,6 +27,7 @@ func PopulateLedger(ctx context.Context, dest *Ledger, row history.Ledger) {
 	dest.BaseReserve = row.BaseReserve
 	dest.MaxTxSetSize = row.MaxTxSetSize
 	dest.ProtocolVersion = row.ProtocolVersion
+	dest.HistoryFiltered = row.HistoryFiltered
 
 	if row.LedgerHeaderXDR.Valid {
 		dest.HeaderXDR = row.LedgerHeaderXDR.String
@@ -0,0 +1,47 @@
+package resourceadapter
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/lomocoin/stellar-go/amount"
+	. "github.com/lomocoin/stellar-go/protocols/horizon"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/core"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/markets"
+)
+
+// PopulateMarket fills out the details of a market using a row from the
+// markets query and the cheapest offers of its order book: `ask` sells the
+// base asset and `bid` sells the counter asset.  Either may be nil when that
+// side of the order book is empty.
+func PopulateMarket(
+	ctx context.Context,
+	dest *Market,
+	row markets.MarketR,
+	ask *core.Offer,
+	bid *core.Offer,
+) {
+	dest.PT = row.SortKey
+	dest.BaseAssetType = row.BaseAssetType
+	dest.BaseAssetCode = row.BaseAssetCode
+	dest.BaseAssetIssuer = row.BaseAssetIssuer
+	dest.CounterAssetType = row.CounterAssetType
+	dest.CounterAssetCode = row.CounterAssetCode
+	dest.CounterAssetIssuer = row.CounterAssetIssuer
+	dest.TradeCount = row.TradeCount
+	dest.BaseVolume = amount.StringFromInt64(row.BaseVolume)
+	dest.CounterVolume = amount.StringFromInt64(row.CounterVolume)
+	dest.Open = row.Open.String()
+	dest.High = row.High.String()
+	dest.Low = row.Low.String()
+	dest.Close = row.Close.String()
+
+	if bid != nil {
+		// a bid is priced in counter units per base unit: the inverse of the
+		// price of the offer selling the counter asset.
+		dest.BestBid = big.NewRat(int64(bid.Priced), int64(bid.Pricen)).FloatString(7)
+	}
+	if ask != nil {
+		dest.BestAsk = ask.PriceAsString()
+	}
+}
@@ -2,6 +2,7 @@ package resourceadapter
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/lomocoin/stellar-go/amount"
 	. "github.com/lomocoin/stellar-go/protocols/horizon"
@@ -39,3 +40,65 @@ func PopulateOffer(ctx context.Context, dest *Offer, row core.Offer, ledger *his
 	dest.Links.OfferMaker = lb.Linkf("/accounts/%s", row.SellerID)
 	return
 }
+
+// PopulateHistoryOffer fills out the resource's fields from the latest event
+// recorded for an offer, which may no longer be on the order book.
+func PopulateHistoryOffer(ctx context.Context, dest *Offer, row history.OfferEvent, ledger *history.Ledger) {
+	dest.ID = row.OfferID
+	dest.PT = fmt.Sprintf("%d", row.OfferID)
+	dest.Seller = row.SellerID
+	dest.Amount = amount.String(row.Amount)
+	dest.PriceR.N = row.Pricen
+	dest.PriceR.D = row.Priced
+	dest.Price = row.PriceAsString()
+	dest.Buying = Asset{
+		Type:   assets.MustString(row.BuyingAssetType),
+		Code:   row.BuyingAssetCode.String,
+		Issuer: row.BuyingAssetIssuer.String,
+	}
+	dest.Selling = Asset{
+		Type:   assets.MustString(row.SellingAssetType),
+		Code:   row.SellingAssetCode.String,
+		Issuer: row.SellingAssetIssuer.String,
+	}
+	dest.LastModifiedLedger = row.LedgerSequence
+	if ledger != nil {
+		dest.LastModifiedTime = &ledger.ClosedAt
+	}
+	dest.State = row.State()
+	lb := hal.LinkBuilder{httpx.BaseURL(ctx)}
+	dest.Links.Self = lb.Linkf("/offers/%d", row.OfferID)
+	dest.Links.OfferMaker = lb.Linkf("/accounts/%s", row.SellerID)
+	return
+}
+
+// PopulateOfferEvent fills out the resource's fields
+func PopulateOfferEvent(ctx context.Context, dest *OfferEvent, row history.OfferEvent, ledger *history.Ledger) {
+	dest.ID = fmt.Sprintf("%d-%d", row.OfferID, row.HistoryOperationID)
+	dest.PT = row.PagingToken()
+	dest.OfferID = row.OfferID
+	dest.Type = row.Type.String()
+	dest.Seller = row.SellerID
+	dest.Amount = amount.String(row.Amount)
+	dest.PriceR.N = row.Pricen
+	dest.PriceR.D = row.Priced
+	dest.Price = row.PriceAsString()
+	dest.Buying = Asset{
+		Type:   assets.MustString(row.BuyingAssetType),
+		Code:   row.BuyingAssetCode.String,
+		Issuer: row.BuyingAssetIssuer.String,
+	}
+	dest.Selling = Asset{
+		Type:   assets.MustString(row.SellingAssetType),
+		Code:   row.SellingAssetCode.String,
+		Issuer: row.SellingAssetIssuer.String,
+	}
+	dest.Ledger = row.LedgerSequence
+	if ledger != nil {
+		dest.LedgerTime = &ledger.ClosedAt
+	}
+	lb := hal.LinkBuilder{httpx.BaseURL(ctx)}
+	dest.Links.Offer = lb.Linkf("/offers/%d", row.OfferID)
+	dest.Links.Operation = lb.Linkf("/operations/%d", row.HistoryOperationID)
+	return
+}
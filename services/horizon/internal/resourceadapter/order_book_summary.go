@@ -2,7 +2,9 @@ package resourceadapter
 
 import (
 	"context"
+	"math/big"
 
+	"github.com/lomocoin/stellar-go/amount"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/core"
 	"github.com/lomocoin/stellar-go/xdr"
 	. "github.com/lomocoin/stellar-go/protocols/horizon"
@@ -35,10 +37,13 @@ func populatePriceLevels(destp *[]PriceLevel, rows []core.OrderBookSummaryPriceL
 	*destp = make([]PriceLevel, len(rows))
 	dest := *destp
 
+	var cumulative int64
 	for i, row := range rows {
+		cumulative += row.Amount
 		dest[i] = PriceLevel{
-			Price:  row.PriceAsString(),
-			Amount: row.AmountAsString(),
+			Price:            row.PriceAsString(),
+			Amount:           row.AmountAsString(),
+			CumulativeAmount: amount.String(xdr.Int64(cumulative)),
 			PriceR: Price{
 				N: row.Pricen,
 				D: row.Priced,
@@ -46,3 +51,48 @@ func populatePriceLevels(destp *[]PriceLevel, rows []core.OrderBookSummaryPriceL
 		}
 	}
 }
+
+// PopulateOrderBookStats computes the spread statistics of the order book
+// summarized by `row` and sets the depth of the whole order book from `depth`.
+// When `depthPercent` is not nil, the depth of each side within
+// `depthPercent` percent of the mid price, loaded in `depth` between the
+// bounds returned by core.DepthBounds, is set too.
+func PopulateOrderBookStats(
+	ctx context.Context,
+	dest *OrderBookStats,
+	row core.OrderBookSummary,
+	depth core.OrderBookDepth,
+	depthPercent *big.Rat,
+) {
+	bids, asks := row.Bids(), row.Asks()
+
+	*dest = OrderBookStats{
+		BidDepth: amount.String(xdr.Int64(depth.BidDepth)),
+		AskDepth: amount.String(xdr.Int64(depth.AskDepth)),
+	}
+
+	if len(bids) > 0 {
+		dest.BestBid = bids[0].PriceAsString()
+	}
+	if len(asks) > 0 {
+		dest.BestAsk = asks[0].PriceAsString()
+	}
+
+	mid := row.MidPrice()
+	if mid == nil {
+		return
+	}
+
+	bestBid := big.NewRat(int64(bids[0].Pricen), int64(bids[0].Priced))
+	bestAsk := big.NewRat(int64(asks[0].Pricen), int64(asks[0].Priced))
+	dest.MidPrice = mid.FloatString(7)
+	dest.Spread = new(big.Rat).Sub(bestAsk, bestBid).FloatString(7)
+
+	if depthPercent == nil {
+		return
+	}
+
+	dest.DepthPercent = depthPercent.FloatString(2)
+	dest.BidDepthWithin = amount.String(xdr.Int64(depth.BidDepthWithin))
+	dest.AskDepthWithin = amount.String(xdr.Int64(depth.AskDepthWithin))
+}
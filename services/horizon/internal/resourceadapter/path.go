@@ -11,6 +11,9 @@ import (
 // PopulatePath converts the paths.Path into a Path
 func PopulatePath(ctx context.Context, dest *horizon.Path, q paths.Query, p paths.Path) (err error) {
 	dest.DestinationAmount = amount.String(q.DestinationAmount)
+	if q.StrictSend {
+		dest.DestinationAmount = amount.String(p.DestinationAmount)
+	}
 	dest.SourceAmount = amount.String(p.Cost)
 
 	err = p.Source.Extract(
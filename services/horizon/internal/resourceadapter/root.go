@@ -27,6 +27,7 @@ func PopulateRoot(
 	dest.StellarCoreVersion = cVersion
 	dest.NetworkPassphrase = passphrase
 	dest.ProtocolVersion = pVersion
+	dest.HistoryFiltered = ledgerState.HistoryFiltered
 
 	lb := hal.LinkBuilder{Base: httpx.BaseURL(ctx)}
 	if friendBotURL != nil {
@@ -2,6 +2,7 @@ package resourceadapter
 
 import (
 	"context"
+	"math/big"
 
 	"github.com/lomocoin/stellar-go/amount"
 	"github.com/lomocoin/stellar-go/price"
@@ -18,9 +19,14 @@ func PopulateTradeAggregation(
 ) (err error) {
 	dest.Timestamp = row.Timestamp
 	dest.TradeCount = row.TradeCount
+	dest.BuyCount = row.BuyCount
+	dest.SellCount = row.SellCount
 	dest.BaseVolume = amount.StringFromInt64(row.BaseVolume)
 	dest.CounterVolume = amount.StringFromInt64(row.CounterVolume)
 	dest.Average = price.StringFromFloat64(row.Average)
+	if row.BaseVolume != 0 {
+		dest.VWAP = big.NewRat(row.CounterVolume, row.BaseVolume).FloatString(7)
+	}
 	dest.High = row.High.String()
 	dest.HighR = row.High
 	dest.Low = row.Low.String()
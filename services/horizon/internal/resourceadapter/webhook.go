@@ -0,0 +1,56 @@
+package resourceadapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lomocoin/stellar-go/protocols/horizon"
+	"github.com/lomocoin/stellar-go/protocols/horizon/base"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/httpx"
+	"github.com/lomocoin/stellar-go/support/render/hal"
+)
+
+// PopulateWebhook fills out the details of a webhook, without its secret.
+func PopulateWebhook(ctx context.Context, dest *horizon.Webhook, row history.Webhook) {
+	dest.ID = fmt.Sprintf("%d", row.ID)
+	dest.PT = dest.ID
+	dest.URL = row.URL
+	dest.Accounts = row.AccountList()
+	dest.EventTypes = row.EventTypeList()
+	if dest.EventTypes == nil {
+		dest.EventTypes = []string{}
+	}
+	dest.CreatedAt = row.CreatedAt
+
+	dest.Asset = nil
+	if row.AssetType.Valid {
+		dest.Asset = &base.Asset{
+			Type:   row.AssetType.String,
+			Code:   row.AssetCode.String,
+			Issuer: row.AssetIssuer.String,
+		}
+	}
+
+	self := fmt.Sprintf("/webhooks/%d", row.ID)
+	lb := hal.LinkBuilder{httpx.BaseURL(ctx)}
+	dest.Links.Self = lb.Link(self)
+	dest.Links.DeadLetters = lb.PagedLink(self, "dead_letters")
+}
+
+// PopulateWebhookDeadLetter fills out the details of an event that could not
+// be delivered to a webhook.
+func PopulateWebhookDeadLetter(
+	ctx context.Context,
+	dest *horizon.WebhookDeadLetter,
+	row history.WebhookDeadLetter,
+) {
+	dest.ID = fmt.Sprintf("%d", row.ID)
+	dest.PT = dest.ID
+	dest.WebhookID = fmt.Sprintf("%d", row.WebhookID)
+	dest.Event = json.RawMessage(row.Payload)
+	dest.Attempts = row.Attempts
+	dest.LastError = row.LastError
+	dest.FailedAt = row.FailedAt
+}
@@ -0,0 +1,136 @@
+package horizon
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/hashicorp/golang-lru"
+	"github.com/lomocoin/stellar-go/support/log"
+)
+
+// responseCache stores the immutable responses served by horizon, such that
+// they are not loaded from the database again.
+type responseCache interface {
+	// Get returns the response cached at `key`, if any.
+	Get(key string) (*cachedResponse, bool)
+	// Add caches `response` at `key`.
+	Add(key string, response *cachedResponse)
+}
+
+// cachedResponse is a response stored by a responseCache.
+type cachedResponse struct {
+	ContentType  string `json:"content_type"`
+	CacheControl string `json:"cache_control"`
+	ETag         string `json:"etag"`
+	Body         []byte `json:"body"`
+}
+
+// lruResponseCache is a responseCache keeping the most recently used
+// responses in memory.
+type lruResponseCache struct {
+	cache *lru.Cache
+}
+
+// redisResponseCache is a responseCache storing the responses in redis, where
+// they expire after `ttl`, so that every horizon of a cluster shares them.
+type redisResponseCache struct {
+	pool *redis.Pool
+	ttl  time.Duration
+}
+
+const redisResponseCachePrefix = "response_cache:"
+
+// Get implements responseCache.
+func (c *lruResponseCache) Get(key string) (*cachedResponse, bool) {
+	value, ok := c.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+
+	return value.(*cachedResponse), true
+}
+
+// Add implements responseCache.
+func (c *lruResponseCache) Add(key string, response *cachedResponse) {
+	c.cache.Add(key, response)
+}
+
+// Get implements responseCache.  Failing to load a response from redis is
+// treated as a cache miss.
+func (c *redisResponseCache) Get(key string) (*cachedResponse, bool) {
+	conn := c.pool.Get()
+	defer conn.Close()
+
+	data, err := redis.Bytes(conn.Do("GET", redisResponseCachePrefix+key))
+	if err == redis.ErrNil {
+		return nil, false
+	}
+	if err != nil {
+		log.WithField("err", err.Error()).Error("failed to load cached response")
+		return nil, false
+	}
+
+	var response cachedResponse
+	err = json.Unmarshal(data, &response)
+	if err != nil {
+		log.WithField("err", err.Error()).Error("failed to decode cached response")
+		return nil, false
+	}
+
+	return &response, true
+}
+
+// Add implements responseCache.
+func (c *redisResponseCache) Add(key string, response *cachedResponse) {
+	if c.ttl < time.Millisecond {
+		return
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		log.WithField("err", err.Error()).Error("failed to encode cached response")
+		return
+	}
+
+	conn := c.pool.Get()
+	defer conn.Close()
+
+	_, err = conn.Do("SET", redisResponseCachePrefix+key, data, "PX", int64(c.ttl/time.Millisecond))
+	if err != nil {
+		log.WithField("err", err.Error()).Error("failed to cache response")
+	}
+}
+
+// initResponseCache creates the response cache configured for the app, if
+// any.
+func initResponseCache(app *App) {
+	switch {
+	case app.config.ResponseCacheRedis:
+		if app.redis == nil {
+			log.Panic("response cache: redis-url must be set to cache responses in redis")
+		}
+
+		app.web.responseCache = &redisResponseCache{
+			pool: app.redis,
+			ttl:  app.config.ResponseCacheMaxAge,
+		}
+	case app.config.ResponseCacheSize > 0:
+		cache, err := lru.New(app.config.ResponseCacheSize)
+		if err != nil {
+			log.Panic(err)
+		}
+
+		app.web.responseCache = &lruResponseCache{cache: cache}
+	}
+}
+
+func init() {
+	appInit.Add(
+		"web.response-cache",
+		initResponseCache,
+
+		"web.init",
+		"redis",
+	)
+}
@@ -0,0 +1,59 @@
+package horizon
+
+import (
+	"context"
+	"time"
+
+	"github.com/lomocoin/stellar-go/support/log"
+)
+
+// defaultShutdownTimeout is the shutdown timeout used when the config does not
+// set one.
+const defaultShutdownTimeout = 10 * time.Second
+
+// shutdownGracePeriod is the time given to the transaction submissions timed
+// out at the end of the shutdown timeout to send their response.
+const shutdownGracePeriod = time.Second
+
+// shutdown starts shutting horizon down, once the server has stopped accepting
+// connections.  New requests are rejected and streams end with an event
+// telling clients to reconnect to another horizon.  The ingestion system stops
+// starting new sessions and the current one stops after the ledger it is
+// ingesting.  Transaction submissions keep waiting for their result until
+// `timeout` elapses, then time out.
+func (a *App) shutdown(timeout time.Duration) {
+	a.drain()
+
+	if a.ingester != nil {
+		a.ingester.Shutdown()
+	}
+
+	time.AfterFunc(timeout, func() {
+		pending := len(a.submitter.Pending.Pending(context.Background()))
+		if pending == 0 {
+			return
+		}
+
+		log.WithField("pending", pending).Warn("Shutdown timeout elapsed, timing out pending transaction submissions")
+		a.submitter.Pending.Clean(context.Background(), 0)
+	})
+}
+
+// waitForIngestion waits, for up to `timeout`, for the current ingestion
+// session to commit or roll back before the databases are closed.
+func (a *App) waitForIngestion(timeout time.Duration) {
+	if a.ingester == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := a.ingester.Wait(ctx)
+	if err != nil {
+		log.Warn("Ingestion session did not finish within the shutdown timeout, its transaction will be rolled back")
+		return
+	}
+
+	log.Info("Ingestion stopped")
+}
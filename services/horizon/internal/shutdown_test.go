@@ -0,0 +1,43 @@
+package horizon
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/lomocoin/stellar-go/services/horizon/internal/txsub"
+)
+
+func TestAppShutdown(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	w := ht.Get("/ledgers")
+	ht.Assert.Equal(200, w.Code)
+
+	// a transaction submission waiting for its result
+	listener := make(chan txsub.Result, 1)
+	ht.Require.NoError(ht.App.submitter.Pending.Add(context.Background(), "2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d", listener))
+
+	ht.App.shutdown(10 * time.Millisecond)
+
+	// new requests are rejected
+	w = ht.Get("/ledgers")
+	if ht.Assert.Equal(503, w.Code) {
+		var problem struct {
+			Type string `json:"type"`
+		}
+		ht.Require.NoError(json.Unmarshal(w.Body.Bytes(), &problem))
+		ht.Assert.Contains(problem.Type, "shutting_down")
+		ht.Assert.Equal("close", w.Header().Get("Connection"))
+	}
+
+	// pending submissions time out once the shutdown timeout has elapsed
+	select {
+	case r := <-listener:
+		ht.Assert.Equal(txsub.ErrTimeout, r.Err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("pending submission not timed out")
+	}
+}
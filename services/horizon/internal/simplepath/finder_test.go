@@ -161,4 +161,24 @@ func TestFinder(t *testing.T) {
 			}
 		}
 	}
+
+	// strict-send: sending 10 USD, the direct path delivers 20 EUR
+	query = paths.Query{
+		StrictSend:        true,
+		SourceAsset:       usd,
+		SourceAmount:      xdr.Int64(100000000), // 10.0000000
+		DestinationAssets: []xdr.Asset{eur},
+	}
+	p, err = finder.Find(query, MaxPathLength)
+	if tt.Assert.NoError(err) && tt.Assert.NotEmpty(p) {
+		tt.Assert.Equal(p[0].Source.String(), usd.String())
+		tt.Assert.Equal(p[0].Destination.String(), eur.String())
+		tt.Assert.Equal(p[0].Cost, xdr.Int64(100000000))
+		tt.Assert.Equal(p[0].DestinationAmount, xdr.Int64(200000000)) // 20.0000000
+		tt.Assert.Len(p[0].Path, 0)
+	}
+
+	query.DestinationAssets = nil
+	_, err = finder.Find(query, MaxPathLength)
+	tt.Assert.Error(err)
 }
@@ -47,7 +47,7 @@ func (ob *orderBook) CostToConsumeLiquidity(sellingAmount xdr.Int64) (xdr.Int64,
 			return 0, e
 		}
 
-		buyingUnitsExtracted, sellingUnitsExtracted, e := convertToBuyingUnits(offerAmount, remaining, pricen, priced)
+		buyingUnitsExtracted, sellingUnitsExtracted, e := ConvertToBuyingUnits(offerAmount, remaining, pricen, priced)
 		if e != nil {
 			return 0, e
 		}
@@ -66,6 +66,52 @@ func (ob *orderBook) CostToConsumeLiquidity(sellingAmount xdr.Int64) (xdr.Int64,
 	return 0, ErrNotEnough
 }
 
+// AmountToReceive returns the sellingAmount (ob.Selling) received when consuming
+// the liquidity of the orderbook with buyingAmount (ob.Buying).  It runs the
+// math of CostToConsumeLiquidity in the opposite direction, as needed by
+// strict-send path finding.
+func (ob *orderBook) AmountToReceive(buyingAmount xdr.Int64) (xdr.Int64, error) {
+	// load orderbook from core's db
+	sql, e := ob.query()
+	if e != nil {
+		return 0, e
+	}
+	rows, e := ob.Q.Query(sql)
+	if e != nil {
+		return 0, e
+	}
+	defer rows.Close()
+
+	// remaining is the units of ob.Buying that we want to spend
+	remaining := int64(buyingAmount)
+	var sellingAmount int64
+	for rows.Next() {
+		// load data from the row
+		var offerAmount, pricen, priced, offerid int64
+		e = rows.Scan(&offerAmount, &pricen, &priced, &offerid)
+		if e != nil {
+			return 0, e
+		}
+
+		sellingUnitsExtracted, buyingUnitsSpent, e := ConvertToSellingUnits(offerAmount, remaining, pricen, priced)
+		if e != nil {
+			return 0, e
+		}
+		// overflow check
+		if willAddOverflow(sellingAmount, sellingUnitsExtracted) {
+			return xdr.Int64(0), fmt.Errorf("adding these two values will cause an integer overflow: %d, %d", sellingAmount, sellingUnitsExtracted)
+		}
+		sellingAmount += sellingUnitsExtracted
+		remaining -= buyingUnitsSpent
+
+		// check if we spent all the units we wanted
+		if remaining <= 0 {
+			return xdr.Int64(sellingAmount), nil
+		}
+	}
+	return 0, ErrNotEnough
+}
+
 func willAddOverflow(a int64, b int64) bool {
 	return a > math.MaxInt64-b
 }
@@ -103,7 +149,7 @@ func (ob *orderBook) query() (sq.SelectBuilder, error) {
 	return sql, nil
 }
 
-// convertToBuyingUnits uses special rounding logic to multiply the amount by the price and returns (buyingUnits, sellingUnits) that can be taken from the offer
+// ConvertToBuyingUnits uses special rounding logic to multiply the amount by the price and returns (buyingUnits, sellingUnits) that can be taken from the offer
 //
 // offerSellingBound = (offer.price.n > offer.price.d)
 // 	? offer.amount : ceil(floor(offer.amount * offer.price) / offer.price)
@@ -119,7 +165,7 @@ func (ob *orderBook) query() (sq.SelectBuilder, error) {
 
 // this is how we do floor and ceiling in stellar-core:
 // https://github.com/stellar/stellar-core/blob/9af27ef4e20b66f38ab148d52ba7904e74fe502f/src/util/types.cpp#L201
-func convertToBuyingUnits(sellingOfferAmount int64, sellingUnitsNeeded int64, pricen int64, priced int64) (int64, int64, error) {
+func ConvertToBuyingUnits(sellingOfferAmount int64, sellingUnitsNeeded int64, pricen int64, priced int64) (int64, int64, error) {
 	var e error
 	// offerSellingBound
 	result := sellingOfferAmount
@@ -147,6 +193,46 @@ func convertToBuyingUnits(sellingOfferAmount int64, sellingUnitsNeeded int64, pr
 	return result, sellingUnitsExtracted, nil
 }
 
+// ConvertToSellingUnits is the inverse of ConvertToBuyingUnits: given the units
+// of the offer's buying asset available to pay, it returns (sellingUnits,
+// buyingUnits), the units that can be taken from the offer and the units spent
+// to take them.
+//
+// offerSellingBound is computed as in ConvertToBuyingUnits
+// pathPaymentAmountBought = min(offerSellingBound, floor(buyingUnitsAvailable / offer.price))
+// pathPaymentAmountSold = ceil(pathPaymentAmountBought * offer.price)
+func ConvertToSellingUnits(sellingOfferAmount int64, buyingUnitsAvailable int64, pricen int64, priced int64) (int64, int64, error) {
+	var e error
+	// offerSellingBound
+	bound := sellingOfferAmount
+	if pricen <= priced {
+		bound, e = mulFractionRoundDown(sellingOfferAmount, pricen, priced)
+		if e != nil {
+			return 0, 0, e
+		}
+		bound, e = mulFractionRoundUp(bound, priced, pricen)
+		if e != nil {
+			return 0, 0, e
+		}
+	}
+
+	// pathPaymentAmountBought
+	result, e := mulFractionRoundDown(buyingUnitsAvailable, priced, pricen)
+	if e != nil {
+		return 0, 0, e
+	}
+	result = min(result, bound)
+	sellingUnitsExtracted := result
+
+	// pathPaymentAmountSold
+	result, e = mulFractionRoundUp(result, pricen, priced)
+	if e != nil {
+		return 0, 0, e
+	}
+
+	return sellingUnitsExtracted, result, nil
+}
+
 // mulFractionRoundDown sets x = (x * n) / d, which is a round-down operation
 // see https://github.com/stellar/stellar-core/blob/9af27ef4e20b66f38ab148d52ba7904e74fe502f/src/util/types.cpp#L201
 func mulFractionRoundDown(x int64, n int64, d int64) (int64, error) {
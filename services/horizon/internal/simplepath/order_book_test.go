@@ -54,6 +54,31 @@ func TestOrderBook(t *testing.T) {
 		_, err := ob.CostToConsumeLiquidity(xdr.Int64(300000001))
 		tt.Assert.Error(err)
 	})
+
+	receiveCases := []struct {
+		scenario string
+		usd      int64
+		wantEUR  int64
+	}{
+		{"first full offer", 50000000, 100000000},
+		{"first two full offers", 100000000, 200000000},
+		{"first three full offers", 200000000, 300000000},
+	}
+
+	for _, kase := range receiveCases {
+		t.Run(kase.scenario, func(t *testing.T) {
+			r, err := ob.AmountToReceive(xdr.Int64(kase.usd))
+			if tt.Assert.NoError(err) {
+				tt.Assert.Equal(xdr.Int64(kase.wantEUR), r)
+			}
+		})
+	}
+
+	// spending 1 more than the available liquidity accepts
+	t.Run("one more than available liquidity", func(t *testing.T) {
+		_, err := ob.AmountToReceive(xdr.Int64(200000001))
+		tt.Assert.Equal(ErrNotEnough, err)
+	})
 }
 
 func TestOrderBook_BadCost(t *testing.T) {
@@ -101,7 +126,7 @@ func TestConvertToBuyingUnits(t *testing.T) {
 	}
 	for _, kase := range testCases {
 		t.Run(t.Name(), func(t *testing.T) {
-			buyingUnits, sellingUnits, e := convertToBuyingUnits(kase.sellingOfferAmount, kase.sellingUnitsNeeded, kase.pricen, kase.priced)
+			buyingUnits, sellingUnits, e := ConvertToBuyingUnits(kase.sellingOfferAmount, kase.sellingUnitsNeeded, kase.pricen, kase.priced)
 			if !assert.Nil(t, e) {
 				return
 			}
@@ -133,3 +158,32 @@ func TestWillAddOverflow(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertToSellingUnits(t *testing.T) {
+	testCases := []struct {
+		sellingOfferAmount   int64
+		buyingUnitsAvailable int64
+		pricen               int64
+		priced               int64
+		wantSellingUnits     int64
+		wantBuyingUnits      int64
+	}{
+		{7, 1, 3, 7, 2, 1},
+		{20, 5, 1, 4, 20, 5},
+		{20, 100, 1, 4, 20, 5},
+		{20, 13, 7, 11, 19, 13},
+		{20, 32, 11, 7, 20, 32},
+		{1, 0, 3, 7, 0, 0},
+		{math.MaxInt64, 0, 3, 7, 0, 0},
+	}
+	for _, kase := range testCases {
+		t.Run(t.Name(), func(t *testing.T) {
+			sellingUnits, buyingUnits, e := ConvertToSellingUnits(kase.sellingOfferAmount, kase.buyingUnitsAvailable, kase.pricen, kase.priced)
+			if !assert.Nil(t, e) {
+				return
+			}
+			assert.Equal(t, kase.wantSellingUnits, sellingUnits)
+			assert.Equal(t, kase.wantBuyingUnits, buyingUnits)
+		})
+	}
+}
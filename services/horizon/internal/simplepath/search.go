@@ -94,13 +94,25 @@ func (s *search) Run() {
 		return
 	}
 
-	s.Err = s.Q.Begin()
+	s.Err = begin(s.Q)
 	if s.Err != nil {
 		return
 	}
 
 	defer s.Q.Rollback()
 
+	for s.hasMore() {
+		s.runOnce()
+	}
+}
+
+// begin starts the read only transaction a search runs in.
+func begin(q *core.Q) error {
+	err := q.Begin()
+	if err != nil {
+		return err
+	}
+
 	// We need REPEATABLE READ here to have a stable view of the offers
 	// table. Without it, it's possible that search started in ledger X
 	// and finished in ledger X+1 would give invalid results.
@@ -108,14 +120,13 @@ func (s *search) Run() {
 	// https://www.postgresql.org/docs/9.1/static/transaction-iso.html
 	// > Note that only updating transactions might need to be retried;
 	// > read-only transactions will never have serialization conflicts.
-	_, s.Err = s.Q.ExecRaw("SET TRANSACTION ISOLATION LEVEL REPEATABLE READ, READ ONLY")
-	if s.Err != nil {
-		return
+	_, err = q.ExecRaw("SET TRANSACTION ISOLATION LEVEL REPEATABLE READ, READ ONLY")
+	if err != nil {
+		q.Rollback()
+		return err
 	}
 
-	for s.hasMore() {
-		s.runOnce()
-	}
+	return nil
 }
 
 // pop removes the head from the search queue, returning it to the caller
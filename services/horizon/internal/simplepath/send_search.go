@@ -0,0 +1,166 @@
+package simplepath
+
+import (
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/core"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/paths"
+	"github.com/lomocoin/stellar-go/xdr"
+)
+
+// sendSearch represents a single strict-send query against the simple finder.
+// It is used like search, but works the other way around: paths are extended
+// from the source asset towards the destination assets, the head of the path
+// being the most recently added asset and the cost of a computedNode being the
+// units of its head asset received when sending the source amount.
+type sendSearch struct {
+	Query     paths.Query
+	Q         *core.Q
+	MaxLength uint
+
+	// Fields below are initialized by a call to Init() after
+	// setting the fields above
+	queue   []computedNode
+	targets map[string]bool
+
+	//This fields below are initialized after the search is run
+	Err     error
+	Results []paths.Path
+}
+
+// Init initialized the search, setting fields on the struct used to
+// hold state needed during the actual search.
+func (s *sendSearch) Init() {
+	s.queue = []computedNode{
+		computedNode{
+			path: pathNode{
+				Asset: s.Query.SourceAsset,
+				Tail:  nil,
+				Q:     s.Q,
+				Depth: 1,
+			},
+			cost: s.Query.SourceAmount,
+		},
+	}
+
+	s.targets = map[string]bool{}
+	for _, a := range s.Query.DestinationAssets {
+		s.targets[a.String()] = true
+	}
+
+	s.Err = nil
+	s.Results = nil
+}
+
+// Run triggers the search, which will populate the Results and Err
+// field for the search after completion.
+func (s *sendSearch) Run() {
+	if s.Err != nil {
+		return
+	}
+
+	s.Err = begin(s.Q)
+	if s.Err != nil {
+		return
+	}
+
+	defer s.Q.Rollback()
+
+	for s.hasMore() {
+		s.runOnce()
+	}
+}
+
+// returns false if the search should stop.
+func (s *sendSearch) hasMore() bool {
+	if s.Err != nil {
+		return false
+	}
+
+	if len(s.Results) >= maxResults {
+		return false
+	}
+
+	return len(s.queue) > 0
+}
+
+// runOnce processes the head of the search queue, findings results
+// and extending the search as necessary.
+func (s *sendSearch) runOnce() {
+	cur := s.queue[0]
+	s.queue = s.queue[1:]
+
+	if s.targets[cur.path.Asset.String()] {
+		s.Results = append(s.Results, s.asPath(cur))
+	}
+
+	if cur.path.Depth == s.MaxLength {
+		return
+	}
+
+	s.extendSearch(cur)
+}
+
+func (s *sendSearch) extendSearch(cur computedNode) {
+	// find the assets sold in exchange for the head asset
+	var connected []xdr.Asset
+	s.Err = s.Q.ConnectedSellingAssets(&connected, cur.path.Asset)
+	if s.Err != nil {
+		return
+	}
+
+	for _, a := range connected {
+		// see search.extendSearch
+		if cur.path.IsOnPath(a) {
+			continue
+		}
+
+		if cur.path.Depth == s.MaxLength-1 && !s.targets[a.String()] {
+			continue
+		}
+
+		ob := orderBook{
+			Selling: a,              // offer is selling this asset
+			Buying:  cur.path.Asset, // offer is buying this asset
+			Q:       s.Q,
+		}
+
+		var received xdr.Int64
+		received, s.Err = ob.AmountToReceive(cur.cost)
+		if s.Err == ErrNotEnough {
+			s.Err = nil
+			continue
+		}
+		if s.Err != nil {
+			return
+		}
+
+		tail := cur.path
+		s.queue = append(s.queue, computedNode{
+			path: pathNode{
+				Asset: a,
+				Tail:  &tail,
+				Q:     s.Q,
+				Depth: tail.Depth + 1,
+			},
+			cost: received,
+		})
+	}
+}
+
+// asPath converts `c`, whose head is the destination asset, into a paths.Path.
+func (s *sendSearch) asPath(c computedNode) paths.Path {
+	assets := c.path.Flatten()
+	for i, j := 0, len(assets)-1; i < j; i, j = i+1, j-1 {
+		assets[i], assets[j] = assets[j], assets[i]
+	}
+
+	result := paths.Path{
+		Source:            assets[0],
+		Destination:       assets[len(assets)-1],
+		Cost:              s.Query.SourceAmount,
+		DestinationAmount: c.cost,
+	}
+	if len(assets) > 2 {
+		result.Path = assets[1 : len(assets)-1]
+	}
+	return result
+}
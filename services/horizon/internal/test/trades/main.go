@@ -2,6 +2,8 @@
 package trades
 
 import (
+	"math"
+
 	"github.com/lomocoin/stellar-go/keypair"
 	. "github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
 	"github.com/lomocoin/stellar-go/support/time"
@@ -52,6 +54,24 @@ func IngestTestTrade(
 	return q.InsertTrade(opCounter, 0, buyer, false, xdr.OfferEntry{}, trade, price, timestamp)
 }
 
+//RebuildTestTradeRollups rebuilds the trade rollups of every trade in the
+//history_trades table, as ingestion does once it has written its trades
+func RebuildTestTradeRollups(q *Q) error {
+	var buckets []TradeRollupBucket
+	err := q.TradeRollupBuckets(&buckets, 0, math.MaxInt64)
+	if err != nil {
+		return err
+	}
+
+	for _, bucket := range buckets {
+		err = q.RebuildTradeRollup(bucket)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 //PopulateTestTrades generates and ingests trades between two assets according to given parameters
 func PopulateTestTrades(
 	q *Q,
@@ -87,6 +87,52 @@ type Result struct {
 	ResultMetaXDR string
 }
 
+// ResultOutcomes are the outcomes results are counted by: `success`, `failed`
+// (rejected by stellar-core), `bad_seq` (bad sequence number), `no_account`
+// (missing source account), `malformed`, `timeout`, `canceled` and `error`
+// (any other error).
+var ResultOutcomes = []string{
+	"success",
+	"failed",
+	"bad_seq",
+	"no_account",
+	"malformed",
+	"timeout",
+	"canceled",
+	"error",
+}
+
+// ResultOutcome returns the outcome of `r`, one of ResultOutcomes.
+func ResultOutcome(r Result) string {
+	switch err := r.Err.(type) {
+	case nil:
+		return "success"
+	case *MalformedTransactionError:
+		return "malformed"
+	case *FailedTransactionError:
+		code, cerr := err.TransactionResultCode()
+		switch {
+		case cerr != nil:
+			return "error"
+		case code == "tx_bad_seq":
+			return "bad_seq"
+		case code == "tx_no_source_account":
+			return "no_account"
+		default:
+			return "failed"
+		}
+	}
+
+	switch r.Err {
+	case ErrTimeout:
+		return "timeout"
+	case ErrCanceled:
+		return "canceled"
+	default:
+		return "error"
+	}
+}
+
 // SubmissionResult gets returned in response to a call to Submitter.Submit.
 // It represents a single discrete submission of a transaction envelope to
 // the stellar network.
@@ -49,6 +49,10 @@ type System struct {
 		// SuccessfulSubmissionsMeter tracks the rate of successful transactions that
 		// have been submitted to this process
 		SuccessfulSubmissionsMeter metrics.Meter
+
+		// ResultCounters counts the results returned to submitters, by outcome.
+		// See ResultOutcomes for the possible outcomes.
+		ResultCounters map[string]metrics.Counter
 	}
 }
 
@@ -57,7 +61,9 @@ type System struct {
 func (sys *System) Submit(ctx context.Context, env string) (result <-chan Result) {
 	sys.Init()
 	response := make(chan Result, 1)
-	result = response
+	// every result, whether found right away or once the transaction is
+	// included in a ledger by the pending list, is counted on its way out.
+	result = sys.countResult(response)
 
 	// calculate hash of transaction
 	info, err := extractEnvelopeInfo(ctx, env, sys.NetworkPassphrase)
@@ -162,6 +168,21 @@ func (sys *System) Submit(ctx context.Context, env string) (result <-chan Result
 	return
 }
 
+// countResult returns a channel emitting the result emitted by `response`,
+// once counted by its outcome.
+func (sys *System) countResult(response <-chan Result) <-chan Result {
+	counted := make(chan Result, 1)
+	go func() {
+		r, ok := <-response
+		if ok {
+			sys.Metrics.ResultCounters[ResultOutcome(r)].Inc(1)
+			counted <- r
+		}
+		close(counted)
+	}()
+	return counted
+}
+
 // Submit submits the provided base64 encoded transaction envelope to the
 // network using this submission system.
 func (sys *System) submitOnce(ctx context.Context, env string) SubmissionResult {
@@ -271,6 +292,10 @@ func (sys *System) Init() {
 		sys.Metrics.SubmissionTimer = metrics.NewTimer()
 		sys.Metrics.OpenSubmissionsGauge = metrics.NewGauge()
 		sys.Metrics.BufferedSubmissionsGauge = metrics.NewGauge()
+		sys.Metrics.ResultCounters = map[string]metrics.Counter{}
+		for _, outcome := range ResultOutcomes {
+			sys.Metrics.ResultCounters[outcome] = metrics.NewCounter()
+		}
 
 		if sys.SubmissionTimeout == 0 {
 			// HTTP clients in SDKs usually timeout in 60 seconds. We want SubmissionTimeout
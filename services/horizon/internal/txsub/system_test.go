@@ -66,6 +66,7 @@ func (suite *SystemTestSuite) TestSubmit_Basic() {
 	assert.Nil(suite.T(), r.Err)
 	assert.Equal(suite.T(), suite.successTx.Hash, r.Hash)
 	assert.False(suite.T(), suite.submitter.WasSubmittedTo)
+	assert.Equal(suite.T(), int64(1), suite.system.Metrics.ResultCounters["success"].Count())
 }
 
 // Returns the error from submission if no result is found by hash and the suite.submitter returns an error.
@@ -78,6 +79,7 @@ func (suite *SystemTestSuite) TestSubmit_NotFoundError() {
 	assert.Equal(suite.T(), int64(0), suite.system.Metrics.SuccessfulSubmissionsMeter.Count())
 	assert.Equal(suite.T(), int64(1), suite.system.Metrics.FailedSubmissionsMeter.Count())
 	assert.Equal(suite.T(), int64(1), suite.system.Metrics.SubmissionTimer.Count())
+	assert.Equal(suite.T(), int64(1), suite.system.Metrics.ResultCounters["error"].Count())
 }
 
 // If the error is bad_seq and the result at the transaction's sequence number is for the same hash, return result.
@@ -100,6 +102,7 @@ func (suite *SystemTestSuite) TestSubmit_BadSeqNotFound() {
 
 	assert.NotNil(suite.T(), r.Err)
 	assert.True(suite.T(), suite.submitter.WasSubmittedTo)
+	assert.Equal(suite.T(), int64(1), suite.system.Metrics.ResultCounters["bad_seq"].Count())
 }
 
 // If no result found and no error submitting, add to open transaction list.
@@ -0,0 +1,112 @@
+package webhooks
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/lomocoin/stellar-go/support/errors"
+)
+
+var (
+	// errForbiddenAddress is returned when dialing a webhook whose host only
+	// resolves to addresses of the internal network.
+	errForbiddenAddress = errors.New("forbidden address")
+	// errConnectionFailed is the error recorded for deliveries whose request
+	// failed, whatever the cause.
+	errConnectionFailed = errors.New("connection failed")
+	// errInvalidURL is the error recorded for deliveries to a url that
+	// cannot be requested.
+	errInvalidURL = errors.New("invalid url")
+)
+
+// privateNetworks are the ranges, beside loopback, link-local, multicast and
+// unspecified addresses, webhooks cannot be delivered to.
+var privateNetworks = mustParseCIDRs(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"fc00::/7",
+)
+
+// newClient returns the client deliveries are made with.  It only dials
+// public addresses, checked once the host of a webhook is resolved so that
+// names pointing to the internal network are rejected too, doesn't use the
+// proxy of the environment and doesn't follow redirects.
+func newClient() *http.Client {
+	dialer := &net.Dialer{Timeout: deliveryTimeout}
+
+	return &http.Client{
+		Timeout: deliveryTimeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+				return dialPublic(ctx, dialer, network, address)
+			},
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: deliveryTimeout,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// dialPublic resolves the host of `address` and dials the first of its public
+// addresses.
+func dialPublic(ctx context.Context, dialer *net.Dialer, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, addr := range addrs {
+		if isForbiddenIP(addr.IP) {
+			continue
+		}
+		return dialer.DialContext(ctx, network, net.JoinHostPort(addr.IP.String(), port))
+	}
+
+	return nil, errForbiddenAddress
+}
+
+// isForbiddenIP returns true if `ip` belongs to the internal network.
+func isForbiddenIP(ip net.IP) bool {
+	if ip.IsLoopback() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() {
+		return true
+	}
+
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+
+	return networks
+}
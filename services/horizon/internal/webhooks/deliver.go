@@ -0,0 +1,139 @@
+package webhooks
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/errors"
+	"github.com/lomocoin/stellar-go/support/log"
+)
+
+// Tick triggers the webhook system to dispatch the events of the ledgers
+// ingested since the last tick and to attempt the deliveries that are due.
+// Ticks are skipped while the previous one is still running.
+func (s *System) Tick() {
+	if !atomic.CompareAndSwapInt32(&s.running, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&s.running, 0)
+
+	s.runOnce()
+}
+
+func (s *System) runOnce() {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err := errors.FromPanic(rec)
+			log.Errorf("webhooks panicked: %s", err)
+			errors.ReportToSentry(err, nil)
+		}
+	}()
+
+	err := s.Dispatch()
+	if err != nil {
+		log.Errorf("webhooks: dispatch failed: %s", err)
+	}
+
+	err = s.Deliver()
+	if err != nil {
+		log.Errorf("webhooks: delivery failed: %s", err)
+	}
+}
+
+// Deliver attempts the deliveries that are due.  Deliveries that fail are
+// scheduled to be attempted again after a backoff or, once they reach the
+// maximum number of attempts, moved to the dead letters of their webhook.
+func (s *System) Deliver() error {
+	q := &history.Q{Session: s.HorizonDB}
+
+	var deliveries []history.WebhookDelivery
+	err := q.ClaimWebhookDeliveries(&deliveries, maxDeliveriesPerTick, deliveryLease)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	queue := make(chan history.WebhookDelivery)
+	for i := 0; i < deliveryConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for delivery := range queue {
+				s.attempt(q, delivery)
+			}
+		}()
+	}
+
+	for _, delivery := range deliveries {
+		queue <- delivery
+	}
+	close(queue)
+	wg.Wait()
+
+	return nil
+}
+
+// attempt delivers `delivery` and records the outcome.
+func (s *System) attempt(q *history.Q, delivery history.WebhookDelivery) {
+	l := log.WithField("webhook_id", delivery.WebhookID).
+		WithField("event_id", delivery.EventID)
+
+	var err error
+	deliveryErr := s.post(delivery)
+	attempts := delivery.Attempts + 1
+
+	switch {
+	case deliveryErr == nil:
+		err = q.DeleteWebhookDelivery(delivery.ID)
+	case attempts >= s.MaxAttempts:
+		l.WithField("err", deliveryErr.Error()).Warn("webhooks: giving up on delivery")
+		err = q.DeadLetterWebhookDelivery(delivery.ID, deliveryErr.Error())
+	default:
+		l.WithField("err", deliveryErr.Error()).Info("webhooks: delivery failed")
+		err = q.RetryWebhookDelivery(delivery.ID, deliveryErr.Error(), time.Now().Add(Backoff(attempts)))
+	}
+
+	if err != nil {
+		l.WithField("err", err.Error()).Error("webhooks: failed to record delivery")
+	}
+}
+
+// post sends the payload of `delivery` to its webhook, signed with the
+// webhook's secret.  Webhooks must respond with a 2xx status code.  The errors
+// returned are shown to the owner of the webhook, so they don't tell why a
+// connection failed: the cause is only logged.
+func (s *System) post(delivery history.WebhookDelivery) error {
+	req, err := http.NewRequest(http.MethodPost, delivery.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return errInvalidURL
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, timestamp, []byte(delivery.Payload)))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		log.WithField("webhook_id", delivery.WebhookID).
+			WithField("err", err.Error()).
+			Debug("webhooks: connection failed")
+		return errConnectionFailed
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,314 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/guregu/null"
+	"github.com/lomocoin/stellar-go/protocols/horizon"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/ledger"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/resourceadapter"
+	"github.com/lomocoin/stellar-go/support/errors"
+	"github.com/lomocoin/stellar-go/support/log"
+	"github.com/lomocoin/stellar-go/xdr"
+)
+
+// event is a payment or an effect that webhooks can be notified of.
+type event struct {
+	ID       string
+	Type     string
+	Accounts []string
+	// Assets are the assets involved in the event, empty when it involves
+	// none.
+	Assets []asset
+	Record json.RawMessage
+}
+
+// asset identifies an asset as it appears in the details of operations and
+// effects.
+type asset struct {
+	Type   string
+	Code   string
+	Issuer string
+}
+
+// filter matches the events a webhook is notified of.
+type filter struct {
+	accounts map[string]bool
+	types    map[string]bool
+	asset    *asset
+}
+
+// Dispatch queues the deliveries of the events of the ledgers ingested since
+// the last dispatch to the webhooks they match.
+func (s *System) Dispatch() error {
+	var oldest null.Int
+	err := (&history.Q{Session: s.HorizonDB}).OldestWebhookLedger(&oldest)
+	if err != nil {
+		return errors.Wrap(err, "load oldest webhook ledger failed")
+	}
+
+	// no webhooks
+	if !oldest.Valid {
+		return nil
+	}
+
+	latest := ledger.CurrentState()
+	from := int32(oldest.Int64) + 1
+	if from < latest.HistoryElder {
+		from = latest.HistoryElder
+	}
+
+	for seq := from; seq <= latest.HistoryLatest && seq < from+maxLedgersPerTick; seq++ {
+		err = s.dispatchLedger(seq, latest.HistoryElder)
+		if err != nil {
+			return errors.Wrapf(err, "dispatch ledger %d failed", seq)
+		}
+	}
+
+	return nil
+}
+
+// dispatchLedger queues the deliveries of the events of ledger `seq`, in a
+// single transaction, to the webhooks dispatched the ledger before it, or to
+// every webhook behind when `seq` is `elder`, the oldest ledger in history.
+func (s *System) dispatchLedger(seq int32, elder int32) error {
+	q := &history.Q{Session: s.HorizonDB.Clone()}
+	err := q.Begin()
+	if err != nil {
+		return err
+	}
+	defer q.Rollback()
+
+	var webhooks []history.Webhook
+	err = q.WebhooksBehind(&webhooks, seq, elder)
+	if err != nil {
+		return errors.Wrap(err, "load webhooks failed")
+	}
+
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	events, err := loadEvents(q, seq)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int64, len(webhooks))
+	for i, webhook := range webhooks {
+		ids[i] = webhook.ID
+
+		f := newFilter(webhook)
+		for _, e := range events {
+			if !f.matches(e) {
+				continue
+			}
+
+			payload, err := json.Marshal(horizon.WebhookEvent{
+				ID:        e.ID,
+				WebhookID: strconv.FormatInt(webhook.ID, 10),
+				Type:      e.Type,
+				Record:    e.Record,
+			})
+			if err != nil {
+				return errors.Wrap(err, "encode event failed")
+			}
+
+			err = q.InsertWebhookDelivery(webhook.ID, e.ID, e.Type, string(payload))
+			if err != nil {
+				return errors.Wrap(err, "insert delivery failed")
+			}
+		}
+	}
+
+	err = q.SetWebhooksLedger(ids, seq)
+	if err != nil {
+		return errors.Wrap(err, "update webhooks failed")
+	}
+
+	log.WithField("ledger", seq).
+		WithField("webhooks", len(webhooks)).
+		Debug("webhooks: dispatched ledger")
+
+	return q.Commit()
+}
+
+// loadEvents loads the payments and effects of ledger `seq`.
+func loadEvents(q *history.Q, seq int32) ([]event, error) {
+	var (
+		ledgerRow  history.Ledger
+		operations []history.Operation
+		effects    []history.Effect
+	)
+
+	err := q.LedgerBySequence(&ledgerRow, seq)
+	if err != nil {
+		return nil, errors.Wrap(err, "load ledger failed")
+	}
+
+	err = q.Operations().ForLedger(seq).OnlyPayments().Select(&operations)
+	if err != nil {
+		return nil, errors.Wrap(err, "load payments failed")
+	}
+
+	err = q.Effects().ForLedger(seq).Select(&effects)
+	if err != nil {
+		return nil, errors.Wrap(err, "load effects failed")
+	}
+
+	// There is no request to resolve links against when dispatching events:
+	// the links of the records are relative to the root of horizon.
+	ctx := context.Background()
+	events := make([]event, 0, len(operations)+len(effects))
+
+	for _, row := range operations {
+		resource, err := resourceadapter.NewOperation(ctx, row, ledgerRow)
+		if err != nil {
+			return nil, errors.Wrap(err, "populate payment failed")
+		}
+
+		e, err := newPaymentEvent(row, resource)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	for _, row := range effects {
+		resource, err := resourceadapter.NewEffect(ctx, row, ledgerRow)
+		if err != nil {
+			return nil, errors.Wrap(err, "populate effect failed")
+		}
+
+		e, err := newEffectEvent(row, resource)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
+
+func newPaymentEvent(row history.Operation, resource interface{}) (event, error) {
+	var details map[string]interface{}
+	err := row.UnmarshalDetails(&details)
+	if err != nil {
+		return event{}, err
+	}
+
+	e := event{
+		ID:       row.PagingToken(),
+		Type:     EventTypePayment,
+		Accounts: []string{row.SourceAccount},
+	}
+
+	for _, field := range []string{"from", "to", "funder", "account", "into"} {
+		if account, ok := details[field].(string); ok {
+			e.Accounts = append(e.Accounts, account)
+		}
+	}
+
+	switch row.Type {
+	case xdr.OperationTypeCreateAccount, xdr.OperationTypeAccountMerge:
+		e.Assets = []asset{{Type: "native"}}
+	default:
+		e.Assets = detailsAssets(details)
+	}
+
+	e.Record, err = json.Marshal(resource)
+	return e, errors.Wrap(err, "encode payment failed")
+}
+
+func newEffectEvent(row history.Effect, resource interface{}) (event, error) {
+	var details map[string]interface{}
+	err := row.UnmarshalDetails(&details)
+	if err != nil {
+		return event{}, err
+	}
+
+	e := event{
+		ID:       row.PagingToken(),
+		Type:     resourceadapter.EffectTypeNames[row.Type],
+		Accounts: []string{row.Account},
+		Assets:   detailsAssets(details),
+	}
+
+	e.Record, err = json.Marshal(resource)
+	return e, errors.Wrap(err, "encode effect failed")
+}
+
+// detailsAssets returns the assets found in the details of an operation or an
+// effect, e.g. in the `asset_type`, `asset_code` and `asset_issuer` fields.
+func detailsAssets(details map[string]interface{}) []asset {
+	var assets []asset
+	for _, prefix := range []string{"", "source_", "sold_", "bought_"} {
+		typ, ok := details[prefix+"asset_type"].(string)
+		if !ok {
+			continue
+		}
+
+		code, _ := details[prefix+"asset_code"].(string)
+		issuer, _ := details[prefix+"asset_issuer"].(string)
+		assets = append(assets, asset{Type: typ, Code: code, Issuer: issuer})
+	}
+
+	return assets
+}
+
+func newFilter(webhook history.Webhook) filter {
+	f := filter{
+		accounts: map[string]bool{},
+		types:    map[string]bool{},
+	}
+
+	for _, account := range webhook.AccountList() {
+		f.accounts[account] = true
+	}
+	for _, typ := range webhook.EventTypeList() {
+		f.types[typ] = true
+	}
+
+	if webhook.AssetType.Valid {
+		f.asset = &asset{
+			Type:   webhook.AssetType.String,
+			Code:   webhook.AssetCode.String,
+			Issuer: webhook.AssetIssuer.String,
+		}
+	}
+
+	return f
+}
+
+// matches returns true if the webhook of `f` is notified of `e`: if `e`
+// involves one of its accounts, is of one of its event types, if any, and
+// involves its asset, if any.
+func (f filter) matches(e event) bool {
+	if len(f.types) > 0 && !f.types[e.Type] {
+		return false
+	}
+
+	if f.asset != nil {
+		found := false
+		for _, a := range e.Assets {
+			if a == *f.asset {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for _, account := range e.Accounts {
+		if f.accounts[account] {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,95 @@
+package webhooks
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/schema"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/ledger"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/test"
+)
+
+func TestDispatch(t *testing.T) {
+	tt := test.Start(t).Scenario("base")
+	defer tt.Finish()
+
+	// the webhook tables are newer than the scenario
+	_, err := schema.Migrate(tt.HorizonDB.DB, schema.MigrateUp, 0)
+	tt.Require.NoError(err)
+
+	q := &history.Q{Session: tt.HorizonSession()}
+	s := New(0, tt.HorizonSession())
+	latest := ledger.CurrentState()
+	tt.Require.True(latest.HistoryLatest > latest.HistoryElder+1)
+
+	// webhooks of every account are notified of every event
+	var accounts []string
+	tt.Require.NoError(q.SelectRaw(&accounts, "SELECT address FROM history_accounts"))
+
+	events := map[int32]int{}
+	total := 0
+	for seq := latest.HistoryElder; seq <= latest.HistoryLatest; seq++ {
+		e, err := loadEvents(q, seq)
+		tt.Require.NoError(err)
+		events[seq] = len(e)
+		total += len(e)
+	}
+	tt.Require.True(total > 0)
+
+	insert := func(lastLedger int32) history.Webhook {
+		webhook := history.Webhook{
+			Owner:      "partner",
+			URL:        "https://example.com/hook",
+			Secret:     "secret",
+			Accounts:   strings.Join(accounts, ","),
+			LastLedger: lastLedger,
+		}
+		tt.Require.NoError(q.InsertWebhook(&webhook))
+		return webhook
+	}
+	deliveries := func(webhook history.Webhook) int {
+		var count int
+		tt.Require.NoError(q.GetRaw(&count, "SELECT COUNT(*) FROM webhook_deliveries WHERE webhook_id = ?", webhook.ID))
+		return count
+	}
+	lastLedger := func(webhook history.Webhook) int32 {
+		var seq int32
+		tt.Require.NoError(q.GetRaw(&seq, "SELECT last_ledger FROM webhooks WHERE id = ?", webhook.ID))
+		return seq
+	}
+
+	behind := insert(0)
+	ahead := insert(latest.HistoryElder)
+
+	// ledgers are only dispatched to the webhooks dispatched the ledger before
+	tt.Require.NoError(s.dispatchLedger(latest.HistoryElder+2, latest.HistoryElder))
+	tt.Assert.Equal(int32(0), lastLedger(behind))
+	tt.Assert.Equal(latest.HistoryElder, lastLedger(ahead))
+	tt.Assert.Equal(0, deliveries(ahead))
+
+	// webhooks behind the oldest ledger in history resume from it
+	tt.Require.NoError(s.Dispatch())
+	tt.Assert.Equal(latest.HistoryLatest, lastLedger(behind))
+	tt.Assert.Equal(latest.HistoryLatest, lastLedger(ahead))
+	tt.Assert.Equal(total, deliveries(behind))
+	tt.Assert.Equal(total-events[latest.HistoryElder], deliveries(ahead))
+
+	// dead letters replayed while their event is queued again are not lost
+	var delivery history.WebhookDelivery
+	tt.Require.NoError(q.GetRaw(&delivery, "SELECT *, '' AS url, '' AS secret FROM webhook_deliveries WHERE webhook_id = ? LIMIT 1", behind.ID))
+	tt.Require.NoError(q.DeadLetterWebhookDelivery(delivery.ID, "failed"))
+	tt.Require.NoError(q.InsertWebhookDelivery(behind.ID, delivery.EventID, delivery.EventType, delivery.Payload))
+	var queued int64
+	tt.Require.NoError(q.GetRaw(&queued, "SELECT id FROM webhook_deliveries WHERE webhook_id = ? AND event_id = ?", behind.ID, delivery.EventID))
+	tt.Require.NoError(q.RetryWebhookDelivery(queued, "failed", delivery.NextAttemptAt))
+
+	replayed, err := q.ReplayWebhookDeadLetters(behind.ID, 0)
+	tt.Require.NoError(err)
+	tt.Assert.Equal(int64(1), replayed)
+	tt.Assert.Equal(total, deliveries(behind))
+
+	var attempts int32
+	tt.Require.NoError(q.GetRaw(&attempts, "SELECT attempts FROM webhook_deliveries WHERE webhook_id = ? AND event_id = ?", behind.ID, delivery.EventID))
+	tt.Assert.Equal(int32(0), attempts)
+}
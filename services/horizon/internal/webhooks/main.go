@@ -0,0 +1,136 @@
+// Package webhooks contains the webhook notification subsystem for horizon.
+// Clients register webhooks, scoped to their API key, listing the accounts
+// whose payments and effects they want to be notified of.  Once a ledger is
+// ingested, the system dispatches its matching events to the webhooks, queuing
+// a delivery for each of them in the history database, then POSTs the
+// deliveries, signed with the secret of their webhook.
+//
+// Failed deliveries are retried with an exponential backoff until they reach
+// the maximum number of attempts, when they are moved to the dead letters of
+// their webhook, from which they can be replayed.
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/lomocoin/stellar-go/services/horizon/internal/resourceadapter"
+	"github.com/lomocoin/stellar-go/support/db"
+)
+
+const (
+	// EventTypePayment is the type of the events of payment operations: account
+	// creations, payments, path payments and account merges.  Effects are of
+	// the type of the effect, e.g. `account_credited`.
+	EventTypePayment = "payment"
+
+	// SignatureHeader is the header carrying the signature of a delivery.
+	SignatureHeader = "X-Horizon-Signature"
+
+	// TimestampHeader is the header carrying the time, in seconds since the
+	// unix epoch, a delivery was signed at.
+	TimestampHeader = "X-Horizon-Timestamp"
+
+	// DefaultMaxAttempts is the default number of attempts made to deliver an
+	// event before giving up on it.
+	DefaultMaxAttempts = 10
+)
+
+const (
+	// maxLedgersPerTick is the maximum number of ledgers dispatched per tick.
+	maxLedgersPerTick = 100
+	// maxDeliveriesPerTick is the maximum number of deliveries attempted per
+	// tick.
+	maxDeliveriesPerTick = 100
+	// deliveryConcurrency is the number of deliveries attempted at once.
+	deliveryConcurrency = 10
+	// deliveryTimeout is the time a webhook has to respond to a delivery.
+	deliveryTimeout = 10 * time.Second
+	// deliveryLease is the time claimed deliveries are hidden from other
+	// horizon instances, which must exceed the time to attempt a batch.
+	deliveryLease = 5 * time.Minute
+	// minBackoff and maxBackoff bound the time between two attempts to
+	// deliver an event.
+	minBackoff = 10 * time.Second
+	maxBackoff = 1 * time.Hour
+)
+
+// System represents the webhook notification subsystem of horizon.
+type System struct {
+	HorizonDB   *db.Session
+	Client      *http.Client
+	MaxAttempts int32
+
+	running int32
+}
+
+// New initializes the webhook system, giving up on deliveries after
+// `maxAttempts` attempts.
+func New(maxAttempts int32, horizon *db.Session) *System {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	return &System{
+		HorizonDB:   horizon,
+		Client:      newClient(),
+		MaxAttempts: maxAttempts,
+	}
+}
+
+// IsEventType returns true if `typ` is the type of an event webhooks can be
+// notified of.
+func IsEventType(typ string) bool {
+	if typ == EventTypePayment {
+		return true
+	}
+
+	for _, name := range resourceadapter.EffectTypeNames {
+		if name == typ {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewSecret returns a new random secret to sign the deliveries of a webhook
+// with.
+func NewSecret() (string, error) {
+	raw := make([]byte, 32)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(raw), nil
+}
+
+// Sign returns the signature of a delivery of `body` made at `timestamp`: the
+// hex-encoded HMAC-SHA256, keyed with `secret`, of the timestamp, a dot and the
+// body.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff returns the time to wait before attempting again a delivery that
+// failed `attempts` times.
+func Backoff(attempts int32) time.Duration {
+	backoff := minBackoff
+	for i := int32(1); i < attempts; i++ {
+		backoff *= 2
+		if backoff >= maxBackoff {
+			return maxBackoff
+		}
+	}
+
+	return backoff
+}
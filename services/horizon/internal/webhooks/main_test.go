@@ -0,0 +1,138 @@
+package webhooks
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/history"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	// echo -n '1530000000.{"id":"1"}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t,
+		"444061fb650a90b6332462442d8b82a89acf679010753d684c5f84c29f66a802",
+		Sign("secret", "1530000000", []byte(`{"id":"1"}`)),
+	)
+	assert.NotEqual(t,
+		Sign("secret", "1530000000", []byte(`{"id":"1"}`)),
+		Sign("secret", "1530000001", []byte(`{"id":"1"}`)),
+	)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 10*time.Second, Backoff(1))
+	assert.Equal(t, 20*time.Second, Backoff(2))
+	assert.Equal(t, 80*time.Second, Backoff(4))
+	assert.Equal(t, time.Hour, Backoff(20))
+	assert.Equal(t, time.Hour, Backoff(1000))
+}
+
+func TestIsEventType(t *testing.T) {
+	assert.True(t, IsEventType("payment"))
+	assert.True(t, IsEventType("account_credited"))
+	assert.True(t, IsEventType("trade"))
+	assert.False(t, IsEventType("bogus"))
+	assert.False(t, IsEventType(""))
+}
+
+func TestFilter(t *testing.T) {
+	usd := asset{Type: "credit_alphanum4", Code: "USD", Issuer: "GISSUER"}
+	payment := event{Type: "payment", Accounts: []string{"GA", "GB"}, Assets: []asset{usd}}
+	credit := event{Type: "account_credited", Accounts: []string{"GC"}, Assets: []asset{{Type: "native"}}}
+	signer := event{Type: "signer_created", Accounts: []string{"GB"}}
+
+	f := newFilter(history.Webhook{Accounts: "GB,GC"})
+	assert.True(t, f.matches(payment))
+	assert.True(t, f.matches(credit))
+	assert.True(t, f.matches(signer))
+	assert.False(t, newFilter(history.Webhook{Accounts: "GD"}).matches(payment))
+
+	f = newFilter(history.Webhook{Accounts: "GB,GC", EventTypes: "payment"})
+	assert.True(t, f.matches(payment))
+	assert.False(t, f.matches(credit))
+
+	f = newFilter(history.Webhook{
+		Accounts:    "GA,GB,GC",
+		AssetType:   null.StringFrom("credit_alphanum4"),
+		AssetCode:   null.StringFrom("USD"),
+		AssetIssuer: null.StringFrom("GISSUER"),
+	})
+	assert.True(t, f.matches(payment))
+	assert.False(t, f.matches(credit))
+	assert.False(t, f.matches(signer))
+
+	f = newFilter(history.Webhook{Accounts: "GC", AssetType: null.StringFrom("native")})
+	assert.True(t, f.matches(credit))
+}
+
+func TestPost(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	s := New(0, nil)
+	assert.Equal(t, int32(DefaultMaxAttempts), s.MaxAttempts)
+
+	// the test server listens on loopback, which deliveries cannot reach
+	assert.Equal(t, errConnectionFailed, s.post(history.WebhookDelivery{URL: server.URL}))
+	s.Client = server.Client()
+
+	delivery := history.WebhookDelivery{
+		URL:     server.URL,
+		Secret:  "secret",
+		Payload: `{"id":"1"}`,
+	}
+
+	if assert.NoError(t, s.post(delivery)) {
+		assert.Equal(t, "POST", received.Method)
+		assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+		assert.Equal(t, delivery.Payload, string(body))
+
+		timestamp := received.Header.Get(TimestampHeader)
+		assert.NotEmpty(t, timestamp)
+		assert.Equal(t, Sign("secret", timestamp, body), received.Header.Get(SignatureHeader))
+	}
+
+	status = http.StatusInternalServerError
+	assert.EqualError(t, s.post(delivery), "HTTP 500")
+}
+
+func TestClient(t *testing.T) {
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data", http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	// redirects are not followed
+	client := newClient()
+	client.Transport = redirect.Client().Transport
+	s := &System{Client: client}
+	assert.EqualError(t, s.post(history.WebhookDelivery{URL: redirect.URL}), "HTTP 302")
+
+	forbidden := []string{
+		"127.0.0.1", "::1", "0.0.0.0", "::", "10.1.2.3", "172.16.0.1",
+		"192.168.1.1", "100.64.0.1", "169.254.169.254", "fe80::1", "fd00::1",
+		"224.0.0.1", "ff02::1", "::ffff:10.0.0.1",
+	}
+	for _, ip := range forbidden {
+		assert.True(t, isForbiddenIP(net.ParseIP(ip)), ip)
+	}
+	assert.False(t, isForbiddenIP(net.ParseIP("8.8.8.8")))
+	assert.False(t, isForbiddenIP(net.ParseIP("2001:4860:4860::8888")))
+
+	_, err := dialPublic(context.Background(), &net.Dialer{}, "tcp", "localhost:11626")
+	assert.Equal(t, errForbiddenAddress, err)
+}
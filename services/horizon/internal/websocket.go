@@ -0,0 +1,432 @@
+package horizon
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lomocoin/stellar-go/services/horizon/internal/apikey"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/render"
+	hProblem "github.com/lomocoin/stellar-go/services/horizon/internal/render/problem"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/render/sse"
+	"github.com/lomocoin/stellar-go/support/errors"
+	"github.com/lomocoin/stellar-go/support/log"
+	"github.com/lomocoin/stellar-go/support/render/problem"
+	"golang.org/x/net/websocket"
+)
+
+// maxWebsocketSubscriptions is the maximum number of subscriptions a single
+// websocket connection can have open at once.
+const maxWebsocketSubscriptions = 100
+
+// websocketPath is the path websocket connections are opened at.
+const websocketPath = "/ws"
+
+// websocketMessage is a message exchanged over a websocket connection.
+//
+// Clients send `subscribe` messages, with the `id` of the subscription and its
+// `topic`, the path of a streaming endpoint (e.g.
+// `/accounts/G.../payments?cursor=now`), and `unsubscribe` messages.  Horizon
+// replies with `subscribed` and `unsubscribed` messages, sends the records of
+// the subscriptions in `event` messages and ends subscriptions that fail with
+// an `error` message.  A `shutdown` message is sent before closing the
+// connection when horizon shuts down.
+type websocketMessage struct {
+	Type        string      `json:"type"`
+	ID          string      `json:"id,omitempty"`
+	Topic       string      `json:"topic,omitempty"`
+	PagingToken string      `json:"paging_token,omitempty"`
+	Data        interface{} `json:"data,omitempty"`
+	Error       interface{} `json:"error,omitempty"`
+}
+
+// ServeWebsocket serves websocket connections multiplexing subscriptions to
+// the streaming endpoints.  Each subscription is served by the router as a
+// streaming request of its own, sharing the headers and the API key of the
+// websocket request, and is resumed after its last event, like EventSource
+// clients do, whenever that request ends.
+func (web *Web) ServeWebsocket(w http.ResponseWriter, r *http.Request) {
+	server := websocket.Server{
+		// Like the CORS configuration, accept connections from any origin.
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			web.websocketsGauge.Update(atomic.AddInt64(&web.openWebsockets, 1))
+			defer func() {
+				web.websocketsGauge.Update(atomic.AddInt64(&web.openWebsockets, -1))
+			}()
+
+			conn := &websocketConn{
+				ws:            ws,
+				handler:       web.router,
+				request:       r,
+				subscriptions: map[string]context.CancelFunc{},
+			}
+			if app := AppFromContext(r.Context()); app != nil {
+				conn.shutdown = app.draining.Done()
+			}
+			conn.serve()
+		},
+	}
+
+	server.ServeHTTP(w, r)
+}
+
+// isWebsocketUpgrade returns true if `r` opens a websocket connection.  Only
+// requests to websocketPath do: other requests asking for an upgrade are
+// served like any other.
+func isWebsocketUpgrade(r *http.Request) bool {
+	return r.Method == http.MethodGet &&
+		strings.TrimSuffix(r.URL.Path, "/") == websocketPath &&
+		strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
+// websocketConn is a websocket connection and its subscriptions.
+type websocketConn struct {
+	ws      *websocket.Conn
+	handler http.Handler
+	request *http.Request
+
+	// shutdown is closed once horizon starts shutting down.
+	shutdown <-chan struct{}
+
+	sendMu sync.Mutex // protects writes to ws
+
+	mu            sync.Mutex // protects subscriptions
+	subscriptions map[string]context.CancelFunc
+	wg            sync.WaitGroup
+}
+
+// serve reads the messages of the client until the connection is closed,
+// then ends its subscriptions.
+func (c *websocketConn) serve() {
+	// Subscriptions are routed anew, so they must not inherit the routing
+	// context of the websocket request.
+	subscriptionsCtx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		c.wg.Wait()
+	}()
+
+	go func() {
+		select {
+		case <-c.shutdown:
+			// Tell the client to reconnect to another horizon, then close the
+			// connection, ending its subscriptions.
+			c.send(websocketMessage{Type: "shutdown"})
+			c.ws.Close()
+		case <-subscriptionsCtx.Done():
+		}
+	}()
+
+	for {
+		var msg websocketMessage
+		err := websocket.JSON.Receive(c.ws, &msg)
+		switch err.(type) {
+		case nil:
+		case *json.SyntaxError, *json.UnmarshalTypeError:
+			c.sendProblem("", problem.BadRequest)
+			continue
+		default:
+			return
+		}
+
+		switch msg.Type {
+		case "subscribe":
+			c.subscribe(subscriptionsCtx, msg.ID, msg.Topic)
+		case "unsubscribe":
+			c.unsubscribe(msg.ID)
+		default:
+			c.sendProblem(msg.ID, *problem.MakeInvalidFieldProblem("type", errors.New("must be subscribe or unsubscribe")))
+		}
+	}
+}
+
+func (c *websocketConn) subscribe(ctx context.Context, id, topic string) {
+	if id == "" {
+		c.sendProblem(id, *problem.MakeInvalidFieldProblem("id", errors.New("missing subscription id")))
+		return
+	}
+
+	u, err := url.Parse(topic)
+	if err != nil || !strings.HasPrefix(u.Path, "/") || u.Host != "" {
+		c.sendProblem(id, *problem.MakeInvalidFieldProblem("topic", errors.New("must be the path of a streaming endpoint")))
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.subscriptions[id]; ok {
+		c.sendProblem(id, *problem.MakeInvalidFieldProblem("id", errors.New("subscription already exists")))
+		return
+	}
+	if len(c.subscriptions) >= maxWebsocketSubscriptions {
+		c.sendProblem(id, *problem.MakeInvalidFieldProblem("id", errors.Errorf("too many subscriptions, the maximum is %d", maxWebsocketSubscriptions)))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	c.subscriptions[id] = cancel
+	c.send(websocketMessage{Type: "subscribed", ID: id})
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer c.remove(id)
+		c.stream(ctx, id, u)
+	}()
+}
+
+func (c *websocketConn) unsubscribe(id string) {
+	c.mu.Lock()
+	cancel, ok := c.subscriptions[id]
+	c.mu.Unlock()
+
+	if !ok {
+		c.sendProblem(id, *problem.MakeInvalidFieldProblem("id", errors.New("unknown subscription")))
+		return
+	}
+
+	cancel()
+}
+
+// remove forgets the subscription `id`, once ended.
+func (c *websocketConn) remove(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.subscriptions[id]()
+	delete(c.subscriptions, id)
+}
+
+// stream serves the subscription `id` to `topic` until it is unsubscribed,
+// fails or, if `topic` has a limit, has sent that many events.  Streaming
+// requests ended by the connection timeout are resumed like any other.
+func (c *websocketConn) stream(ctx context.Context, id string, topic *url.URL) {
+	limit, _ := strconv.Atoi(topic.Query().Get("limit"))
+	lastID := ""
+	sent := 0
+
+	for {
+		stream := &subscriptionStream{conn: c, id: id}
+		w := &subscriptionResponseWriter{header: http.Header{}, status: http.StatusOK}
+		if ctx.Err() == nil {
+			c.handler.ServeHTTP(w, c.subscriptionRequest(ctx, stream, topic, lastID, limit-sent))
+		}
+
+		if ctx.Err() != nil {
+			c.send(websocketMessage{Type: "unsubscribed", ID: id})
+			return
+		}
+
+		if w.status != http.StatusOK && !w.timedOut() {
+			c.sendResponseProblem(id, w)
+			return
+		}
+
+		if stream.err != nil {
+			p := problem.ServerError
+			p.Detail = stream.err.Error()
+			c.sendProblem(id, p)
+			return
+		}
+
+		if stream.lastID != "" {
+			lastID = stream.lastID
+		}
+		sent += stream.SentCount()
+
+		if limit > 0 && sent >= limit {
+			c.send(websocketMessage{Type: "unsubscribed", ID: id})
+			return
+		}
+
+		// Don't spin on requests ending without events.
+		if stream.SentCount() == 0 {
+			select {
+			case <-time.After(time.Second):
+			case <-ctx.Done():
+			}
+		}
+	}
+}
+
+// subscriptionRequest returns the streaming request serving a subscription to
+// `topic`, resumed after the event `lastID`, if any.
+func (c *websocketConn) subscriptionRequest(
+	ctx context.Context,
+	stream sse.Stream,
+	topic *url.URL,
+	lastID string,
+	remaining int,
+) *http.Request {
+	u := *topic
+	if remaining > 0 && u.Query().Get("limit") != "" {
+		q := u.Query()
+		q.Set("limit", strconv.Itoa(remaining))
+		u.RawQuery = q.Encode()
+	}
+
+	r := &http.Request{
+		Method:     http.MethodGet,
+		URL:        &u,
+		Proto:      c.request.Proto,
+		ProtoMajor: c.request.ProtoMajor,
+		ProtoMinor: c.request.ProtoMinor,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+		Host:       c.request.Host,
+		RemoteAddr: c.request.RemoteAddr,
+		RequestURI: u.RequestURI(),
+		TLS:        c.request.TLS,
+	}
+
+	for name, values := range c.request.Header {
+		if name == "Connection" || name == "Upgrade" || strings.HasPrefix(name, "Sec-Websocket-") {
+			continue
+		}
+		r.Header[name] = values
+	}
+	r.Header.Set("Accept", render.MimeEventStream)
+	// browsers cannot set the headers of websocket requests, and send their
+	// API key in the query string instead.
+	if key := apikey.FromRequest(c.request); key != "" {
+		r.Header.Set(apikey.Header, key)
+	}
+	if lastID != "" {
+		r.Header.Set("Last-Event-ID", lastID)
+	}
+
+	return r.WithContext(sse.WithStream(ctx, stream))
+}
+
+func (c *websocketConn) send(msg websocketMessage) {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	err := websocket.JSON.Send(c.ws, msg)
+	if err != nil {
+		log.Ctx(c.request.Context()).WithField("err", err.Error()).Debug("failed to send websocket message")
+	}
+}
+
+// sendProblem ends the subscription `id` with the problem `p`.
+func (c *websocketConn) sendProblem(id string, p problem.P) {
+	problem.Inflate(&p)
+	c.send(websocketMessage{Type: "error", ID: id, Error: p})
+}
+
+// sendResponseProblem ends the subscription `id` with the problem the
+// streaming request serving it failed with.  Responses that are not problems
+// come from endpoints that cannot be streamed.
+func (c *websocketConn) sendResponseProblem(id string, w *subscriptionResponseWriter) {
+	var p json.RawMessage
+	if json.Unmarshal(w.body, &p) != nil {
+		c.sendProblem(id, hProblem.NotAcceptable)
+		return
+	}
+
+	c.send(websocketMessage{Type: "error", ID: id, Error: p})
+}
+
+// subscriptionStream is the sse.Stream of a subscription, sending the events
+// of the streaming request serving it over the websocket connection.
+type subscriptionStream struct {
+	conn *websocketConn
+	id   string
+
+	mu     sync.Mutex // protects the following fields
+	done   bool
+	sent   int
+	limit  int
+	lastID string
+	err    error
+}
+
+func (s *subscriptionStream) Init() {}
+
+func (s *subscriptionStream) Send(e sse.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.conn.send(websocketMessage{Type: "event", ID: s.id, PagingToken: e.ID, Data: e.Data})
+	s.sent++
+	if e.ID != "" {
+		s.lastID = e.ID
+	}
+}
+
+func (s *subscriptionStream) SentCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sent
+}
+
+func (s *subscriptionStream) Done() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = true
+}
+
+// Shutdown ends the stream.  The connection tells the client that horizon is
+// shutting down once for all its subscriptions.
+func (s *subscriptionStream) Shutdown() {
+	s.Done()
+}
+
+func (s *subscriptionStream) SetLimit(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limit = limit
+}
+
+func (s *subscriptionStream) IsDone() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done || (s.limit > 0 && s.sent >= s.limit)
+}
+
+// Err ends the stream.  The error is sent once the streaming request is over.
+func (s *subscriptionStream) Err(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+	s.done = true
+}
+
+// subscriptionResponseWriter records the response to a streaming request
+// serving a subscription: nothing but a problem, if the request fails before
+// sending any event.
+type subscriptionResponseWriter struct {
+	header http.Header
+	status int
+	body   []byte
+}
+
+func (w *subscriptionResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *subscriptionResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *subscriptionResponseWriter) Write(b []byte) (int, error) {
+	w.body = append(w.body, b...)
+	return len(b), nil
+}
+
+func (w *subscriptionResponseWriter) Flush() {}
+
+// timedOut returns true if the streaming request was ended by the connection
+// timeout of the router, which answers with an empty 504 response once the
+// deadline of the request has expired.
+func (w *subscriptionResponseWriter) timedOut() bool {
+	return w.status == http.StatusGatewayTimeout && len(w.body) == 0
+}
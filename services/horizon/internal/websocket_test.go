@@ -0,0 +1,183 @@
+package horizon
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lomocoin/stellar-go/protocols/horizon"
+	"github.com/lomocoin/stellar-go/support/render/problem"
+	"github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+func TestWebsocket(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	server := httptest.NewServer(ht.App.web.router)
+	defer server.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", "", server.URL)
+	ht.Require.NoError(err)
+	defer ws.Close()
+
+	send := func(msg websocketMessage) {
+		ht.Require.NoError(websocket.JSON.Send(ws, msg))
+	}
+	receive := func() websocketMessage {
+		var msg websocketMessage
+		ws.SetReadDeadline(time.Now().Add(10 * time.Second))
+		ht.Require.NoError(websocket.JSON.Receive(ws, &msg))
+		return msg
+	}
+
+	// subscriptions with a limit end once it is reached
+	send(websocketMessage{Type: "subscribe", ID: "ledgers", Topic: "/ledgers?cursor=0&limit=2"})
+	ht.Assert.Equal(websocketMessage{Type: "subscribed", ID: "ledgers"}, receive())
+	for i := 1; i <= 2; i++ {
+		msg := receive()
+		ht.Assert.Equal("event", msg.Type)
+		ht.Assert.Equal("ledgers", msg.ID)
+
+		var ledger horizon.Ledger
+		data, _ := json.Marshal(msg.Data)
+		ht.Require.NoError(json.Unmarshal(data, &ledger))
+		ht.Assert.Equal(int32(i), ledger.Sequence)
+		ht.Assert.Equal(ledger.PT, msg.PagingToken)
+	}
+	ht.Assert.Equal(websocketMessage{Type: "unsubscribed", ID: "ledgers"}, receive())
+
+	// subscriptions last until unsubscribed
+	send(websocketMessage{Type: "subscribe", ID: "now", Topic: "/ledgers?cursor=now"})
+	ht.Assert.Equal(websocketMessage{Type: "subscribed", ID: "now"}, receive())
+	send(websocketMessage{Type: "unsubscribe", ID: "now"})
+	ht.Assert.Equal(websocketMessage{Type: "unsubscribed", ID: "now"}, receive())
+
+	// endpoints failing or that cannot be streamed end subscriptions
+	for topic, status := range map[string]int{"/ledgers/1": 406, "/not_found": 404} {
+		send(websocketMessage{Type: "subscribe", ID: topic, Topic: topic})
+		ht.Assert.Equal(websocketMessage{Type: "subscribed", ID: topic}, receive())
+		msg := receive()
+		ht.Assert.Equal("error", msg.Type)
+		ht.Assert.Equal(topic, msg.ID)
+
+		var p problem.P
+		data, _ := json.Marshal(msg.Error)
+		ht.Require.NoError(json.Unmarshal(data, &p))
+		ht.Assert.Equal(status, p.Status, topic)
+	}
+
+	// invalid messages
+	send(websocketMessage{Type: "unsubscribe", ID: "unknown"})
+	msg := receive()
+	ht.Assert.Equal("error", msg.Type)
+	ht.Assert.Equal("unknown", msg.ID)
+
+	send(websocketMessage{Type: "subscribe", ID: "absolute", Topic: "http://example.com/ledgers"})
+	msg = receive()
+	ht.Assert.Equal("error", msg.Type)
+	ht.Assert.Equal("absolute", msg.ID)
+
+	send(websocketMessage{Type: "bogus"})
+	ht.Assert.Equal("error", receive().Type)
+}
+
+// subscriptions outlive the connection timeout of their streaming requests
+func TestWebsocket_ConnectionTimeout(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	c := NewTestConfig()
+	c.ConnectionTimeout = time.Second
+	app, err := NewApp(c)
+	ht.Require.NoError(err)
+	defer app.Close()
+
+	server := httptest.NewServer(app.web.router)
+	defer server.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", "", server.URL)
+	ht.Require.NoError(err)
+	defer ws.Close()
+
+	receive := func() websocketMessage {
+		var msg websocketMessage
+		ws.SetReadDeadline(time.Now().Add(10 * time.Second))
+		ht.Require.NoError(websocket.JSON.Receive(ws, &msg))
+		return msg
+	}
+
+	var ledgers int
+	ht.Require.NoError(ht.HorizonDB.Get(&ledgers, "SELECT COUNT(*) FROM history_ledgers"))
+
+	ht.Require.NoError(websocket.JSON.Send(ws, websocketMessage{Type: "subscribe", ID: "ledgers", Topic: "/ledgers?cursor=0"}))
+	ht.Assert.Equal(websocketMessage{Type: "subscribed", ID: "ledgers"}, receive())
+	for i := 0; i < ledgers; i++ {
+		ht.Assert.Equal("event", receive().Type)
+	}
+
+	// the streaming request is resumed after its last event, rather than
+	// failing, each time the connection timeout expires
+	time.Sleep(3 * c.ConnectionTimeout)
+	ht.Require.NoError(websocket.JSON.Send(ws, websocketMessage{Type: "unsubscribe", ID: "ledgers"}))
+	ht.Assert.Equal(websocketMessage{Type: "unsubscribed", ID: "ledgers"}, receive())
+}
+
+// subscriptions are made with the API key of the websocket request, which
+// browsers send in the query string
+func TestWebsocket_APIKey(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	file := writeAPIKeysFile(t)
+	defer os.Remove(file)
+
+	c := NewTestConfig()
+	c.APIKeysFile = file
+	app, err := NewApp(c)
+	ht.Require.NoError(err)
+	defer app.Close()
+
+	server := httptest.NewServer(app.web.router)
+	defer server.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws?api_key=partner-secret", "", server.URL)
+	ht.Require.NoError(err)
+	defer ws.Close()
+
+	receive := func() websocketMessage {
+		var msg websocketMessage
+		ws.SetReadDeadline(time.Now().Add(10 * time.Second))
+		ht.Require.NoError(websocket.JSON.Receive(ws, &msg))
+		return msg
+	}
+
+	requests := app.metrics.Get(`api_keys.requests{key="partner"}`).(metrics.Counter)
+	ht.Assert.Equal(int64(1), requests.Count())
+
+	ht.Require.NoError(websocket.JSON.Send(ws, websocketMessage{Type: "subscribe", ID: "ledgers", Topic: "/ledgers?cursor=0&limit=1"}))
+	ht.Assert.Equal(websocketMessage{Type: "subscribed", ID: "ledgers"}, receive())
+	ht.Assert.Equal("event", receive().Type)
+	ht.Assert.Equal(websocketMessage{Type: "unsubscribed", ID: "ledgers"}, receive())
+	ht.Assert.Equal(int64(2), requests.Count())
+}
+
+func TestIsWebsocketUpgrade(t *testing.T) {
+	upgrade := func(method, path string) *http.Request {
+		r := httptest.NewRequest(method, path, nil)
+		r.Header.Set("Upgrade", "websocket")
+		return r
+	}
+
+	assert.True(t, isWebsocketUpgrade(upgrade("GET", "/ws")))
+	assert.True(t, isWebsocketUpgrade(upgrade("GET", "/ws/")))
+	assert.False(t, isWebsocketUpgrade(upgrade("GET", "/trades")))
+	assert.False(t, isWebsocketUpgrade(upgrade("POST", "/ws")))
+	assert.False(t, isWebsocketUpgrade(httptest.NewRequest("GET", "/ws", nil)))
+}
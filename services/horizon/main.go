@@ -4,15 +4,19 @@ import (
 	stdLog "log"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/lomocoin/stellar-go/amount"
 	"github.com/lomocoin/stellar-go/network"
 	horizon "github.com/lomocoin/stellar-go/services/horizon/internal"
 	"github.com/lomocoin/stellar-go/services/horizon/internal/db2/schema"
+	"github.com/lomocoin/stellar-go/services/horizon/internal/ingest"
 	apkg "github.com/lomocoin/stellar-go/support/app"
+	"github.com/lomocoin/stellar-go/support/errors"
 	"github.com/lomocoin/stellar-go/support/log"
 	"github.com/throttled/throttled"
 )
@@ -30,16 +34,24 @@ func init() {
 	viper.SetDefault("port", 8000)
 	viper.SetDefault("history-retention-count", 0)
 
+	viper.BindEnv("config-file", "CONFIG_FILE")
 	viper.BindEnv("port", "PORT")
 	viper.BindEnv("db-url", "DATABASE_URL")
 	viper.BindEnv("stellar-core-db-url", "STELLAR_CORE_DATABASE_URL")
+	viper.BindEnv("db-replica-urls", "DATABASE_REPLICA_URLS")
+	viper.BindEnv("stellar-core-db-replica-urls", "STELLAR_CORE_DATABASE_REPLICA_URLS")
 	viper.BindEnv("stellar-core-url", "STELLAR_CORE_URL")
 	viper.BindEnv("max-db-connections", "MAX_DB_CONNECTIONS")
 	viper.BindEnv("sse-update-frequency", "SSE_UPDATE_FREQUENCY")
 	viper.BindEnv("connection-timeout", "CONNECTION_TIMEOUT")
+	viper.BindEnv("shutdown-timeout", "SHUTDOWN_TIMEOUT")
 	viper.BindEnv("per-hour-rate-limit", "PER_HOUR_RATE_LIMIT")
 	viper.BindEnv("rate-limit-redis-key", "RATE_LIMIT_REDIS_KEY")
 	viper.BindEnv("redis-url", "REDIS_URL")
+	viper.BindEnv("api-keys-file", "API_KEYS_FILE")
+	viper.BindEnv("response-cache-size", "RESPONSE_CACHE_SIZE")
+	viper.BindEnv("response-cache-redis", "RESPONSE_CACHE_REDIS")
+	viper.BindEnv("response-cache-max-age", "RESPONSE_CACHE_MAX_AGE")
 	viper.BindEnv("ruby-horizon-url", "RUBY_HORIZON_URL")
 	viper.BindEnv("friendbot-url", "FRIENDBOT_URL")
 	viper.BindEnv("log-level", "LOG_LEVEL")
@@ -52,10 +64,20 @@ func init() {
 	viper.BindEnv("ingest", "INGEST")
 	viper.BindEnv("network-passphrase", "NETWORK_PASSPHRASE")
 	viper.BindEnv("history-retention-count", "HISTORY_RETENTION_COUNT")
+	viper.BindEnv("reaper-archive-url", "REAPER_ARCHIVE_URL")
 	viper.BindEnv("history-stale-threshold", "HISTORY_STALE_THRESHOLD")
 	viper.BindEnv("skip-cursor-update", "SKIP_CURSOR_UPDATE")
 	viper.BindEnv("enable-asset-stats", "ENABLE_ASSET_STATS")
+	viper.BindEnv("asset-stats-holder-threshold", "ASSET_STATS_HOLDER_THRESHOLD")
+	viper.BindEnv("enable-webhooks", "ENABLE_WEBHOOKS")
+	viper.BindEnv("webhook-max-attempts", "WEBHOOK_MAX_ATTEMPTS")
+	viper.BindEnv("graphql-max-cost", "GRAPHQL_MAX_COST")
+	viper.BindEnv("ingest-account-filter", "INGEST_ACCOUNT_FILTER")
+	viper.BindEnv("ingest-asset-filter", "INGEST_ASSET_FILTER")
 	viper.BindEnv("max-path-length", "MAX_PATH_LENGTH")
+	viper.BindEnv("path-finder", "PATH_FINDER")
+	viper.BindEnv("path-finder-timeout", "PATH_FINDER_TIMEOUT")
+	viper.BindEnv("path-finder-max-results", "PATH_FINDER_MAX_RESULTS")
 
 	rootCmd = &cobra.Command{
 		Use:   "horizon",
@@ -63,10 +85,17 @@ func init() {
 		Long:  "client-facing api server for the stellar network",
 		Run: func(cmd *cobra.Command, args []string) {
 			initApp(cmd, args)
+			go reloadOnSIGHUP()
 			app.Serve()
 		},
 	}
 
+	rootCmd.PersistentFlags().String(
+		"config-file",
+		"",
+		"TOML file setting any of the other flags by name, overridden by the command line flags and environment variables, reloaded on SIGHUP",
+	)
+
 	rootCmd.PersistentFlags().String(
 		"db-url",
 		"",
@@ -79,6 +108,18 @@ func init() {
 		"stellar-core postgres database to connect with",
 	)
 
+	rootCmd.PersistentFlags().String(
+		"db-replica-urls",
+		"",
+		"comma separated list of read replicas of the horizon postgres database, queried by read-only requests while no more than history-stale-threshold ledgers behind",
+	)
+
+	rootCmd.PersistentFlags().String(
+		"stellar-core-db-replica-urls",
+		"",
+		"comma separated list of read replicas of the stellar-core postgres database, queried by read-only requests while no more than history-stale-threshold ledgers behind",
+	)
+
 	rootCmd.PersistentFlags().String(
 		"stellar-core-url",
 		"",
@@ -115,6 +156,12 @@ func init() {
 		"defines the timeout of connection after which 504 response will be sent or stream will be closed, if Horizon is behind a load balancer with idle connection timeout, this should be set to a few seconds less that idle timeout",
 	)
 
+	rootCmd.PersistentFlags().Int(
+		"shutdown-timeout",
+		10,
+		"defines the time (in seconds) given to in-flight requests and transaction submissions waiting for their result to finish when horizon shuts down, and then to the current ingestion session to commit",
+	)
+
 	rootCmd.PersistentFlags().String(
 		"rate-limit-redis-key",
 		"",
@@ -127,6 +174,30 @@ func init() {
 		"redis to connect with, for rate limiting",
 	)
 
+	rootCmd.PersistentFlags().String(
+		"api-keys-file",
+		"",
+		"TOML file listing the API keys clients can send in the X-Api-Key header or api_key parameter, each with its own rate limit and allowed routes",
+	)
+
+	rootCmd.PersistentFlags().Int(
+		"response-cache-size",
+		1000,
+		"number of immutable responses (single ledgers, transactions and operations, and pages of settled history) kept in memory, 0 disables the response cache",
+	)
+
+	rootCmd.PersistentFlags().Bool(
+		"response-cache-redis",
+		false,
+		"store the immutable responses in redis, shared by every horizon using it, rather than in memory, requires redis-url",
+	)
+
+	rootCmd.PersistentFlags().Int(
+		"response-cache-max-age",
+		86400,
+		"max-age of the Cache-Control header of the immutable responses (in seconds), also their time to live in redis",
+	)
+
 	rootCmd.PersistentFlags().String(
 		"friendbot-url",
 		"",
@@ -193,6 +264,12 @@ func init() {
 		"the minimum number of ledgers to maintain within horizon's history tables.  0 signifies an unlimited number of ledgers will be retained",
 	)
 
+	rootCmd.PersistentFlags().String(
+		"reaper-archive-url",
+		"",
+		"archive (file:///path or s3://bucket/prefix) that history is exported to before being reaped, restore it with `horizon db restore-range`",
+	)
+
 	rootCmd.PersistentFlags().Uint(
 		"history-stale-threshold",
 		0,
@@ -201,8 +278,44 @@ func init() {
 
 	rootCmd.PersistentFlags().Bool(
 		"enable-asset-stats",
+		true,
+		"enables asset stats during the ingestion and expose `/assets` endpoint, disabling it saves CPU when ingesting ledgers with many asset related operations",
+	)
+
+	rootCmd.PersistentFlags().String(
+		"asset-stats-holder-threshold",
+		"1",
+		"balance from which an account holding an asset counts towards its num_holders_above_threshold stat",
+	)
+
+	rootCmd.PersistentFlags().Bool(
+		"enable-webhooks",
 		false,
-		"enables asset stats during the ingestion and expose `/assets` endpoint,  Enabling it has a negative impact on CPU",
+		"enables the `/webhooks` endpoints and the delivery of payments and effects to the webhooks registered with an API key",
+	)
+
+	rootCmd.PersistentFlags().Int(
+		"webhook-max-attempts",
+		10,
+		"the number of attempts made to deliver an event to a webhook before moving it to the webhook's dead letters",
+	)
+
+	rootCmd.PersistentFlags().Int(
+		"graphql-max-cost",
+		1000,
+		"the cost budget of a query to the `/graphql` endpoint, the number of records it can load, which is also charged to the rate limit",
+	)
+
+	rootCmd.PersistentFlags().String(
+		"ingest-account-filter",
+		"",
+		"comma separated list of accounts, only the history of transactions involving one of these accounts (or of the ingest-asset-filter assets) is ingested",
+	)
+
+	rootCmd.PersistentFlags().String(
+		"ingest-asset-filter",
+		"",
+		"comma separated list of assets, either `native` or `CODE:ISSUER`, only the history of transactions involving one of these assets (or of the ingest-account-filter accounts) is ingested",
 	)
 
 	rootCmd.PersistentFlags().Uint(
@@ -211,7 +324,26 @@ func init() {
 		"the maximum number of assets on the path in `/paths` endpoint",
 	)
 
+	rootCmd.PersistentFlags().String(
+		"path-finder",
+		"simple",
+		"path finding implementation used by the `/paths` endpoint: `simple` queries stellar-core's database, `graph` searches an in-memory order book refreshed on every ledger",
+	)
+
+	rootCmd.PersistentFlags().Int(
+		"path-finder-timeout",
+		500,
+		"the maximum time (in milliseconds) spent searching for paths by the `graph` path finder, the paths found until then are returned",
+	)
+
+	rootCmd.PersistentFlags().Uint(
+		"path-finder-max-results",
+		20,
+		"the maximum number of paths returned for each source asset by the `graph` path finder",
+	)
+
 	rootCmd.AddCommand(dbCmd)
+	rootCmd.AddCommand(configCmd)
 
 	viper.BindPFlags(rootCmd.PersistentFlags())
 }
@@ -230,23 +362,13 @@ func initApp(cmd *cobra.Command, args []string) *horizon.App {
 }
 
 func initConfig() {
-	if viper.GetString("db-url") == "" {
-		stdLog.Fatal("Invalid config: db-url is blank.  Please specify --db-url on the command line or set the DATABASE_URL environment variable.")
-	}
-
-	if viper.GetString("stellar-core-db-url") == "" {
-		stdLog.Fatal("Invalid config: stellar-core-db-url is blank.  Please specify --stellar-core-db-url on the command line or set the STELLAR_CORE_DATABASE_URL environment variable.")
-	}
-
-	if viper.GetString("stellar-core-url") == "" {
-		stdLog.Fatal("Invalid config: stellar-core-url is blank.  Please specify --stellar-core-url on the command line or set the STELLAR_CORE_URL environment variable.")
-	}
-
-	if viper.GetString("network-passphrase") == "" {
-		stdLog.Fatal("Invalid config: network-passphrase is blank.  Please specify --network-passphrase on the command line or set the NETWORK_PASSPHRASE environment variable.")
+	var err error
+	config, err = newConfig()
+	if err != nil {
+		stdLog.Fatal(err)
 	}
 
-	migrationsToApplyUp := schema.GetMigrationsUp(viper.GetString("db-url"))
+	migrationsToApplyUp := schema.GetMigrationsUp(config.DatabaseURL)
 	if len(migrationsToApplyUp) > 0 {
 		stdLog.Printf(`There are %v migrations to apply in the "up" direction.`, len(migrationsToApplyUp))
 		stdLog.Printf("The necessary migrations are: %v", migrationsToApplyUp)
@@ -254,38 +376,61 @@ func initConfig() {
 		os.Exit(1)
 	}
 
-	nMigrationsDown := schema.GetNumMigrationsDown(viper.GetString("db-url"))
+	nMigrationsDown := schema.GetNumMigrationsDown(config.DatabaseURL)
 	if nMigrationsDown > 0 {
 		stdLog.Printf("A database migration DOWN to an earlier version of the schema is required to run this version (%v) of Horizon. Consult the Changelog (https://github.com/stellar/horizon/blob/master/CHANGELOG.md) for more information.", apkg.Version())
 		stdLog.Printf("In order to migrate the database DOWN, using the HIGHEST version number of Horizon you have installed (not this binary), run \"horizon db migrate down %v\".", nMigrationsDown)
 		os.Exit(1)
 	}
 
-	ll, err := logrus.ParseLevel(viper.GetString("log-level"))
-
-	if err != nil {
-		stdLog.Fatalf("Could not parse log-level: %v", viper.GetString("log-level"))
-	}
-
-	log.DefaultLogger.Level = ll
+	log.DefaultLogger.Level = config.LogLevel
 
-	lf := viper.GetString("log-file")
-	if lf != "" {
-		logFile, err := os.OpenFile(lf, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if config.LogFile != "" {
+		logFile, err := os.OpenFile(config.LogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 		if err == nil {
 			log.DefaultLogger.Logger.Out = logFile
 		} else {
 			stdLog.Fatal("Failed to log to file")
 		}
 	}
+}
+
+// newConfig builds horizon's config from the command line flags, the
+// environment variables and the config file, in that order of precedence.
+func newConfig() (horizon.Config, error) {
+	err := readConfigFile()
+	if err != nil {
+		return horizon.Config{}, err
+	}
+
+	if viper.GetString("db-url") == "" {
+		return horizon.Config{}, errors.New("Invalid config: db-url is blank.  Please specify --db-url on the command line or set the DATABASE_URL environment variable.")
+	}
+
+	if viper.GetString("stellar-core-db-url") == "" {
+		return horizon.Config{}, errors.New("Invalid config: stellar-core-db-url is blank.  Please specify --stellar-core-db-url on the command line or set the STELLAR_CORE_DATABASE_URL environment variable.")
+	}
+
+	if viper.GetString("stellar-core-url") == "" {
+		return horizon.Config{}, errors.New("Invalid config: stellar-core-url is blank.  Please specify --stellar-core-url on the command line or set the STELLAR_CORE_URL environment variable.")
+	}
+
+	if viper.GetString("network-passphrase") == "" {
+		return horizon.Config{}, errors.New("Invalid config: network-passphrase is blank.  Please specify --network-passphrase on the command line or set the NETWORK_PASSPHRASE environment variable.")
+	}
+
+	ll, err := logrus.ParseLevel(viper.GetString("log-level"))
+	if err != nil {
+		return horizon.Config{}, errors.Errorf("Could not parse log-level: %v", viper.GetString("log-level"))
+	}
 
 	cert, key := viper.GetString("tls-cert"), viper.GetString("tls-key")
 
 	switch {
 	case cert != "" && key == "":
-		stdLog.Fatal("Invalid TLS config: key not configured")
+		return horizon.Config{}, errors.New("Invalid TLS config: key not configured")
 	case cert == "" && key != "":
-		stdLog.Fatal("Invalid TLS config: cert not configured")
+		return horizon.Config{}, errors.New("Invalid TLS config: cert not configured")
 	}
 
 	var friendbotURL *url.URL
@@ -293,10 +438,29 @@ func initConfig() {
 	if friendbotURLString != "" {
 		friendbotURL, err = url.Parse(friendbotURLString)
 		if err != nil {
-			stdLog.Fatalf("Unable to parse URL: %s/%v", friendbotURLString, err)
+			return horizon.Config{}, errors.Errorf("Unable to parse URL: %s/%v", friendbotURLString, err)
 		}
 	}
 
+	switch viper.GetString("path-finder") {
+	case horizon.PathFinderSimple, horizon.PathFinderGraph:
+	default:
+		return horizon.Config{}, errors.Errorf("Invalid path-finder: %s", viper.GetString("path-finder"))
+	}
+
+	ingestFilter, err := ingest.NewFilter(
+		splitList(viper.GetString("ingest-account-filter")),
+		splitList(viper.GetString("ingest-asset-filter")),
+	)
+	if err != nil {
+		return horizon.Config{}, errors.Errorf("Invalid ingest filter: %v", err)
+	}
+
+	holderThreshold, err := amount.Parse(viper.GetString("asset-stats-holder-threshold"))
+	if err != nil {
+		return horizon.Config{}, errors.Errorf("Invalid asset-stats-holder-threshold: %v", err)
+	}
+
 	var rateLimit *throttled.RateQuota = nil
 	perHourRateLimit := viper.GetInt("per-hour-rate-limit")
 	if perHourRateLimit != 0 {
@@ -306,21 +470,31 @@ func initConfig() {
 		}
 	}
 
-	config = horizon.Config{
+	return horizon.Config{
 		DatabaseURL:            viper.GetString("db-url"),
 		StellarCoreDatabaseURL: viper.GetString("stellar-core-db-url"),
 		StellarCoreURL:         viper.GetString("stellar-core-url"),
+		HistoryReplicaURLs:     splitList(viper.GetString("db-replica-urls")),
+		CoreReplicaURLs:        splitList(viper.GetString("stellar-core-db-replica-urls")),
 		Port:                   viper.GetInt("port"),
 		MaxDBConnections:       viper.GetInt("max-db-connections"),
 		SSEUpdateFrequency:     time.Duration(viper.GetInt("sse-update-frequency")) * time.Second,
 		ConnectionTimeout:      time.Duration(viper.GetInt("connection-timeout")) * time.Second,
+		ShutdownTimeout:        time.Duration(viper.GetInt("shutdown-timeout")) * time.Second,
 		RateLimit:              rateLimit,
 		RateLimitRedisKey:      viper.GetString("rate-limit-redis-key"),
 		RedisURL:               viper.GetString("redis-url"),
+		APIKeysFile:            viper.GetString("api-keys-file"),
+		ResponseCacheSize:      viper.GetInt("response-cache-size"),
+		ResponseCacheRedis:     viper.GetBool("response-cache-redis"),
+		ResponseCacheMaxAge:    time.Duration(viper.GetInt("response-cache-max-age")) * time.Second,
 		FriendbotURL:           friendbotURL,
 		LogLevel:               ll,
-		LogFile:                lf,
+		LogFile:                viper.GetString("log-file"),
 		MaxPathLength:          uint(viper.GetInt("max-path-length")),
+		PathFinder:             viper.GetString("path-finder"),
+		PathFinderTimeout:      time.Duration(viper.GetInt("path-finder-timeout")) * time.Millisecond,
+		PathFinderMaxResults:   uint(viper.GetInt("path-finder-max-results")),
 		NetworkPassphrase:      viper.GetString("network-passphrase"),
 		SentryDSN:              viper.GetString("sentry-dsn"),
 		LogglyToken:            viper.GetString("loggly-token"),
@@ -329,8 +503,26 @@ func initConfig() {
 		TLSKey:                 key,
 		Ingest:                 viper.GetBool("ingest"),
 		HistoryRetentionCount:  uint(viper.GetInt("history-retention-count")),
+		ReaperArchiveURL:       viper.GetString("reaper-archive-url"),
 		StaleThreshold:         uint(viper.GetInt("history-stale-threshold")),
 		SkipCursorUpdate:       viper.GetBool("skip-cursor-update"),
-		EnableAssetStats:       viper.GetBool("enable-asset-stats"),
+		DisableAssetStats:      !viper.GetBool("enable-asset-stats"),
+		AssetHolderThreshold:   holderThreshold,
+		IngestFilter:           ingestFilter,
+		EnableWebhooks:         viper.GetBool("enable-webhooks"),
+		WebhookMaxAttempts:     int32(viper.GetInt("webhook-max-attempts")),
+		GraphQLMaxCost:         int32(viper.GetInt("graphql-max-cost")),
+	}, nil
+}
+
+// splitList splits a comma separated list, ignoring empty elements.
+func splitList(s string) []string {
+	var result []string
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			result = append(result, part)
+		}
 	}
+	return result
 }
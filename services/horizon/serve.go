@@ -10,6 +10,7 @@ var serveCmd = &cobra.Command{
 	Long:  "serve initializes then starts the horizon HTTP server",
 	Run: func(cmd *cobra.Command, args []string) {
 		initApp(cmd, args)
+		go reloadOnSIGHUP()
 		app.Serve()
 	},
 }
@@ -14,6 +14,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
@@ -94,6 +95,10 @@ type Session struct {
 	// Ctx is the optional context in which the repo is operating under.
 	Ctx context.Context
 
+	// ObserveQuery, if set, is called with the type ("select", "get", "exec"
+	// or "query") and the duration of every query run by the session.
+	ObserveQuery func(typ string, duration time.Duration)
+
 	tx *sqlx.Tx
 }
 
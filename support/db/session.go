@@ -35,8 +35,9 @@ func (s *Session) Begin() error {
 // source is currently within.
 func (s *Session) Clone() *Session {
 	return &Session{
-		DB:  s.DB,
-		Ctx: s.Ctx,
+		DB:           s.DB,
+		Ctx:          s.Ctx,
+		ObserveQuery: s.ObserveQuery,
 	}
 }
 
@@ -303,6 +304,10 @@ func (s *Session) conn() Conn {
 }
 
 func (s *Session) log(typ string, start time.Time, query string, args []interface{}) {
+	if s.ObserveQuery != nil {
+		s.ObserveQuery(typ, time.Since(start))
+	}
+
 	log.
 		Ctx(s.logCtx()).
 		WithField("args", args).
@@ -34,7 +34,7 @@ func (e *Entry) Ctx(ctx context.Context) *Entry {
 }
 
 func (e *Entry) SetLevel(level logrus.Level) {
-	e.Logger.Level = level
+	e.Logger.SetLevel(level)
 }
 
 // WithField creates a child logger annotated with the provided key value pair.